@@ -0,0 +1,149 @@
+// Package clog is a minimal structured logger that is safe for concurrent use.
+// Each entry is formatted into its own buffer and written with a single Write
+// under a mutex, so lines from different goroutines never interleave. The
+// level is atomic and can be changed while logging; a disabled call costs one
+// atomic load and takes no lock.
+//
+//	log := clog.New(os.Stdout, clog.LevelInfo)
+//	log.Info("request done", "status", 200, "took", time.Millisecond)
+//	// time=2024-01-02T03:04:05.000Z level=INFO msg="request done" status=200 took=1ms
+package clog
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Level is the severity of an entry; entries below the logger's level are dropped
+type Level int32
+
+// Levels in increasing severity
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = [...]string{"DEBUG", "INFO", "WARN", "ERROR"}
+
+// String returns the level name, e.g. "INFO"
+func (l Level) String() string {
+	if l >= 0 && int(l) < len(levelNames) {
+		return levelNames[l]
+	}
+	return "LEVEL(" + strconv.Itoa(int(l)) + ")"
+}
+
+// ParseLevel parses a level name, ignoring case
+func ParseLevel(s string) (Level, error) {
+	for i, name := range levelNames {
+		if strings.EqualFold(s, name) {
+			return Level(i), nil
+		}
+	}
+	return 0, fmt.Errorf("clog: unknown level %q", s)
+}
+
+// sink is shared by a Logger and every child created with With
+type sink struct {
+	mu    sync.Mutex // Serialises writes so lines stay whole
+	w     io.Writer
+	level int32
+	now   func() time.Time
+}
+
+// Logger writes leveled key-value entries in logfmt style
+type Logger struct {
+	sink   *sink
+	fields []byte // Pre-rendered " key=value" pairs added by With
+}
+
+// New creates a logger writing entries at level and above to w
+func New(w io.Writer, level Level) *Logger {
+	return &Logger{sink: &sink{w: w, level: int32(level), now: time.Now}}
+}
+
+// SetLevel changes the minimum level of this logger and all loggers derived from it
+func (l *Logger) SetLevel(level Level) {
+	atomic.StoreInt32(&l.sink.level, int32(level))
+}
+
+// Level returns the current minimum level
+func (l *Logger) Level() Level {
+	return Level(atomic.LoadInt32(&l.sink.level))
+}
+
+// Enabled reports whether entries at level would be written. Use it to skip
+// computing expensive values for disabled entries.
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.Level()
+}
+
+// With returns a logger that adds keyvals to every entry. It shares the
+// parent's writer and level.
+func (l *Logger) With(keyvals ...interface{}) *Logger {
+	fields := append([]byte(nil), l.fields...)
+	return &Logger{sink: l.sink, fields: appendKeyvals(fields, keyvals)}
+}
+
+// Debug logs at LevelDebug; keyvals are alternating keys and values
+func (l *Logger) Debug(msg string, keyvals ...interface{}) { l.Log(LevelDebug, msg, keyvals...) }
+
+// Info logs at LevelInfo
+func (l *Logger) Info(msg string, keyvals ...interface{}) { l.Log(LevelInfo, msg, keyvals...) }
+
+// Warn logs at LevelWarn
+func (l *Logger) Warn(msg string, keyvals ...interface{}) { l.Log(LevelWarn, msg, keyvals...) }
+
+// Error logs at LevelError
+func (l *Logger) Error(msg string, keyvals ...interface{}) { l.Log(LevelError, msg, keyvals...) }
+
+// Log writes one entry at level if it is enabled
+func (l *Logger) Log(level Level, msg string, keyvals ...interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+	buf := make([]byte, 0, 128)
+	buf = append(buf, "time="...)
+	buf = l.sink.now().UTC().AppendFormat(buf, "2006-01-02T15:04:05.000Z07:00")
+	buf = append(buf, " level="...)
+	buf = append(buf, level.String()...)
+	buf = append(buf, " msg="...)
+	buf = appendValue(buf, msg)
+	buf = append(buf, l.fields...)
+	buf = appendKeyvals(buf, keyvals)
+	buf = append(buf, '\n')
+
+	l.sink.mu.Lock()
+	l.sink.w.Write(buf)
+	l.sink.mu.Unlock()
+}
+
+// appendKeyvals renders pairs as " key=value"; a trailing key gets the value "<missing>"
+func appendKeyvals(buf []byte, keyvals []interface{}) []byte {
+	for i := 0; i < len(keyvals); i += 2 {
+		var v interface{} = "<missing>"
+		if i+1 < len(keyvals) {
+			v = keyvals[i+1]
+		}
+		buf = append(buf, ' ')
+		buf = append(buf, fmt.Sprint(keyvals[i])...)
+		buf = append(buf, '=')
+		buf = appendValue(buf, fmt.Sprint(v))
+	}
+	return buf
+}
+
+// appendValue quotes values that would otherwise be ambiguous to parse
+func appendValue(buf []byte, s string) []byte {
+	if s == "" || strings.ContainsAny(s, " =\"\t\r\n\\") {
+		return strconv.AppendQuote(buf, s)
+	}
+	return append(buf, s...)
+}
@@ -0,0 +1,111 @@
+package clog
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func newTestLogger(level Level) (*Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	l := New(&buf, level)
+	l.sink.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 6e6, time.UTC) }
+	return l, &buf
+}
+
+func TestLogger(t *testing.T) {
+	g := NewWithT(t)
+
+	l, buf := newTestLogger(LevelInfo)
+	l.Debug("hidden")
+	l.Info("request done", "status", 200, "took", 1500*time.Microsecond)
+	l.With("worker", 3).Error("failed", "err", errors.New("disk full"), "dangling")
+	l.Warn("", "empty", "")
+
+	g.Expect(buf.String()).To(Equal(
+		`time=2024-01-02T03:04:05.006Z level=INFO msg="request done" status=200 took=1.5ms` + "\n" +
+			`time=2024-01-02T03:04:05.006Z level=ERROR msg=failed worker=3 err="disk full" dangling=<missing>` + "\n" +
+			`time=2024-01-02T03:04:05.006Z level=WARN msg="" empty=""` + "\n"))
+}
+
+func TestSetLevel(t *testing.T) {
+	g := NewWithT(t)
+
+	l, buf := newTestLogger(LevelWarn)
+	child := l.With("component", "cache")
+	child.Info("dropped")
+	g.Expect(buf.Len()).To(BeZero())
+
+	// Lowering the parent's level applies to children too
+	l.SetLevel(LevelDebug)
+	g.Expect(child.Enabled(LevelDebug)).To(BeTrue())
+	child.Debug("kept")
+	g.Expect(buf.String()).To(ContainSubstring("level=DEBUG msg=kept component=cache"))
+}
+
+func TestParseLevel(t *testing.T) {
+	g := NewWithT(t)
+
+	for _, level := range []Level{LevelDebug, LevelInfo, LevelWarn, LevelError} {
+		parsed, err := ParseLevel(strings.ToLower(level.String()))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(parsed).To(Equal(level))
+	}
+	_, err := ParseLevel("verbose")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(Level(9).String()).To(Equal("LEVEL(9)"))
+}
+
+func TestConcurrentLinesStayWhole(t *testing.T) {
+	g := NewWithT(t)
+
+	l, buf := newTestLogger(LevelInfo)
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			log := l.With("worker", w)
+			for i := 0; i < 200; i++ {
+				log.Info("tick", "i", i)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	g.Expect(lines).To(HaveLen(1600))
+	for _, line := range lines {
+		g.Expect(line).To(MatchRegexp(`^time=\S+ level=INFO msg=tick worker=\d i=\d+$`))
+	}
+}
+
+func BenchmarkDisabled(b *testing.B) {
+	l, _ := newTestLogger(LevelInfo)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Debug("hidden", "i", 1)
+		}
+	})
+}
+
+func BenchmarkEnabled(b *testing.B) {
+	l, _ := newTestLogger(LevelInfo)
+	l.sink.w = discard{}
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Info("shown", "i", 1)
+		}
+	})
+}
+
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) { return len(p), nil }
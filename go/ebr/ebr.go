@@ -0,0 +1,138 @@
+// Package ebr implements epoch-based reclamation, a way for lock-free data
+// structures to reuse nodes safely. A global epoch advances only once every
+// goroutine inside a critical section has seen the current value. Anything
+// retired during epoch e was unlinked before those goroutines could have
+// picked it up afresh, so once the epoch reaches e+2 nobody can still hold it
+// and it is freed. Readers pay two atomic stores per critical section, however
+// many nodes they touch, where hazard pointers cost a store and a re-check per
+// node; in exchange one stalled reader holds up every free.
+package ebr
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// active marks a record's epoch as belonging to a goroutine in a critical section
+const active = 1
+
+// record is one participant's published epoch. Records are acquired for the
+// length of a critical section and reused, standing in for per-thread state.
+type record struct {
+	local int64 // epoch<<1 | active, or 0 when outside a critical section
+	owned int32
+	next  *record
+}
+
+// Domain is a set of participants sharing one global epoch and one set of
+// deferred frees
+type Domain struct {
+	epoch   int64
+	records atomic.Pointer[record] // Grows by prepending, never shrinks
+
+	mu    sync.Mutex
+	limbo [3][]func() // Frees deferred in each epoch, modulo 3
+	freed int64
+}
+
+// New creates a domain at epoch 0
+func New() *Domain {
+	return &Domain{}
+}
+
+// Guard is a critical section. Nodes reached from a shared structure inside
+// it stay valid until Exit.
+type Guard struct {
+	d   *Domain
+	rec *record
+}
+
+// Enter starts a critical section
+func (d *Domain) Enter() Guard {
+	rec := d.acquire()
+	for {
+		e := atomic.LoadInt64(&d.epoch)
+		atomic.StoreInt64(&rec.local, e<<1|active)
+		// If the epoch moved before the store was visible, announce the new one
+		if atomic.LoadInt64(&d.epoch) == e {
+			return Guard{d: d, rec: rec}
+		}
+	}
+}
+
+// Exit ends the critical section; g must not be used afterwards. It leaves
+// advancing the epoch to Defer, so a read-only section never takes the lock.
+func (g Guard) Exit() {
+	atomic.StoreInt64(&g.rec.local, 0)
+	atomic.StoreInt32(&g.rec.owned, 0)
+}
+
+// Defer schedules free to run once no critical section active now can still
+// be using what it frees. Call it after unlinking the object.
+func (g Guard) Defer(free func()) {
+	g.d.mu.Lock()
+	e := atomic.LoadInt64(&g.d.epoch)
+	g.d.limbo[e%3] = append(g.d.limbo[e%3], free)
+	g.d.mu.Unlock()
+	g.d.TryAdvance()
+}
+
+// TryAdvance moves the epoch on if every active participant has seen it,
+// running the frees deferred two epochs ago. It reports whether it advanced.
+func (d *Domain) TryAdvance() bool {
+	d.mu.Lock()
+	e := atomic.LoadInt64(&d.epoch)
+	for r := d.records.Load(); r != nil; r = r.next {
+		local := atomic.LoadInt64(&r.local)
+		if local&active != 0 && local>>1 != e {
+			d.mu.Unlock()
+			return false
+		}
+	}
+	atomic.StoreInt64(&d.epoch, e+1)
+	// Frees deferred in epoch e-1 are now two epochs old
+	slot := (e + 2) % 3
+	frees := d.limbo[slot]
+	d.limbo[slot] = nil
+	d.freed += int64(len(frees))
+	d.mu.Unlock()
+
+	for _, free := range frees {
+		free()
+	}
+	return true
+}
+
+// Epoch returns the global epoch
+func (d *Domain) Epoch() int64 {
+	return atomic.LoadInt64(&d.epoch)
+}
+
+// Freed returns the number of deferred frees run so far
+func (d *Domain) Freed() int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.freed
+}
+
+// Pending returns the number of deferred frees not yet run
+func (d *Domain) Pending() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.limbo[0]) + len(d.limbo[1]) + len(d.limbo[2])
+}
+
+func (d *Domain) acquire() *record {
+	for r := d.records.Load(); r != nil; r = r.next {
+		if atomic.CompareAndSwapInt32(&r.owned, 0, 1) {
+			return r
+		}
+	}
+	r := &record{owned: 1}
+	for {
+		r.next = d.records.Load()
+		if d.records.CompareAndSwap(r.next, r) {
+			return r
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package ebr
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestDeferredFreeWaitsForReaders(t *testing.T) {
+	g := NewWithT(t)
+	d := New()
+
+	// A reader that entered before the object was unlinked may still hold it
+	reader := d.Enter()
+	writer := d.Enter()
+	freed := false
+	writer.Defer(func() { freed = true })
+	writer.Exit()
+
+	for i := 0; i < 5; i++ {
+		d.TryAdvance()
+	}
+	g.Expect(freed).To(BeFalse())
+	g.Expect(d.Epoch()).To(Equal(int64(1)), "the reader pins the epoch it entered in, plus one")
+	g.Expect(d.Pending()).To(Equal(1))
+
+	// Once it leaves, the next advance, the second since the Defer, frees it
+	reader.Exit()
+	g.Expect(d.TryAdvance()).To(BeTrue())
+	g.Expect(freed).To(BeTrue())
+	g.Expect(d.Pending()).To(BeZero())
+	g.Expect(d.Freed()).To(Equal(int64(1)))
+}
+
+func TestNewReadersDoNotHoldOldFrees(t *testing.T) {
+	g := NewWithT(t)
+	d := New()
+
+	w := d.Enter()
+	freed := 0
+	w.Defer(func() { freed++ })
+	w.Exit()
+
+	// A reader entering after the unlink could not have reached the object,
+	// so it does not hold up the free
+	g.Expect(d.Epoch()).To(Equal(int64(1)))
+	late := d.Enter()
+	g.Expect(d.TryAdvance()).To(BeTrue())
+	g.Expect(freed).To(Equal(1))
+	late.Exit()
+}
+
+func TestRecordsAreReused(t *testing.T) {
+	g := NewWithT(t)
+	d := New()
+
+	for i := 0; i < 100; i++ {
+		d.Enter().Exit()
+	}
+	a, b := d.Enter(), d.Enter()
+	defer a.Exit()
+	defer b.Exit()
+	count := 0
+	for r := d.records.Load(); r != nil; r = r.next {
+		count++
+	}
+	g.Expect(count).To(Equal(2))
+}
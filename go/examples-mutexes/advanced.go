@@ -2,19 +2,29 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/camilbenameur/learning/go/clog"
+	"github.com/camilbenameur/learning/go/examples"
+	"github.com/camilbenameur/learning/go/stage"
 )
 
+// logger keeps lines from concurrent goroutines whole, unlike interleaved fmt.Printf calls
+var logger = clog.New(os.Stdout, clog.LevelInfo)
+
 // Example 1: sync.Once for lazy initialization
 type HeavyResource struct {
 	data string
 }
 
 func NewHeavyResource() *HeavyResource {
-	fmt.Println("  Initializing heavy resource (expensive operation)...")
+	logger.Info("initializing heavy resource")
 	time.Sleep(100 * time.Millisecond) // Simulate expensive initialization
 	return &HeavyResource{data: "initialized"}
 }
@@ -42,7 +52,7 @@ func demonstrateSyncOnce() {
 		go func(id int) {
 			defer wg.Done()
 			resource := service.GetResource()
-			fmt.Printf("Goroutine %d got resource: %s\n", id, resource.data)
+			logger.Info("got resource", "goroutine", id, "data", resource.data)
 		}(i)
 	}
 	
@@ -125,7 +135,7 @@ func (q *Queue) Enqueue(item int) {
 	defer q.mu.Unlock()
 	
 	q.items = append(q.items, item)
-	fmt.Printf("Enqueued %d, queue size: %d\n", item, len(q.items))
+	logger.Info("enqueued", "item", item, "size", len(q.items))
 	q.cond.Signal() // Wake one waiting goroutine
 }
 
@@ -134,16 +144,57 @@ func (q *Queue) Dequeue() int {
 	defer q.mu.Unlock()
 	
 	for len(q.items) == 0 {
-		fmt.Println("Queue empty, waiting...")
+		logger.Info("queue empty, waiting")
 		q.cond.Wait() // Atomically unlocks mu and waits
 	}
 	
 	item := q.items[0]
 	q.items = q.items[1:]
-	fmt.Printf("Dequeued %d, queue size: %d\n", item, len(q.items))
+	logger.Info("dequeued", "item", item, "size", len(q.items))
 	return item
 }
 
+// SignalQueue is Queue with the sync.Cond replaced by an examples.Signal.
+// Waiting on a channel instead of cond.Wait lets Dequeue give up when ctx ends,
+// which sync.Cond cannot do. Every Enqueue wakes all waiters; the losers
+// find the queue empty again and go back to waiting.
+type SignalQueue struct {
+	mu      sync.Mutex
+	changed examples.Signal
+	items   []int
+}
+
+func (q *SignalQueue) Enqueue(item int) {
+	q.mu.Lock()
+	q.items = append(q.items, item)
+	logger.Info("enqueued", "item", item, "size", len(q.items))
+	q.mu.Unlock()
+	q.changed.Broadcast()
+}
+
+func (q *SignalQueue) Dequeue(ctx context.Context) (int, error) {
+	for {
+		// Fetch the channel before checking, so an Enqueue in between still wakes us
+		changed := q.changed.Wait()
+		q.mu.Lock()
+		if len(q.items) > 0 {
+			item := q.items[0]
+			q.items = q.items[1:]
+			logger.Info("dequeued", "item", item, "size", len(q.items))
+			q.mu.Unlock()
+			return item, nil
+		}
+		q.mu.Unlock()
+		
+		logger.Info("queue empty, waiting")
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
+
 func demonstrateSyncCond() {
 	fmt.Println("\n=== sync.Cond for Producer-Consumer ===")
 	queue := NewQueue()
@@ -155,7 +206,7 @@ func demonstrateSyncCond() {
 		go func(id int) {
 			defer wg.Done()
 			item := queue.Dequeue()
-			fmt.Printf("Consumer %d received: %d\n", id, item)
+			logger.Info("received", "consumer", id, "item", item)
 		}(i)
 	}
 	
@@ -170,6 +221,34 @@ func demonstrateSyncCond() {
 	
 	wg.Wait()
 	fmt.Println("✓ sync.Cond enables efficient waiting for conditions")
+	
+	fmt.Println("\n=== Channel Signal for Producer-Consumer ===")
+	signalQueue := &SignalQueue{}
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	
+	// One more consumer than items: the last gives up when ctx ends
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			item, err := signalQueue.Dequeue(ctx)
+			if err != nil {
+				logger.Info("gave up", "consumer", id, "err", err)
+				return
+			}
+			logger.Info("received", "consumer", id, "item", item)
+		}(i)
+	}
+	
+	time.Sleep(100 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		signalQueue.Enqueue(i * 10)
+		time.Sleep(50 * time.Millisecond)
+	}
+	
+	wg.Wait()
+	fmt.Println("✓ A Signal channel can be selected with ctx.Done(), so waiters can time out")
 }
 
 // Example 4: Mutex vs Atomic operations
@@ -371,6 +450,33 @@ func demonstrateRCU() {
 	fmt.Println("  Best for read-heavy workloads with infrequent updates")
 }
 
+// Example 7: Pipelines built from stages instead of hand-wired goroutines
+func demonstratePipeline() {
+	fmt.Println("\n=== Pipeline Stages ===")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	words := stage.Source(ctx, "mutex", "", "atomic", "cond", "once", "")
+	// Fetching is slow, so it gets four goroutines; the rest need one each
+	fetched, fetchErrs := stage.Stage(ctx, words, func(ctx context.Context, w string) (string, error) {
+		if w == "" {
+			return "", fmt.Errorf("empty word")
+		}
+		time.Sleep(20 * time.Millisecond) // Simulate I/O
+		return w, nil
+	}, 4)
+	upper, upperErrs := stage.Stage(ctx, fetched, func(_ context.Context, w string) (string, error) {
+		return strings.ToUpper(w), nil
+	}, 1)
+
+	results, err := stage.Collect(ctx, upper, fetchErrs, upperErrs)
+	fmt.Printf("Processed %d words: %v\n", len(results), results)
+	if err != nil {
+		fmt.Printf("Errors: %v\n", strings.ReplaceAll(err.Error(), "\n", "; "))
+	}
+	fmt.Println("✓ Each stage owns its goroutines and closes its output when its input ends")
+}
+
 func main() {
 	fmt.Println("Advanced Mutex Patterns in Go")
 	fmt.Println("==============================\n")
@@ -381,12 +487,14 @@ func main() {
 	demonstrateMutexVsAtomic()
 	compareLazyInit()
 	demonstrateRCU()
+	demonstratePipeline()
 	
 	fmt.Println("✓ All advanced pattern examples completed successfully!")
 	fmt.Println("\nKey Takeaways:")
 	fmt.Println("1. sync.Once: Simplest way for one-time initialization")
 	fmt.Println("2. Try-lock: Non-blocking lock attempts")
-	fmt.Println("3. sync.Cond: Efficient waiting for conditions")
+	fmt.Println("3. sync.Cond: Efficient waiting for conditions; a closed-channel Signal when waits need a timeout")
 	fmt.Println("4. Atomics: Faster than mutexes for simple operations")
 	fmt.Println("5. RCU: Lock-free reads for read-heavy workloads")
+	fmt.Println("6. Pipelines: Bounded stages with context cancellation and error channels")
 }
@@ -3,10 +3,16 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"sync"
 	"time"
+
+	"github.com/camilbenameur/learning/go/clog"
 )
 
+// logger keeps lines from concurrent goroutines whole, unlike interleaved fmt.Printf calls
+var logger = clog.New(os.Stdout, clog.LevelInfo)
+
 // Counter demonstrates unsafe concurrent access
 type UnsafeCounter struct {
 	value int
@@ -86,10 +92,10 @@ func (a *BankAccount) Deposit(amount int) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	
-	fmt.Printf("[%s] Depositing %d, balance before: %d\n", a.holder, amount, a.balance)
+	logger.Info("depositing", "holder", a.holder, "amount", amount, "balance", a.balance)
 	time.Sleep(10 * time.Millisecond) // Simulate processing
 	a.balance += amount
-	fmt.Printf("[%s] Balance after: %d\n", a.holder, a.balance)
+	logger.Info("deposited", "holder", a.holder, "balance", a.balance)
 }
 
 func (a *BankAccount) Withdraw(amount int) bool {
@@ -97,13 +103,13 @@ func (a *BankAccount) Withdraw(amount int) bool {
 	defer a.mu.Unlock()
 	
 	if a.balance >= amount {
-		fmt.Printf("[%s] Withdrawing %d, balance before: %d\n", a.holder, amount, a.balance)
+		logger.Info("withdrawing", "holder", a.holder, "amount", amount, "balance", a.balance)
 		time.Sleep(10 * time.Millisecond) // Simulate processing
 		a.balance -= amount
-		fmt.Printf("[%s] Balance after: %d\n", a.holder, a.balance)
+		logger.Info("withdrew", "holder", a.holder, "balance", a.balance)
 		return true
 	}
-	fmt.Printf("[%s] Insufficient funds to withdraw %d\n", a.holder, amount)
+	logger.Warn("insufficient funds", "holder", a.holder, "amount", amount, "balance", a.balance)
 	return false
 }
 
@@ -2,11 +2,20 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/camilbenameur/learning/go/clog"
+	"github.com/camilbenameur/learning/go/examples"
 )
 
+// logger keeps lines from concurrent goroutines whole, unlike interleaved fmt.Printf calls
+var logger = clog.New(os.Stdout, clog.LevelInfo)
+
 // Example 1: Deadlock from circular lock ordering
 type Account struct {
 	mu      sync.Mutex
@@ -51,28 +60,38 @@ func demonstrateDeadlock() {
 	acc2 := &Account{id: 2, balance: 1000}
 	
 	fmt.Println("Attempting transfers with proper lock ordering...")
-	var wg sync.WaitGroup
+	tasks := examples.NewTaskGroup(nil)
 	
 	// These won't deadlock due to consistent ordering
-	wg.Add(2)
-	go func() {
-		defer wg.Done()
+	tasks.Go(func() error {
 		goodTransfer(acc1, acc2, 100)
-		fmt.Println("Transfer 1: acc1 -> acc2 completed")
-	}()
-	
-	go func() {
-		defer wg.Done()
+		logger.Info("transfer completed", "from", acc1.id, "to", acc2.id, "amount", 100)
+		return nil
+	})
+	tasks.Go(func() error {
 		goodTransfer(acc2, acc1, 50)
-		fmt.Println("Transfer 2: acc2 -> acc1 completed")
-	}()
+		logger.Info("transfer completed", "from", acc2.id, "to", acc1.id, "amount", 50)
+		return nil
+	})
 	
-	wg.Wait()
+	if err := tasks.WaitTimeout(time.Second); err != nil {
+		fmt.Println("Unexpected:", err)
+	}
 	fmt.Printf("Final balances: acc1=%d, acc2=%d\n", acc1.balance, acc2.balance)
 	fmt.Println("✓ No deadlock occurred due to consistent lock ordering")
 	
-	fmt.Println("\nNote: badTransfer() is commented out to prevent actual deadlock")
-	fmt.Println("Uncommenting it would cause the program to hang!")
+	// The same transfers with badTransfer: each goroutine holds one lock and
+	// waits forever for the other. WaitTimeout reports it instead of hanging;
+	// the two goroutines stay stuck until the program exits.
+	fmt.Println("\nAttempting transfers without lock ordering...")
+	acc3 := &Account{id: 3, balance: 1000}
+	acc4 := &Account{id: 4, balance: 1000}
+	tasks = examples.NewTaskGroup(nil)
+	tasks.Go(func() error { badTransfer(acc3, acc4, 100); return nil })
+	tasks.Go(func() error { badTransfer(acc4, acc3, 50); return nil })
+	if err := tasks.WaitTimeout(200 * time.Millisecond); errors.Is(err, examples.ErrWaitTimeout) {
+		fmt.Println("✗ Deadlock detected:", err)
+	}
 }
 
 // Example 2: Forgetting to unlock
@@ -329,6 +348,48 @@ func demonstrateContention() {
 	fmt.Println("✓ Sharding reduces lock contention and improves performance")
 }
 
+// Example 6: False sharing between per-goroutine counters
+func demonstrateFalseSharing() {
+	fmt.Println("\n=== False Sharing ===")
+	const goroutines, increments = 8, 1_000_000
+
+	// BAD: Each goroutine has its own counter, but they sit on the same cache line
+	packed := make([]int64, goroutines)
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for j := 0; j < increments; j++ {
+				atomic.AddInt64(&packed[id], 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+	packedDuration := time.Since(start)
+
+	// GOOD: Each counter fills its own cache line
+	padded := make([]examples.PaddedCounter, goroutines)
+	start = time.Now()
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for j := 0; j < increments; j++ {
+				padded[id].Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+	paddedDuration := time.Since(start)
+
+	fmt.Printf("Cache line size:          %d bytes\n", examples.CacheLineSize())
+	fmt.Printf("Packed counters (shared): %v\n", packedDuration)
+	fmt.Printf("Padded counters:          %v\n", paddedDuration)
+	fmt.Println("✓ Pad counters written by different goroutines onto separate cache lines")
+}
+
 func main() {
 	fmt.Println("Common Mutex Pitfalls in Go")
 	fmt.Println("============================\n")
@@ -338,6 +399,7 @@ func main() {
 	demonstrateBlockingWithLock()
 	demonstrateCopying()
 	demonstrateContention()
+	demonstrateFalseSharing()
 	
 	fmt.Println("✓ All pitfall examples completed successfully!")
 	fmt.Println("\nKey Takeaways:")
@@ -346,4 +408,5 @@ func main() {
 	fmt.Println("3. Don't hold locks during blocking operations")
 	fmt.Println("4. Never copy mutexes - use pointer receivers")
 	fmt.Println("5. Use sharding to reduce lock contention")
+	fmt.Println("6. Pad hot per-goroutine data to avoid false sharing")
 }
@@ -3,10 +3,17 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"sync"
 	"time"
+
+	"github.com/camilbenameur/learning/go/clog"
+	"github.com/camilbenameur/learning/go/examples"
 )
 
+// logger keeps lines from concurrent goroutines whole, unlike interleaved fmt.Printf calls
+var logger = clog.New(os.Stdout, clog.LevelInfo)
+
 // Cache demonstrates RWMutex for read-heavy workloads
 type Cache struct {
 	mu   sync.RWMutex
@@ -60,7 +67,7 @@ func demonstrateRWMutex() {
 				key := fmt.Sprintf("key%d", j%10)
 				if val, ok := cache.Get(key); ok {
 					if id == 0 && j == 0 {
-						fmt.Printf("Reader %d: Got %s=%s\n", id, key, val)
+						logger.Info("read", "reader", id, "key", key, "value", val)
 					}
 				}
 				time.Sleep(1 * time.Millisecond)
@@ -78,7 +85,7 @@ func demonstrateRWMutex() {
 				value := fmt.Sprintf("new-value%d-%d", id, j)
 				cache.Set(key, value)
 				if id == 0 {
-					fmt.Printf("Writer %d: Set %s=%s\n", id, key, value)
+					logger.Info("wrote", "writer", id, "key", key, "value", value)
 				}
 				time.Sleep(5 * time.Millisecond)
 			}
@@ -157,76 +164,58 @@ func demonstratePerformance() {
 	}
 }
 
-// StatsTracker demonstrates RWMutex for statistics
-type StatsTracker struct {
-	mu       sync.RWMutex
-	requests int64
-	errors   int64
-	latency  []time.Duration
-}
-
-func (s *StatsTracker) RecordRequest(duration time.Duration, isError bool) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	
-	s.requests++
-	if isError {
-		s.errors++
-	}
-	s.latency = append(s.latency, duration)
-}
-
-func (s *StatsTracker) GetStats() (requests, errors int64, avgLatency time.Duration) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	
-	requests = s.requests
-	errors = s.errors
-	
-	if len(s.latency) > 0 {
-		var total time.Duration
-		for _, lat := range s.latency {
-			total += lat
-		}
-		avgLatency = total / time.Duration(len(s.latency))
-	}
-	
-	return
-}
-
 func demonstrateStatsTracker() {
-	fmt.Println("\n=== Stats Tracker with RWMutex ===")
-	stats := &StatsTracker{}
-	var wg sync.WaitGroup
-	
-	// Simulate requests
-	for i := 0; i < 100; i++ {
-		wg.Add(1)
-		go func(id int) {
-			defer wg.Done()
-			duration := time.Duration(id%50) * time.Millisecond
-			isError := id%10 == 0
-			stats.RecordRequest(duration, isError)
-		}(i)
-	}
-	
-	// Periodic stats reading
-	for i := 0; i < 5; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			time.Sleep(10 * time.Millisecond)
-			requests, errors, avgLatency := stats.GetStats()
-			fmt.Printf("Stats snapshot: %d requests, %d errors, avg latency: %v\n", 
-				requests, errors, avgLatency)
-		}()
-	}
-	
-	wg.Wait()
+	fmt.Println("\n=== Stats Tracker ===")
+	// examples.StatsTracker started out here guarded by an RWMutex; its
+	// GetStats now reads under a sequence lock so pollers never block writers
+	stats := examples.NewStatsTracker(examples.StatsTrackerOptions{SampleSize: 1000})
+	metrics := &examples.Metrics{}
+	
+	// Per-interval reports replace polling GetStats from sleeping goroutines;
+	// SwapAndReset means each request is counted in exactly one interval
+	reporter := examples.NewReporter(metrics, 10*time.Millisecond, nil, func(s examples.MetricsSnapshot) {
+		fmt.Printf("Interval %v: %d requests, %d errors\n", 
+			s.Interval.Round(time.Millisecond), s.Counters[examples.CounterRequests], s.Counters[examples.CounterErrors])
+	})
+	
+	// The load and the reporter run as a group: when the load finishes, the
+	// reporter is interrupted, and Stop flushes the last partial interval
+	var group examples.RunGroup
+	stopReporting := make(chan struct{})
+	group.Add(func() error {
+		reporter.Start()
+		<-stopReporting
+		reporter.Stop()
+		return nil
+	}, func(error) {
+		close(stopReporting)
+	})
+	group.Add(func() error {
+		// Simulate requests
+		var wg sync.WaitGroup
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func(id int) {
+				defer wg.Done()
+				duration := time.Duration(id%50) * time.Millisecond
+				isError := id%10 == 0
+				time.Sleep(duration)
+				stats.RecordRequest(duration, isError)
+				metrics.RecordRequest()
+				if isError {
+					metrics.RecordError()
+				}
+			}(i)
+		}
+		wg.Wait()
+		return nil
+	}, func(error) {})
+	group.Run()
 	
 	requests, errors, avgLatency := stats.GetStats()
 	fmt.Printf("\nFinal stats: %d requests, %d errors, avg latency: %v\n", 
 		requests, errors, avgLatency)
+	fmt.Printf("Sampled latency: p50=%v p99=%v\n", stats.Percentile(0.5), stats.Percentile(0.99))
 }
 
 func main() {
@@ -0,0 +1,76 @@
+package examples
+
+import "sync/atomic"
+
+// ABAStack is an intrusive lock-free stack of slot indexes, the structure at
+// the heart of a free list: Pop takes a slot off the top and Push puts a given
+// slot back. It exists to show the ABA problem and its fix side by side.
+//
+// Untagged, the head holds just the top slot and Pop's CAS compares only
+// that. If Pop reads top A and its next B, then stalls while others pop A and
+// B and push A back, the head is A again and the stale CAS succeeds: it makes
+// B the top although B is in use, so B is later handed out twice. Tagged, the
+// head also carries a version bumped on every change, as FreeList's does, and
+// the stale CAS fails because A came back with a new version.
+type ABAStack struct {
+	next   []uint32 // Index plus one of the slot below each slot; accessed atomically
+	head   uint64   // Tagged reference to the top slot; the generation stays 0 when untagged
+	tagged bool
+
+	// BeforeCAS, if set, is called inside Pop between reading the top slot's
+	// next and the CAS, the window in which ABA strikes. Tests use it to run
+	// other operations at exactly that point instead of hoping the scheduler
+	// interleaves them; it must not be changed while the stack is in use.
+	BeforeCAS func()
+}
+
+// NewABAStack creates a stack holding slots 0 to capacity-1, 0 on top. With
+// tagged false it is the naive version that ABA corrupts.
+func NewABAStack(capacity int, tagged bool) *ABAStack {
+	s := &ABAStack{next: make([]uint32, capacity), tagged: tagged}
+	for i := 0; i+1 < capacity; i++ {
+		s.next[i] = uint32(i + 2)
+	}
+	if capacity > 0 {
+		s.head = packTagged(0, 1)
+	}
+	return s
+}
+
+// Pop removes the top slot and returns it, or reports false if none is left
+func (s *ABAStack) Pop() (int, bool) {
+	for {
+		head := atomic.LoadUint64(&s.head)
+		gen, ref := unpackTagged(head)
+		if ref == 0 {
+			return 0, false
+		}
+		next := atomic.LoadUint32(&s.next[ref-1])
+		if s.BeforeCAS != nil {
+			s.BeforeCAS()
+		}
+		if atomic.CompareAndSwapUint64(&s.head, head, packTagged(s.bump(gen), next)) {
+			return int(ref - 1), true
+		}
+	}
+}
+
+// Push puts slot i back on top. i must have come from Pop.
+func (s *ABAStack) Push(i int) {
+	for {
+		head := atomic.LoadUint64(&s.head)
+		gen, ref := unpackTagged(head)
+		atomic.StoreUint32(&s.next[i], ref)
+		if atomic.CompareAndSwapUint64(&s.head, head, packTagged(s.bump(gen), uint32(i+1))) {
+			return
+		}
+	}
+}
+
+// bump returns the next head generation: gen+1 when tagged, else always 0
+func (s *ABAStack) bump(gen uint32) uint32 {
+	if s.tagged {
+		return gen + 1
+	}
+	return 0
+}
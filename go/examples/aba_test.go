@@ -0,0 +1,78 @@
+package examples
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// interleavedPops runs the ABA interleaving on a fresh stack of slots 0, 1
+// and 2: X starts a Pop and reads top 0 with next 1, then, inside the CAS
+// window, Y pops 0 and 1 and pushes 0 back. X's Pop then completes, and one
+// more Pop runs. It returns the slots X and that last Pop got, and Y's slot 1,
+// which Y still holds.
+func interleavedPops(tagged bool) (x, after, yHeld int) {
+	s := NewABAStack(3, tagged)
+	s.BeforeCAS = func() {
+		s.BeforeCAS = nil // Y runs once, inside X's first attempt only
+		a, _ := s.Pop()
+		yHeld, _ = s.Pop()
+		s.Push(a)
+	}
+	x, _ = s.Pop()
+	after, _ = s.Pop()
+	return x, after, yHeld
+}
+
+func TestABAStackNaiveCorrupts(t *testing.T) {
+	g := NewWithT(t)
+
+	// X's stale CAS sees slot 0 on top again and succeeds, making slot 1 the
+	// top although Y holds it, so the next Pop hands slot 1 out a second time
+	x, after, yHeld := interleavedPops(false)
+	g.Expect(x).To(Equal(0))
+	g.Expect(yHeld).To(Equal(1))
+	g.Expect(after).To(Equal(yHeld))
+}
+
+func TestABAStackTaggedIsSafe(t *testing.T) {
+	g := NewWithT(t)
+
+	// Slot 0 came back with a new generation, so X's CAS fails and X retries
+	// against the real top, and the next Pop gets the untouched slot 2
+	x, after, yHeld := interleavedPops(true)
+	g.Expect(x).To(Equal(0))
+	g.Expect(yHeld).To(Equal(1))
+	g.Expect(after).To(Equal(2))
+}
+
+func TestABAStackTaggedStress(t *testing.T) {
+	g := NewWithT(t)
+
+	const slots = 8
+	s := NewABAStack(slots, true)
+	var holders [slots]atomic.Int32
+	var doubled atomic.Int64
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 5000; i++ {
+				slot, ok := s.Pop()
+				if !ok {
+					continue
+				}
+				if holders[slot].Add(1) != 1 {
+					doubled.Add(1)
+				}
+				holders[slot].Add(-1)
+				s.Push(slot)
+			}
+		}()
+	}
+	wg.Wait()
+	g.Expect(doubled.Load()).To(BeZero())
+}
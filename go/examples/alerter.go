@@ -0,0 +1,227 @@
+package examples
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AlertRule fires when a value computed over a trailing window of metrics
+// crosses Threshold, and resolves only once it is back past Recover. Keeping
+// Recover short of Threshold (hysteresis) stops a value hovering around the
+// threshold from firing and resolving on every evaluation.
+type AlertRule struct {
+	Name string
+
+	// Value computes the watched quantity from the change in metrics over Window,
+	// e.g. MetricsSnapshot.ErrorRatio for "error ratio over 1m"
+	Value  func(window MetricsSnapshot) float64
+	Window time.Duration
+
+	Threshold float64
+	Recover   *float64 // Nil means Threshold, i.e. no hysteresis
+	Below     bool     // Fire when Value drops below Threshold instead of rising above it
+}
+
+// firing reports whether v should trip the rule
+func (r AlertRule) firing(v float64) bool {
+	if r.Below {
+		return v < r.Threshold
+	}
+	return v > r.Threshold
+}
+
+// recovered reports whether v is far enough back to resolve the rule
+func (r AlertRule) recovered(v float64) bool {
+	if r.Below {
+		return v >= *r.Recover
+	}
+	return v <= *r.Recover
+}
+
+// Alert is the state of a rule passed to the Alerter callbacks
+type Alert struct {
+	Rule   string
+	Value  float64
+	Since  time.Time // When the rule last fired
+	Firing bool
+}
+
+// DefaultAlertInterval is how often Start evaluates when AlerterOptions.Interval is not positive
+const DefaultAlertInterval = 10 * time.Second
+
+// AlerterOptions configures an Alerter
+type AlerterOptions struct {
+	Interval  time.Duration // How often Start evaluates the rules; defaults to DefaultAlertInterval
+	Clock     Clock         // RealClock if nil
+	OnFire    func(Alert)   // Optional; called when a rule starts firing
+	OnResolve func(Alert)   // Optional; called when a firing rule recovers
+}
+
+// Alerter evaluates threshold rules against a Metrics. Each evaluation keeps
+// the cumulative snapshot it took, so a rule's value is computed from the Diff
+// between now and the snapshot one Window ago; the Metrics itself is never
+// reset. Callbacks run on the evaluating goroutine, outside the Alerter's lock.
+type Alerter struct {
+	metrics *Metrics
+	opts    AlerterOptions
+
+	mu      sync.Mutex
+	rules   []*alertState
+	history []MetricsSnapshot // Oldest first, pruned to the longest Window
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	done      chan struct{}
+	wg        sync.WaitGroup
+}
+
+type alertState struct {
+	rule  AlertRule
+	alert Alert
+}
+
+// NewAlerter creates an alerter for m; call Start to evaluate periodically or Evaluate to evaluate once
+func NewAlerter(m *Metrics, opts AlerterOptions) *Alerter {
+	if opts.Clock == nil {
+		opts.Clock = RealClock
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultAlertInterval
+	}
+	return &Alerter{metrics: m, opts: opts, done: make(chan struct{})}
+}
+
+// AddRule registers a rule. Names must be unique, and Recover must not be on
+// the firing side of Threshold.
+func (a *Alerter) AddRule(rule AlertRule) error {
+	if rule.Value == nil {
+		return errors.New("alert rule needs a Value function")
+	}
+	level := rule.Threshold
+	if rule.Recover != nil {
+		level = *rule.Recover
+	}
+	rule.Recover = &level // Our own copy, so the caller cannot change it later
+	if rule.firing(level) {
+		return fmt.Errorf("alert rule %s: recover level %v would still fire", rule.Name, level)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, s := range a.rules {
+		if s.rule.Name == rule.Name {
+			return fmt.Errorf("alert rule %s already exists", rule.Name)
+		}
+	}
+	a.rules = append(a.rules, &alertState{rule: rule, alert: Alert{Rule: rule.Name}})
+	return nil
+}
+
+// Evaluate takes a snapshot now and checks every rule against it.
+// The first evaluation only records a baseline.
+func (a *Alerter) Evaluate() {
+	var fired, resolved []Alert
+	a.mu.Lock()
+	// Snapshot under the lock, so concurrent calls append history in time order
+	s := a.metrics.Snapshot()
+	s.Timestamp = a.opts.Clock.Now()
+	if len(a.history) > 0 {
+		for _, st := range a.rules {
+			v := st.rule.Value(s.Diff(a.baseLocked(s.Timestamp.Add(-st.rule.Window))))
+			st.alert.Value = v
+			switch {
+			case !st.alert.Firing && st.rule.firing(v):
+				st.alert.Firing = true
+				st.alert.Since = s.Timestamp
+				fired = append(fired, st.alert)
+			case st.alert.Firing && st.rule.recovered(v):
+				st.alert.Firing = false
+				resolved = append(resolved, st.alert)
+			}
+		}
+	}
+	a.history = append(a.history, s)
+	a.pruneLocked(s.Timestamp)
+	a.mu.Unlock()
+
+	for _, alert := range fired {
+		if a.opts.OnFire != nil {
+			a.opts.OnFire(alert)
+		}
+	}
+	for _, alert := range resolved {
+		if a.opts.OnResolve != nil {
+			a.opts.OnResolve(alert)
+		}
+	}
+}
+
+// baseLocked returns the newest snapshot taken at or before t, or the oldest
+// one if history does not reach back that far yet
+func (a *Alerter) baseLocked(t time.Time) MetricsSnapshot {
+	i := sort.Search(len(a.history), func(i int) bool { return a.history[i].Timestamp.After(t) })
+	if i == 0 {
+		return a.history[0]
+	}
+	return a.history[i-1]
+}
+
+// pruneLocked drops snapshots no rule can use as a base any more
+func (a *Alerter) pruneLocked(now time.Time) {
+	var longest time.Duration
+	for _, st := range a.rules {
+		if st.rule.Window > longest {
+			longest = st.rule.Window
+		}
+	}
+	// Keep the newest snapshot at or before the longest window as its base
+	cutoff := now.Add(-longest)
+	drop := sort.Search(len(a.history), func(i int) bool { return a.history[i].Timestamp.After(cutoff) }) - 1
+	if drop > 0 {
+		a.history = append(a.history[:0], a.history[drop:]...)
+	}
+}
+
+// Firing returns the rules currently firing, sorted by name
+func (a *Alerter) Firing() []Alert {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var alerts []Alert
+	for _, st := range a.rules {
+		if st.alert.Firing {
+			alerts = append(alerts, st.alert)
+		}
+	}
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].Rule < alerts[j].Rule })
+	return alerts
+}
+
+// Start takes a baseline and evaluates every Interval in the background; later calls do nothing
+func (a *Alerter) Start() {
+	a.startOnce.Do(func() {
+		a.Evaluate()
+		ticker := a.opts.Clock.NewTicker(a.opts.Interval)
+		a.wg.Add(1)
+		go func() {
+			defer a.wg.Done()
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C():
+					a.Evaluate()
+				case <-a.done:
+					return
+				}
+			}
+		}()
+	})
+}
+
+// Stop halts periodic evaluation
+func (a *Alerter) Stop() {
+	a.stopOnce.Do(func() { close(a.done) })
+	a.wg.Wait()
+}
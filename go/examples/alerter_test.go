@@ -0,0 +1,255 @@
+package examples
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// alertRecorder collects Alerter callbacks as "fire rule value"/"resolve rule value"
+type alertRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *alertRecorder) options(interval time.Duration, clock Clock) AlerterOptions {
+	record := func(kind string) func(Alert) {
+		return func(a Alert) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.events = append(r.events, fmt.Sprintf("%s %s %.2f", kind, a.Rule, a.Value))
+		}
+	}
+	return AlerterOptions{Interval: interval, Clock: clock, OnFire: record("fire"), OnResolve: record("resolve")}
+}
+
+func (r *alertRecorder) Events() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+// recordTraffic records requests, of which errors failed
+func recordTraffic(m *Metrics, requests, errors int) {
+	for i := 0; i < requests; i++ {
+		m.RecordRequest()
+		if i < errors {
+			m.RecordError()
+		}
+	}
+}
+
+func TestAlerterHysteresis(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	m := &Metrics{}
+	var rec alertRecorder
+	a := NewAlerter(m, rec.options(10*time.Second, clock))
+	recoverAt := 0.02
+	g.Expect(a.AddRule(AlertRule{
+		Name:      "errors",
+		Value:     MetricsSnapshot.ErrorRatio,
+		Window:    10 * time.Second,
+		Threshold: 0.05,
+		Recover:   &recoverAt,
+	})).To(Succeed())
+
+	a.Evaluate() // Baseline
+	step := func(requests, errors int) {
+		recordTraffic(m, requests, errors)
+		clock.Advance(10 * time.Second)
+		a.Evaluate()
+	}
+
+	step(100, 10) // 10% fires
+	step(100, 4)  // 4% is below the threshold but not recovered
+	step(100, 6)  // Still firing: no second callback
+	step(100, 1)  // 1% recovers
+	step(100, 4)  // 4% is not enough to fire again
+
+	g.Expect(rec.Events()).To(Equal([]string{"fire errors 0.10", "resolve errors 0.01"}))
+	g.Expect(a.Firing()).To(BeEmpty())
+}
+
+// TestAlerterRecoverAtZero checks that an explicit Recover of 0 is kept
+// rather than read as "unset" and replaced by Threshold
+func TestAlerterRecoverAtZero(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	m := &Metrics{}
+	var rec alertRecorder
+	a := NewAlerter(m, rec.options(10*time.Second, clock))
+	zero := 0.0
+	g.Expect(a.AddRule(AlertRule{
+		Name:      "errors",
+		Value:     MetricsSnapshot.ErrorRatio,
+		Window:    10 * time.Second,
+		Threshold: 0.05,
+		Recover:   &zero,
+	})).To(Succeed())
+	zero = 1 // The rule keeps its own copy
+
+	a.Evaluate() // Baseline
+	step := func(requests, errors int) {
+		recordTraffic(m, requests, errors)
+		clock.Advance(10 * time.Second)
+		a.Evaluate()
+	}
+
+	step(100, 10) // 10% fires
+	step(100, 1)  // 1% is under the threshold but not yet 0
+	step(100, 0)  // Back to 0 recovers
+
+	g.Expect(rec.Events()).To(Equal([]string{"fire errors 0.10", "resolve errors 0.00"}))
+}
+
+func TestAlerterWindow(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	m := &Metrics{}
+	a := NewAlerter(m, AlerterOptions{Clock: clock})
+	g.Expect(a.AddRule(AlertRule{
+		Name:      "error ratio over 1m",
+		Value:     MetricsSnapshot.ErrorRatio,
+		Window:    time.Minute,
+		Threshold: 0.05,
+	})).To(Succeed())
+	g.Expect(a.AddRule(AlertRule{
+		Name:      "low traffic over 10s",
+		Value:     func(s MetricsSnapshot) float64 { return s.Rate(CounterRequests) },
+		Window:    10 * time.Second,
+		Threshold: 1,
+		Below:     true,
+	})).To(Succeed())
+
+	a.Evaluate()
+	// One bad burst, then a minute of clean traffic at 10 requests a second
+	recordTraffic(m, 100, 50)
+	for i := 0; i < 6; i++ {
+		clock.Advance(10 * time.Second)
+		a.Evaluate()
+		g.Expect(a.Firing()).To(HaveLen(1), "tick %d", i)
+		g.Expect(a.Firing()[0].Rule).To(Equal("error ratio over 1m"))
+		recordTraffic(m, 100, 0)
+	}
+	// The burst has left the one minute window
+	clock.Advance(10 * time.Second)
+	a.Evaluate()
+	g.Expect(a.Firing()).To(BeEmpty())
+
+	// Traffic stops; the 10s rate drops to zero
+	clock.Advance(10 * time.Second)
+	a.Evaluate()
+	firing := a.Firing()
+	g.Expect(firing).To(HaveLen(1))
+	g.Expect(firing[0]).To(Equal(Alert{Rule: "low traffic over 10s", Value: 0, Since: clock.Now(), Firing: true}))
+
+	// History only reaches back as far as the longest window needs
+	a.mu.Lock()
+	g.Expect(len(a.history)).To(BeNumerically("<=", 8))
+	a.mu.Unlock()
+}
+
+func TestAlerterAddRuleValidation(t *testing.T) {
+	g := NewWithT(t)
+
+	a := NewAlerter(&Metrics{}, AlerterOptions{})
+	rule := AlertRule{Name: "r", Value: MetricsSnapshot.ErrorRatio, Threshold: 0.1}
+	g.Expect(a.AddRule(rule)).To(Succeed())
+	g.Expect(a.AddRule(rule)).To(MatchError(ContainSubstring("already exists")))
+	g.Expect(a.AddRule(AlertRule{Name: "nil"})).To(HaveOccurred())
+	up, down := 0.2, 0.8
+	g.Expect(a.AddRule(AlertRule{Name: "up", Value: MetricsSnapshot.ErrorRatio, Threshold: 0.1, Recover: &up})).
+		To(MatchError(ContainSubstring("would still fire")))
+	g.Expect(a.AddRule(AlertRule{Name: "down", Value: MetricsSnapshot.ErrorRatio, Threshold: 0.9, Recover: &down, Below: true})).
+		To(MatchError(ContainSubstring("would still fire")))
+}
+
+// yieldingClock yields right after reading the time, widening the gap in
+// which another goroutine could read a later time and record it first
+type yieldingClock struct{ Clock }
+
+func (c yieldingClock) Now() time.Time {
+	defer runtime.Gosched()
+	return c.Clock.Now()
+}
+
+// TestAlerterConcurrentEvaluate runs Evaluate from several goroutines, as
+// Start's ticker and a manual call can, and checks history stays in time
+// order for baseLocked and pruneLocked to search
+func TestAlerterConcurrentEvaluate(t *testing.T) {
+	g := NewWithT(t)
+
+	a := NewAlerter(&Metrics{}, AlerterOptions{Clock: yieldingClock{RealClock}})
+	g.Expect(a.AddRule(AlertRule{Name: "r", Value: MetricsSnapshot.ErrorRatio, Window: time.Hour, Threshold: 0.5})).To(Succeed())
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				a.Evaluate()
+			}
+		}()
+	}
+	wg.Wait()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	g.Expect(a.history).To(HaveLen(1600))
+	for i := 1; i < len(a.history); i++ {
+		g.Expect(a.history[i].Timestamp.Before(a.history[i-1].Timestamp)).To(BeFalse(), "history out of order at %d", i)
+	}
+}
+
+func TestAlerterStart(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	m := &Metrics{}
+	var rec alertRecorder
+	a := NewAlerter(m, rec.options(time.Second, clock))
+	g.Expect(a.AddRule(AlertRule{Name: "errors", Value: MetricsSnapshot.ErrorRatio, Window: time.Second, Threshold: 0.5})).To(Succeed())
+	a.Start()
+	defer a.Stop()
+	clock.BlockUntil(1)
+
+	recordTraffic(m, 10, 10)
+	clock.Advance(time.Second)
+	g.Eventually(rec.Events).Should(Equal([]string{"fire errors 1.00"}))
+}
+
+// TestAlerterDefaultInterval checks that a zero Interval starts a ticker at
+// DefaultAlertInterval instead of panicking
+func TestAlerterDefaultInterval(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	m := &Metrics{}
+	var rec alertRecorder
+	a := NewAlerter(m, rec.options(0, clock))
+	g.Expect(a.AddRule(AlertRule{Name: "errors", Value: MetricsSnapshot.ErrorRatio, Window: time.Second, Threshold: 0.5})).To(Succeed())
+	g.Expect(a.Start).NotTo(Panic())
+	defer a.Stop()
+	clock.BlockUntil(1)
+
+	recordTraffic(m, 10, 10)
+	clock.Advance(DefaultAlertInterval)
+	g.Eventually(rec.Events).Should(Equal([]string{"fire errors 1.00"}))
+}
+
+func TestMetricsSnapshotErrorRatio(t *testing.T) {
+	g := NewWithT(t)
+
+	m := &Metrics{}
+	g.Expect(m.Snapshot().ErrorRatio()).To(Equal(0.0))
+	recordTraffic(m, 4, 1)
+	g.Expect(m.Snapshot().ErrorRatio()).To(Equal(0.25))
+}
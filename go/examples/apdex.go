@@ -0,0 +1,76 @@
+package examples
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Apdex scores how satisfying response times are against a target T.
+// Requests within T are satisfied, within 4T tolerating and anything slower
+// (or failed) frustrated; the score is (satisfied + tolerating/2) / total,
+// from 0 for all frustrated to 1 for all satisfied. Each zone is one atomic
+// counter, so recording never locks.
+type Apdex struct {
+	threshold  time.Duration
+	satisfied  int64
+	tolerating int64
+	frustrated int64
+}
+
+// ApdexSnapshot is a point-in-time copy of an Apdex
+type ApdexSnapshot struct {
+	Threshold  time.Duration `json:"threshold"`
+	Satisfied  int64         `json:"satisfied"`
+	Tolerating int64         `json:"tolerating"`
+	Frustrated int64         `json:"frustrated"`
+	Score      float64       `json:"score"`
+}
+
+// NewApdex creates an Apdex with satisfied target threshold
+func NewApdex(threshold time.Duration) *Apdex {
+	return &Apdex{threshold: threshold}
+}
+
+// Observe classifies one response time
+func (a *Apdex) Observe(d time.Duration) {
+	switch {
+	case d <= a.threshold:
+		atomic.AddInt64(&a.satisfied, 1)
+	case d <= 4*a.threshold:
+		atomic.AddInt64(&a.tolerating, 1)
+	default:
+		atomic.AddInt64(&a.frustrated, 1)
+	}
+}
+
+// ObserveFrustrated counts a request that failed, however fast it was
+func (a *Apdex) ObserveFrustrated() {
+	atomic.AddInt64(&a.frustrated, 1)
+}
+
+// Score returns the current Apdex score, or 1 if nothing was observed yet
+func (a *Apdex) Score() float64 {
+	return a.Snapshot().Score
+}
+
+// Snapshot copies the zone counts and computes the score from them
+func (a *Apdex) Snapshot() ApdexSnapshot {
+	s := ApdexSnapshot{
+		Threshold:  a.threshold,
+		Satisfied:  atomic.LoadInt64(&a.satisfied),
+		Tolerating: atomic.LoadInt64(&a.tolerating),
+		Frustrated: atomic.LoadInt64(&a.frustrated),
+		Score:      1,
+	}
+	if total := s.Satisfied + s.Tolerating + s.Frustrated; total > 0 {
+		s.Score = (float64(s.Satisfied) + float64(s.Tolerating)/2) / float64(total)
+	}
+	return s
+}
+
+// Reset zeroes every zone
+func (a *Apdex) Reset() {
+	atomic.StoreInt64(&a.satisfied, 0)
+	atomic.StoreInt64(&a.tolerating, 0)
+	atomic.StoreInt64(&a.frustrated, 0)
+}
@@ -0,0 +1,60 @@
+package examples
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestApdex(t *testing.T) {
+	g := NewWithT(t)
+
+	a := NewApdex(100 * time.Millisecond)
+	g.Expect(a.Score()).To(Equal(1.0))
+
+	// Zone edges are inclusive: exactly T is satisfied, exactly 4T tolerating
+	for _, d := range []time.Duration{
+		10 * time.Millisecond, 100 * time.Millisecond, // satisfied
+		101 * time.Millisecond, 400 * time.Millisecond, // tolerating
+		401 * time.Millisecond, // frustrated
+	} {
+		a.Observe(d)
+	}
+	a.ObserveFrustrated()
+
+	g.Expect(a.Snapshot()).To(Equal(ApdexSnapshot{
+		Threshold:  100 * time.Millisecond,
+		Satisfied:  2,
+		Tolerating: 2,
+		Frustrated: 2,
+		Score:      0.5,
+	}))
+
+	a.Reset()
+	g.Expect(a.Snapshot().Satisfied).To(BeZero())
+	g.Expect(a.Score()).To(Equal(1.0))
+}
+
+func TestApdexConcurrent(t *testing.T) {
+	g := NewWithT(t)
+
+	a := NewApdex(time.Millisecond)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				a.Observe(time.Duration(j%4) * time.Millisecond) // 0,1 satisfied; 2,3 tolerating
+			}
+		}()
+	}
+	wg.Wait()
+
+	s := a.Snapshot()
+	g.Expect(s.Satisfied).To(Equal(int64(5000)))
+	g.Expect(s.Tolerating).To(Equal(int64(5000)))
+	g.Expect(s.Score).To(Equal(0.75))
+}
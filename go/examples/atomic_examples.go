@@ -1,8 +1,10 @@
 package examples
 
 import (
+	"math"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // AtomicCounter demonstrates atomic operations for thread-safe counting
@@ -35,6 +37,33 @@ func (c *AtomicCounter) CompareAndSet(old, new int64) bool {
 	return atomic.CompareAndSwapInt64(&c.value, old, new)
 }
 
+// AtomicMax raises *addr to v if v is larger, retrying the CAS until it wins
+// or sees a value at least as large. Returns true if it stored v.
+func AtomicMax(addr *int64, v int64) bool {
+	for {
+		old := atomic.LoadInt64(addr)
+		if v <= old {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(addr, old, v) {
+			return true
+		}
+	}
+}
+
+// AtomicMin lowers *addr to v if v is smaller; the mirror of AtomicMax
+func AtomicMin(addr *int64, v int64) bool {
+	for {
+		old := atomic.LoadInt64(addr)
+		if v >= old {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(addr, old, v) {
+			return true
+		}
+	}
+}
+
 // AtomicConfig demonstrates atomic.Value for configuration hot-reload
 type AtomicConfig struct {
 	config atomic.Value
@@ -96,7 +125,7 @@ func (f *AtomicFlag) Toggle() bool {
 
 // ReferenceCounter demonstrates atomic reference counting
 type ReferenceCounter struct {
-	refs  int32
+	refs   int32
 	onZero func()
 }
 
@@ -155,8 +184,18 @@ type Metrics struct {
 	requests   int64
 	errors     int64
 	totalBytes int64
+
+	latencyOnce  sync.Once
+	latency      *AtomicHistogram
+	latencyRange latencyRange
+	histograms   sync.Map // name -> *AtomicHistogram for durations other than latency
+
+	gauges sync.Map // name -> *uint64 holding float64 bits
 }
 
+// DefaultLatencyBounds are histogram buckets from 50µs growing 1.5x up to about an hour
+var DefaultLatencyBounds = ExponentialBounds(int64(50*time.Microsecond), 1.5, 36)
+
 // RecordRequest atomically increments the request counter
 func (m *Metrics) RecordRequest() {
 	atomic.AddInt64(&m.requests, 1)
@@ -172,12 +211,52 @@ func (m *Metrics) RecordBytes(bytes int64) {
 	atomic.AddInt64(&m.totalBytes, bytes)
 }
 
-// GetSnapshot returns a snapshot of current metrics
+// RecordLatency records a request duration in the latency histogram and its min/max
+func (m *Metrics) RecordLatency(d time.Duration) {
+	m.latencyHistogram().Observe(int64(d))
+	m.latencyRange.observe(int64(d))
+}
+
+// Percentiles estimates latency quantiles (e.g. 0.5, 0.95, 0.99) from the histogram.
+// Memory use is fixed by the bucket count, not by how many latencies were recorded.
+func (m *Metrics) Percentiles(qs ...float64) map[float64]time.Duration {
+	snap := m.latencyHistogram().Snapshot()
+	result := make(map[float64]time.Duration, len(qs))
+	for _, q := range qs {
+		result[q] = time.Duration(snap.Quantile(q))
+	}
+	return result
+}
+
+func (m *Metrics) latencyHistogram() *AtomicHistogram {
+	m.latencyOnce.Do(func() {
+		m.latency = NewAtomicHistogram(DefaultLatencyBounds)
+		m.latencyRange.reset()
+	})
+	return m.latency
+}
+
+// SetGauge atomically sets a named gauge to value
+func (m *Metrics) SetGauge(name string, value float64) {
+	bits, _ := m.gauges.LoadOrStore(name, new(uint64))
+	atomic.StoreUint64(bits.(*uint64), math.Float64bits(value))
+}
+
+// Gauge returns the current value of a named gauge, or 0 if it was never set
+func (m *Metrics) Gauge(name string) float64 {
+	bits, ok := m.gauges.Load(name)
+	if !ok {
+		return 0
+	}
+	return math.Float64frombits(atomic.LoadUint64(bits.(*uint64)))
+}
+
+// GetSnapshot returns the three core counters.
+//
+// Deprecated: use Snapshot, which also carries gauges and histograms.
 func (m *Metrics) GetSnapshot() (requests, errors, totalBytes int64) {
-	requests = atomic.LoadInt64(&m.requests)
-	errors = atomic.LoadInt64(&m.errors)
-	totalBytes = atomic.LoadInt64(&m.totalBytes)
-	return
+	s := m.Snapshot()
+	return s.Counters[CounterRequests], s.Counters[CounterErrors], s.Counters[CounterBytes]
 }
 
 // Reset atomically resets all metrics to zero
@@ -185,6 +264,12 @@ func (m *Metrics) Reset() {
 	atomic.StoreInt64(&m.requests, 0)
 	atomic.StoreInt64(&m.errors, 0)
 	atomic.StoreInt64(&m.totalBytes, 0)
+	m.latencyHistogram().Reset()
+	m.latencyRange.reset()
+	m.histograms.Range(func(_, h interface{}) bool {
+		h.(*AtomicHistogram).Reset()
+		return true
+	})
 }
 
 // Worker demonstrates using atomic operations for worker coordination
@@ -193,6 +278,7 @@ type Worker struct {
 	processed  int64
 	workQueue  chan int
 	stopSignal chan struct{}
+	heartbeat  *Heartbeat
 }
 
 // NewWorker creates a new worker
@@ -203,6 +289,14 @@ func NewWorker() *Worker {
 	}
 }
 
+// WithHeartbeat makes the worker beat h after every item and at h's interval
+// while idle, so a Monitor can tell a stuck or stopped worker from an idle one.
+// Call it before Start.
+func (w *Worker) WithHeartbeat(h *Heartbeat) *Worker {
+	w.heartbeat = h
+	return w
+}
+
 // Start starts the worker
 func (w *Worker) Start() {
 	if atomic.CompareAndSwapInt32(&w.running, 0, 1) {
@@ -239,12 +333,24 @@ func (w *Worker) Submit(work int) {
 }
 
 func (w *Worker) run() {
+	var tick <-chan time.Time // Nil, and never ready, without a heartbeat
+	if w.heartbeat != nil {
+		ticker := w.heartbeat.clock.NewTicker(w.heartbeat.interval)
+		defer ticker.Stop()
+		tick = ticker.C()
+		w.heartbeat.Beat()
+	}
 	for {
 		select {
 		case work := <-w.workQueue:
 			// Process work
 			_ = work
 			atomic.AddInt64(&w.processed, 1)
+			if w.heartbeat != nil {
+				w.heartbeat.Beat()
+			}
+		case <-tick:
+			w.heartbeat.Beat()
 		case <-w.stopSignal:
 			return
 		}
@@ -255,27 +361,60 @@ func (w *Worker) run() {
 type SafeMap struct {
 	m    sync.Map
 	size int64
+
+	// snapMu is held shared by writers and exclusively by Snapshot,
+	// so snapshots see no writes in progress while writers never block each other
+	snapMu sync.RWMutex
+
+	counters cacheCounters
 }
 
-// Set stores a key-value pair
+// Set stores a key-value pair, inserting or overwriting atomically
 func (sm *SafeMap) Set(key string, value interface{}) {
-	// Check if key exists first
-	_, exists := sm.m.Load(key)
-	// Store the value (overwrites if exists)
-	sm.m.Store(key, value)
-	// Only increment size if key didn't exist before
-	if !exists {
+	sm.snapMu.RLock()
+	defer sm.snapMu.RUnlock()
+
+	// Fast path: the key is new and LoadOrStore inserted it
+	if _, loaded := sm.m.LoadOrStore(key, value); !loaded {
 		atomic.AddInt64(&sm.size, 1)
+		return
 	}
+	// Key existed: overwrite it. Swap reports whether the key was still
+	// present, so a Delete racing in between is counted correctly.
+	if _, loaded := sm.m.Swap(key, value); !loaded {
+		atomic.AddInt64(&sm.size, 1)
+	}
+}
+
+// SetIfAbsent stores the value only if the key is not present.
+// Returns true if the value was stored.
+func (sm *SafeMap) SetIfAbsent(key string, value interface{}) bool {
+	sm.snapMu.RLock()
+	defer sm.snapMu.RUnlock()
+
+	if _, loaded := sm.m.LoadOrStore(key, value); loaded {
+		return false
+	}
+	atomic.AddInt64(&sm.size, 1)
+	return true
 }
 
 // Get retrieves a value by key
 func (sm *SafeMap) Get(key string) (interface{}, bool) {
-	return sm.m.Load(key)
+	val, ok := sm.m.Load(key)
+	if ok {
+		sm.counters.hit()
+	} else {
+		sm.counters.miss()
+	}
+	return val, ok
 }
 
 // Delete removes a key
 func (sm *SafeMap) Delete(key string) {
+	sm.snapMu.RLock()
+	defer sm.snapMu.RUnlock()
+
 	_, loaded := sm.m.LoadAndDelete(key)
 	if loaded {
 		atomic.AddInt64(&sm.size, -1)
@@ -286,3 +425,8 @@ func (sm *SafeMap) Delete(key string) {
 func (sm *SafeMap) Size() int64 {
 	return atomic.LoadInt64(&sm.size)
 }
+
+// CacheStats returns hit/miss counters and the current size
+func (sm *SafeMap) CacheStats() CacheStats {
+	return sm.counters.snapshot(sm.Size())
+}
@@ -70,6 +70,34 @@ func TestAtomicCounterConcurrency(t *testing.T) {
 	g.Expect(counter.Get()).To(Equal(int64(100000)))
 }
 
+func TestAtomicMaxMin(t *testing.T) {
+	g := NewWithT(t)
+
+	var max, min int64 = 0, 1000
+	done := make(chan bool)
+
+	// Racing CAS loops must still settle on the true extremes
+	for i := 0; i < 100; i++ {
+		go func(i int) {
+			for j := 0; j < 100; j++ {
+				v := int64(i*100 + j)
+				AtomicMax(&max, v)
+				AtomicMin(&min, v)
+			}
+			done <- true
+		}(i)
+	}
+	for i := 0; i < 100; i++ {
+		<-done
+	}
+
+	g.Expect(max).To(Equal(int64(9999)))
+	g.Expect(min).To(Equal(int64(0)))
+	g.Expect(AtomicMax(&max, 5)).To(BeFalse())
+	g.Expect(AtomicMin(&min, 5)).To(BeFalse())
+	g.Expect(AtomicMax(&max, 10000)).To(BeTrue())
+}
+
 func TestAtomicConfig(t *testing.T) {
 	g := NewWithT(t)
 
@@ -391,6 +419,67 @@ func TestSafeMapConcurrency(t *testing.T) {
 	g.Expect(sm.Size()).To(BeNumerically("<=", int64(100)))
 }
 
+func TestSafeMapSetIfAbsent(t *testing.T) {
+	g := NewWithT(t)
+
+	sm := &SafeMap{}
+
+	// First insert succeeds
+	g.Expect(sm.SetIfAbsent("key1", "value1")).To(BeTrue())
+	g.Expect(sm.Size()).To(Equal(int64(1)))
+
+	// Second insert is rejected and keeps the original value
+	g.Expect(sm.SetIfAbsent("key1", "value2")).To(BeFalse())
+	g.Expect(sm.Size()).To(Equal(int64(1)))
+
+	val, exists := sm.Get("key1")
+	g.Expect(exists).To(BeTrue())
+	g.Expect(val).To(Equal("value1"))
+
+	// After delete the key can be inserted again
+	sm.Delete("key1")
+	g.Expect(sm.SetIfAbsent("key1", "value3")).To(BeTrue())
+	g.Expect(sm.Size()).To(Equal(int64(1)))
+}
+
+func TestSafeMapSizeStress(t *testing.T) {
+	g := NewWithT(t)
+
+	sm := &SafeMap{}
+	done := make(chan bool)
+
+	// Many goroutines hammer a small key space with inserts,
+	// overwrites and deletes so that every operation races
+	for i := 0; i < 50; i++ {
+		go func(id int) {
+			for j := 0; j < 2000; j++ {
+				key := fmt.Sprintf("key_%d", j%16)
+				switch (id + j) % 3 {
+				case 0:
+					sm.Set(key, j)
+				case 1:
+					sm.SetIfAbsent(key, j)
+				case 2:
+					sm.Delete(key)
+				}
+			}
+			done <- true
+		}(i)
+	}
+
+	for i := 0; i < 50; i++ {
+		<-done
+	}
+
+	// Size must match the real number of entries exactly
+	var actual int64
+	sm.m.Range(func(_, _ interface{}) bool {
+		actual++
+		return true
+	})
+	g.Expect(sm.Size()).To(Equal(actual))
+}
+
 func TestGomegaMatcherExamples(t *testing.T) {
 	g := NewWithT(t)
 
@@ -0,0 +1,115 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrBarrierBroken is returned by Barrier.Await when another party gave up
+// waiting, so the generation can never trip, and by every Await after that
+// until Reset
+var ErrBarrierBroken = errors.New("barrier: broken")
+
+// Barrier is a cyclic barrier: parties goroutines call Await, and all are
+// released together once the last one arrives. It then resets for the next
+// round, so a fixed group of workers can use one Barrier between phases.
+//
+// If one party's ctx ends while it waits, the others would wait forever, so
+// the barrier breaks instead: every party waiting in that round, and every
+// later Await, gets ErrBarrierBroken until Reset.
+type Barrier struct {
+	mu      sync.Mutex
+	parties int
+	action  func()
+	count   int         // Parties arrived in the current generation
+	gen     *barrierGen // Current generation
+}
+
+type barrierGen struct {
+	done   chan struct{} // Closed when the generation trips or breaks
+	broken bool
+}
+
+// NewBarrier creates a barrier for parties goroutines. If action is not nil,
+// the last party to arrive runs it before anyone is released, e.g. to merge
+// the phase's results; it must not call Await.
+func NewBarrier(parties int, action func()) *Barrier {
+	return &Barrier{parties: parties, action: action, gen: &barrierGen{done: make(chan struct{})}}
+}
+
+// Await waits until all parties have called it, then returns the caller's
+// arrival index in this round: 0 for the first, parties-1 for the last, which
+// also ran the action. If ctx ends first, the barrier breaks and Await
+// returns ctx.Err(); if another party broke it, Await returns ErrBarrierBroken.
+func (b *Barrier) Await(ctx context.Context) (int, error) {
+	b.mu.Lock()
+	g := b.gen
+	if g.broken {
+		b.mu.Unlock()
+		return 0, ErrBarrierBroken
+	}
+	arrival := b.count
+	b.count++
+	if b.count == b.parties {
+		if b.action != nil {
+			b.action()
+		}
+		close(g.done)
+		b.nextLocked()
+		b.mu.Unlock()
+		return arrival, nil
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-g.done:
+	case <-ctx.Done():
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if g == b.gen && !g.broken {
+			b.breakLocked()
+			return arrival, ctx.Err()
+		}
+		// The round tripped or broke while ctx was ending: report that instead
+	}
+	if g.broken {
+		return arrival, ErrBarrierBroken
+	}
+	return arrival, nil
+}
+
+// Reset breaks the current round, releasing its waiters with
+// ErrBarrierBroken, and starts a fresh one, repairing a broken barrier
+func (b *Barrier) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.gen.broken && b.count > 0 {
+		b.breakLocked()
+	}
+	b.nextLocked()
+}
+
+// Broken reports whether the barrier is broken
+func (b *Barrier) Broken() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.gen.broken
+}
+
+// Waiting returns the number of parties waiting in the current round
+func (b *Barrier) Waiting() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.count
+}
+
+func (b *Barrier) breakLocked() {
+	b.gen.broken = true
+	close(b.gen.done)
+}
+
+func (b *Barrier) nextLocked() {
+	b.count = 0
+	b.gen = &barrierGen{done: make(chan struct{})}
+}
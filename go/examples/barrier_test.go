@@ -0,0 +1,115 @@
+package examples
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestBarrierReuse(t *testing.T) {
+	g := NewWithT(t)
+
+	const parties, rounds = 4, 5
+	var actions int
+	var phase [parties]int // Rounds each worker has passed
+	var lagging int        // Workers the action found a round behind
+	b := NewBarrier(parties, func() {
+		actions++
+		for _, p := range phase {
+			if p != actions-1 {
+				lagging++
+			}
+		}
+	})
+
+	var wg sync.WaitGroup
+	arrivals := make(chan int, parties*rounds)
+	for w := 0; w < parties; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for r := 0; r < rounds; r++ {
+				arrival, err := b.Await(context.Background())
+				if err != nil {
+					return
+				}
+				arrivals <- arrival
+				phase[w]++
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(arrivals)
+
+	// The action runs once per round, after every worker has passed the round before
+	g.Expect(actions).To(Equal(rounds))
+	g.Expect(lagging).To(BeZero())
+	g.Expect(phase).To(Equal([parties]int{rounds, rounds, rounds, rounds}))
+	var got []int
+	for a := range arrivals {
+		got = append(got, a)
+	}
+	sort.Ints(got)
+	// Each round hands out arrival indexes 0 to parties-1 once
+	for i, a := range got {
+		g.Expect(a).To(Equal(i / rounds))
+	}
+	g.Expect(b.Waiting()).To(BeZero())
+}
+
+func TestBarrierCancellationBreaks(t *testing.T) {
+	g := NewWithT(t)
+
+	b := NewBarrier(3, nil)
+	other := make(chan error, 1)
+	go func() {
+		_, err := b.Await(context.Background())
+		other <- err
+	}()
+	g.Eventually(b.Waiting).Should(Equal(1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := b.Await(ctx)
+	g.Expect(err).To(MatchError(context.DeadlineExceeded))
+
+	// The party already waiting is released with the broken error, and so is
+	// anyone who arrives later
+	g.Eventually(other).Should(Receive(MatchError(ErrBarrierBroken)))
+	g.Expect(b.Broken()).To(BeTrue())
+	_, err = b.Await(context.Background())
+	g.Expect(err).To(MatchError(ErrBarrierBroken))
+
+	// Reset repairs it for a full new round
+	b.Reset()
+	g.Expect(b.Broken()).To(BeFalse())
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			_, err := b.Await(context.Background())
+			errs <- err
+		}()
+	}
+	for i := 0; i < 3; i++ {
+		g.Eventually(errs).Should(Receive(BeNil()))
+	}
+}
+
+func TestBarrierResetReleasesWaiters(t *testing.T) {
+	g := NewWithT(t)
+
+	b := NewBarrier(2, nil)
+	waiter := make(chan error, 1)
+	go func() {
+		_, err := b.Await(context.Background())
+		waiter <- err
+	}()
+	g.Eventually(b.Waiting).Should(Equal(1))
+	b.Reset()
+	g.Eventually(waiter).Should(Receive(MatchError(ErrBarrierBroken)))
+	g.Expect(b.Broken()).To(BeFalse())
+}
@@ -0,0 +1,69 @@
+package examples
+
+import (
+	"context"
+	"time"
+)
+
+// Collect groups values from in into batches, emitting a batch once it holds
+// maxSize values or maxWait after its first value arrived, whichever comes
+// first, so a consumer that writes in bulk gets full batches under load and
+// bounded latency when traffic is light. When in closes the partial batch is
+// flushed and the output closes; when ctx is done the output closes and any
+// partial batch is dropped. clock drives the deadline (RealClock if nil).
+func Collect[T any](ctx context.Context, in <-chan T, maxSize int, maxWait time.Duration, clock Clock) <-chan []T {
+	if maxSize < 1 {
+		maxSize = 1
+	}
+	if clock == nil {
+		clock = RealClock
+	}
+	out := make(chan []T)
+	go func() {
+		defer close(out)
+		var batch []T
+		var timer Timer
+		var deadline <-chan time.Time // Nil, so never ready, while the batch is empty
+		flush := func() bool {
+			if timer != nil {
+				timer.Stop()
+				timer, deadline = nil, nil
+			}
+			b := batch
+			batch = nil
+			select {
+			case out <- b:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					if len(batch) > 0 {
+						flush()
+					}
+					return
+				}
+				batch = append(batch, v)
+				if len(batch) == 1 && maxSize > 1 {
+					timer = clock.NewTimer(maxWait)
+					deadline = timer.C()
+				}
+				if len(batch) == maxSize && !flush() {
+					return
+				}
+			case <-deadline:
+				timer, deadline = nil, nil
+				if !flush() {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
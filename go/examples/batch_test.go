@@ -0,0 +1,58 @@
+package examples
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCollectBySize(t *testing.T) {
+	g := NewWithT(t)
+
+	// No clock movement at all: only the size trigger and the final flush fire
+	clock := NewFakeClock(time.Unix(0, 0))
+	out := Collect(context.Background(), Generator(context.Background(), 1, 2, 3, 4, 5, 6, 7), 3, time.Second, clock)
+	g.Expect(drain(out)).To(Equal([][]int{{1, 2, 3}, {4, 5, 6}, {7}}))
+	g.Expect(clock.Waiters()).To(BeZero())
+}
+
+func TestCollectByDeadline(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := &countingClock{FakeClock: NewFakeClock(time.Unix(0, 0))}
+	in := make(chan int)
+	out := Collect(context.Background(), in, 10, 100*time.Millisecond, clock)
+
+	// The deadline runs from a batch's first value, not its latest
+	clock.send(g, in, 1)
+	clock.Advance(60 * time.Millisecond)
+	in <- 2
+	g.Consistently(out).ShouldNot(Receive())
+	clock.Advance(40 * time.Millisecond)
+	g.Eventually(out).Should(Receive(Equal([]int{1, 2})))
+
+	// An idle period emits nothing, however long
+	clock.Advance(time.Second)
+	g.Consistently(out).ShouldNot(Receive())
+
+	clock.send(g, in, 3)
+	clock.Advance(100 * time.Millisecond)
+	g.Eventually(out).Should(Receive(Equal([]int{3})))
+
+	close(in)
+	g.Eventually(out).Should(BeClosed())
+}
+
+func TestCollectCancellation(t *testing.T) {
+	g := NewWithT(t)
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := Collect(ctx, make(chan int), 10, time.Hour, nil)
+	cancel()
+	g.Eventually(out).Should(BeClosed())
+	g.Eventually(runtime.NumGoroutine, time.Second).Should(BeNumerically("<=", before))
+}
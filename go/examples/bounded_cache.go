@@ -0,0 +1,309 @@
+package examples
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// EvictionReason explains why an entry left a cache
+type EvictionReason int
+
+const (
+	// EvictCapacity means the entry was dropped to respect MaxEntries or MaxBytes
+	EvictCapacity EvictionReason = iota
+	// EvictExpired means the entry outlived its TTL
+	EvictExpired
+	// EvictDeleted means the entry was removed explicitly
+	EvictDeleted
+)
+
+func (r EvictionReason) String() string {
+	switch r {
+	case EvictCapacity:
+		return "capacity"
+	case EvictExpired:
+		return "expired"
+	case EvictDeleted:
+		return "deleted"
+	default:
+		return "unknown"
+	}
+}
+
+// CacheOptions configures bounding and eviction for BoundedCache.
+// Zero values disable the corresponding limit.
+type CacheOptions struct {
+	MaxEntries int
+	MaxBytes   int64
+	TTL        time.Duration
+
+	// SizeOf estimates the cost of an entry for MaxBytes (defaults to defaultSizeOf)
+	SizeOf func(key string, value interface{}) int64
+
+	// OnEvict is called after an entry is removed, outside of any lock
+	OnEvict func(key string, value interface{}, reason EvictionReason)
+}
+
+// cacheStore is the storage used by the cache types; SafeMap is unbounded,
+// BoundedCache enforces limits
+type cacheStore interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{})
+	Delete(key string)
+	Size() int64
+	CacheStats() CacheStats
+}
+
+type boundedEntry struct {
+	key     string
+	value   interface{}
+	size    int64
+	expires time.Time
+	byAge   *list.Element // The entry's place in BoundedCache.deadlines, when TTL is set
+}
+
+type eviction struct {
+	key    string
+	value  interface{}
+	reason EvictionReason
+}
+
+// BoundedCache is an LRU cache bounded by entry count and/or bytes, with optional TTL.
+// A single mutex guards the map and recency list; eviction callbacks run after it is released.
+//
+// Expiry has its own list in deadline order. Every entry lives for the same
+// TTL from its last Set, so appending on each Set keeps that list sorted, and
+// purging expired entries only visits the ones that have expired.
+type BoundedCache struct {
+	opts CacheOptions
+	now  func() time.Time
+
+	mu        sync.Mutex
+	items     map[string]*list.Element
+	order     *list.List // Front is most recently used
+	deadlines *list.List // Of *boundedEntry; front expires first
+	bytes     int64
+
+	counters cacheCounters
+}
+
+// NewBoundedCache creates a cache that enforces opts
+func NewBoundedCache(opts CacheOptions) *BoundedCache {
+	if opts.SizeOf == nil {
+		opts.SizeOf = defaultSizeOf
+	}
+	return &BoundedCache{
+		opts:      opts,
+		now:       time.Now,
+		items:     make(map[string]*list.Element),
+		order:     list.New(),
+		deadlines: list.New(),
+	}
+}
+
+// defaultSizeOf counts key bytes plus string/[]byte payloads, or 8 bytes for anything else
+func defaultSizeOf(key string, value interface{}) int64 {
+	switch v := value.(type) {
+	case string:
+		return int64(len(key) + len(v))
+	case []byte:
+		return int64(len(key) + len(v))
+	default:
+		return int64(len(key) + 8)
+	}
+}
+
+// Get returns the value for key and marks it as recently used
+func (c *BoundedCache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	elem, ok := c.items[key]
+	if !ok {
+		c.mu.Unlock()
+		c.counters.miss()
+		return nil, false
+	}
+	entry := elem.Value.(*boundedEntry)
+	if c.expired(entry) {
+		c.removeElement(elem)
+		c.mu.Unlock()
+		c.counters.miss()
+		c.notify([]eviction{{entry.key, entry.value, EvictExpired}})
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	c.mu.Unlock()
+	c.counters.hit()
+	return entry.value, true
+}
+
+// Set inserts or replaces key, evicting least recently used entries as needed
+func (c *BoundedCache) Set(key string, value interface{}) {
+	c.replace(key, value)
+}
+
+// replace is Set, also returning the value it replaced, expired or not
+func (c *BoundedCache) replace(key string, value interface{}) (old interface{}, replaced bool) {
+	entry := &boundedEntry{
+		key:   key,
+		value: value,
+		size:  c.opts.SizeOf(key, value),
+	}
+
+	c.mu.Lock()
+	if c.opts.TTL > 0 {
+		// Read the clock under the lock, so deadlines is appended in expiry order
+		entry.expires = c.now().Add(c.opts.TTL)
+	}
+	if elem, ok := c.items[key]; ok {
+		// Replacing a value is not an eviction
+		prev := elem.Value.(*boundedEntry)
+		c.bytes -= prev.size
+		if prev.byAge != nil {
+			c.deadlines.Remove(prev.byAge)
+		}
+		old, replaced = prev.value, true
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+	} else {
+		c.items[key] = c.order.PushFront(entry)
+	}
+	if c.opts.TTL > 0 {
+		entry.byAge = c.deadlines.PushBack(entry)
+	}
+	c.bytes += entry.size
+	evicted := c.enforceLimits()
+	c.mu.Unlock()
+
+	c.notify(evicted)
+	return old, replaced
+}
+
+// Delete removes key, reporting EvictDeleted to OnEvict
+func (c *BoundedCache) Delete(key string) {
+	c.mu.Lock()
+	elem, ok := c.items[key]
+	if !ok {
+		c.mu.Unlock()
+		return
+	}
+	entry := elem.Value.(*boundedEntry)
+	c.removeElement(elem)
+	c.mu.Unlock()
+
+	c.notify([]eviction{{entry.key, entry.value, EvictDeleted}})
+}
+
+// Peek returns the value for key without counting a hit or miss or marking
+// it as recently used, for housekeeping that should not skew either
+func (c *BoundedCache) Peek(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.items[key]
+	if !ok || c.expired(elem.Value.(*boundedEntry)) {
+		return nil, false
+	}
+	return elem.Value.(*boundedEntry).value, true
+}
+
+// CompareAndDelete removes key only if its value is still old, reporting
+// EvictDeleted to OnEvict, so a caller that decided to drop a value it read
+// cannot drop a newer one written since. old must be comparable; pointers
+// compare by identity.
+func (c *BoundedCache) CompareAndDelete(key string, old interface{}) bool {
+	c.mu.Lock()
+	elem, ok := c.items[key]
+	if !ok || elem.Value.(*boundedEntry).value != old {
+		c.mu.Unlock()
+		return false
+	}
+	entry := elem.Value.(*boundedEntry)
+	c.removeElement(elem)
+	c.mu.Unlock()
+
+	c.notify([]eviction{{entry.key, entry.value, EvictDeleted}})
+	return true
+}
+
+// Size returns the number of entries, including expired ones not yet purged
+func (c *BoundedCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return int64(len(c.items))
+}
+
+// Bytes returns the total SizeOf of all entries
+func (c *BoundedCache) Bytes() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bytes
+}
+
+// enforceLimits drops expired entries, oldest deadline first, then LRU entries
+// until within bounds.
+// Must be called with c.mu held.
+func (c *BoundedCache) enforceLimits() []eviction {
+	var evicted []eviction
+
+	for front := c.deadlines.Front(); front != nil; front = c.deadlines.Front() {
+		entry := front.Value.(*boundedEntry)
+		if !c.expired(entry) {
+			break
+		}
+		c.removeElement(c.items[entry.key])
+		evicted = append(evicted, eviction{entry.key, entry.value, EvictExpired})
+	}
+
+	for c.overCapacity() {
+		elem := c.order.Back()
+		entry := elem.Value.(*boundedEntry)
+		c.removeElement(elem)
+		evicted = append(evicted, eviction{entry.key, entry.value, EvictCapacity})
+	}
+	return evicted
+}
+
+func (c *BoundedCache) overCapacity() bool {
+	if c.order.Len() == 0 {
+		return false
+	}
+	if c.opts.MaxEntries > 0 && c.order.Len() > c.opts.MaxEntries {
+		return true
+	}
+	return c.opts.MaxBytes > 0 && c.bytes > c.opts.MaxBytes
+}
+
+func (c *BoundedCache) expired(entry *boundedEntry) bool {
+	return c.opts.TTL > 0 && !c.now().Before(entry.expires)
+}
+
+// removeElement unlinks elem; must be called with c.mu held
+func (c *BoundedCache) removeElement(elem *list.Element) {
+	entry := elem.Value.(*boundedEntry)
+	c.order.Remove(elem)
+	if entry.byAge != nil {
+		c.deadlines.Remove(entry.byAge)
+	}
+	delete(c.items, entry.key)
+	c.bytes -= entry.size
+}
+
+// CacheStats returns hit/miss/eviction counters and the current size
+func (c *BoundedCache) CacheStats() CacheStats {
+	return c.counters.snapshot(c.Size())
+}
+
+// notify counts and reports evictions; explicit deletions are reported but not counted
+func (c *BoundedCache) notify(evicted []eviction) {
+	for _, e := range evicted {
+		if e.reason != EvictDeleted {
+			c.counters.evicted(1)
+		}
+	}
+	if c.opts.OnEvict == nil {
+		return
+	}
+	for _, e := range evicted {
+		c.opts.OnEvict(e.key, e.value, e.reason)
+	}
+}
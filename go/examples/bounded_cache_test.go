@@ -0,0 +1,260 @@
+package examples
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// evictionRecorder collects OnEvict calls in order
+type evictionRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *evictionRecorder) record(key string, _ interface{}, reason EvictionReason) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, key+":"+reason.String())
+}
+
+func (r *evictionRecorder) Events() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+func TestBoundedCacheMaxEntries(t *testing.T) {
+	g := NewWithT(t)
+
+	rec := &evictionRecorder{}
+	cache := NewBoundedCache(CacheOptions{MaxEntries: 3, OnEvict: rec.record})
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3)
+	g.Expect(rec.Events()).To(BeEmpty())
+
+	// Touch "a" so "b" becomes least recently used
+	_, ok := cache.Get("a")
+	g.Expect(ok).To(BeTrue())
+
+	cache.Set("d", 4)
+	cache.Set("e", 5)
+
+	// Evictions happen in LRU order
+	g.Expect(rec.Events()).To(Equal([]string{"b:capacity", "c:capacity"}))
+	g.Expect(cache.Size()).To(Equal(int64(3)))
+
+	_, ok = cache.Get("b")
+	g.Expect(ok).To(BeFalse())
+	_, ok = cache.Get("a")
+	g.Expect(ok).To(BeTrue())
+}
+
+func TestBoundedCacheMaxBytes(t *testing.T) {
+	g := NewWithT(t)
+
+	rec := &evictionRecorder{}
+	cache := NewBoundedCache(CacheOptions{
+		MaxBytes: 10,
+		SizeOf: func(_ string, value interface{}) int64 {
+			return int64(len(value.(string)))
+		},
+		OnEvict: rec.record,
+	})
+
+	cache.Set("a", "xxxx")
+	cache.Set("b", "xxxx")
+	g.Expect(cache.Bytes()).To(Equal(int64(8)))
+
+	// Adding 4 more bytes pushes out the oldest entry
+	cache.Set("c", "xxxx")
+	g.Expect(rec.Events()).To(Equal([]string{"a:capacity"}))
+	g.Expect(cache.Bytes()).To(Equal(int64(8)))
+
+	// Replacing a value adjusts bytes without an eviction
+	cache.Set("c", "xx")
+	g.Expect(cache.Bytes()).To(Equal(int64(6)))
+	g.Expect(rec.Events()).To(HaveLen(1))
+
+	// A single oversized value cannot be kept
+	cache.Set("big", "xxxxxxxxxxxx")
+	g.Expect(rec.Events()).To(Equal([]string{"a:capacity", "b:capacity", "c:capacity", "big:capacity"}))
+	g.Expect(cache.Size()).To(Equal(int64(0)))
+	g.Expect(cache.Bytes()).To(Equal(int64(0)))
+}
+
+func TestBoundedCacheTTLAndDelete(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Unix(0, 0)
+	rec := &evictionRecorder{}
+	cache := NewBoundedCache(CacheOptions{TTL: time.Minute, OnEvict: rec.record})
+	cache.now = func() time.Time { return now }
+
+	cache.Set("a", 1)
+	now = now.Add(30 * time.Second)
+	cache.Set("b", 2)
+
+	// Explicit deletion
+	cache.Delete("b")
+	g.Expect(rec.Events()).To(Equal([]string{"b:deleted"}))
+
+	// Deleting a missing key is silent
+	cache.Delete("missing")
+	g.Expect(rec.Events()).To(HaveLen(1))
+
+	// "a" expires on read
+	now = now.Add(30 * time.Second)
+	_, ok := cache.Get("a")
+	g.Expect(ok).To(BeFalse())
+	g.Expect(rec.Events()).To(Equal([]string{"b:deleted", "a:expired"}))
+
+	// Expired entries are also purged when writing
+	cache.Set("c", 3)
+	now = now.Add(time.Minute)
+	cache.Set("d", 4)
+	g.Expect(rec.Events()).To(Equal([]string{"b:deleted", "a:expired", "c:expired"}))
+	g.Expect(cache.Size()).To(Equal(int64(1)))
+}
+
+func TestBoundedCacheExpiryOrder(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Unix(0, 0)
+	rec := &evictionRecorder{}
+	cache := NewBoundedCache(CacheOptions{TTL: time.Minute, OnEvict: rec.record})
+	cache.now = func() time.Time { return now }
+
+	for _, key := range []string{"a", "b", "c"} {
+		cache.Set(key, key)
+		now = now.Add(10 * time.Second)
+	}
+	// Reading "a" makes it most recently used but does not extend its life;
+	// setting "b" again does
+	cache.Get("a")
+	cache.Set("b", "b2")
+	g.Expect(cache.deadlines.Len()).To(Equal(3))
+
+	now = now.Add(55 * time.Second) // Past a's and c's deadlines, not b's new one
+	cache.Set("d", "d")
+	g.Expect(rec.Events()).To(Equal([]string{"a:expired", "c:expired"}))
+	g.Expect(cache.Size()).To(Equal(int64(2)))
+	g.Expect(cache.deadlines.Len()).To(Equal(2))
+
+	cache.Delete("b")
+	g.Expect(cache.deadlines.Len()).To(Equal(1))
+}
+
+// TestBoundedCacheConcurrentDeadlineOrder sets keys from several goroutines
+// with a clock that yields after each read, and checks the deadline list the
+// purge relies on is still sorted
+func TestBoundedCacheConcurrentDeadlineOrder(t *testing.T) {
+	g := NewWithT(t)
+
+	cache := NewBoundedCache(CacheOptions{TTL: time.Hour})
+	cache.now = func() time.Time {
+		defer runtime.Gosched()
+		return time.Now()
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				cache.Set(fmt.Sprintf("%d-%d", w, i), i)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	var last time.Time
+	for e := cache.deadlines.Front(); e != nil; e = e.Next() {
+		expires := e.Value.(*boundedEntry).expires
+		g.Expect(expires.Before(last)).To(BeFalse(), "deadlines out of order")
+		last = expires
+	}
+}
+
+func TestBoundedCacheConcurrency(t *testing.T) {
+	g := NewWithT(t)
+
+	var evictions sync.Map
+	cache := NewBoundedCache(CacheOptions{
+		MaxEntries: 50,
+		OnEvict: func(key string, _ interface{}, _ EvictionReason) {
+			evictions.Store(key, true)
+		},
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				key := fmt.Sprintf("key_%d_%d", id, j)
+				cache.Set(key, j)
+				cache.Get(key)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// The bound holds no matter how writers interleave
+	g.Expect(cache.Size()).To(Equal(int64(50)))
+
+	// Every other key was evicted exactly once
+	evicted := 0
+	evictions.Range(func(_, _ interface{}) bool {
+		evicted++
+		return true
+	})
+	g.Expect(evicted).To(Equal(2000 - 50))
+}
+
+func TestBoundedLoadingCache(t *testing.T) {
+	g := NewWithT(t)
+
+	rec := &evictionRecorder{}
+	cache := NewBoundedLoadingCache(func(key string) (interface{}, error) {
+		return "fetched-" + key, nil
+	}, 0, CacheOptions{MaxEntries: 2, OnEvict: rec.record})
+
+	cache.Get("a")
+	cache.Get("b")
+	cache.Get("c")
+	g.Expect(cache.Size()).To(Equal(int64(2)))
+	g.Expect(rec.Events()).To(Equal([]string{"a:capacity"}))
+
+	cache.Invalidate("b")
+	g.Expect(rec.Events()).To(Equal([]string{"a:capacity", "b:deleted"}))
+}
+
+func TestBoundedL1L2Cache(t *testing.T) {
+	g := NewWithT(t)
+
+	l2 := NewSimulatedL2(0)
+	for i := 0; i < 5; i++ {
+		l2.Put(fmt.Sprintf("key_%d", i), i)
+	}
+	cache := NewBoundedL1L2Cache(l2.Load, 2, CacheOptions{MaxEntries: 2})
+
+	for i := 0; i < 5; i++ {
+		cache.Get(fmt.Sprintf("key_%d", i))
+	}
+
+	// L1 stays bounded; evicted keys are still reachable through L2
+	g.Expect(cache.L1Size()).To(Equal(int64(2)))
+	_, found, _ := cache.Get("key_0")
+	g.Expect(found).To(BeTrue())
+	g.Expect(cache.Stats().L2Hits).To(Equal(int64(6)))
+}
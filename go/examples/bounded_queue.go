@@ -0,0 +1,150 @@
+package examples
+
+import (
+	"errors"
+	"sync"
+)
+
+// Errors returned by BoundedQueue.Put
+var (
+	ErrQueueFull   = errors.New("queue full")
+	ErrQueueClosed = errors.New("queue closed")
+)
+
+// OverflowStrategy says what BoundedQueue.Put does when the queue is full
+type OverflowStrategy int
+
+const (
+	// OverflowBlock waits for a consumer to make room
+	OverflowBlock OverflowStrategy = iota
+	// OverflowDropNewest discards the item being put
+	OverflowDropNewest
+	// OverflowDropOldest discards the item at the head of the queue to make room
+	OverflowDropOldest
+	// OverflowError rejects the item with ErrQueueFull
+	OverflowError
+)
+
+// BoundedQueue is a FIFO of fixed capacity kept in a ring buffer under a
+// mutex, with one sync.Cond per direction: consumers wait on notEmpty and
+// blocking producers on notFull. The overflow strategy decides what a
+// producer that finds the queue full does.
+type BoundedQueue[T any] struct {
+	strategy OverflowStrategy
+
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	items    []T
+	head     int // Index of the oldest item
+	size     int
+	closed   bool
+	dropped  int64
+}
+
+// NewBoundedQueue creates a queue holding at most capacity items (at least 1)
+func NewBoundedQueue[T any](capacity int, strategy OverflowStrategy) *BoundedQueue[T] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	q := &BoundedQueue[T]{strategy: strategy, items: make([]T, capacity)}
+	q.notEmpty = sync.NewCond(&q.mu)
+	q.notFull = sync.NewCond(&q.mu)
+	return q
+}
+
+// Put adds v at the tail. On a full queue it blocks, drops an item or returns
+// ErrQueueFull according to the strategy; dropping is not an error. Put on a
+// closed queue, including one closed while Put was blocked, returns ErrQueueClosed.
+func (q *BoundedQueue[T]) Put(v T) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for !q.closed && q.size == len(q.items) {
+		switch q.strategy {
+		case OverflowDropNewest:
+			q.dropped++
+			return nil
+		case OverflowDropOldest:
+			q.popLocked()
+			q.dropped++
+		case OverflowError:
+			return ErrQueueFull
+		default:
+			q.notFull.Wait()
+		}
+	}
+	if q.closed {
+		return ErrQueueClosed
+	}
+	q.items[(q.head+q.size)%len(q.items)] = v
+	q.size++
+	q.notEmpty.Signal()
+	return nil
+}
+
+// Get removes and returns the head item, waiting while the queue is empty.
+// It returns false once the queue is closed and drained.
+func (q *BoundedQueue[T]) Get() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for q.size == 0 && !q.closed {
+		q.notEmpty.Wait()
+	}
+	if q.size == 0 {
+		var zero T
+		return zero, false
+	}
+	v := q.popLocked()
+	q.notFull.Signal()
+	return v, true
+}
+
+// TryGet is Get without waiting
+func (q *BoundedQueue[T]) TryGet() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.size == 0 {
+		var zero T
+		return zero, false
+	}
+	v := q.popLocked()
+	q.notFull.Signal()
+	return v, true
+}
+
+func (q *BoundedQueue[T]) popLocked() T {
+	var zero T
+	v := q.items[q.head]
+	q.items[q.head] = zero // Let the GC reclaim what the item points to
+	q.head = (q.head + 1) % len(q.items)
+	q.size--
+	return v
+}
+
+// Len returns the number of queued items
+func (q *BoundedQueue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size
+}
+
+// Cap returns the capacity
+func (q *BoundedQueue[T]) Cap() int {
+	return len(q.items)
+}
+
+// Dropped returns how many items the drop strategies discarded
+func (q *BoundedQueue[T]) Dropped() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.dropped
+}
+
+// Close rejects further Puts and wakes every waiter; queued items can still be drained with Get
+func (q *BoundedQueue[T]) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.notEmpty.Broadcast()
+	q.notFull.Broadcast()
+}
@@ -0,0 +1,164 @@
+package examples
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// drainQueue returns every item left in q
+func drainQueue[T any](q *BoundedQueue[T]) []T {
+	var items []T
+	for {
+		v, ok := q.TryGet()
+		if !ok {
+			return items
+		}
+		items = append(items, v)
+	}
+}
+
+func TestBoundedQueueOverflowStrategies(t *testing.T) {
+	g := NewWithT(t)
+
+	newest := NewBoundedQueue[int](3, OverflowDropNewest)
+	oldest := NewBoundedQueue[int](3, OverflowDropOldest)
+	reject := NewBoundedQueue[int](3, OverflowError)
+	for i := 1; i <= 5; i++ {
+		g.Expect(newest.Put(i)).To(Succeed())
+		g.Expect(oldest.Put(i)).To(Succeed())
+		if i <= 3 {
+			g.Expect(reject.Put(i)).To(Succeed())
+		} else {
+			g.Expect(reject.Put(i)).To(MatchError(ErrQueueFull))
+		}
+	}
+
+	g.Expect(drainQueue(newest)).To(Equal([]int{1, 2, 3}))
+	g.Expect(drainQueue(oldest)).To(Equal([]int{3, 4, 5}))
+	g.Expect(drainQueue(reject)).To(Equal([]int{1, 2, 3}))
+	g.Expect(newest.Dropped()).To(Equal(int64(2)))
+	g.Expect(oldest.Dropped()).To(Equal(int64(2)))
+	g.Expect(reject.Dropped()).To(BeZero())
+}
+
+func TestBoundedQueueBlock(t *testing.T) {
+	g := NewWithT(t)
+
+	q := NewBoundedQueue[string](1, OverflowBlock)
+	g.Expect(q.Put("a")).To(Succeed())
+
+	put := make(chan error)
+	go func() { put <- q.Put("b") }()
+	g.Consistently(put, 20*time.Millisecond).ShouldNot(Receive())
+
+	v, ok := q.Get()
+	g.Expect(ok).To(BeTrue())
+	g.Expect(v).To(Equal("a"))
+	g.Eventually(put).Should(Receive(BeNil()))
+	g.Expect(q.Len()).To(Equal(1))
+
+	// Close releases a blocked producer and lets consumers drain what is left
+	go func() { put <- q.Put("c") }()
+	g.Consistently(put, 20*time.Millisecond).ShouldNot(Receive())
+	q.Close()
+	g.Eventually(put).Should(Receive(MatchError(ErrQueueClosed)))
+	v, ok = q.Get()
+	g.Expect(v).To(Equal("b"))
+	g.Expect(ok).To(BeTrue())
+	_, ok = q.Get()
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestBoundedQueueConcurrent(t *testing.T) {
+	g := NewWithT(t)
+
+	q := NewBoundedQueue[int](8, OverflowBlock)
+	var consumers sync.WaitGroup
+	got := make(chan int, 4000)
+	for c := 0; c < 4; c++ {
+		consumers.Add(1)
+		go func() {
+			defer consumers.Done()
+			for {
+				v, ok := q.Get()
+				if !ok {
+					return
+				}
+				got <- v
+			}
+		}()
+	}
+
+	var producers sync.WaitGroup
+	for p := 0; p < 4; p++ {
+		producers.Add(1)
+		go func(p int) {
+			defer producers.Done()
+			for i := 0; i < 1000; i++ {
+				q.Put(p*1000 + i)
+			}
+		}(p)
+	}
+	producers.Wait()
+	q.Close()
+	consumers.Wait()
+	close(got)
+
+	seen := make(map[int]bool)
+	for v := range got {
+		seen[v] = true
+	}
+	g.Expect(seen).To(HaveLen(4000))
+	g.Expect(q.Cap()).To(Equal(8))
+}
+
+// queueBenchSink keeps the simulated consumer work from being optimised away
+var queueBenchSink int
+
+// BenchmarkBoundedQueueOverflow pits a producer against a consumer that does
+// more work per item, so the queue is full most of the time
+func BenchmarkBoundedQueueOverflow(b *testing.B) {
+	strategies := []struct {
+		name     string
+		strategy OverflowStrategy
+	}{
+		{"Block", OverflowBlock},
+		{"DropNewest", OverflowDropNewest},
+		{"DropOldest", OverflowDropOldest},
+		{"Error", OverflowError},
+	}
+	for _, s := range strategies {
+		b.Run(s.name, func(b *testing.B) {
+			q := NewBoundedQueue[int](64, s.strategy)
+			done := make(chan int)
+			go func() {
+				consumed := 0
+				for {
+					if _, ok := q.Get(); !ok {
+						done <- consumed
+						return
+					}
+					consumed++
+					for i := 0; i < 200; i++ { // Simulated per-item work
+						queueBenchSink += i
+					}
+				}
+			}()
+
+			var rejected int
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if q.Put(i) != nil {
+					rejected++
+				}
+			}
+			q.Close()
+			consumed := <-done
+			b.ReportMetric(float64(consumed)/float64(b.N), "delivered/op")
+			b.ReportMetric(float64(q.Dropped()+int64(rejected))/float64(b.N), "lost/op")
+		})
+	}
+}
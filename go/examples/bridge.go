@@ -0,0 +1,30 @@
+package examples
+
+import "context"
+
+// Bridge flattens a stream of channels into one channel, draining each inner
+// channel in turn before moving to the next, so a source that hands out a new
+// channel per connection or page can be ranged over as a single stream. The
+// output closes when chanStream closes or ctx is done; a nil inner channel is
+// skipped.
+func Bridge[T any](ctx context.Context, chanStream <-chan <-chan T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for {
+			stream, err := RecvCtx(ctx, chanStream)
+			if err != nil {
+				return
+			}
+			if stream == nil {
+				continue
+			}
+			for v := range OrDone(ctx, stream) {
+				if SendCtx(ctx, out, v) != nil {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
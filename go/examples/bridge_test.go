@@ -0,0 +1,73 @@
+package examples
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// streamOf returns a channel yielding chans and then closing
+func streamOf(chans ...<-chan int) <-chan <-chan int {
+	ch := make(chan (<-chan int))
+	go func() {
+		defer close(ch)
+		for _, c := range chans {
+			ch <- c
+		}
+	}()
+	return ch
+}
+
+func TestBridge(t *testing.T) {
+	g := NewWithT(t)
+
+	// Empty and nil inner channels contribute nothing and do not end the stream
+	stream := streamOf(sendAll(1, 2), sendAll(), nil, sendAll(3), sendAll())
+	var got []int
+	for v := range Bridge(context.Background(), stream) {
+		got = append(got, v)
+	}
+	g.Expect(got).To(Equal([]int{1, 2, 3}))
+
+	_, ok := <-Bridge(context.Background(), streamOf())
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestBridgeReconnectingSource(t *testing.T) {
+	g := NewWithT(t)
+
+	// Each "connection" delivers a few values before dropping
+	chans := make(chan (<-chan int))
+	go func() {
+		defer close(chans)
+		for conn := 0; conn < 3; conn++ {
+			chans <- sendAll(conn*10, conn*10+1)
+		}
+	}()
+
+	var got []int
+	for v := range Bridge(context.Background(), chans) {
+		got = append(got, v)
+	}
+	g.Expect(got).To(Equal([]int{0, 1, 10, 11, 20, 21}))
+}
+
+func TestBridgeCancellation(t *testing.T) {
+	g := NewWithT(t)
+	before := runtime.NumGoroutine()
+
+	// An inner channel that never closes, followed by a stream that never ends
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := make(chan (<-chan int))
+	out := Bridge(ctx, stream)
+	stream <- counting(ctx)
+	g.Expect(<-out).To(Equal(0))
+	g.Expect(<-out).To(Equal(1))
+	cancel()
+
+	g.Eventually(out).Should(BeClosed())
+	g.Eventually(runtime.NumGoroutine, time.Second).Should(BeNumerically("<=", before))
+}
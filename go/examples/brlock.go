@@ -0,0 +1,65 @@
+package examples
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"sync"
+)
+
+// BRLock is a big-reader lock: a reader-biased lock that splits readers over
+// several independently padded RWMutexes. A reader locks just one shard, so
+// readers on different cores do not all write the same reader count the way
+// they do with one sync.RWMutex. A writer must lock every shard, in order,
+// which makes writes cost O(shards): use it only where writes are rare.
+type BRLock struct {
+	shards []brShard
+}
+
+type brShard struct {
+	mu sync.RWMutex
+	_  [cacheLinePad]byte // Keeps each shard's reader count on its own cache line
+}
+
+// NewBRLock creates a lock with the given number of reader shards. Fewer than
+// 1 means GOMAXPROCS, one per core that can run a reader at once; more shards
+// spread readers further but make Lock slower.
+func NewBRLock(shards int) *BRLock {
+	if shards < 1 {
+		shards = runtime.GOMAXPROCS(0)
+	}
+	return &BRLock{shards: make([]brShard, shards)}
+}
+
+// RLock read-locks one shard and returns its index, which must be passed to
+// RUnlock. The shard is picked at random: Go has no cheap way to ask which
+// core a goroutine is on, and the runtime's per-thread generator is close to
+// free and spreads concurrent readers evenly.
+func (l *BRLock) RLock() int {
+	shard := rand.IntN(len(l.shards))
+	l.shards[shard].mu.RLock()
+	return shard
+}
+
+// RUnlock releases the read lock taken by the RLock that returned shard
+func (l *BRLock) RUnlock(shard int) {
+	l.shards[shard].mu.RUnlock()
+}
+
+// Lock write-locks every shard, waiting for all readers to leave
+func (l *BRLock) Lock() {
+	for i := range l.shards {
+		l.shards[i].mu.Lock()
+	}
+}
+
+// Unlock releases the write lock
+func (l *BRLock) Unlock() {
+	for i := len(l.shards) - 1; i >= 0; i-- {
+		l.shards[i].mu.Unlock()
+	}
+}
+
+// Shards returns the number of reader shards
+func (l *BRLock) Shards() int {
+	return len(l.shards)
+}
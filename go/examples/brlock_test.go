@@ -0,0 +1,119 @@
+package examples
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestBRLock(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(NewBRLock(0).Shards()).To(BeNumerically(">=", 1))
+	l := NewBRLock(4)
+	g.Expect(l.Shards()).To(Equal(4))
+
+	// Readers share the lock
+	a, b := l.RLock(), l.RLock()
+
+	// A writer waits for every reader, whichever shard it holds
+	locked := make(chan struct{})
+	go func() {
+		l.Lock()
+		close(locked)
+	}()
+	l.RUnlock(a)
+	g.Consistently(locked, 20*time.Millisecond).ShouldNot(BeClosed())
+	l.RUnlock(b)
+	g.Eventually(locked).Should(BeClosed())
+
+	// And no reader gets in while it holds the lock
+	read := make(chan struct{})
+	go func() {
+		l.RUnlock(l.RLock())
+		close(read)
+	}()
+	g.Consistently(read, 20*time.Millisecond).ShouldNot(BeClosed())
+	l.Unlock()
+	g.Eventually(read).Should(BeClosed())
+}
+
+func TestBRLockExclusion(t *testing.T) {
+	g := NewWithT(t)
+
+	l := NewBRLock(8)
+	var pair [2]int // Writers keep both halves equal; readers must never see them differ
+	var wg sync.WaitGroup
+	for w := 0; w < 2; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				l.Lock()
+				pair[0]++
+				pair[1]++
+				l.Unlock()
+			}
+		}()
+	}
+	torn := make(chan bool, 4)
+	for r := 0; r < 4; r++ {
+		go func() {
+			bad := false
+			for i := 0; i < 2000; i++ {
+				s := l.RLock()
+				bad = bad || pair[0] != pair[1]
+				l.RUnlock(s)
+			}
+			torn <- bad
+		}()
+	}
+	for r := 0; r < 4; r++ {
+		g.Expect(<-torn).To(BeFalse())
+	}
+	wg.Wait()
+	g.Expect(pair).To(Equal([2]int{400, 400}))
+}
+
+// BenchmarkBRLock compares BRLock at several shard counts with sync.RWMutex,
+// with one write per 1000 operations. The gap only opens up with many cores
+// reading at once.
+func BenchmarkBRLock(b *testing.B) {
+	const readsPerWrite = 1000
+	b.Run("RWMutex", func(b *testing.B) {
+		var mu sync.RWMutex
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				if i%readsPerWrite == 0 {
+					mu.Lock()
+					mu.Unlock()
+				} else {
+					mu.RLock()
+					mu.RUnlock()
+				}
+			}
+		})
+	})
+	for _, shards := range []int{1, 4, 16, 0} {
+		l := NewBRLock(shards)
+		name := fmt.Sprintf("BRLock/shards=%d", shards)
+		if shards == 0 {
+			name = "BRLock/shards=GOMAXPROCS"
+		}
+		b.Run(name, func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					if i%readsPerWrite == 0 {
+						l.Lock()
+						l.Unlock()
+					} else {
+						l.RUnlock(l.RLock())
+					}
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package examples
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultBlockTimeout bounds how long Publish waits on a Block subscriber
+// whose SubscriberOptions.Timeout is zero
+const DefaultBlockTimeout = 10 * time.Millisecond
+
+// SubscriberOptions configures one Broadcaster subscriber
+type SubscriberOptions struct {
+	Name    string // Labels the subscriber in Stats and metrics gauges
+	Buffer  int
+	Policy  BufferPolicy
+	Timeout time.Duration // For Block: how long to wait for room before dropping
+}
+
+// Subscription is one subscriber's view of a Broadcaster
+type Subscription[T any] struct {
+	opts    SubscriberOptions
+	ch      chan T
+	done    chan struct{} // Closed on cancel to release a publisher waiting for room
+	once    sync.Once
+	dropped int64
+	cancel  func()
+}
+
+// C returns the channel values arrive on; it is closed by Cancel
+func (s *Subscription[T]) C() <-chan T {
+	return s.ch
+}
+
+// Dropped returns how many values this subscriber missed because it was too slow
+func (s *Subscription[T]) Dropped() int64 {
+	return atomic.LoadInt64(&s.dropped)
+}
+
+// Cancel unsubscribes and closes C; calling it again does nothing
+func (s *Subscription[T]) Cancel() {
+	s.cancel()
+}
+
+// Broadcaster sends every published value to all subscribers without letting
+// a slow one stall the publisher: a full subscriber either loses the oldest
+// buffered value, loses the new one, or gets a bounded wait before the value
+// is dropped. Drops are counted per subscriber and, if Metrics is set,
+// published as the gauge "<name>_dropped".
+type Broadcaster[T any] struct {
+	metrics *Metrics
+	clock   Clock
+
+	mu   sync.RWMutex
+	subs map[*Subscription[T]]struct{}
+}
+
+// NewBroadcaster creates a broadcaster; metrics may be nil, clock defaults to RealClock
+func NewBroadcaster[T any](metrics *Metrics, clock Clock) *Broadcaster[T] {
+	if clock == nil {
+		clock = RealClock
+	}
+	return &Broadcaster[T]{metrics: metrics, clock: clock, subs: make(map[*Subscription[T]]struct{})}
+}
+
+// Subscribe adds a subscriber with the given options
+func (b *Broadcaster[T]) Subscribe(opts SubscriberOptions) *Subscription[T] {
+	if opts.Buffer < 0 {
+		opts.Buffer = 0
+	}
+	if opts.Policy == Block && opts.Timeout <= 0 {
+		opts.Timeout = DefaultBlockTimeout
+	}
+	s := &Subscription[T]{opts: opts, ch: make(chan T, opts.Buffer), done: make(chan struct{})}
+	s.cancel = func() { b.unsubscribe(s) }
+
+	b.mu.Lock()
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+	return s
+}
+
+func (b *Broadcaster[T]) unsubscribe(s *Subscription[T]) {
+	s.once.Do(func() {
+		close(s.done)
+		b.mu.Lock()
+		delete(b.subs, s)
+		b.mu.Unlock()
+		close(s.ch)
+	})
+}
+
+// Publish offers v to every subscriber and returns how many took it. Full
+// Block subscribers are waited on together, all timers starting at once, so a
+// call takes at most the longest of their timeouts rather than the sum.
+// Subscribe and Cancel wait for Publish to finish.
+func (b *Broadcaster[T]) Publish(v T) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var delivered int64
+	var full []*Subscription[T]
+	for s := range b.subs {
+		if b.offer(s, v) {
+			delivered++
+		} else if s.opts.Policy == Block {
+			full = append(full, s)
+		} else {
+			b.drop(s)
+		}
+	}
+	if len(full) == 0 {
+		return int(delivered)
+	}
+
+	// Start every timer before waiting on any, so they share one deadline
+	timers := make([]Timer, len(full))
+	for i, s := range full {
+		timers[i] = b.clock.NewTimer(s.opts.Timeout)
+	}
+	var wg sync.WaitGroup
+	for i, s := range full {
+		wg.Add(1)
+		go func(s *Subscription[T], timer Timer) {
+			defer wg.Done()
+			if b.wait(s, v, timer) {
+				atomic.AddInt64(&delivered, 1)
+			}
+		}(s, timers[i])
+	}
+	wg.Wait()
+	return int(delivered)
+}
+
+// offer delivers v to s without blocking, making room first under DropOldest
+func (b *Broadcaster[T]) offer(s *Subscription[T], v T) bool {
+	select {
+	case s.ch <- v:
+		return true
+	default:
+	}
+	if s.opts.Policy != DropOldest {
+		return false
+	}
+	select {
+	case <-s.ch:
+		b.drop(s)
+	default:
+	}
+	select {
+	case s.ch <- v:
+		return true
+	default:
+		return false
+	}
+}
+
+// wait delivers v to a full Block subscriber unless timer fires first
+func (b *Broadcaster[T]) wait(s *Subscription[T], v T, timer Timer) bool {
+	defer timer.Stop()
+	select {
+	case s.ch <- v:
+		return true
+	case <-timer.C():
+		b.drop(s)
+		return false
+	case <-s.done:
+		return false // Cancelled, not slow
+	}
+}
+
+func (b *Broadcaster[T]) drop(s *Subscription[T]) {
+	n := atomic.AddInt64(&s.dropped, 1)
+	if b.metrics != nil && s.opts.Name != "" {
+		b.metrics.SetGauge(s.opts.Name+"_dropped", float64(n))
+	}
+}
+
+// Stats returns the dropped count of every named subscriber
+func (b *Broadcaster[T]) Stats() map[string]int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	stats := make(map[string]int64, len(b.subs))
+	for s := range b.subs {
+		if s.opts.Name != "" {
+			stats[s.opts.Name] += s.Dropped()
+		}
+	}
+	return stats
+}
+
+// Close cancels every subscription
+func (b *Broadcaster[T]) Close() {
+	b.mu.RLock()
+	subs := make([]*Subscription[T], 0, len(b.subs))
+	for s := range b.subs {
+		subs = append(subs, s)
+	}
+	b.mu.RUnlock()
+	for _, s := range subs {
+		s.Cancel()
+	}
+}
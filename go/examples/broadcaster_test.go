@@ -0,0 +1,147 @@
+package examples
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestBroadcaster(t *testing.T) {
+	g := NewWithT(t)
+
+	b := NewBroadcaster[int](nil, nil)
+	a := b.Subscribe(SubscriberOptions{Name: "a", Buffer: 4})
+	c := b.Subscribe(SubscriberOptions{Name: "c", Buffer: 4})
+
+	g.Expect(b.Publish(1)).To(Equal(2))
+	g.Expect(a.C()).To(Receive(Equal(1)))
+	g.Expect(c.C()).To(Receive(Equal(1)))
+
+	c.Cancel()
+	c.Cancel()
+	g.Expect(c.C()).To(BeClosed())
+	g.Expect(b.Publish(2)).To(Equal(1))
+
+	b.Close()
+	g.Expect(a.C()).To(Receive(Equal(2)))
+	g.Expect(a.C()).To(BeClosed())
+}
+
+func TestBroadcasterDropPolicies(t *testing.T) {
+	g := NewWithT(t)
+
+	m := &Metrics{}
+	b := NewBroadcaster[string](m, nil)
+	oldest := b.Subscribe(SubscriberOptions{Name: "oldest", Buffer: 2, Policy: DropOldest})
+	newest := b.Subscribe(SubscriberOptions{Name: "newest", Buffer: 2, Policy: DropNewest})
+
+	// Nobody reads; Publish still returns at once
+	for _, v := range []string{"a", "b", "c", "d", "e"} {
+		b.Publish(v)
+	}
+
+	drain := func(ch <-chan string) []string {
+		var got []string
+		for len(ch) > 0 {
+			got = append(got, <-ch)
+		}
+		return got
+	}
+	g.Expect(drain(oldest.C())).To(Equal([]string{"d", "e"}))
+	g.Expect(drain(newest.C())).To(Equal([]string{"a", "b"}))
+	g.Expect(oldest.Dropped()).To(Equal(int64(3)))
+	g.Expect(newest.Dropped()).To(Equal(int64(3)))
+	g.Expect(b.Stats()).To(Equal(map[string]int64{"oldest": 3, "newest": 3}))
+	g.Expect(m.Gauge("oldest_dropped")).To(Equal(3.0))
+	g.Expect(m.Gauge("newest_dropped")).To(Equal(3.0))
+}
+
+func TestBroadcasterSlowSubscriber(t *testing.T) {
+	g := NewWithT(t)
+
+	m := &Metrics{}
+	b := NewBroadcaster[int](m, nil)
+	fast := b.Subscribe(SubscriberOptions{Name: "fast", Buffer: 1, Policy: Block, Timeout: time.Second})
+	slow := b.Subscribe(SubscriberOptions{Name: "slow", Buffer: 1, Policy: Block, Timeout: 2 * time.Millisecond})
+
+	var wg sync.WaitGroup
+	var fastGot, slowGot []int
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for v := range fast.C() {
+			fastGot = append(fastGot, v)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for v := range slow.C() {
+			time.Sleep(10 * time.Millisecond) // Deliberately slower than the publisher
+			slowGot = append(slowGot, v)
+		}
+	}()
+
+	start := time.Now()
+	for i := 0; i < 20; i++ {
+		b.Publish(i)
+	}
+	elapsed := time.Since(start)
+	b.Close()
+	wg.Wait()
+
+	// The fast subscriber saw everything; the slow one cost each publish at most its timeout
+	g.Expect(fastGot).To(HaveLen(20))
+	g.Expect(fast.Dropped()).To(BeZero())
+	g.Expect(len(slowGot) + int(slow.Dropped())).To(Equal(20))
+	g.Expect(slow.Dropped()).To(BeNumerically(">", 10))
+	g.Expect(m.Gauge("slow_dropped")).To(Equal(float64(slow.Dropped())))
+	g.Expect(elapsed).To(BeNumerically("<", 20*(2*time.Millisecond)+100*time.Millisecond))
+}
+
+func TestBroadcasterBlockTimeoutFakeClock(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	b := NewBroadcaster[int](nil, clock)
+	s := b.Subscribe(SubscriberOptions{Buffer: 1, Policy: Block})
+	b.Publish(1)
+
+	done := make(chan int)
+	go func() { done <- b.Publish(2) }()
+	clock.BlockUntil(1)
+	g.Consistently(done).ShouldNot(Receive())
+
+	clock.Advance(DefaultBlockTimeout)
+	g.Eventually(done).Should(Receive(Equal(0)))
+	g.Expect(s.Dropped()).To(Equal(int64(1)))
+
+	// Cancelling releases a waiting publisher without counting a drop
+	go func() { done <- b.Publish(3) }()
+	clock.BlockUntil(1)
+	s.Cancel()
+	g.Eventually(done).Should(Receive(Equal(0)))
+	g.Expect(s.Dropped()).To(Equal(int64(1)))
+}
+
+// TestBroadcasterBlockTimeoutsOverlap checks that full Block subscribers are
+// waited on together: one Advance past the longest timeout releases Publish
+func TestBroadcasterBlockTimeoutsOverlap(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	b := NewBroadcaster[int](nil, clock)
+	short := b.Subscribe(SubscriberOptions{Buffer: 1, Policy: Block, Timeout: time.Second})
+	long := b.Subscribe(SubscriberOptions{Buffer: 1, Policy: Block, Timeout: 2 * time.Second})
+	b.Publish(1)
+
+	done := make(chan int)
+	go func() { done <- b.Publish(2) }()
+	g.Eventually(clock.Waiters).Should(Equal(2))
+
+	clock.Advance(2 * time.Second)
+	g.Eventually(done).Should(Receive(Equal(0)))
+	g.Expect(short.Dropped()).To(Equal(int64(1)))
+	g.Expect(long.Dropped()).To(Equal(int64(1)))
+}
@@ -0,0 +1,81 @@
+package examples
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// CacheStats is the shared snapshot reported by every cache type's CacheStats method
+type CacheStats struct {
+	Hits          int64         // Lookups answered from the cache
+	Misses        int64         // Lookups that found nothing usable
+	Evictions     int64         // Entries dropped by the cache itself (capacity or TTL)
+	Loads         int64         // Calls to a loader or slower level
+	LoadErrors    int64         // Loads that returned an error
+	TotalLoadTime time.Duration // Time spent in loads
+	Entries       int64         // Current number of cached entries
+}
+
+// HitRatio returns Hits / (Hits + Misses), or 0 with no lookups
+func (s CacheStats) HitRatio() float64 {
+	return ratio(s.Hits, s.Hits+s.Misses)
+}
+
+// AvgLoadTime returns the mean load duration, or 0 with no loads
+func (s CacheStats) AvgLoadTime() time.Duration {
+	if s.Loads == 0 {
+		return 0
+	}
+	return s.TotalLoadTime / time.Duration(s.Loads)
+}
+
+// cacheCounters holds the atomic counters behind CacheStats
+type cacheCounters struct {
+	hits       int64
+	misses     int64
+	evictions  int64
+	loads      int64
+	loadErrors int64
+	loadNanos  int64
+}
+
+func (c *cacheCounters) hit() {
+	atomic.AddInt64(&c.hits, 1)
+}
+
+func (c *cacheCounters) miss() {
+	atomic.AddInt64(&c.misses, 1)
+}
+
+func (c *cacheCounters) evicted(n int) {
+	atomic.AddInt64(&c.evictions, int64(n))
+}
+
+// load records one load that took d and failed if err is non-nil
+func (c *cacheCounters) load(d time.Duration, err error) {
+	atomic.AddInt64(&c.loads, 1)
+	atomic.AddInt64(&c.loadNanos, int64(d))
+	if err != nil {
+		atomic.AddInt64(&c.loadErrors, 1)
+	}
+}
+
+func (c *cacheCounters) snapshot(entries int64) CacheStats {
+	return CacheStats{
+		Hits:          atomic.LoadInt64(&c.hits),
+		Misses:        atomic.LoadInt64(&c.misses),
+		Evictions:     atomic.LoadInt64(&c.evictions),
+		Loads:         atomic.LoadInt64(&c.loads),
+		LoadErrors:    atomic.LoadInt64(&c.loadErrors),
+		TotalLoadTime: time.Duration(atomic.LoadInt64(&c.loadNanos)),
+		Entries:       entries,
+	}
+}
+
+// withLoads returns s with the load counters replaced by c's, for caches whose
+// front store counts hits and misses while the cache itself counts the loads
+func (c *cacheCounters) withLoads(s CacheStats) CacheStats {
+	loads := c.snapshot(0)
+	s.Loads, s.LoadErrors, s.TotalLoadTime = loads.Loads, loads.LoadErrors, loads.TotalLoadTime
+	return s
+}
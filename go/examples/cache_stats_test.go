@@ -0,0 +1,114 @@
+package examples
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCacheStatsRatios(t *testing.T) {
+	g := NewWithT(t)
+
+	// Empty stats never divide by zero
+	g.Expect(CacheStats{}.HitRatio()).To(Equal(0.0))
+	g.Expect(CacheStats{}.AvgLoadTime()).To(Equal(time.Duration(0)))
+
+	stats := CacheStats{Hits: 3, Misses: 1, Loads: 2, TotalLoadTime: 10 * time.Millisecond}
+	g.Expect(stats.HitRatio()).To(BeNumerically("~", 0.75, 0.001))
+	g.Expect(stats.AvgLoadTime()).To(Equal(5 * time.Millisecond))
+}
+
+func TestCacheCountersLoad(t *testing.T) {
+	g := NewWithT(t)
+
+	var c cacheCounters
+	c.load(3*time.Millisecond, nil)
+	c.load(time.Millisecond, errors.New("boom"))
+	stats := c.snapshot(0)
+	g.Expect(stats.Loads).To(Equal(int64(2)))
+	g.Expect(stats.LoadErrors).To(Equal(int64(1)))
+	g.Expect(stats.TotalLoadTime).To(Equal(4 * time.Millisecond))
+	g.Expect(stats.AvgLoadTime()).To(Equal(2 * time.Millisecond))
+}
+
+func TestSafeMapCacheStats(t *testing.T) {
+	g := NewWithT(t)
+
+	sm := &SafeMap{}
+	sm.Set("a", 1)
+	sm.Get("a")
+	sm.Get("a")
+	sm.Get("missing")
+
+	stats := sm.CacheStats()
+	g.Expect(stats.Hits).To(Equal(int64(2)))
+	g.Expect(stats.Misses).To(Equal(int64(1)))
+	g.Expect(stats.Entries).To(Equal(int64(1)))
+	g.Expect(stats.Evictions).To(Equal(int64(0)))
+}
+
+func TestBoundedCacheCacheStats(t *testing.T) {
+	g := NewWithT(t)
+
+	cache := NewBoundedCache(CacheOptions{MaxEntries: 2})
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3) // Evicts "a"
+	cache.Get("a")
+	cache.Get("c")
+	cache.Delete("b") // Explicit deletes are not evictions
+
+	stats := cache.CacheStats()
+	g.Expect(stats.Hits).To(Equal(int64(1)))
+	g.Expect(stats.Misses).To(Equal(int64(1)))
+	g.Expect(stats.Evictions).To(Equal(int64(1)))
+	g.Expect(stats.Entries).To(Equal(int64(1)))
+}
+
+func TestLoadingCacheCacheStats(t *testing.T) {
+	g := NewWithT(t)
+
+	cache := NewBoundedLoadingCache(func(key string) (interface{}, error) {
+		time.Sleep(5 * time.Millisecond)
+		if key == "bad" {
+			return nil, errors.New("boom")
+		}
+		return key, nil
+	}, time.Minute, CacheOptions{MaxEntries: 1})
+
+	cache.Get("a")
+	cache.Get("a")
+	cache.Get("b") // Evicts "a"
+	cache.Get("bad")
+	cache.Get("bad") // Cached error counts as a hit
+
+	stats := cache.CacheStats()
+	g.Expect(stats.Hits).To(Equal(int64(2)))
+	g.Expect(stats.Misses).To(Equal(int64(3)))
+	g.Expect(stats.Loads).To(Equal(int64(3)))
+	g.Expect(stats.LoadErrors).To(Equal(int64(1)))
+	g.Expect(stats.Evictions).To(Equal(int64(1)))
+	g.Expect(stats.Entries).To(Equal(int64(1)))
+	g.Expect(stats.AvgLoadTime()).To(BeNumerically(">=", 5*time.Millisecond))
+}
+
+func TestL1L2CacheCacheStats(t *testing.T) {
+	g := NewWithT(t)
+
+	l2 := NewSimulatedL2(2 * time.Millisecond)
+	l2.Put("a", 1)
+	cache := NewL1L2Cache(l2.Load, 1)
+
+	cache.Get("a") // L2 hit
+	cache.Get("a") // L1 hit
+	cache.Get("b") // Miss at both levels
+
+	stats := cache.CacheStats()
+	g.Expect(stats.Hits).To(Equal(int64(2)))
+	g.Expect(stats.Misses).To(Equal(int64(1)))
+	g.Expect(stats.Loads).To(Equal(int64(2)))
+	g.Expect(stats.Entries).To(Equal(int64(1)))
+	g.Expect(stats.TotalLoadTime).To(BeNumerically(">=", 4*time.Millisecond))
+}
@@ -0,0 +1,135 @@
+package examples
+
+import "context"
+
+// Request carries a call's argument together with the channel its reply is to
+// be sent on, so a server goroutine reading a single channel can answer many
+// callers. Reply has a buffer of one, so the server never blocks on a caller
+// that has given up waiting.
+type Request[Req, Resp any] struct {
+	Req   Req
+	Reply chan Resp
+}
+
+// Call sends req to a server reading requests and waits for the reply. Both
+// the send and the wait give up when ctx is done, so a deadline on ctx bounds
+// the whole round trip; a server that has stopped reading just makes callers
+// time out.
+func Call[Req, Resp any](ctx context.Context, requests chan<- Request[Req, Resp], req Req) (Resp, error) {
+	var zero Resp
+	r := Request[Req, Resp]{Req: req, Reply: make(chan Resp, 1)}
+	select {
+	case requests <- r:
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+	select {
+	case resp := <-r.Reply:
+		return resp, nil
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// Serve is the dispatcher loop on the server side of Call: it answers each
+// request with handle, one at a time, until requests closes or ctx is done.
+// Because only this goroutine runs handle, state handle closes over needs no
+// lock, as in an actor.
+func Serve[Req, Resp any](ctx context.Context, requests <-chan Request[Req, Resp], handle func(Req) Resp) {
+	for {
+		select {
+		case r, ok := <-requests:
+			if !ok {
+				return
+			}
+			r.Reply <- handle(r.Req)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+type kvOpKind int
+
+const (
+	kvGet kvOpKind = iota
+	kvSet
+	kvDelete
+	kvAdd
+	kvLen
+)
+
+type kvOp struct {
+	kind  kvOpKind
+	key   string
+	value int
+}
+
+type kvResult struct {
+	value int
+	found bool
+}
+
+// KVActor is a key-value service whose map is owned by a single goroutine and
+// reached only through Call, sharing memory by communicating instead of
+// guarding it with a mutex
+type KVActor struct {
+	requests chan Request[kvOp, kvResult]
+}
+
+// NewKVActor starts the actor's loop, which runs until ctx is done
+func NewKVActor(ctx context.Context) *KVActor {
+	a := &KVActor{requests: make(chan Request[kvOp, kvResult])}
+	data := make(map[string]int)
+	go Serve(ctx, a.requests, func(op kvOp) kvResult {
+		switch op.kind {
+		case kvGet:
+			v, ok := data[op.key]
+			return kvResult{v, ok}
+		case kvSet:
+			data[op.key] = op.value
+		case kvDelete:
+			_, ok := data[op.key]
+			delete(data, op.key)
+			return kvResult{found: ok}
+		case kvAdd:
+			data[op.key] += op.value
+			return kvResult{data[op.key], true}
+		case kvLen:
+			return kvResult{value: len(data)}
+		}
+		return kvResult{}
+	})
+	return a
+}
+
+// Get returns the value stored under key
+func (a *KVActor) Get(ctx context.Context, key string) (int, bool, error) {
+	r, err := Call(ctx, a.requests, kvOp{kind: kvGet, key: key})
+	return r.value, r.found, err
+}
+
+// Set stores value under key
+func (a *KVActor) Set(ctx context.Context, key string, value int) error {
+	_, err := Call(ctx, a.requests, kvOp{kind: kvSet, key: key, value: value})
+	return err
+}
+
+// Delete removes key, reporting whether it was present
+func (a *KVActor) Delete(ctx context.Context, key string) (bool, error) {
+	r, err := Call(ctx, a.requests, kvOp{kind: kvDelete, key: key})
+	return r.found, err
+}
+
+// Add adds delta to the value under key and returns the result. The
+// read-modify-write happens inside the actor, so concurrent Adds never lose updates.
+func (a *KVActor) Add(ctx context.Context, key string, delta int) (int, error) {
+	r, err := Call(ctx, a.requests, kvOp{kind: kvAdd, key: key, value: delta})
+	return r.value, err
+}
+
+// Len returns the number of keys stored
+func (a *KVActor) Len(ctx context.Context) (int, error) {
+	r, err := Call(ctx, a.requests, kvOp{kind: kvLen})
+	return r.value, err
+}
@@ -0,0 +1,95 @@
+package examples
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCall(t *testing.T) {
+	g := NewWithT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	requests := make(chan Request[string, string])
+	go Serve(ctx, requests, strings.ToUpper)
+
+	resp, err := Call(ctx, requests, "hello")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(resp).To(Equal("HELLO"))
+}
+
+func TestCallTimeout(t *testing.T) {
+	g := NewWithT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	release := make(chan struct{})
+	requests := make(chan Request[int, int])
+	go Serve(ctx, requests, func(n int) int {
+		if n == 0 {
+			<-release
+		}
+		return n * 2
+	})
+
+	// The server is stuck on a slow request: the caller gives up at its deadline
+	callCtx, callCancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer callCancel()
+	_, err := Call(callCtx, requests, 0)
+	g.Expect(err).To(MatchError(context.DeadlineExceeded))
+
+	// Replying to the abandoned call does not block the server
+	close(release)
+	resp, err := Call(ctx, requests, 21)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(resp).To(Equal(42))
+
+	// Nobody serving: the send itself times out
+	idle := make(chan Request[int, int])
+	callCtx, callCancel = context.WithTimeout(ctx, 20*time.Millisecond)
+	defer callCancel()
+	_, err = Call(callCtx, idle, 1)
+	g.Expect(err).To(MatchError(context.DeadlineExceeded))
+}
+
+func TestKVActor(t *testing.T) {
+	g := NewWithT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	kv := NewKVActor(ctx)
+	g.Expect(kv.Set(ctx, "a", 1)).To(Succeed())
+	v, found, err := kv.Get(ctx, "a")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(found).To(BeTrue())
+	g.Expect(v).To(Equal(1))
+
+	_, found, _ = kv.Get(ctx, "missing")
+	g.Expect(found).To(BeFalse())
+
+	deleted, _ := kv.Delete(ctx, "a")
+	g.Expect(deleted).To(BeTrue())
+	deleted, _ = kv.Delete(ctx, "a")
+	g.Expect(deleted).To(BeFalse())
+
+	// Concurrent Adds are serialised by the actor, so none are lost
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				_, _ = kv.Add(ctx, "hits", 1)
+			}
+		}()
+	}
+	wg.Wait()
+	v, _, _ = kv.Get(ctx, "hits")
+	g.Expect(v).To(Equal(1000))
+	g.Expect(kv.Len(ctx)).To(Equal(1))
+}
@@ -0,0 +1,91 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrChannelClosed is returned by RecvCtx when the channel is closed, and by
+// CloseOnce.Send after Close
+var ErrChannelClosed = errors.New("channel closed")
+
+// SendCtx sends v on ch, giving up with ctx's error if ctx is done first
+func SendCtx[T any](ctx context.Context, ch chan<- T, v T) error {
+	select {
+	case ch <- v:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RecvCtx receives from ch, giving up with ctx's error if ctx is done first.
+// A closed channel yields ErrChannelClosed.
+func RecvCtx[T any](ctx context.Context, ch <-chan T) (T, error) {
+	select {
+	case v, ok := <-ch:
+		if !ok {
+			return v, ErrChannelClosed
+		}
+		return v, nil
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// CloseOnce is a channel that may be closed any number of times, by any
+// goroutine, and never panics a sender: Send after Close returns
+// ErrChannelClosed, and Close wakes senders blocked on a full channel.
+type CloseOnce[T any] struct {
+	ch   chan T
+	done chan struct{} // Closed first, to release blocked senders
+
+	once sync.Once
+	mu   sync.RWMutex // Held by senders so ch is not closed under them
+}
+
+// NewCloseOnce creates an open channel with the given buffer
+func NewCloseOnce[T any](buffer int) *CloseOnce[T] {
+	return &CloseOnce[T]{ch: make(chan T, buffer), done: make(chan struct{})}
+}
+
+// C returns the channel to receive from; it closes after Close, once
+// buffered values have been read
+func (c *CloseOnce[T]) C() <-chan T {
+	return c.ch
+}
+
+// Send sends v unless the channel is closed, waiting for room until ctx is
+// done or the channel is closed
+func (c *CloseOnce[T]) Send(ctx context.Context, v T) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	select {
+	case <-c.done:
+		return ErrChannelClosed
+	default:
+	}
+	select {
+	case c.ch <- v:
+		return nil
+	case <-c.done:
+		return ErrChannelClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close closes the channel, reporting whether this call was the one that did
+func (c *CloseOnce[T]) Close() bool {
+	closed := false
+	c.once.Do(func() {
+		close(c.done)
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		close(c.ch)
+		closed = true
+	})
+	return closed
+}
@@ -0,0 +1,68 @@
+package examples
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSendRecvCtx(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	ch := make(chan int, 1)
+	g.Expect(SendCtx(ctx, ch, 1)).To(Succeed())
+	g.Expect(RecvCtx(ctx, ch)).To(Equal(1))
+
+	// A full or empty channel gives up when ctx does
+	g.Expect(SendCtx(ctx, ch, 2)).To(Succeed())
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	g.Expect(SendCtx(timeout, ch, 3)).To(MatchError(context.DeadlineExceeded))
+	<-ch
+	_, err := RecvCtx(timeout, ch)
+	g.Expect(err).To(MatchError(context.DeadlineExceeded))
+
+	close(ch)
+	_, err = RecvCtx(ctx, ch)
+	g.Expect(err).To(MatchError(ErrChannelClosed))
+}
+
+func TestCloseOnce(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	c := NewCloseOnce[int](1)
+	g.Expect(c.Send(ctx, 1)).To(Succeed())
+
+	// A sender blocked on the full channel is released by Close, not panicked
+	blocked := make(chan error)
+	go func() { blocked <- c.Send(ctx, 2) }()
+	g.Consistently(blocked, 10*time.Millisecond).ShouldNot(Receive())
+
+	// Concurrent closes: exactly one does the work
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	closers := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if c.Close() {
+				mu.Lock()
+				closers++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	g.Expect(closers).To(Equal(1))
+	g.Eventually(blocked).Should(Receive(MatchError(ErrChannelClosed)))
+
+	g.Expect(c.Send(ctx, 3)).To(MatchError(ErrChannelClosed))
+	g.Expect(c.C()).To(Receive(Equal(1)))
+	g.Expect(c.C()).To(BeClosed())
+}
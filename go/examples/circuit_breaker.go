@@ -0,0 +1,218 @@
+package examples
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrCircuitOpen is returned for calls a CircuitBreaker rejects
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// BreakerState is the state of a CircuitBreaker
+type BreakerState int32
+
+const (
+	// BreakerClosed lets every call through while watching the failure rate
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects every call until OpenTimeout has passed
+	BreakerOpen
+	// BreakerHalfOpen lets a few probe calls through to test for recovery
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerOptions configures a CircuitBreaker. Zero values take the defaults shown.
+type BreakerOptions struct {
+	Window         time.Duration // Sliding window the failure rate is measured over; 10s
+	Buckets        int           // Slices the window is kept in; 10
+	FailureRate    float64       // Failure ratio in the window that trips the breaker; 0.5
+	MinRequests    int64         // Calls in the window before the rate is trusted; 10
+	OpenTimeout    time.Duration // Time spent open before probing; 5s
+	HalfOpenProbes int           // Probes admitted at once, all of which must succeed to close; 1
+	Clock          Clock         // RealClock if nil
+}
+
+type breakerBucket struct {
+	slot      int64 // Which window slice the counts belong to
+	successes int64
+	failures  int64
+}
+
+// CircuitBreaker stops calling a dependency that keeps failing. Closed, it
+// counts outcomes in a sliding window of time buckets and opens once the
+// failure rate crosses the threshold. Open, it rejects calls outright for
+// OpenTimeout, then turns half-open and admits a limited number of probes: if
+// they all succeed it closes, and any failure opens it again. The state is an
+// atomic, so the common closed-state check takes no lock.
+type CircuitBreaker struct {
+	opts  BreakerOptions
+	width time.Duration // Of one bucket
+
+	state int32 // BreakerState
+
+	mu         sync.Mutex
+	buckets    []breakerBucket
+	openedAt   time.Time
+	generation int64 // Bumped on every transition, so late results of an earlier state are ignored; written under mu
+	probes     int   // HalfOpen probes admitted
+	probesOK   int   // HalfOpen probes that succeeded
+}
+
+// NewCircuitBreaker creates a closed breaker
+func NewCircuitBreaker(opts BreakerOptions) *CircuitBreaker {
+	if opts.Window <= 0 {
+		opts.Window = 10 * time.Second
+	}
+	if opts.Buckets < 1 {
+		opts.Buckets = 10
+	}
+	if opts.FailureRate <= 0 {
+		opts.FailureRate = 0.5
+	}
+	if opts.MinRequests < 1 {
+		opts.MinRequests = 10
+	}
+	if opts.OpenTimeout <= 0 {
+		opts.OpenTimeout = 5 * time.Second
+	}
+	if opts.HalfOpenProbes < 1 {
+		opts.HalfOpenProbes = 1
+	}
+	if opts.Clock == nil {
+		opts.Clock = RealClock
+	}
+	return &CircuitBreaker{
+		opts:    opts,
+		width:   opts.Window / time.Duration(opts.Buckets),
+		buckets: make([]breakerBucket, opts.Buckets),
+	}
+}
+
+// State returns the current state. An open breaker whose timeout has passed
+// reports BreakerOpen until the next call turns it half-open.
+func (b *CircuitBreaker) State() BreakerState {
+	return BreakerState(atomic.LoadInt32(&b.state))
+}
+
+// Allow asks to make a call. If admitted, the caller must report the outcome
+// through done exactly once; otherwise err is ErrCircuitOpen.
+func (b *CircuitBreaker) Allow() (done func(success bool), err error) {
+	// Generation before state: a transition stores the state first, so a
+	// closed state read here was current for gen or for a later generation
+	gen := atomic.LoadInt64(&b.generation)
+	if b.State() == BreakerClosed {
+		return func(success bool) { b.record(gen, success) }, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.State() {
+	case BreakerOpen:
+		if b.opts.Clock.Since(b.openedAt) < b.opts.OpenTimeout {
+			return nil, ErrCircuitOpen
+		}
+		b.transitionLocked(BreakerHalfOpen)
+		fallthrough
+	case BreakerHalfOpen:
+		if b.probes >= b.opts.HalfOpenProbes {
+			return nil, ErrCircuitOpen
+		}
+		b.probes++
+	}
+	gen = b.generation
+	return func(success bool) { b.record(gen, success) }, nil
+}
+
+// Do runs fn if the breaker admits it, counting a non-nil error as a failure
+func (b *CircuitBreaker) Do(fn func() error) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+	err = fn()
+	done(err == nil)
+	return err
+}
+
+func (b *CircuitBreaker) record(gen int64, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if gen != b.generation {
+		return
+	}
+	switch b.State() {
+	case BreakerClosed:
+		bucket := b.bucketLocked()
+		if success {
+			bucket.successes++
+		} else {
+			bucket.failures++
+		}
+		successes, failures := b.totalsLocked()
+		total := successes + failures
+		if total >= b.opts.MinRequests && ratio(failures, total) >= b.opts.FailureRate {
+			b.transitionLocked(BreakerOpen)
+		}
+	case BreakerHalfOpen:
+		if !success {
+			b.transitionLocked(BreakerOpen)
+			return
+		}
+		b.probesOK++
+		if b.probesOK >= b.opts.HalfOpenProbes {
+			b.transitionLocked(BreakerClosed)
+		}
+	}
+}
+
+// bucketLocked returns the bucket for now, clearing it if it last held an
+// older slice of time
+func (b *CircuitBreaker) bucketLocked() *breakerBucket {
+	slot := b.opts.Clock.Now().UnixNano() / int64(b.width)
+	bucket := &b.buckets[slot%int64(len(b.buckets))]
+	if bucket.slot != slot {
+		*bucket = breakerBucket{slot: slot}
+	}
+	return bucket
+}
+
+// totalsLocked sums the buckets still inside the window
+func (b *CircuitBreaker) totalsLocked() (successes, failures int64) {
+	oldest := b.opts.Clock.Now().UnixNano()/int64(b.width) - int64(len(b.buckets)) + 1
+	for _, bucket := range b.buckets {
+		if bucket.slot >= oldest {
+			successes += bucket.successes
+			failures += bucket.failures
+		}
+	}
+	return successes, failures
+}
+
+func (b *CircuitBreaker) transitionLocked(to BreakerState) {
+	atomic.StoreInt32(&b.state, int32(to))
+	atomic.AddInt64(&b.generation, 1)
+	b.probes, b.probesOK = 0, 0
+	switch to {
+	case BreakerOpen:
+		b.openedAt = b.opts.Clock.Now()
+	case BreakerClosed:
+		// Start afresh rather than reopen on the failures that tripped it
+		for i := range b.buckets {
+			b.buckets[i] = breakerBucket{}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package examples
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+var errBackend = errors.New("backend down")
+
+func newTestBreaker(clock Clock, probes int) *CircuitBreaker {
+	return NewCircuitBreaker(BreakerOptions{
+		Window:         10 * time.Second,
+		Buckets:        10,
+		FailureRate:    0.5,
+		MinRequests:    4,
+		OpenTimeout:    5 * time.Second,
+		HalfOpenProbes: probes,
+		Clock:          clock,
+	})
+}
+
+// calls runs n calls through b that fail or succeed, returning how many were rejected
+func calls(b *CircuitBreaker, n int, fail bool) (rejected int) {
+	for i := 0; i < n; i++ {
+		err := b.Do(func() error {
+			if fail {
+				return errBackend
+			}
+			return nil
+		})
+		if errors.Is(err, ErrCircuitOpen) {
+			rejected++
+		}
+	}
+	return rejected
+}
+
+func TestCircuitBreakerTrips(t *testing.T) {
+	g := NewWithT(t)
+	clock := NewFakeClock(time.Unix(0, 0))
+	b := newTestBreaker(clock, 1)
+
+	// Below MinRequests a 100% failure rate is not trusted
+	calls(b, 3, true)
+	g.Expect(b.State()).To(Equal(BreakerClosed))
+	b = newTestBreaker(clock, 1)
+
+	// 3 failures in 6 calls reaches the 50% threshold
+	calls(b, 3, false)
+	calls(b, 2, true)
+	g.Expect(b.State()).To(Equal(BreakerClosed))
+	calls(b, 1, true)
+	g.Expect(b.State()).To(Equal(BreakerOpen))
+
+	g.Expect(calls(b, 5, false)).To(Equal(5))
+	g.Expect(b.Do(func() error { return nil })).To(MatchError(ErrCircuitOpen))
+}
+
+func TestCircuitBreakerWindowSlides(t *testing.T) {
+	g := NewWithT(t)
+	clock := NewFakeClock(time.Unix(0, 0))
+	b := newTestBreaker(clock, 1)
+
+	// Old failures age out of the window instead of adding up forever
+	calls(b, 3, true)
+	clock.Advance(11 * time.Second)
+	calls(b, 3, false)
+	calls(b, 1, true)
+	g.Expect(b.State()).To(Equal(BreakerClosed))
+
+	// Within the window they count
+	calls(b, 2, true)
+	g.Expect(b.State()).To(Equal(BreakerOpen))
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	g := NewWithT(t)
+	clock := NewFakeClock(time.Unix(0, 0))
+	b := newTestBreaker(clock, 2)
+	calls(b, 4, true)
+	g.Expect(b.State()).To(Equal(BreakerOpen))
+
+	// Still open just before the timeout
+	clock.Advance(4 * time.Second)
+	_, err := b.Allow()
+	g.Expect(err).To(MatchError(ErrCircuitOpen))
+
+	// After it, only HalfOpenProbes calls are admitted at once
+	clock.Advance(time.Second)
+	probe1, err := b.Allow()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(b.State()).To(Equal(BreakerHalfOpen))
+	probe2, err := b.Allow()
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = b.Allow()
+	g.Expect(err).To(MatchError(ErrCircuitOpen))
+
+	// One failed probe reopens, restarting the timeout
+	probe1(true)
+	probe2(false)
+	g.Expect(b.State()).To(Equal(BreakerOpen))
+	clock.Advance(4 * time.Second)
+	_, err = b.Allow()
+	g.Expect(err).To(MatchError(ErrCircuitOpen))
+
+	// All probes succeeding closes it, with a clean window
+	clock.Advance(time.Second)
+	g.Expect(calls(b, 2, false)).To(BeZero())
+	g.Expect(b.State()).To(Equal(BreakerClosed))
+	calls(b, 3, true)
+	g.Expect(b.State()).To(Equal(BreakerClosed))
+}
+
+func TestCircuitBreakerIgnoresStaleResults(t *testing.T) {
+	g := NewWithT(t)
+	clock := NewFakeClock(time.Unix(0, 0))
+	b := newTestBreaker(clock, 1)
+
+	// A slow call admitted while closed finishes after the breaker opened and
+	// turned half-open: it must not count as the probe's outcome
+	slow, _ := b.Allow()
+	calls(b, 4, true)
+	clock.Advance(5 * time.Second)
+	probe, err := b.Allow()
+	g.Expect(err).NotTo(HaveOccurred())
+	slow(false)
+	g.Expect(b.State()).To(Equal(BreakerHalfOpen))
+	probe(true)
+	g.Expect(b.State()).To(Equal(BreakerClosed))
+}
+
+func TestBreakerStateString(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(BreakerHalfOpen.String()).To(Equal("half-open"))
+	g.Expect(BreakerState(9).String()).To(Equal("unknown"))
+}
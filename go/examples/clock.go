@@ -0,0 +1,208 @@
+package examples
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock abstracts time so that time-driven code can be tested deterministically
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer mirrors time.Timer behind an interface
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker mirrors time.Ticker behind an interface
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// RealClock is the Clock backed by the time package
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) NewTimer(d time.Duration) Timer         { return realTimer{time.NewTimer(d)} }
+func (realClock) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+
+type realTimer struct{ t *time.Timer }
+
+func (t realTimer) C() <-chan time.Time        { return t.t.C }
+func (t realTimer) Stop() bool                 { return t.t.Stop() }
+func (t realTimer) Reset(d time.Duration) bool { return t.t.Reset(d) }
+
+type realTicker struct{ t *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.t.C }
+func (t realTicker) Stop()               { t.t.Stop() }
+
+// FakeClock is a Clock that only moves when Advance is called.
+// Timers and tickers fire synchronously inside Advance; like the real ones,
+// their channels hold one value and further ticks are dropped until it is read.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+	changed chan struct{} // Closed and replaced whenever waiters change
+}
+
+type fakeWaiter struct {
+	clock  *FakeClock
+	when   time.Time
+	period time.Duration // Zero for timers
+	ch     chan time.Time
+}
+
+// NewFakeClock creates a fake clock starting at start
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start, changed: make(chan struct{})}
+}
+
+// Now returns the fake current time
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Since returns the fake time elapsed since t
+func (c *FakeClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// After returns a channel that receives once the clock has advanced by d
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// NewTimer creates a timer that fires once the clock has advanced by d
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	w := &fakeWaiter{clock: c, ch: make(chan time.Time, 1)}
+	c.mu.Lock()
+	w.when = c.now.Add(d)
+	c.addLocked(w)
+	c.mu.Unlock()
+	return w
+}
+
+// NewTicker creates a ticker that fires every d of fake time
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for FakeClock.NewTicker")
+	}
+	w := &fakeWaiter{clock: c, period: d, ch: make(chan time.Time, 1)}
+	c.mu.Lock()
+	w.when = c.now.Add(d)
+	c.addLocked(w)
+	c.mu.Unlock()
+	return fakeTicker{w}
+}
+
+// Advance moves the clock forward by d, firing every timer and ticker that
+// comes due along the way in time order
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	target := c.now.Add(d)
+	for {
+		sort.Slice(c.waiters, func(i, j int) bool { return c.waiters[i].when.Before(c.waiters[j].when) })
+		if len(c.waiters) == 0 || c.waiters[0].when.After(target) {
+			break
+		}
+		w := c.waiters[0]
+		c.now = w.when
+		select {
+		case w.ch <- w.when:
+		default: // Receiver is behind; drop the tick like time.Ticker does
+		}
+		if w.period > 0 {
+			w.when = w.when.Add(w.period)
+		} else {
+			c.removeLocked(w)
+		}
+	}
+	c.now = target
+}
+
+// Waiters returns the number of pending timers and tickers
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil waits until at least n timers or tickers are pending, so a test
+// can be sure a goroutine has started waiting before it advances the clock
+func (c *FakeClock) BlockUntil(n int) {
+	for {
+		c.mu.Lock()
+		if len(c.waiters) >= n {
+			c.mu.Unlock()
+			return
+		}
+		changed := c.changed
+		c.mu.Unlock()
+		<-changed
+	}
+}
+
+func (c *FakeClock) addLocked(w *fakeWaiter) {
+	c.waiters = append(c.waiters, w)
+	c.notifyLocked()
+}
+
+func (c *FakeClock) removeLocked(w *fakeWaiter) bool {
+	for i, other := range c.waiters {
+		if other == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			c.notifyLocked()
+			return true
+		}
+	}
+	return false
+}
+
+func (c *FakeClock) notifyLocked() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+func (w *fakeWaiter) C() <-chan time.Time {
+	return w.ch
+}
+
+// Stop removes the timer or ticker; returns true if it was still pending
+func (w *fakeWaiter) Stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	return w.clock.removeLocked(w)
+}
+
+// Reset reschedules a timer to fire d from now; returns true if it was pending
+func (w *fakeWaiter) Reset(d time.Duration) bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	pending := w.clock.removeLocked(w)
+	w.when = w.clock.now.Add(d)
+	w.clock.addLocked(w)
+	return pending
+}
+
+// fakeTicker adapts fakeWaiter to the Ticker interface
+type fakeTicker struct{ *fakeWaiter }
+
+func (t fakeTicker) Stop() { t.fakeWaiter.Stop() }
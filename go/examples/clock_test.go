@@ -0,0 +1,70 @@
+package examples
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestFakeClockTimers(t *testing.T) {
+	g := NewWithT(t)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewFakeClock(start)
+
+	timer := c.NewTimer(5 * time.Second)
+	after := c.After(10 * time.Second)
+	g.Expect(c.Waiters()).To(Equal(2))
+
+	c.Advance(4 * time.Second)
+	g.Expect(timer.C()).NotTo(Receive())
+
+	c.Advance(time.Second)
+	g.Expect(timer.C()).To(Receive(Equal(start.Add(5 * time.Second))))
+	g.Expect(c.Waiters()).To(Equal(1))
+
+	// Reset re-arms a fired timer relative to the current time
+	g.Expect(timer.Reset(time.Second)).To(BeFalse())
+	g.Expect(timer.Stop()).To(BeTrue())
+	c.Advance(10 * time.Second)
+	g.Expect(timer.C()).NotTo(Receive())
+	g.Expect(after).To(Receive(Equal(start.Add(10 * time.Second))))
+	g.Expect(c.Now()).To(Equal(start.Add(15 * time.Second)))
+	g.Expect(c.Since(start)).To(Equal(15 * time.Second))
+}
+
+func TestFakeClockTicker(t *testing.T) {
+	g := NewWithT(t)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewFakeClock(start)
+	ticker := c.NewTicker(time.Second)
+
+	c.Advance(time.Second)
+	g.Expect(ticker.C()).To(Receive(Equal(start.Add(time.Second))))
+
+	// Unread ticks are dropped, keeping only the first
+	c.Advance(3 * time.Second)
+	g.Expect(ticker.C()).To(Receive(Equal(start.Add(2 * time.Second))))
+	g.Expect(ticker.C()).NotTo(Receive())
+
+	ticker.Stop()
+	c.Advance(time.Second)
+	g.Expect(ticker.C()).NotTo(Receive())
+}
+
+func TestFakeClockBlockUntil(t *testing.T) {
+	g := NewWithT(t)
+
+	c := NewFakeClock(time.Unix(0, 0))
+	fired := make(chan struct{})
+	go func() {
+		<-c.After(time.Minute)
+		close(fired)
+	}()
+
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+	g.Eventually(fired).Should(BeClosed())
+}
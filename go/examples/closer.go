@@ -0,0 +1,101 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Errors returned by Closer and Producer
+var (
+	ErrCloserSealed = errors.New("closer sealed")
+	ErrProducerDone = errors.New("producer done")
+)
+
+// Closer enforces "only the sender closes" when many producers share one
+// output channel: no single producer may close it, so each registers, sends
+// through its Producer and calls Done, and the Closer closes the output
+// exactly once, after Seal has been called and every registered producer is
+// done. Producers may register late, until Seal.
+type Closer[T any] struct {
+	out chan T
+
+	mu        sync.Mutex
+	producers int // Registered and not yet done
+	sealed    bool
+	closed    bool
+}
+
+// Producer is one registered sender on a Closer's output
+type Producer[T any] struct {
+	c    *Closer[T]
+	mu   sync.RWMutex // Held by Send so Done cannot let the output close under it
+	done bool
+}
+
+// NewCloser creates a closer whose output has the given buffer
+func NewCloser[T any](buffer int) *Closer[T] {
+	return &Closer[T]{out: make(chan T, buffer)}
+}
+
+// Out returns the merged output, which closes once Seal has been called and
+// every producer is done
+func (c *Closer[T]) Out() <-chan T {
+	return c.out
+}
+
+// Register adds a producer. It fails with ErrCloserSealed after Seal, since
+// the output may already be closed.
+func (c *Closer[T]) Register() (*Producer[T], error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sealed {
+		return nil, ErrCloserSealed
+	}
+	c.producers++
+	return &Producer[T]{c: c}, nil
+}
+
+// Seal declares that no more producers will register. The output closes now
+// if every registered producer is already done, or else when the last one is.
+func (c *Closer[T]) Seal() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sealed = true
+	c.closeIfDoneLocked()
+}
+
+// Send sends v on the output, waiting for room until ctx is done. It fails
+// with ErrProducerDone after Done.
+func (p *Producer[T]) Send(ctx context.Context, v T) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.done {
+		return ErrProducerDone
+	}
+	return SendCtx(ctx, p.c.out, v)
+}
+
+// Done marks the producer finished, waiting for its Sends in progress to
+// return. Calling it again does nothing.
+func (p *Producer[T]) Done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.done {
+		return
+	}
+	p.done = true
+
+	c := p.c
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.producers--
+	c.closeIfDoneLocked()
+}
+
+func (c *Closer[T]) closeIfDoneLocked() {
+	if c.sealed && c.producers == 0 && !c.closed {
+		c.closed = true
+		close(c.out)
+	}
+}
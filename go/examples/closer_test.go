@@ -0,0 +1,102 @@
+package examples
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCloser(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	c := NewCloser[int](0)
+	var wg sync.WaitGroup
+	for p := 0; p < 4; p++ {
+		prod, err := c.Register()
+		g.Expect(err).NotTo(HaveOccurred())
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			defer prod.Done()
+			for i := 0; i < 5; i++ {
+				_ = prod.Send(ctx, p*10+i)
+			}
+		}(p)
+	}
+	c.Seal()
+
+	var got []int
+	for v := range c.Out() {
+		got = append(got, v)
+	}
+	wg.Wait()
+	sort.Ints(got)
+	g.Expect(got).To(HaveLen(20))
+	g.Expect(got[0]).To(Equal(0))
+	g.Expect(got[19]).To(Equal(34))
+}
+
+func TestCloserLateRegistration(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	c := NewCloser[string](4)
+
+	// The only producer finishing does not close the output before Seal,
+	// so one that registers afterwards can still send
+	early, _ := c.Register()
+	g.Expect(early.Send(ctx, "early")).To(Succeed())
+	early.Done()
+	g.Expect(c.Out()).To(Receive(Equal("early")))
+	g.Consistently(c.Out(), 10*time.Millisecond).ShouldNot(Receive())
+
+	late, err := c.Register()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(late.Send(ctx, "late")).To(Succeed())
+	c.Seal()
+	g.Expect(c.Out()).To(Receive(Equal("late")))
+	g.Consistently(c.Out(), 10*time.Millisecond).ShouldNot(Receive())
+
+	// After Seal nobody else may join
+	_, err = c.Register()
+	g.Expect(err).To(MatchError(ErrCloserSealed))
+
+	late.Done()
+	g.Expect(c.Out()).To(BeClosed())
+}
+
+func TestCloserDoubleDone(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	c := NewCloser[int](1)
+	a, _ := c.Register()
+	b, _ := c.Register()
+	c.Seal()
+
+	// Calling Done twice must not count as b finishing too
+	a.Done()
+	a.Done()
+	g.Expect(a.Send(ctx, 1)).To(MatchError(ErrProducerDone))
+	g.Expect(b.Send(ctx, 2)).To(Succeed())
+	g.Expect(c.Out()).To(Receive(Equal(2)))
+
+	b.Done()
+	b.Done()
+	g.Expect(c.Out()).To(BeClosed())
+	g.Expect(b.Send(ctx, 3)).To(MatchError(ErrProducerDone))
+}
+
+func TestCloserSealWithNoProducers(t *testing.T) {
+	g := NewWithT(t)
+
+	c := NewCloser[int](0)
+	c.Seal()
+	c.Seal()
+	g.Expect(c.Out()).To(BeClosed())
+}
@@ -0,0 +1,149 @@
+package examples
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// ConfigStore is what the config benchmarks compare: a Config read on every
+// request and replaced now and then
+type ConfigStore interface {
+	Get() Config
+	Update(cfg Config)
+}
+
+// MutexConfig guards a Config with a plain mutex, so readers serialise too
+type MutexConfig struct {
+	mu  sync.Mutex
+	cfg Config
+}
+
+// Get returns the current configuration
+func (c *MutexConfig) Get() Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cfg
+}
+
+// Update replaces the configuration
+func (c *MutexConfig) Update(cfg Config) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cfg = cfg
+}
+
+// RWMutexConfig guards a Config with an RWMutex so readers share the lock,
+// though they still all write its reader count
+type RWMutexConfig struct {
+	mu  sync.RWMutex
+	cfg Config
+}
+
+// Get returns the current configuration
+func (c *RWMutexConfig) Get() Config {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cfg
+}
+
+// Update replaces the configuration
+func (c *RWMutexConfig) Update(cfg Config) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cfg = cfg
+}
+
+// rcuConfig adapts RCUCell to ConfigStore
+type rcuConfig struct {
+	cell *RCUCell[Config]
+}
+
+func (c rcuConfig) Get() Config       { return c.cell.Load() }
+func (c rcuConfig) Update(cfg Config) { c.cell.Store(cfg) }
+
+// ConfigStores are the implementations ConfigBenchmark compares, by name
+var ConfigStores = []struct {
+	Name string
+	New  func() ConfigStore
+}{
+	{"AtomicConfig", func() ConfigStore { return NewAtomicConfig(Config{}) }},
+	{"Mutex", func() ConfigStore { return &MutexConfig{} }},
+	{"RWMutex", func() ConfigStore { return &RWMutexConfig{} }},
+	{"RCUCell", func() ConfigStore { return rcuConfig{NewRCUCell(Config{})} }},
+}
+
+// ConfigBenchOptions sizes ConfigBenchmark
+type ConfigBenchOptions struct {
+	ReadsPerWrite []int // Read:write ratios to run; defaults to 10, 100 and 1000
+	Goroutines    int   // Defaults to GOMAXPROCS
+	Ops           int   // Operations per run, split across goroutines; defaults to 1,000,000
+}
+
+// ConfigBenchResult is one store at one read:write ratio, in a form the
+// reporting tooling can serialise directly
+type ConfigBenchResult struct {
+	Store         string  `json:"store"`
+	ReadsPerWrite int     `json:"reads_per_write"`
+	Goroutines    int     `json:"goroutines"`
+	Ops           int     `json:"ops"`
+	NsPerOp       float64 `json:"ns_per_op"`
+}
+
+// ConfigBenchmark runs every ConfigStore at every ratio in opts and returns
+// the timings, store-major. Each goroutine does one Update per ReadsPerWrite
+// operations and a Get for the rest. BenchmarkConfigStores runs the same
+// workload under go test -bench.
+func ConfigBenchmark(opts ConfigBenchOptions) []ConfigBenchResult {
+	if len(opts.ReadsPerWrite) == 0 {
+		opts.ReadsPerWrite = []int{10, 100, 1000}
+	}
+	if opts.Goroutines == 0 {
+		opts.Goroutines = runtime.GOMAXPROCS(0)
+	}
+	if opts.Ops == 0 {
+		opts.Ops = 1_000_000
+	}
+
+	var results []ConfigBenchResult
+	for _, store := range ConfigStores {
+		for _, ratio := range opts.ReadsPerWrite {
+			s := store.New()
+			per := opts.Ops / opts.Goroutines
+			var wg sync.WaitGroup
+			start := time.Now()
+			for g := 0; g < opts.Goroutines; g++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					configWorkload(s, ratio, per)
+				}()
+			}
+			wg.Wait()
+			elapsed := time.Since(start)
+			results = append(results, ConfigBenchResult{
+				Store:         store.Name,
+				ReadsPerWrite: ratio,
+				Goroutines:    opts.Goroutines,
+				Ops:           per * opts.Goroutines,
+				NsPerOp:       float64(elapsed.Nanoseconds()) / float64(per*opts.Goroutines),
+			})
+		}
+	}
+	return results
+}
+
+// configWorkload does ops operations on s, one in every readsPerWrite an
+// Update, and returns the sum of the MaxConnections it read so the reads
+// cannot be optimised away
+func configWorkload(s ConfigStore, readsPerWrite, ops int) int {
+	var sink int
+	for i := 0; i < ops; i++ {
+		if i%readsPerWrite == 0 {
+			s.Update(Config{MaxConnections: i})
+		} else {
+			sink += s.Get().MaxConnections
+		}
+	}
+	return sink
+}
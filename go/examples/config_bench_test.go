@@ -0,0 +1,61 @@
+package examples
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestConfigStores(t *testing.T) {
+	for _, store := range ConfigStores {
+		t.Run(store.Name, func(t *testing.T) {
+			g := NewWithT(t)
+			s := store.New()
+			g.Expect(s.Get()).To(Equal(Config{}))
+			s.Update(Config{MaxConnections: 5, Debug: true})
+			g.Expect(s.Get()).To(Equal(Config{MaxConnections: 5, Debug: true}))
+		})
+	}
+}
+
+func TestConfigBenchmark(t *testing.T) {
+	g := NewWithT(t)
+
+	results := ConfigBenchmark(ConfigBenchOptions{ReadsPerWrite: []int{10, 100}, Goroutines: 3, Ops: 3000})
+	g.Expect(results).To(HaveLen(2 * len(ConfigStores)))
+	g.Expect(results[0].Store).To(Equal("AtomicConfig"))
+	g.Expect(results[1].ReadsPerWrite).To(Equal(100))
+	for _, r := range results {
+		g.Expect(r.Ops).To(Equal(3000))
+		g.Expect(r.Goroutines).To(Equal(3))
+		g.Expect(r.NsPerOp).To(BeNumerically(">", 0))
+	}
+
+	out, err := json.Marshal(results[0])
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`"store":"AtomicConfig","reads_per_write":10`))
+}
+
+// BenchmarkConfigStores is ConfigBenchmark's workload under go test -bench
+func BenchmarkConfigStores(b *testing.B) {
+	for _, ratio := range []int{10, 100, 1000} {
+		for _, store := range ConfigStores {
+			b.Run(fmt.Sprintf("reads=%d/%s", ratio, store.Name), func(b *testing.B) {
+				s := store.New()
+				b.RunParallel(func(pb *testing.PB) {
+					var sink int
+					for i := 0; pb.Next(); i++ {
+						if i%ratio == 0 {
+							s.Update(Config{MaxConnections: i})
+						} else {
+							sink += s.Get().MaxConnections
+						}
+					}
+					_ = sink
+				})
+			})
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package examples
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Conflator delivers only the most recent value to a consumer that cannot keep
+// up: it is a 1-buffered channel where a send finding the slot full takes the
+// stale value out and puts the new one in. Useful for state such as config or
+// health, where only the latest version matters. Senders are serialised so a
+// slower sender cannot overwrite a newer value; the consumer just reads C.
+type Conflator[T any] struct {
+	ch        chan T
+	mu        sync.Mutex // Serialises Send and Close
+	closed    bool
+	coalesced int64
+}
+
+// NewConflator creates an empty conflator
+func NewConflator[T any]() *Conflator[T] {
+	return &Conflator[T]{ch: make(chan T, 1)}
+}
+
+// Send makes v the value the consumer will receive next, replacing any value
+// it has not read yet. Send never blocks on the consumer and does nothing after Close.
+func (c *Conflator[T]) Send(v T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	for {
+		select {
+		case c.ch <- v:
+			return
+		default:
+		}
+		// The slot is full: discard the stale value, unless the consumer just took it
+		select {
+		case <-c.ch:
+			atomic.AddInt64(&c.coalesced, 1)
+		default:
+		}
+	}
+}
+
+// C returns the channel the latest value arrives on; it is closed by Close
+// after the last value sent has been read
+func (c *Conflator[T]) C() <-chan T {
+	return c.ch
+}
+
+// Coalesced returns how many values were replaced before the consumer read them
+func (c *Conflator[T]) Coalesced() int64 {
+	return atomic.LoadInt64(&c.coalesced)
+}
+
+// Close stops accepting values; a pending value can still be received
+func (c *Conflator[T]) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		c.closed = true
+		close(c.ch)
+	}
+}
@@ -0,0 +1,87 @@
+package examples
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestConflatorCoalesces(t *testing.T) {
+	g := NewWithT(t)
+
+	c := NewConflator[int]()
+	for i := 1; i <= 5; i++ {
+		c.Send(i) // Never blocks, though nobody is reading
+	}
+	g.Expect(c.C()).To(Receive(Equal(5)))
+	g.Expect(c.C()).NotTo(Receive())
+	g.Expect(c.Coalesced()).To(Equal(int64(4)))
+
+	c.Send(6)
+	c.Close()
+	c.Send(7) // Ignored after Close
+	g.Expect(c.C()).To(Receive(Equal(6)))
+	g.Expect(c.C()).To(BeClosed())
+	c.Close()
+}
+
+func TestConflatorSlowConsumer(t *testing.T) {
+	g := NewWithT(t)
+
+	c := NewConflator[int]()
+	var got []int
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for v := range c.C() {
+			got = append(got, v)
+			time.Sleep(2 * time.Millisecond) // Much slower than the sender
+		}
+	}()
+
+	for i := 1; i <= 1000; i++ {
+		c.Send(i)
+	}
+	c.Close()
+	wg.Wait()
+
+	// The consumer skipped ahead, but only ever forwards in time and ends on the latest value
+	g.Expect(len(got)).To(BeNumerically("<", 1000))
+	g.Expect(got[len(got)-1]).To(Equal(1000))
+	for i := 1; i < len(got); i++ {
+		g.Expect(got[i]).To(BeNumerically(">", got[i-1]))
+	}
+	g.Expect(int(c.Coalesced()) + len(got)).To(Equal(1000))
+}
+
+func TestConflatorConcurrentSenders(t *testing.T) {
+	g := NewWithT(t)
+
+	c := NewConflator[int]()
+	var wg sync.WaitGroup
+	for s := 0; s < 8; s++ {
+		wg.Add(1)
+		go func(s int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				c.Send(s)
+			}
+		}(s)
+	}
+	received := 0
+	done := make(chan struct{})
+	go func() {
+		for range c.C() {
+			received++
+		}
+		close(done)
+	}()
+	wg.Wait()
+	c.Close()
+	<-done
+
+	g.Expect(int(c.Coalesced()) + received).To(Equal(4000))
+}
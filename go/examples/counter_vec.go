@@ -0,0 +1,150 @@
+package examples
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// labelSeparator joins label values into a map key; it cannot appear in valid UTF-8
+const labelSeparator = "\xff"
+
+// Counter is a single monotonically increasing value inside a CounterVec
+type Counter struct {
+	value int64
+}
+
+// Inc adds one
+func (c *Counter) Inc() {
+	atomic.AddInt64(&c.value, 1)
+}
+
+// Add adds n, which should not be negative
+func (c *Counter) Add(n int64) {
+	atomic.AddInt64(&c.value, n)
+}
+
+// Value returns the current count
+func (c *Counter) Value() int64 {
+	return atomic.LoadInt64(&c.value)
+}
+
+// CounterSample is one labeled counter in a CounterVec snapshot
+type CounterSample struct {
+	Labels map[string]string
+	Value  int64
+}
+
+// counterFamily is the name, help text and label names shared by the counter vectors
+type counterFamily struct {
+	name       string
+	help       string
+	labelNames []string
+}
+
+func newCounterFamily(name, help string, labelNames []string) counterFamily {
+	return counterFamily{name: name, help: help, labelNames: append([]string(nil), labelNames...)}
+}
+
+// key joins label values into a map key, panicking on a label count mismatch
+func (f *counterFamily) key(labelValues []string) string {
+	if len(labelValues) != len(f.labelNames) {
+		panic(fmt.Sprintf("counter %s: got %d label values, want %d", f.name, len(labelValues), len(f.labelNames)))
+	}
+	return strings.Join(labelValues, labelSeparator)
+}
+
+// samples turns key -> value pairs into samples sorted by label values
+func (f *counterFamily) samples(values map[string]int64) []CounterSample {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	samples := make([]CounterSample, 0, len(keys))
+	for _, key := range keys {
+		labels := make(map[string]string, len(f.labelNames))
+		if len(f.labelNames) > 0 {
+			for i, value := range strings.Split(key, labelSeparator) {
+				labels[f.labelNames[i]] = value
+			}
+		}
+		samples = append(samples, CounterSample{Labels: labels, Value: values[key]})
+	}
+	return samples
+}
+
+// writePrometheus renders samples as one Prometheus counter family
+func (f *counterFamily) writePrometheus(w io.Writer, samples []CounterSample) error {
+	bw := bufio.NewWriter(w)
+	name := promName(f.name)
+	fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s counter\n", name, f.help, name)
+	for _, s := range samples {
+		fmt.Fprintf(bw, "%s%s %d\n", name, f.promLabels(s.Labels), s.Value)
+	}
+	return bw.Flush()
+}
+
+func (f *counterFamily) promLabels(labels map[string]string) string {
+	if len(f.labelNames) == 0 {
+		return ""
+	}
+	parts := make([]string, len(f.labelNames))
+	for i, n := range f.labelNames {
+		parts[i] = promName(n) + "=" + promLabelValue(labels[n])
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// CounterVec is a family of counters sharing a name and distinguished by
+// label values, e.g. requests by method and status. Each label set gets its
+// own atomic Counter, created on first use and stored in a ShardedMap, so
+// hot paths that keep the *Counter around only pay for one atomic add.
+type CounterVec struct {
+	counterFamily
+	counters *ShardedMap[string, *Counter]
+}
+
+// NewCounterVec creates a counter family with the given label names
+func NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	return &CounterVec{
+		counterFamily: newCounterFamily(name, help, labelNames),
+		counters:      NewShardedMap[string, *Counter](DefaultShardCount),
+	}
+}
+
+// With returns the counter for the given label values, in label-name order.
+// It panics if the number of values does not match the label names.
+func (v *CounterVec) With(labelValues ...string) *Counter {
+	key := v.key(labelValues)
+	if c, ok := v.counters.Get(key); ok {
+		return c
+	}
+	c, _ := v.counters.LoadOrStore(key, &Counter{})
+	return c
+}
+
+// Delete drops the counter for the given label values; returns true if it
+// existed. Like With, it panics on the wrong number of label values.
+func (v *CounterVec) Delete(labelValues ...string) bool {
+	return v.counters.Delete(v.key(labelValues))
+}
+
+// Snapshot returns every labeled counter, sorted by label values
+func (v *CounterVec) Snapshot() []CounterSample {
+	values := make(map[string]int64)
+	v.counters.Range(func(key string, c *Counter) bool {
+		values[key] = c.Value()
+		return true
+	})
+	return v.samples(values)
+}
+
+// WritePrometheus renders every labeled counter as one Prometheus counter family
+func (v *CounterVec) WritePrometheus(w io.Writer) error {
+	return v.writePrometheus(w, v.Snapshot())
+}
@@ -0,0 +1,114 @@
+package examples
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCounterVec(t *testing.T) {
+	g := NewWithT(t)
+
+	v := NewCounterVec("http_requests_total", "Requests by method and status.", "method", "status")
+	v.With("GET", "200").Inc()
+	v.With("GET", "200").Inc()
+	v.With("POST", "500").Add(3)
+
+	// The same label set always yields the same counter
+	g.Expect(v.With("GET", "200")).To(BeIdenticalTo(v.With("GET", "200")))
+	g.Expect(v.With("GET", "200").Value()).To(Equal(int64(2)))
+
+	g.Expect(v.Snapshot()).To(Equal([]CounterSample{
+		{Labels: map[string]string{"method": "GET", "status": "200"}, Value: 2},
+		{Labels: map[string]string{"method": "POST", "status": "500"}, Value: 3},
+	}))
+
+	g.Expect(v.Delete("POST", "500")).To(BeTrue())
+	g.Expect(v.Snapshot()).To(HaveLen(1))
+
+	g.Expect(func() { v.With("GET") }).To(Panic())
+	g.Expect(func() { v.Delete("GET") }).To(Panic())
+}
+
+func TestCounterVecConcurrent(t *testing.T) {
+	g := NewWithT(t)
+
+	v := NewCounterVec("hits", "Hits by shard.", "shard")
+	labels := []string{"a", "b", "c", "d"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				v.With(labels[j%len(labels)]).Inc()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, s := range v.Snapshot() {
+		g.Expect(s.Value).To(Equal(int64(2000)), s.Labels["shard"])
+	}
+}
+
+func TestCounterVecWithRacingDelete(t *testing.T) {
+	// With must never return nil, even while the same label set is deleted
+	v := NewCounterVec("hits", "Hits.", "shard")
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				v.Delete("a")
+			}
+		}
+	}()
+	for i := 0; i < 10000; i++ {
+		v.With("a").Inc()
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestCounterVecWritePrometheus(t *testing.T) {
+	g := NewWithT(t)
+
+	v := NewCounterVec("http_requests_total", "Requests by path.", "path")
+	v.With("/users").Inc()
+	v.With(`say "hi"`).Add(2)
+
+	var buf strings.Builder
+	g.Expect(v.WritePrometheus(&buf)).To(Succeed())
+
+	samples, types, err := parsePromText(strings.NewReader(buf.String()))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(types).To(HaveKeyWithValue("http_requests_total", "counter"))
+	g.Expect(samples).To(Equal(map[string]float64{
+		`http_requests_total{path="/users"}`:     1,
+		`http_requests_total{path="say \"hi\""}`: 2,
+	}))
+
+	// Only backslash, quote and newline are escaped; tabs, control bytes and
+	// non-ASCII pass through rather than getting Go escapes
+	buf.Reset()
+	odd := NewCounterVec("odd", "Odd label values.", "v")
+	odd.With("a\tb\x01é\\\n").Inc()
+	g.Expect(odd.WritePrometheus(&buf)).To(Succeed())
+	g.Expect(buf.String()).To(ContainSubstring("odd{v=\"a\tb\x01é\\\\\\n\"} 1\n"))
+
+	// A vector without labels renders bare samples
+	bare := NewCounterVec("jobs", "Jobs.")
+	bare.With().Inc()
+	buf.Reset()
+	g.Expect(bare.WritePrometheus(&buf)).To(Succeed())
+	g.Expect(buf.String()).To(ContainSubstring("\njobs 1\n"))
+}
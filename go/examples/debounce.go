@@ -0,0 +1,86 @@
+package examples
+
+import (
+	"context"
+	"time"
+)
+
+// Debounce emits a value only once in has been quiet for d: every new value
+// restarts the wait and replaces the one pending, so a burst collapses into
+// its last value. When in closes, a pending value is emitted at once and the
+// output closes; when ctx is done the output closes and a pending value is
+// dropped, so a consumer that stops reading does not strand the goroutine.
+// clock drives the wait (RealClock if nil).
+func Debounce[T any](ctx context.Context, in <-chan T, d time.Duration, clock Clock) <-chan T {
+	if clock == nil {
+		clock = RealClock
+	}
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		var pending T
+		var timer Timer
+		var quiet <-chan time.Time // Nil, so never ready, while nothing is pending
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					if timer != nil {
+						SendCtx(ctx, out, pending)
+					}
+					return
+				}
+				// A fresh timer rather than Reset, so a tick the old one already sent is discarded with it
+				if timer != nil {
+					timer.Stop()
+				}
+				pending = v
+				timer = clock.NewTimer(d)
+				quiet = timer.C()
+			case <-quiet:
+				timer, quiet = nil, nil
+				if SendCtx(ctx, out, pending) != nil {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Throttle passes at most one value per interval: a value passes if at least
+// interval has elapsed since the last one that passed, and is dropped
+// otherwise. Use Debounce or a Conflator instead when the latest value must
+// not be lost. The output closes when in closes or ctx is done.
+func Throttle[T any](ctx context.Context, in <-chan T, interval time.Duration, clock Clock) <-chan T {
+	if clock == nil {
+		clock = RealClock
+	}
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		var next time.Time // Earliest time the next value may pass
+		for {
+			v, err := RecvCtx(ctx, in)
+			if err != nil {
+				return
+			}
+			now := clock.Now()
+			if now.Before(next) {
+				continue
+			}
+			next = now.Add(interval)
+			if SendCtx(ctx, out, v) != nil {
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,111 @@
+package examples
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// countingClock counts the calls an operator makes, so a test can wait until a
+// value sent on an unbuffered channel has been fully handled before it moves
+// the fake clock
+type countingClock struct {
+	*FakeClock
+	calls int64
+}
+
+func (c *countingClock) Now() time.Time {
+	defer atomic.AddInt64(&c.calls, 1)
+	return c.FakeClock.Now()
+}
+
+func (c *countingClock) NewTimer(d time.Duration) Timer {
+	defer atomic.AddInt64(&c.calls, 1)
+	return c.FakeClock.NewTimer(d)
+}
+
+// send delivers v and waits until the operator has consulted the clock for it
+func (c *countingClock) send(g *WithT, in chan<- int, v int) {
+	before := atomic.LoadInt64(&c.calls)
+	in <- v
+	g.Eventually(func() int64 { return atomic.LoadInt64(&c.calls) }).Should(Equal(before + 1))
+}
+
+func TestDebounce(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := &countingClock{FakeClock: NewFakeClock(time.Unix(0, 0))}
+	in := make(chan int)
+	out := Debounce[int](context.Background(), in, 100*time.Millisecond, clock)
+
+	// A burst spaced closer than d collapses into its last value
+	for i := 1; i <= 3; i++ {
+		clock.send(g, in, i)
+		clock.Advance(60 * time.Millisecond)
+	}
+	g.Consistently(out).ShouldNot(Receive())
+	clock.Advance(40 * time.Millisecond)
+	g.Eventually(out).Should(Receive(Equal(3)))
+
+	// Quiet after a lone value emits it
+	clock.send(g, in, 4)
+	clock.Advance(100 * time.Millisecond)
+	g.Eventually(out).Should(Receive(Equal(4)))
+
+	// Closing flushes what is pending
+	clock.send(g, in, 5)
+	close(in)
+	g.Eventually(out).Should(Receive(Equal(5)))
+	g.Eventually(out).Should(BeClosed())
+	g.Expect(clock.Waiters()).To(BeZero())
+}
+
+func TestThrottle(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := &countingClock{FakeClock: NewFakeClock(time.Unix(0, 0))}
+	in := make(chan int)
+	out := Throttle[int](context.Background(), in, time.Second, clock)
+
+	// 10 values every 250ms: only those at 0s, 1s and 2s get through
+	var got []int
+	for i := 0; i < 10; i++ {
+		before := atomic.LoadInt64(&clock.calls)
+		in <- i
+		g.Eventually(func() int64 { return atomic.LoadInt64(&clock.calls) }).Should(Equal(before + 1))
+		select {
+		case v := <-out:
+			got = append(got, v)
+		case <-time.After(10 * time.Millisecond):
+		}
+		clock.Advance(250 * time.Millisecond)
+	}
+	close(in)
+	g.Eventually(out).Should(BeClosed())
+	g.Expect(got).To(Equal([]int{0, 4, 8}))
+}
+
+// TestDebounceThrottleCancel checks that cancelling ctx releases both
+// operators while a value is waiting for a consumer that stopped reading
+func TestDebounceThrottleCancel(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := &countingClock{FakeClock: NewFakeClock(time.Unix(0, 0))}
+	ctx, cancel := context.WithCancel(context.Background())
+	debounceIn, throttleIn := make(chan int), make(chan int)
+	debounced := Debounce[int](ctx, debounceIn, 100*time.Millisecond, clock)
+	throttled := Throttle[int](ctx, throttleIn, time.Second, clock)
+
+	// Each operator has a value ready that nobody reads
+	clock.send(g, debounceIn, 1)
+	clock.Advance(100 * time.Millisecond)
+	clock.send(g, throttleIn, 2)
+
+	cancel()
+	g.Eventually(debounced).Should(BeClosed())
+	g.Eventually(throttled).Should(BeClosed())
+	g.Expect(clock.Waiters()).To(BeZero())
+}
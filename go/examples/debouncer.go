@@ -0,0 +1,117 @@
+package examples
+
+import (
+	"sync"
+	"time"
+)
+
+// DebounceOptions configures a Debouncer
+type DebounceOptions struct {
+	Wait     time.Duration // Quiet period that ends a burst of calls
+	Leading  bool          // Run on the first call of a burst
+	Trailing bool          // Run once the burst has been quiet for Wait; the default if neither is set
+	Clock    Clock         // RealClock if nil
+}
+
+// Debouncer coalesces a burst of calls into one run of fn, e.g. a config
+// reload triggered by every write of an editor saving a file. A burst starts
+// with the first Call and ends once no Call has come for Wait. fn runs at the
+// start of the burst (Leading), at its end (Trailing), or both; with both, a
+// burst of a single Call runs fn once. Debounce does the same for a channel
+// of values.
+//
+// Runs of fn never overlap: a leading run waits for a trailing run of the
+// previous burst to finish.
+type Debouncer struct {
+	fn   func()
+	opts DebounceOptions
+
+	run sync.Mutex // Held while fn runs
+
+	mu       sync.Mutex
+	active   bool      // A burst is in progress
+	pending  bool      // The burst has a call not yet covered by a run
+	deadline time.Time // When the burst ends unless another Call comes
+	stopped  bool
+	stop     chan struct{}
+}
+
+// NewDebouncer creates a debouncer for fn
+func NewDebouncer(fn func(), opts DebounceOptions) *Debouncer {
+	if opts.Clock == nil {
+		opts.Clock = RealClock
+	}
+	if !opts.Leading && !opts.Trailing {
+		opts.Trailing = true
+	}
+	return &Debouncer{fn: fn, opts: opts, stop: make(chan struct{})}
+}
+
+// Call records a call, starting a burst or extending the current one. With
+// Leading, the call that starts a burst runs fn before returning.
+func (d *Debouncer) Call() {
+	d.mu.Lock()
+	if d.stopped {
+		d.mu.Unlock()
+		return
+	}
+	d.deadline = d.opts.Clock.Now().Add(d.opts.Wait)
+	if d.active {
+		d.pending = true
+		d.mu.Unlock()
+		return
+	}
+	d.active = true
+	d.pending = !d.opts.Leading
+	timer := d.opts.Clock.NewTimer(d.opts.Wait)
+	d.mu.Unlock()
+
+	go d.wait(timer)
+	if d.opts.Leading {
+		d.invoke()
+	}
+}
+
+// Stop drops any pending trailing run and makes later calls do nothing.
+// A run already in progress completes.
+func (d *Debouncer) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.stopped {
+		d.stopped = true
+		close(d.stop)
+	}
+}
+
+// wait ends the burst once the clock passes the deadline, which each Call
+// pushes back, and makes the trailing run if a call is still uncovered
+func (d *Debouncer) wait(timer Timer) {
+	for {
+		select {
+		case <-timer.C():
+		case <-d.stop:
+			timer.Stop()
+			return
+		}
+		d.mu.Lock()
+		if left := d.deadline.Sub(d.opts.Clock.Now()); left > 0 {
+			timer.Reset(left)
+			d.mu.Unlock()
+			continue
+		}
+		trailing := d.pending && d.opts.Trailing
+		d.active, d.pending = false, false
+		d.mu.Unlock()
+
+		if trailing {
+			d.invoke()
+		}
+		return
+	}
+}
+
+func (d *Debouncer) invoke() {
+	d.run.Lock()
+	defer d.run.Unlock()
+	d.fn()
+}
@@ -0,0 +1,105 @@
+package examples
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// debounceIdle reports whether d has finished its last burst
+func debounceIdle(d *Debouncer) func() bool {
+	return func() bool {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		return !d.active
+	}
+}
+
+func TestDebouncerTrailing(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	var runs atomic.Int64
+	d := NewDebouncer(func() { runs.Add(1) }, DebounceOptions{Wait: 100 * time.Millisecond, Clock: clock})
+
+	// Calls 60ms apart keep pushing the end of the burst back
+	for i := 0; i < 5; i++ {
+		d.Call()
+		clock.BlockUntil(1)
+		clock.Advance(60 * time.Millisecond)
+	}
+	g.Expect(runs.Load()).To(BeZero())
+
+	// 100ms after the last call, fn runs once
+	clock.BlockUntil(1)
+	clock.Advance(40 * time.Millisecond)
+	g.Eventually(runs.Load).Should(BeEquivalentTo(1))
+	g.Eventually(clock.Waiters).Should(BeZero())
+
+	// A later call starts a new burst
+	d.Call()
+	clock.Advance(100 * time.Millisecond)
+	g.Eventually(runs.Load).Should(BeEquivalentTo(2))
+}
+
+func TestDebouncerLeading(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	var runs atomic.Int64
+	d := NewDebouncer(func() { runs.Add(1) }, DebounceOptions{Wait: 100 * time.Millisecond, Leading: true, Clock: clock})
+
+	// The first call runs at once; the rest of the burst is dropped
+	d.Call()
+	g.Expect(runs.Load()).To(BeEquivalentTo(1))
+	d.Call()
+	d.Call()
+	clock.Advance(100 * time.Millisecond)
+	g.Eventually(debounceIdle(d)).Should(BeTrue())
+	g.Expect(runs.Load()).To(BeEquivalentTo(1))
+
+	d.Call()
+	g.Expect(runs.Load()).To(BeEquivalentTo(2))
+}
+
+func TestDebouncerLeadingAndTrailing(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	var runs atomic.Int64
+	d := NewDebouncer(func() { runs.Add(1) }, DebounceOptions{
+		Wait: 100 * time.Millisecond, Leading: true, Trailing: true, Clock: clock,
+	})
+
+	// A single call runs only on the leading edge
+	d.Call()
+	clock.Advance(100 * time.Millisecond)
+	g.Eventually(debounceIdle(d)).Should(BeTrue())
+	g.Expect(runs.Load()).To(BeEquivalentTo(1))
+
+	// A burst of several runs at both edges
+	d.Call()
+	d.Call()
+	d.Call()
+	g.Expect(runs.Load()).To(BeEquivalentTo(2))
+	clock.Advance(100 * time.Millisecond)
+	g.Eventually(runs.Load).Should(BeEquivalentTo(3))
+}
+
+func TestDebouncerStop(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	var runs atomic.Int64
+	d := NewDebouncer(func() { runs.Add(1) }, DebounceOptions{Wait: 100 * time.Millisecond, Clock: clock})
+
+	d.Call()
+	d.Stop()
+	g.Eventually(clock.Waiters).Should(BeZero())
+	clock.Advance(time.Second)
+	d.Call()
+	g.Expect(clock.Waiters()).To(BeZero())
+	g.Expect(runs.Load()).To(BeZero())
+}
@@ -0,0 +1,166 @@
+package examples
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrDisruptorClosed is returned by Disruptor.Publish after Close
+var ErrDisruptorClosed = errors.New("disruptor: closed")
+
+// Disruptor is a small version of the LMAX Disruptor: a preallocated ring of
+// T that producers fill in place and consumers process in sequence order,
+// with no locks and no per-item allocation.
+//
+// Every item gets a sequence number. A producer claims the next one with an
+// atomic add, waits until the slowest last-stage consumer has freed that slot,
+// fills it, and marks it published. Each consumer tracks the last sequence it
+// has processed, and waits on a barrier: the producers' published sequences,
+// or, if it depends on other consumers, the lowest of theirs. Consumers that
+// depend on others form chains, so a later stage can read what an earlier one
+// wrote into the slot, the way the stage package chains pipeline steps with
+// channels but without copying items from channel to channel.
+//
+// Waiting spins with runtime.Gosched, trading CPU for latency as the original's
+// yielding wait strategy does.
+type Disruptor[T any] struct {
+	ring      []T
+	published []atomic.Int64 // Sequence last published into each slot
+	mask      int64
+	next      atomic.Int64 // Next sequence to claim
+	consumers []*Consumer[T]
+	gating    []*Consumer[T] // Consumers no other consumer depends on
+	closed    atomic.Bool
+	wg        sync.WaitGroup
+}
+
+// Consumer is one event handler of a Disruptor
+type Consumer[T any] struct {
+	d         *Disruptor[T]
+	deps      []*Consumer[T]
+	fn        func(seq int64, v *T)
+	processed atomic.Int64 // Last sequence handled
+	gates     bool         // Whether no other consumer depends on this one
+	_         [cacheLinePad]byte
+}
+
+// NewDisruptor creates a disruptor with a ring of size slots, rounded up to a
+// power of two
+func NewDisruptor[T any](size int) *Disruptor[T] {
+	n := 1
+	for n < size {
+		n <<= 1
+	}
+	d := &Disruptor[T]{
+		ring:      make([]T, n),
+		published: make([]atomic.Int64, n),
+		mask:      int64(n - 1),
+	}
+	for i := range d.published {
+		d.published[i].Store(-1)
+	}
+	return d
+}
+
+// Handle registers fn to run on every item, after every consumer in after has
+// handled it. fn gets a pointer into the ring and may modify the item for the
+// consumers that depend on it; it must not keep the pointer. Register all
+// consumers before Start.
+func (d *Disruptor[T]) Handle(fn func(seq int64, v *T), after ...*Consumer[T]) *Consumer[T] {
+	c := &Consumer[T]{d: d, deps: after, fn: fn, gates: true}
+	c.processed.Store(-1)
+	for _, dep := range after {
+		dep.gates = false
+	}
+	d.consumers = append(d.consumers, c)
+	return c
+}
+
+// Start runs every consumer on its own goroutine
+func (d *Disruptor[T]) Start() {
+	for _, c := range d.consumers {
+		if c.gates {
+			d.gating = append(d.gating, c)
+		}
+	}
+	d.wg.Add(len(d.consumers))
+	for _, c := range d.consumers {
+		go c.run()
+	}
+}
+
+// Publish claims the next slot, waiting for room in the ring, and calls fill
+// to write the item in place. It is safe to call from many goroutines.
+func (d *Disruptor[T]) Publish(fill func(v *T)) error {
+	if d.closed.Load() {
+		return ErrDisruptorClosed
+	}
+	seq := d.next.Add(1) - 1
+	// The slot last held seq-size, which every last-stage consumer must be done with
+	wrap := seq - int64(len(d.ring))
+	for d.minGating() < wrap {
+		runtime.Gosched()
+	}
+	fill(&d.ring[seq&d.mask])
+	d.published[seq&d.mask].Store(seq)
+	return nil
+}
+
+// Close waits for the consumers to handle everything published and stops
+// them. All Publish calls must have returned first, as with closing a channel.
+func (d *Disruptor[T]) Close() {
+	d.closed.Store(true)
+	d.wg.Wait()
+}
+
+func (d *Disruptor[T]) minGating() int64 {
+	lowest := int64(1<<63 - 1)
+	for _, c := range d.gating {
+		if p := c.processed.Load(); p < lowest {
+			lowest = p
+		}
+	}
+	return lowest
+}
+
+// available returns the highest sequence this consumer may handle: the end of
+// the contiguous run of published slots after its own position, or the
+// lowest position of the consumers it depends on
+func (c *Consumer[T]) available(from int64) int64 {
+	if len(c.deps) == 0 {
+		seq := from
+		for c.d.published[(seq+1)&c.d.mask].Load() == seq+1 {
+			seq++
+		}
+		return seq
+	}
+	lowest := int64(1<<63 - 1)
+	for _, dep := range c.deps {
+		if p := dep.processed.Load(); p < lowest {
+			lowest = p
+		}
+	}
+	return lowest
+}
+
+func (c *Consumer[T]) run() {
+	defer c.d.wg.Done()
+	seq := c.processed.Load()
+	for {
+		avail := c.available(seq)
+		if avail > seq {
+			// Handle the whole batch, then publish progress once
+			for ; seq < avail; seq++ {
+				c.fn(seq+1, &c.d.ring[(seq+1)&c.d.mask])
+			}
+			c.processed.Store(seq)
+			continue
+		}
+		if c.d.closed.Load() && seq == c.d.next.Load()-1 {
+			return
+		}
+		runtime.Gosched()
+	}
+}
@@ -0,0 +1,160 @@
+package examples
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestDisruptorSingleConsumer(t *testing.T) {
+	g := NewWithT(t)
+
+	// A ring much smaller than the item count forces wrap-around and producer waits
+	d := NewDisruptor[int](3)
+	g.Expect(d.ring).To(HaveLen(4))
+	var got []int
+	var seqs []int64
+	d.Handle(func(seq int64, v *int) {
+		got = append(got, *v)
+		seqs = append(seqs, seq)
+	})
+	d.Start()
+	for i := 0; i < 1000; i++ {
+		g.Expect(d.Publish(func(v *int) { *v = i })).To(Succeed())
+	}
+	d.Close()
+
+	g.Expect(got).To(HaveLen(1000))
+	for i := range got {
+		g.Expect(got[i]).To(Equal(i))
+		g.Expect(seqs[i]).To(Equal(int64(i)))
+	}
+	g.Expect(d.Publish(func(*int) {})).To(MatchError(ErrDisruptorClosed))
+}
+
+func TestDisruptorSizeOne(t *testing.T) {
+	g := NewWithT(t)
+
+	// Every publish after the first reuses the only slot
+	d := NewDisruptor[int](1)
+	var sum int
+	d.Handle(func(_ int64, v *int) { sum += *v })
+	d.Start()
+	for i := 1; i <= 10; i++ {
+		g.Expect(d.Publish(func(v *int) { *v = i })).To(Succeed())
+	}
+	d.Close()
+	g.Expect(sum).To(Equal(55))
+}
+
+func TestDisruptorFullRing(t *testing.T) {
+	g := NewWithT(t)
+
+	// With the consumer stalled on the first item, a ring of 4 takes exactly
+	// 4 items and the fifth publish waits for a slot
+	d := NewDisruptor[int](4)
+	release := make(chan struct{})
+	d.Handle(func(seq int64, _ *int) {
+		if seq == 0 {
+			<-release
+		}
+	})
+	d.Start()
+	var published atomic.Int64
+	go func() {
+		for i := 0; i < 5; i++ {
+			d.Publish(func(v *int) { *v = i })
+			published.Add(1)
+		}
+	}()
+	g.Eventually(published.Load).Should(Equal(int64(4)))
+	g.Consistently(published.Load, 20*time.Millisecond).Should(Equal(int64(4)))
+	close(release)
+	g.Eventually(published.Load).Should(Equal(int64(5)))
+	d.Close()
+}
+
+type pipelineItem struct {
+	n, square int
+}
+
+func TestDisruptorDependentChain(t *testing.T) {
+	g := NewWithT(t)
+
+	d := NewDisruptor[pipelineItem](8)
+	// Two independent first-stage consumers fan out; the last stage runs only
+	// after both and reads what the squarer wrote into the slot
+	var seen int
+	square := d.Handle(func(_ int64, v *pipelineItem) { v.square = v.n * v.n })
+	count := d.Handle(func(int64, *pipelineItem) { seen++ })
+	var sum, mismatches int
+	d.Handle(func(_ int64, v *pipelineItem) {
+		if v.square != v.n*v.n {
+			mismatches++
+		}
+		sum += v.square
+	}, square, count)
+	d.Start()
+
+	var producers sync.WaitGroup
+	for p := 0; p < 4; p++ {
+		producers.Add(1)
+		go func(p int) {
+			defer producers.Done()
+			for i := 1; i <= 100; i++ {
+				d.Publish(func(v *pipelineItem) { v.n = i })
+			}
+		}(p)
+	}
+	producers.Wait()
+	d.Close()
+
+	g.Expect(mismatches).To(BeZero())
+	g.Expect(seen).To(Equal(400))
+	g.Expect(sum).To(Equal(4 * 338350)) // 4 producers × Σi² for i in 1..100
+}
+
+// BenchmarkDisruptorPipeline runs the same two-step pipeline, square then
+// sum, over a Disruptor and over channels like the stage package uses
+func BenchmarkDisruptorPipeline(b *testing.B) {
+	const size = 1024
+	b.Run("Disruptor", func(b *testing.B) {
+		d := NewDisruptor[pipelineItem](size)
+		square := d.Handle(func(_ int64, v *pipelineItem) { v.square = v.n * v.n })
+		var sum int
+		d.Handle(func(_ int64, v *pipelineItem) { sum += v.square }, square)
+		d.Start()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			d.Publish(func(v *pipelineItem) { v.n = i })
+		}
+		d.Close()
+	})
+	b.Run("Channels", func(b *testing.B) {
+		in := make(chan int, size)
+		squares := make(chan int, size)
+		done := make(chan int)
+		go func() {
+			for n := range in {
+				squares <- n * n
+			}
+			close(squares)
+		}()
+		go func() {
+			var sum int
+			for sq := range squares {
+				sum += sq
+			}
+			done <- sum
+		}()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			in <- i
+		}
+		close(in)
+		<-done
+	})
+}
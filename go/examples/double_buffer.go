@@ -0,0 +1,71 @@
+package examples
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// DoubleBuffer lets producers keep writing into one buffer while a consumer
+// reads the other. Write runs against the front buffer; Flip makes the back
+// buffer the new front, waits for writes still running against the old front
+// to finish, and hands it to the consumer. Every write lands in exactly one
+// flip, so none are lost, and producers never wait for the consumer.
+//
+// Writes to the same buffer run concurrently, so the buffer type must be safe
+// for that itself, as Metrics is. A Reporter built on SwapAndReset gets the
+// same no-loss guarantee counter by counter; DoubleBuffer gives it for a whole
+// structure, which the consumer can then read at leisure.
+type DoubleBuffer[T any] struct {
+	front atomic.Uint32 // Index of the buffer writes go to
+	bufs  [2]doubleBufferSide[T]
+	flip  sync.Mutex // Serialises Flip
+}
+
+type doubleBufferSide[T any] struct {
+	v       *T
+	writers atomic.Int64 // Writes in progress against v
+	_       [cacheLinePad]byte
+}
+
+// NewDoubleBuffer creates a double buffer writing to front first
+func NewDoubleBuffer[T any](front, back *T) *DoubleBuffer[T] {
+	d := &DoubleBuffer[T]{}
+	d.bufs[0].v = front
+	d.bufs[1].v = back
+	return d
+}
+
+// Write calls fn with the current front buffer. fn must not keep the pointer.
+func (d *DoubleBuffer[T]) Write(fn func(*T)) {
+	for {
+		i := d.front.Load()
+		side := &d.bufs[i]
+		side.writers.Add(1)
+		// Recheck after announcing ourselves: if a Flip moved the front in
+		// between, it may already have seen zero writers and handed this
+		// buffer to the consumer
+		if d.front.Load() == i {
+			fn(side.v)
+			side.writers.Add(-1)
+			return
+		}
+		side.writers.Add(-1)
+	}
+}
+
+// Flip swaps the buffers, waits for writes to the old front to finish and
+// calls fn with it. fn typically exports and then resets the buffer, which
+// becomes the front again on the next Flip.
+func (d *DoubleBuffer[T]) Flip(fn func(*T)) {
+	d.flip.Lock()
+	defer d.flip.Unlock()
+
+	old := d.front.Load()
+	d.front.Store(1 - old)
+	side := &d.bufs[old]
+	for side.writers.Load() != 0 {
+		runtime.Gosched()
+	}
+	fn(side.v)
+}
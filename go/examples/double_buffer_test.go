@@ -0,0 +1,64 @@
+package examples
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestDoubleBuffer(t *testing.T) {
+	g := NewWithT(t)
+
+	d := NewDoubleBuffer(&[]string{}, &[]string{})
+	d.Write(func(s *[]string) { *s = append(*s, "a") })
+
+	var got []string
+	d.Flip(func(s *[]string) { got = append(got, *s...); *s = (*s)[:0] })
+	g.Expect(got).To(Equal([]string{"a"}))
+
+	// Writes after a flip go to the other buffer
+	d.Write(func(s *[]string) { *s = append(*s, "b") })
+	d.Flip(func(s *[]string) { got = append(got, *s...); *s = (*s)[:0] })
+	d.Flip(func(s *[]string) { got = append(got, *s...) })
+	g.Expect(got).To(Equal([]string{"a", "b"}))
+}
+
+func TestDoubleBufferLosesNothingAcrossFlips(t *testing.T) {
+	g := NewWithT(t)
+
+	// The metrics reporter's use: producers record into one Metrics while the
+	// reporter snapshots and resets the other
+	d := NewDoubleBuffer(&Metrics{}, &Metrics{})
+	const producers, perProducer = 4, 5000
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				d.Write((*Metrics).RecordRequest)
+			}
+		}()
+	}
+
+	var exported int64
+	export := func(m *Metrics) {
+		exported += m.Snapshot().Counters[CounterRequests]
+		m.Reset()
+	}
+	var done atomic.Bool
+	go func() {
+		wg.Wait()
+		done.Store(true)
+	}()
+	for !done.Load() {
+		d.Flip(export)
+	}
+	// Both buffers may still hold writes from the last round
+	d.Flip(export)
+	d.Flip(export)
+
+	g.Expect(exported).To(Equal(int64(producers * perProducer)))
+}
@@ -0,0 +1,180 @@
+package examples
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// SelectStrategy chooses how a DynamicSelect waits on its channels
+type SelectStrategy int
+
+const (
+	// SelectReflect builds a reflect.Select over the current set on each
+	// Recv: no extra goroutines, but each wait costs O(channels)
+	SelectReflect SelectStrategy = iota
+	// SelectForward runs one goroutine per channel forwarding into a shared
+	// channel: a wait is a plain receive, but each channel costs a goroutine
+	// and a value may be in flight from a channel just removed
+	SelectForward
+)
+
+// Received is one value delivered by DynamicSelect.Recv
+type Received[T any] struct {
+	ID    int  // As returned by Add
+	Value T    // Zero when OK is false
+	OK    bool // False when the channel was closed; it is then removed
+}
+
+// DynamicSelect waits on a set of channels that changes at run time, which a
+// select statement cannot express. Channels are added and removed by ID while
+// Recv calls are in progress.
+type DynamicSelect[T any] struct {
+	strategy SelectStrategy
+
+	mu      sync.Mutex
+	chans   map[int]<-chan T
+	stops   map[int]chan struct{} // SelectForward: closing stops a forwarder
+	nextID  int
+	changed chan struct{} // SelectReflect: closed and replaced when the set changes
+
+	merged chan Received[T] // SelectForward
+	wg     sync.WaitGroup
+}
+
+// NewDynamicSelect creates an empty set using strategy; call Close when done
+func NewDynamicSelect[T any](strategy SelectStrategy) *DynamicSelect[T] {
+	return &DynamicSelect[T]{
+		strategy: strategy,
+		chans:    make(map[int]<-chan T),
+		stops:    make(map[int]chan struct{}),
+		changed:  make(chan struct{}),
+		merged:   make(chan Received[T]),
+	}
+}
+
+// Add starts watching ch and returns its ID
+func (s *DynamicSelect[T]) Add(ch <-chan T) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.nextID
+	s.nextID++
+	s.chans[id] = ch
+	if s.strategy == SelectForward {
+		stop := make(chan struct{})
+		s.stops[id] = stop
+		s.wg.Add(1)
+		go s.forward(id, ch, stop)
+	}
+	s.notifyLocked()
+	return id
+}
+
+// Remove stops watching the channel with id, reporting whether it was watched
+func (s *DynamicSelect[T]) Remove(id int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.removeLocked(id)
+}
+
+// Len returns the number of channels watched
+func (s *DynamicSelect[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.chans)
+}
+
+// Recv waits until any watched channel delivers or closes, or ctx is done.
+// With no channels watched it waits for one to be added.
+func (s *DynamicSelect[T]) Recv(ctx context.Context) (Received[T], error) {
+	if s.strategy == SelectForward {
+		select {
+		case r := <-s.merged:
+			if !r.OK {
+				s.Remove(r.ID)
+			}
+			return r, nil
+		case <-ctx.Done():
+			return Received[T]{}, ctx.Err()
+		}
+	}
+
+	for {
+		s.mu.Lock()
+		ids := make([]int, 0, len(s.chans))
+		cases := make([]reflect.SelectCase, 0, len(s.chans)+2)
+		cases = append(cases,
+			reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+			reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(s.changed)})
+		for id, ch := range s.chans {
+			ids = append(ids, id)
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch)})
+		}
+		s.mu.Unlock()
+
+		chosen, v, ok := reflect.Select(cases)
+		switch chosen {
+		case 0:
+			return Received[T]{}, ctx.Err()
+		case 1:
+			continue // The set changed: wait on the new one
+		}
+		r := Received[T]{ID: ids[chosen-2], OK: ok}
+		if ok {
+			reflect.ValueOf(&r.Value).Elem().Set(v) // Interface().(T) panics on a nil interface
+		} else {
+			s.Remove(r.ID)
+		}
+		return r, nil
+	}
+}
+
+// Close removes every channel, stopping the forwarding goroutines, and waits
+// for them to exit
+func (s *DynamicSelect[T]) Close() {
+	s.mu.Lock()
+	for id := range s.chans {
+		s.removeLocked(id)
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func (s *DynamicSelect[T]) removeLocked(id int) bool {
+	if _, ok := s.chans[id]; !ok {
+		return false
+	}
+	delete(s.chans, id)
+	if stop, ok := s.stops[id]; ok {
+		close(stop)
+		delete(s.stops, id)
+	}
+	s.notifyLocked()
+	return true
+}
+
+func (s *DynamicSelect[T]) notifyLocked() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *DynamicSelect[T]) forward(id int, ch <-chan T, stop chan struct{}) {
+	defer s.wg.Done()
+	for {
+		var r Received[T]
+		select {
+		case v, ok := <-ch:
+			r = Received[T]{ID: id, Value: v, OK: ok}
+		case <-stop:
+			return
+		}
+		select {
+		case s.merged <- r:
+		case <-stop:
+			return
+		}
+		if !r.OK {
+			return
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package examples
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+var selectStrategies = []struct {
+	name     string
+	strategy SelectStrategy
+}{
+	{"Reflect", SelectReflect},
+	{"Forward", SelectForward},
+}
+
+func TestDynamicSelect(t *testing.T) {
+	for _, s := range selectStrategies {
+		t.Run(s.name, func(t *testing.T) {
+			g := NewWithT(t)
+			ctx := context.Background()
+			before := runtime.NumGoroutine()
+
+			ds := NewDynamicSelect[int](s.strategy)
+			a, b := make(chan int, 1), make(chan int, 1)
+			idA, idB := ds.Add(a), ds.Add(b)
+			g.Expect(ds.Len()).To(Equal(2))
+
+			b <- 2
+			g.Expect(ds.Recv(ctx)).To(Equal(Received[int]{ID: idB, Value: 2, OK: true}))
+			a <- 1
+			g.Expect(ds.Recv(ctx)).To(Equal(Received[int]{ID: idA, Value: 1, OK: true}))
+
+			// A removed channel is no longer waited on
+			g.Expect(ds.Remove(idB)).To(BeTrue())
+			g.Expect(ds.Remove(idB)).To(BeFalse())
+			b <- 3
+			timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			defer cancel()
+			_, err := ds.Recv(timeout)
+			g.Expect(err).To(MatchError(context.DeadlineExceeded))
+
+			// A closed channel is reported once and dropped
+			close(a)
+			g.Expect(ds.Recv(ctx)).To(Equal(Received[int]{ID: idA}))
+			g.Expect(ds.Len()).To(BeZero())
+
+			ds.Close()
+			g.Eventually(runtime.NumGoroutine, time.Second).Should(BeNumerically("<=", before))
+		})
+	}
+}
+
+func TestDynamicSelectAddWhileWaiting(t *testing.T) {
+	for _, s := range selectStrategies {
+		t.Run(s.name, func(t *testing.T) {
+			g := NewWithT(t)
+			ds := NewDynamicSelect[string](s.strategy)
+			defer ds.Close()
+
+			// Recv starts with nothing to wait on and picks up a channel added later
+			got := make(chan Received[string], 1)
+			go func() {
+				r, _ := ds.Recv(context.Background())
+				got <- r
+			}()
+			g.Consistently(got, 20*time.Millisecond).ShouldNot(Receive())
+			ch := make(chan string, 1)
+			id := ds.Add(ch)
+			ch <- "late"
+			g.Eventually(got).Should(Receive(Equal(Received[string]{ID: id, Value: "late", OK: true})))
+		})
+	}
+}
+
+// TestDynamicSelectNilInterface checks that both strategies deliver a nil
+// interface value the same way
+func TestDynamicSelectNilInterface(t *testing.T) {
+	for _, s := range selectStrategies {
+		t.Run(s.name, func(t *testing.T) {
+			g := NewWithT(t)
+			ds := NewDynamicSelect[error](s.strategy)
+			defer ds.Close()
+
+			ch := make(chan error, 1)
+			id := ds.Add(ch)
+			ch <- nil
+			g.Expect(ds.Recv(context.Background())).To(Equal(Received[error]{ID: id, OK: true}))
+		})
+	}
+}
+
+// BenchmarkDynamicSelect receives from one of n channels chosen in turn, to
+// show how each strategy's cost grows with the size of the set
+func BenchmarkDynamicSelect(b *testing.B) {
+	for _, s := range selectStrategies {
+		for _, n := range []int{4, 64, 512} {
+			b.Run(fmt.Sprintf("%s/%d", s.name, n), func(b *testing.B) {
+				ds := NewDynamicSelect[int](s.strategy)
+				defer ds.Close()
+				chans := make([]chan int, n)
+				for i := range chans {
+					chans[i] = make(chan int, 1)
+					ds.Add(chans[i])
+				}
+				ctx := context.Background()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					chans[i%n] <- i
+					if _, err := ds.Recv(ctx); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
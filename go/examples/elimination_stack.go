@@ -0,0 +1,104 @@
+package examples
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"sync/atomic"
+)
+
+// eliminationSpins is how many times a parked Push yields and checks for a
+// taker before withdrawing its offer
+const eliminationSpins = 64
+
+type eliminationSlot[T any] struct {
+	offer atomic.Pointer[stackNode[T]] // A parked Push's node, or nil
+	_     [cacheLinePad]byte
+}
+
+// EliminationStack is LockFreeStack with an elimination array in front of the
+// head as a backoff. A Push and a Pop that meet cancel out: the stack is the
+// same whether or not they ever touched it. So when a CAS on the head fails,
+// meaning other goroutines are contending for it, a Push parks its node in a
+// random slot for a moment and a Pop checks a random slot for a parked node,
+// and a pair that meets there completes without touching the head at all.
+//
+// Elimination only pays off when the head is heavily contended and pushes and
+// pops arrive in similar numbers; with little contention the CAS on the head
+// rarely fails and the array is never used. See BenchmarkStack.
+type EliminationStack[T any] struct {
+	head       atomic.Pointer[stackNode[T]]
+	size       int64
+	slots      []eliminationSlot[T]
+	eliminated atomic.Int64
+}
+
+// NewEliminationStack creates a stack with width elimination slots. Fewer
+// than 1 means half of GOMAXPROCS, at least one: more slots make contending
+// goroutines less likely to meet.
+func NewEliminationStack[T any](width int) *EliminationStack[T] {
+	if width < 1 {
+		width = max(runtime.GOMAXPROCS(0)/2, 1)
+	}
+	return &EliminationStack[T]{slots: make([]eliminationSlot[T], width)}
+}
+
+// Push adds v on top
+func (s *EliminationStack[T]) Push(v T) {
+	n := &stackNode[T]{value: v}
+	for {
+		n.next = s.head.Load()
+		if s.head.CompareAndSwap(n.next, n) {
+			atomic.AddInt64(&s.size, 1)
+			return
+		}
+		if s.park(n) {
+			return
+		}
+	}
+}
+
+// park offers n to a Pop through a random slot, reporting whether one took it
+func (s *EliminationStack[T]) park(n *stackNode[T]) bool {
+	slot := &s.slots[rand.IntN(len(s.slots))]
+	if !slot.offer.CompareAndSwap(nil, n) {
+		return false // Slot busy: go back to the head
+	}
+	for i := 0; i < eliminationSpins; i++ {
+		if slot.offer.Load() != n {
+			return true // A Pop swapped it out
+		}
+		runtime.Gosched()
+	}
+	// Withdraw. If that fails, a Pop took it in the meantime.
+	return !slot.offer.CompareAndSwap(n, nil)
+}
+
+// Pop removes and returns the top value, or reports false if the stack is empty
+func (s *EliminationStack[T]) Pop() (T, bool) {
+	for {
+		top := s.head.Load()
+		if top == nil {
+			var zero T
+			return zero, false
+		}
+		if s.head.CompareAndSwap(top, top.next) {
+			atomic.AddInt64(&s.size, -1)
+			return top.value, true
+		}
+		slot := &s.slots[rand.IntN(len(s.slots))]
+		if n := slot.offer.Load(); n != nil && slot.offer.CompareAndSwap(n, nil) {
+			s.eliminated.Add(1)
+			return n.value, true
+		}
+	}
+}
+
+// Len returns the number of values; under concurrent use it may be briefly stale
+func (s *EliminationStack[T]) Len() int {
+	return int(atomic.LoadInt64(&s.size))
+}
+
+// Eliminated returns how many push/pop pairs met in the elimination array
+func (s *EliminationStack[T]) Eliminated() int64 {
+	return s.eliminated.Load()
+}
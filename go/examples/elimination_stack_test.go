@@ -0,0 +1,39 @@
+package examples
+
+import (
+	"runtime"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// On few cores the head CAS almost never fails, so the stack tests rarely
+// reach the elimination array; this drives the park/take handshake directly
+func TestEliminationStackPark(t *testing.T) {
+	g := NewWithT(t)
+
+	s := NewEliminationStack[int](1)
+	slot := &s.slots[0].offer
+
+	// With no Pop around the offer is withdrawn
+	g.Expect(s.park(&stackNode[int]{value: 1})).To(BeFalse())
+	g.Expect(slot.Load()).To(BeNil())
+
+	// A taker swapping the node out completes the Push
+	took := make(chan int)
+	go func() {
+		for {
+			if n := slot.Load(); n != nil && slot.CompareAndSwap(n, nil) {
+				took <- n.value
+				return
+			}
+			runtime.Gosched()
+		}
+	}()
+	n := &stackNode[int]{value: 7}
+	for !s.park(n) {
+		runtime.Gosched()
+	}
+	g.Expect(<-took).To(Equal(7))
+	g.Expect(slot.Load()).To(BeNil())
+}
@@ -0,0 +1,204 @@
+package examples
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// BufferPolicy says what Publish does when a subscriber's buffer is full
+type BufferPolicy int
+
+const (
+	// Block makes Publish wait until the subscriber has room or cancels
+	Block BufferPolicy = iota
+	// DropNewest discards the event being published
+	DropNewest
+	// DropOldest discards the oldest buffered event to make room
+	DropOldest
+)
+
+// SubscriptionOptions configures one subscriber's channel
+type SubscriptionOptions struct {
+	Buffer int // Channel capacity
+	Policy BufferPolicy
+}
+
+// DefaultSubscriptionOptions are used by Subscribe
+var DefaultSubscriptionOptions = SubscriptionOptions{Buffer: 16, Policy: DropOldest}
+
+// EventBus delivers events published on a topic to every subscriber of that
+// topic, each through its own buffered channel. Subscribers are kept in a map
+// of sets under a RWMutex: Publish only takes the read lock, so publishers run
+// in parallel, and subscribing or cancelling takes the write lock.
+type EventBus struct {
+	mu     sync.RWMutex
+	topics map[string]map[*subscription]struct{}
+	closed int32 // Set once by Close; checked by Subscribe under the write lock
+
+	published int64
+	dropped   int64
+}
+
+type subscription struct {
+	topic  string
+	ch     chan Event
+	policy BufferPolicy
+	done   chan struct{} // Closed on cancel to release publishers blocked on ch
+	once   sync.Once
+}
+
+// EventBusStats counts events across all topics
+type EventBusStats struct {
+	Published int64 // Calls to Publish
+	Dropped   int64 // Deliveries discarded by DropNewest or DropOldest
+}
+
+// NewEventBus creates an empty bus
+func NewEventBus() *EventBus {
+	return &EventBus{topics: make(map[string]map[*subscription]struct{})}
+}
+
+// Subscribe receives events published on topic with DefaultSubscriptionOptions.
+// Call cancel to unsubscribe; it closes the channel and may be called more than once.
+func (b *EventBus) Subscribe(topic string) (<-chan Event, func()) {
+	return b.SubscribeWith(topic, DefaultSubscriptionOptions)
+}
+
+// SubscribeWith is Subscribe with explicit buffering. On a closed bus the
+// returned channel is already closed.
+func (b *EventBus) SubscribeWith(topic string, opts SubscriptionOptions) (<-chan Event, func()) {
+	if opts.Buffer < 0 {
+		opts.Buffer = 0
+	}
+	s := &subscription{
+		topic:  topic,
+		ch:     make(chan Event, opts.Buffer),
+		policy: opts.Policy,
+		done:   make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if atomic.LoadInt32(&b.closed) == 1 {
+		close(s.done)
+		close(s.ch)
+		return s.ch, func() {}
+	}
+	subs := b.topics[topic]
+	if subs == nil {
+		subs = make(map[*subscription]struct{})
+		b.topics[topic] = subs
+	}
+	subs[s] = struct{}{}
+	return s.ch, func() { b.cancel(s) }
+}
+
+// cancel releases blocked publishers first, then removes and closes the
+// subscription once no publisher can be sending to it
+func (b *EventBus) cancel(s *subscription) {
+	s.once.Do(func() {
+		close(s.done)
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if subs, ok := b.topics[s.topic]; ok {
+			if _, ok := subs[s]; ok {
+				delete(subs, s)
+				if len(subs) == 0 {
+					delete(b.topics, s.topic)
+				}
+				close(s.ch)
+			}
+		}
+	})
+}
+
+// Publish delivers event to every current subscriber of topic, one after the
+// other, applying each subscriber's BufferPolicy. It returns how many
+// subscribers received the event. A Block subscriber that stops reading holds
+// up Publish until it reads again or cancels.
+func (b *EventBus) Publish(topic string, event Event) int {
+	if atomic.LoadInt32(&b.closed) == 1 {
+		return 0
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	atomic.AddInt64(&b.published, 1)
+
+	delivered := 0
+	for s := range b.topics[topic] {
+		if b.deliver(s, event) {
+			delivered++
+		} else {
+			atomic.AddInt64(&b.dropped, 1)
+		}
+	}
+	return delivered
+}
+
+func (b *EventBus) deliver(s *subscription, event Event) bool {
+	select {
+	case s.ch <- event:
+		return true
+	default:
+	}
+	switch s.policy {
+	case DropNewest:
+		return false
+	case DropOldest:
+		// Other publishers may refill the slot first; then this event is dropped instead
+		select {
+		case <-s.ch:
+			atomic.AddInt64(&b.dropped, 1)
+		default:
+		}
+		select {
+		case s.ch <- event:
+			return true
+		default:
+			return false
+		}
+	default:
+		select {
+		case s.ch <- event:
+			return true
+		case <-s.done:
+			return false
+		}
+	}
+}
+
+// Subscribers returns how many subscriptions topic currently has
+func (b *EventBus) Subscribers(topic string) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.topics[topic])
+}
+
+// Stats returns the publish and drop counters
+func (b *EventBus) Stats() EventBusStats {
+	return EventBusStats{
+		Published: atomic.LoadInt64(&b.published),
+		Dropped:   atomic.LoadInt64(&b.dropped),
+	}
+}
+
+// Close cancels every subscription and makes later Publish calls do nothing
+func (b *EventBus) Close() {
+	if !atomic.CompareAndSwapInt32(&b.closed, 0, 1) {
+		return
+	}
+	// No subscription can be added from here on, so this list is final
+	b.mu.RLock()
+	var subs []*subscription
+	for _, topic := range b.topics {
+		for s := range topic {
+			subs = append(subs, s)
+		}
+	}
+	b.mu.RUnlock()
+
+	// Cancel outside the lock so blocked publishers are released first
+	for _, s := range subs {
+		b.cancel(s)
+	}
+}
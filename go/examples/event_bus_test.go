@@ -0,0 +1,144 @@
+package examples
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestEventBus(t *testing.T) {
+	g := NewWithT(t)
+
+	bus := NewEventBus()
+	orders1, cancel1 := bus.Subscribe("orders")
+	orders2, cancel2 := bus.Subscribe("orders")
+	users, cancelUsers := bus.Subscribe("users")
+	defer cancelUsers()
+
+	g.Expect(bus.Publish("orders", Event{Message: "created"})).To(Equal(2))
+	g.Expect(bus.Publish("nobody", Event{Message: "lost"})).To(Equal(0))
+	g.Expect(orders1).To(Receive(HaveField("Message", "created")))
+	g.Expect(orders2).To(Receive(HaveField("Message", "created")))
+	g.Expect(users).NotTo(Receive())
+
+	// Cancelling closes the channel and forgets the subscriber; twice is harmless
+	cancel1()
+	cancel1()
+	g.Expect(orders1).To(BeClosed())
+	g.Expect(bus.Subscribers("orders")).To(Equal(1))
+	g.Expect(bus.Publish("orders", Event{Message: "paid"})).To(Equal(1))
+
+	cancel2()
+	g.Expect(bus.Subscribers("orders")).To(Equal(0))
+	bus.mu.RLock()
+	g.Expect(bus.topics).NotTo(HaveKey("orders"))
+	bus.mu.RUnlock()
+	g.Expect(bus.Stats()).To(Equal(EventBusStats{Published: 3}))
+}
+
+func TestEventBusDropPolicies(t *testing.T) {
+	g := NewWithT(t)
+
+	bus := NewEventBus()
+	newest, _ := bus.SubscribeWith("t", SubscriptionOptions{Buffer: 2, Policy: DropNewest})
+	oldest, _ := bus.SubscribeWith("t", SubscriptionOptions{Buffer: 2, Policy: DropOldest})
+	for _, msg := range []string{"a", "b", "c", "d"} {
+		bus.Publish("t", Event{Message: msg})
+	}
+
+	messages := func(ch <-chan Event) []string {
+		var got []string
+		for len(ch) > 0 {
+			got = append(got, (<-ch).Message)
+		}
+		return got
+	}
+	g.Expect(messages(newest)).To(Equal([]string{"a", "b"}))
+	g.Expect(messages(oldest)).To(Equal([]string{"c", "d"}))
+	g.Expect(bus.Stats().Dropped).To(Equal(int64(4)))
+}
+
+func TestEventBusBlockingSubscriber(t *testing.T) {
+	g := NewWithT(t)
+
+	bus := NewEventBus()
+	ch, cancel := bus.SubscribeWith("t", SubscriptionOptions{Buffer: 1, Policy: Block})
+	bus.Publish("t", Event{Message: "first"})
+
+	published := make(chan int)
+	go func() { published <- bus.Publish("t", Event{Message: "second"}) }()
+	g.Consistently(published, 20*time.Millisecond).ShouldNot(Receive())
+
+	// Reading makes room for the waiting publisher
+	g.Expect((<-ch).Message).To(Equal("first"))
+	g.Eventually(published).Should(Receive(Equal(1)))
+
+	// Cancelling releases a publisher stuck on a subscriber that stopped reading
+	go func() { published <- bus.Publish("t", Event{Message: "third"}) }()
+	g.Consistently(published, 20*time.Millisecond).ShouldNot(Receive())
+	cancel()
+	g.Eventually(published).Should(Receive(Equal(0)))
+}
+
+func TestEventBusClose(t *testing.T) {
+	g := NewWithT(t)
+
+	bus := NewEventBus()
+	a, _ := bus.Subscribe("a")
+	b, _ := bus.SubscribeWith("b", SubscriptionOptions{Policy: Block})
+	go bus.Publish("b", Event{}) // Blocks until Close releases it
+	bus.Close()
+	bus.Close()
+
+	g.Expect(a).To(BeClosed())
+	g.Eventually(b).Should(BeClosed())
+	g.Expect(bus.Publish("a", Event{})).To(Equal(0))
+	late, _ := bus.Subscribe("a")
+	g.Expect(late).To(BeClosed())
+}
+
+func TestEventBusConcurrent(t *testing.T) {
+	g := NewWithT(t)
+
+	bus := NewEventBus()
+	var wg sync.WaitGroup
+	received := make([]int, 8)
+	for i := 0; i < 8; i++ {
+		ch, cancel := bus.SubscribeWith("t", SubscriptionOptions{Buffer: 4, Policy: Block})
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for range ch {
+				received[i]++
+				if received[i] == 50 && i%2 == 0 {
+					cancel() // Half the subscribers leave midway
+				}
+			}
+		}(i)
+		defer cancel()
+	}
+
+	var pubs sync.WaitGroup
+	for p := 0; p < 4; p++ {
+		pubs.Add(1)
+		go func() {
+			defer pubs.Done()
+			for j := 0; j < 100; j++ {
+				bus.Publish("t", Event{Seq: uint64(j)})
+			}
+		}()
+	}
+	pubs.Wait()
+	bus.Close()
+	wg.Wait()
+
+	for i, n := range received {
+		if i%2 == 0 {
+			g.Expect(n).To(BeNumerically(">=", 50), "subscriber %d", i)
+		} else {
+			g.Expect(n).To(Equal(400), "subscriber %d", i)
+		}
+	}
+}
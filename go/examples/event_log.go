@@ -0,0 +1,95 @@
+package examples
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Event is one entry in an EventLog
+type Event struct {
+	Seq     uint64 // Position in the log, starting at 1
+	Time    time.Time
+	Message string
+	Fields  map[string]interface{}
+}
+
+// String formats the event as "seq time message key=value ..." with sorted keys
+func (e Event) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d %s %s", e.Seq, e.Time.Format(time.RFC3339Nano), e.Message)
+	for _, k := range sortedKeys(e.Fields) {
+		fmt.Fprintf(&b, " %s=%v", k, e.Fields[k])
+	}
+	return b.String()
+}
+
+// EventLog keeps the last N events written by any number of goroutines, for
+// post-mortem debugging. Writers never lock: each claims a sequence number with
+// one atomic add and publishes its event into slot seq%N with a CAS, so a slow
+// writer that was lapped by N newer events drops its own rather than
+// overwriting a newer one.
+type EventLog struct {
+	clock Clock
+	seq   uint64
+	slots []atomic.Pointer[Event]
+}
+
+// NewEventLog creates a log holding the last size events, timestamped by clock (RealClock if nil)
+func NewEventLog(size int, clock Clock) *EventLog {
+	if size < 1 {
+		size = 1
+	}
+	if clock == nil {
+		clock = RealClock
+	}
+	return &EventLog{clock: clock, slots: make([]atomic.Pointer[Event], size)}
+}
+
+// Log records an event. keyvals are alternating keys and values; a key that
+// is not a string is formatted with %v, and a trailing key gets a nil value.
+func (l *EventLog) Log(message string, keyvals ...interface{}) {
+	e := &Event{Time: l.clock.Now(), Message: message}
+	if len(keyvals) > 0 {
+		e.Fields = make(map[string]interface{}, (len(keyvals)+1)/2)
+		for i := 0; i < len(keyvals); i += 2 {
+			var v interface{}
+			if i+1 < len(keyvals) {
+				v = keyvals[i+1]
+			}
+			e.Fields[fmt.Sprint(keyvals[i])] = v
+		}
+	}
+	e.Seq = atomic.AddUint64(&l.seq, 1)
+
+	slot := &l.slots[(e.Seq-1)%uint64(len(l.slots))]
+	for {
+		old := slot.Load()
+		if old != nil && old.Seq > e.Seq {
+			return // Lapped; the slot already holds a newer event
+		}
+		if slot.CompareAndSwap(old, e) {
+			return
+		}
+	}
+}
+
+// Written returns how many events were ever logged, including overwritten ones
+func (l *EventLog) Written() uint64 {
+	return atomic.LoadUint64(&l.seq)
+}
+
+// Dump returns the retained events oldest first. Events still being written
+// when Dump runs may be missing, leaving a gap in the sequence numbers.
+func (l *EventLog) Dump() []Event {
+	events := make([]Event, 0, len(l.slots))
+	for i := range l.slots {
+		if e := l.slots[i].Load(); e != nil {
+			events = append(events, *e)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Seq < events[j].Seq })
+	return events
+}
@@ -0,0 +1,85 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/camilbenameur/learning/go/requestid"
+)
+
+func TestEventLog(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	l := NewEventLog(3, clock)
+	g.Expect(l.Dump()).To(BeEmpty())
+
+	l.Log("started", "workers", 4)
+	clock.Advance(time.Second)
+	l.Log("retry", "attempt", 2, "err", "timeout")
+	g.Expect(l.Dump()).To(Equal([]Event{
+		{Seq: 1, Time: clock.Now().Add(-time.Second), Message: "started", Fields: map[string]interface{}{"workers": 4}},
+		{Seq: 2, Time: clock.Now(), Message: "retry", Fields: map[string]interface{}{"attempt": 2, "err": "timeout"}},
+	}))
+	g.Expect(l.Dump()[1].String()).To(Equal("2 2024-01-02T03:04:06Z retry attempt=2 err=timeout"))
+
+	// Only the last three survive, still oldest first
+	l.Log("a")
+	l.Log("b")
+	l.Log("c", "dangling")
+	events := l.Dump()
+	g.Expect(events).To(HaveLen(3))
+	g.Expect([]string{events[0].Message, events[1].Message, events[2].Message}).To(Equal([]string{"a", "b", "c"}))
+	g.Expect(events[2].Fields).To(Equal(map[string]interface{}{"dangling": nil}))
+	g.Expect(l.Written()).To(Equal(uint64(5)))
+}
+
+func TestEventLogConcurrentWriters(t *testing.T) {
+	g := NewWithT(t)
+
+	l := NewEventLog(64, nil)
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				l.Log("tick", "writer", w, "i", i)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	// Once writers are done the ring holds exactly the newest 64 sequence numbers
+	events := l.Dump()
+	g.Expect(l.Written()).To(Equal(uint64(4000)))
+	g.Expect(events).To(HaveLen(64))
+	for i, e := range events {
+		g.Expect(e.Seq).To(Equal(uint64(4000-64+1+i)), fmt.Sprint(i))
+	}
+}
+
+func TestWorkerPoolEvents(t *testing.T) {
+	g := NewWithT(t)
+
+	events := NewEventLog(16, nil)
+	p := NewWorkerPool(WorkerPoolOptions{Events: events})
+	ctx := requestid.WithRequestID(context.Background(), "req-1")
+	g.Expect(p.Submit(ctx, func(context.Context) error { return errors.New("boom") })).To(Succeed())
+	g.Expect(p.Submit(context.Background(), func(context.Context) error { return nil })).To(Succeed())
+	p.Close()
+	p.Close()
+
+	dump := events.Dump()
+	g.Expect(dump).To(HaveLen(2))
+	g.Expect(dump[0].Message).To(Equal("task failed"))
+	g.Expect(dump[0].Fields["err"]).To(MatchError("boom"))
+	g.Expect(dump[0].Fields).To(HaveKeyWithValue("request_id", "req-1"))
+	g.Expect(dump[1].String()).To(HaveSuffix("pool closed completed=1 failed=1"))
+}
@@ -0,0 +1,46 @@
+package examples
+
+import (
+	"expvar"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// expvarMu makes PublishExpvar's check and publish one step, so two calls
+// racing for the same prefix cannot both pass the check
+var expvarMu sync.Mutex
+
+// PublishExpvar registers the metrics under prefix so they appear on /debug/vars.
+// Counters are published individually and gauges as one map, all as expvar.Func
+// values that read the atomics on every scrape. expvar names are global, so
+// publishing the same prefix twice returns an error instead of panicking, even
+// from concurrent calls; only expvar.Publish called directly can still collide.
+func (m *Metrics) PublishExpvar(prefix string) error {
+	vars := map[string]expvar.Func{
+		prefix + "requests": func() interface{} {
+			return atomic.LoadInt64(&m.requests)
+		},
+		prefix + "errors": func() interface{} {
+			return atomic.LoadInt64(&m.errors)
+		},
+		prefix + "bytes": func() interface{} {
+			return atomic.LoadInt64(&m.totalBytes)
+		},
+		prefix + "gauges": func() interface{} {
+			return m.gaugeValues()
+		},
+	}
+
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+	for name := range vars {
+		if expvar.Get(name) != nil {
+			return fmt.Errorf("expvar %q already published", name)
+		}
+	}
+	for name, fn := range vars {
+		expvar.Publish(name, fn)
+	}
+	return nil
+}
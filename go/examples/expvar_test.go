@@ -0,0 +1,82 @@
+package examples
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// expvarRuns numbers the test's runs: expvar names are process-global and
+// cannot be unpublished, so each run of -count needs its own prefix
+var expvarRuns atomic.Int64
+
+func TestMetricsPublishExpvar(t *testing.T) {
+	g := NewWithT(t)
+
+	prefix := fmt.Sprintf("%s_%d_", t.Name(), expvarRuns.Add(1))
+	metrics := &Metrics{}
+	g.Expect(metrics.PublishExpvar(prefix)).To(Succeed())
+
+	// Publishing the same names again is rejected
+	g.Expect(metrics.PublishExpvar(prefix)).To(MatchError(ContainSubstring("already published")))
+
+	// Values are read live on every request
+	metrics.RecordRequest()
+	metrics.RecordRequest()
+	metrics.RecordError()
+	metrics.RecordBytes(512)
+	metrics.SetGauge("workers", 4)
+
+	server := httptest.NewServer(expvar.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/debug/vars")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer resp.Body.Close()
+
+	var vars map[string]json.RawMessage
+	g.Expect(json.NewDecoder(resp.Body).Decode(&vars)).To(Succeed())
+
+	// expvar's own variables are still there
+	g.Expect(vars).To(HaveKey("memstats"))
+
+	var requests, errors, bytes int64
+	g.Expect(json.Unmarshal(vars[prefix+"requests"], &requests)).To(Succeed())
+	g.Expect(json.Unmarshal(vars[prefix+"errors"], &errors)).To(Succeed())
+	g.Expect(json.Unmarshal(vars[prefix+"bytes"], &bytes)).To(Succeed())
+	g.Expect(requests).To(Equal(int64(2)))
+	g.Expect(errors).To(Equal(int64(1)))
+	g.Expect(bytes).To(Equal(int64(512)))
+
+	var gauges map[string]float64
+	g.Expect(json.Unmarshal(vars[prefix+"gauges"], &gauges)).To(Succeed())
+	g.Expect(gauges).To(Equal(map[string]float64{"workers": 4}))
+}
+
+// TestMetricsPublishExpvarConcurrent races several publishes of one prefix:
+// exactly one wins and the rest get an error rather than a panic
+func TestMetricsPublishExpvarConcurrent(t *testing.T) {
+	g := NewWithT(t)
+
+	prefix := fmt.Sprintf("%s_%d_", t.Name(), expvarRuns.Add(1))
+	var wg sync.WaitGroup
+	var ok atomic.Int64
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if (&Metrics{}).PublishExpvar(prefix) == nil {
+				ok.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	g.Expect(ok.Load()).To(Equal(int64(1)))
+}
@@ -0,0 +1,49 @@
+package examples
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// cacheLinePad is the size PaddedCounter rounds up to: 128 bytes, the line
+// size of arm64 and ppc64, which also covers amd64, whose prefetcher pulls
+// its 64-byte lines in adjacent pairs. s390x's 256-byte lines are the one
+// case CacheLineSize reports that it does not cover.
+const cacheLinePad = 128
+
+// CacheLineSize returns the cache line size of the CPU architecture the
+// program was built for, in bytes. Go has no portable runtime query for it,
+// so this is a per-GOARCH table, with 64 as the fallback.
+func CacheLineSize() int {
+	switch runtime.GOARCH {
+	case "arm64", "ppc64", "ppc64le":
+		return 128
+	case "s390x":
+		return 256
+	default:
+		return 64
+	}
+}
+
+// PaddedCounter is an atomic counter that fills a whole cache line on its own.
+//
+// Counters packed next to each other in a slice or struct share cache lines,
+// so goroutines incrementing different counters still fight over the same
+// line: each write invalidates it in every other core's cache. This is false
+// sharing, and it can make per-goroutine counters as slow as a single shared
+// one even though no value is shared. Padding costs memory, so use it only for
+// counters written on hot paths by different goroutines.
+type PaddedCounter struct {
+	n int64
+	_ [cacheLinePad - 8]byte
+}
+
+// Add adds delta and returns the new value
+func (c *PaddedCounter) Add(delta int64) int64 {
+	return atomic.AddInt64(&c.n, delta)
+}
+
+// Load returns the current value
+func (c *PaddedCounter) Load() int64 {
+	return atomic.LoadInt64(&c.n)
+}
@@ -0,0 +1,68 @@
+package examples
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"unsafe"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestPaddedCounter(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(CacheLineSize()).To(BeElementOf(64, 128, 256))
+	// Neighbouring counters in a slice are at least a line apart
+	counters := make([]PaddedCounter, 2)
+	gap := int(uintptr(unsafe.Pointer(&counters[1])) - uintptr(unsafe.Pointer(&counters[0])))
+	g.Expect(gap).To(BeNumerically(">=", 64))
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				counters[0].Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	g.Expect(counters[0].Load()).To(Equal(int64(4000)))
+	g.Expect(counters[1].Load()).To(BeZero())
+}
+
+// BenchmarkFalseSharing gives every goroutine its own counter and compares
+// counters packed into adjacent int64s with PaddedCounters. Nothing is shared,
+// so any gap between the two is the cost of false sharing; it grows with the
+// number of goroutines running on separate cores.
+func BenchmarkFalseSharing(b *testing.B) {
+	for _, goroutines := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("goroutines=%d/Packed", goroutines), func(b *testing.B) {
+			counters := make([]int64, goroutines)
+			runCounters(b, goroutines, func(i int) { atomic.AddInt64(&counters[i], 1) })
+		})
+		b.Run(fmt.Sprintf("goroutines=%d/Padded", goroutines), func(b *testing.B) {
+			counters := make([]PaddedCounter, goroutines)
+			runCounters(b, goroutines, func(i int) { counters[i].Add(1) })
+		})
+	}
+}
+
+// runCounters splits b.N increments across goroutines, goroutine i calling inc(i)
+func runCounters(b *testing.B, goroutines int, inc func(i int)) {
+	var wg sync.WaitGroup
+	per := b.N / goroutines
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < per; i++ {
+				inc(g)
+			}
+		}(g)
+	}
+	wg.Wait()
+}
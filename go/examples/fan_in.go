@@ -0,0 +1,33 @@
+package examples
+
+import (
+	"context"
+	"sync"
+)
+
+// Merge multiplexes chans onto one output channel, one forwarding goroutine per
+// input. The output closes once every input has closed, or once ctx is done;
+// after cancellation the forwarders stop even if the inputs stay open, so no
+// goroutine leaks while waiting on a reader that has gone away. Values from one
+// input keep their order; there is no ordering between inputs.
+func Merge[T any](ctx context.Context, chans ...<-chan T) <-chan T {
+	out := make(chan T)
+	var wg sync.WaitGroup
+	wg.Add(len(chans))
+	for _, ch := range chans {
+		go func(ch <-chan T) {
+			defer wg.Done()
+			for {
+				v, err := RecvCtx(ctx, ch)
+				if err != nil || SendCtx(ctx, out, v) != nil {
+					return
+				}
+			}
+		}(ch)
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
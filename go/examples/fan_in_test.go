@@ -0,0 +1,92 @@
+package examples
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// sendAll returns a channel that yields values and then closes
+func sendAll(values ...int) <-chan int {
+	ch := make(chan int)
+	go func() {
+		defer close(ch)
+		for _, v := range values {
+			ch <- v
+		}
+	}()
+	return ch
+}
+
+func TestMergeCompleteness(t *testing.T) {
+	g := NewWithT(t)
+
+	var inputs []<-chan int
+	var want []int
+	for i := 0; i < 10; i++ {
+		values := make([]int, 100)
+		for j := range values {
+			values[j] = i*100 + j
+		}
+		want = append(want, values...)
+		inputs = append(inputs, sendAll(values...))
+	}
+
+	var got []int
+	last := map[int]int{} // Input -> last value seen, to check per-input order
+	for v := range Merge(context.Background(), inputs...) {
+		g.Expect(v).To(BeNumerically(">", last[v/100]-1))
+		last[v/100] = v
+		got = append(got, v)
+	}
+	sort.Ints(got)
+	g.Expect(got).To(Equal(want))
+}
+
+func TestMergeNoInputs(t *testing.T) {
+	g := NewWithT(t)
+
+	_, ok := <-Merge[string](context.Background())
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestMergeCancellation(t *testing.T) {
+	g := NewWithT(t)
+
+	before := runtime.NumGoroutine()
+
+	// Inputs that never close, and a reader that stops after a few values
+	never := make(chan int)
+	busy := make(chan int)
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case busy <- i:
+			case <-time.After(50 * time.Millisecond):
+				return
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := Merge(ctx, never, busy)
+	for i := 0; i < 3; i++ {
+		<-out
+	}
+	cancel()
+
+	// Output closes promptly even though neither input did
+	g.Eventually(func() bool {
+		select {
+		case _, ok := <-out:
+			return !ok
+		default:
+			return false
+		}
+	}).Should(BeTrue())
+	g.Eventually(runtime.NumGoroutine, 2*time.Second).Should(BeNumerically("<=", before))
+}
@@ -0,0 +1,68 @@
+package examples
+
+import "context"
+
+// FanOutMode chooses how FanOut hands items to its outputs
+type FanOutMode int
+
+const (
+	// RoundRobin sends each item to exactly one output, cycling through them in turn
+	RoundRobin FanOutMode = iota
+	// Broadcast sends every item to every output
+	Broadcast
+)
+
+// FanOut distributes items from in over n outputs from a single goroutine, to
+// feed several workers from one producer. Sends are unbuffered, so in
+// RoundRobin mode a slow worker holds up its turn, and in Broadcast mode the
+// slowest worker sets the pace for all. Every output closes once in closes or
+// ctx is done; after cancellation items not yet delivered are dropped.
+func FanOut[T any](ctx context.Context, in <-chan T, n int, mode FanOutMode) []<-chan T {
+	if n < 1 {
+		n = 1
+	}
+	outs := make([]chan T, n)
+	result := make([]<-chan T, n)
+	for i := range outs {
+		outs[i] = make(chan T)
+		result[i] = outs[i]
+	}
+
+	go func() {
+		defer func() {
+			for _, out := range outs {
+				close(out)
+			}
+		}()
+		send := func(out chan T, v T) bool {
+			select {
+			case out <- v:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for next := 0; ; next = (next + 1) % n {
+			var v T
+			select {
+			case item, ok := <-in:
+				if !ok {
+					return
+				}
+				v = item
+			case <-ctx.Done():
+				return
+			}
+			if mode == Broadcast {
+				for _, out := range outs {
+					if !send(out, v) {
+						return
+					}
+				}
+			} else if !send(outs[next], v) {
+				return
+			}
+		}
+	}()
+	return result
+}
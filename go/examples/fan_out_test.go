@@ -0,0 +1,76 @@
+package examples
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// drainAll reads every output concurrently, as independent workers would
+func drainAll(outs []<-chan int) [][]int {
+	results := make([][]int, len(outs))
+	done := make(chan struct{})
+	for i, out := range outs {
+		go func(i int, out <-chan int) {
+			for v := range out {
+				results[i] = append(results[i], v)
+			}
+			done <- struct{}{}
+		}(i, out)
+	}
+	for range outs {
+		<-done
+	}
+	return results
+}
+
+func TestFanOutRoundRobin(t *testing.T) {
+	g := NewWithT(t)
+
+	values := make([]int, 99)
+	for i := range values {
+		values[i] = i
+	}
+	results := drainAll(FanOut(context.Background(), sendAll(values...), 3, RoundRobin))
+
+	// Each item goes to exactly one worker, and turns rotate strictly
+	var got []int
+	for i, r := range results {
+		g.Expect(r).To(HaveLen(33))
+		for j, v := range r {
+			g.Expect(v).To(Equal(j*3 + i))
+		}
+		got = append(got, r...)
+	}
+	sort.Ints(got)
+	g.Expect(got).To(Equal(values))
+}
+
+func TestFanOutBroadcast(t *testing.T) {
+	g := NewWithT(t)
+
+	results := drainAll(FanOut(context.Background(), sendAll(1, 2, 3, 4), 4, Broadcast))
+	g.Expect(results).To(HaveLen(4))
+	for _, r := range results {
+		g.Expect(r).To(Equal([]int{1, 2, 3, 4}))
+	}
+}
+
+func TestFanOutCancellation(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int) // Never closed
+	outs := FanOut(ctx, in, 2, RoundRobin)
+
+	in <- 1
+	g.Expect(<-outs[0]).To(Equal(1))
+	in <- 2 // Waits for outs[1], which nobody reads
+	cancel()
+
+	for _, out := range outs {
+		g.Eventually(out).Should(BeClosed())
+	}
+}
@@ -0,0 +1,69 @@
+package examples
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// FastCounterVec is a CounterVec whose lookups never lock. The label -> counter
+// index is an immutable map behind an atomic pointer: With loads the pointer and
+// reads the map, and only registering a new label set copies the map under a
+// mutex and swaps the pointer. Best when label sets are few and stable, since
+// each registration costs a full copy.
+type FastCounterVec struct {
+	counterFamily
+
+	index atomic.Pointer[map[string]*Counter]
+	mu    sync.Mutex // Serialises copy-on-write registrations
+}
+
+// NewFastCounterVec creates a lock-free counter family with the given label names
+func NewFastCounterVec(name, help string, labelNames ...string) *FastCounterVec {
+	v := &FastCounterVec{counterFamily: newCounterFamily(name, help, labelNames)}
+	empty := map[string]*Counter{}
+	v.index.Store(&empty)
+	return v
+}
+
+// With returns the counter for the given label values, in label-name order.
+// It panics if the number of values does not match the label names.
+func (v *FastCounterVec) With(labelValues ...string) *Counter {
+	key := v.key(labelValues)
+	if c, ok := (*v.index.Load())[key]; ok {
+		return c
+	}
+	return v.register(key)
+}
+
+func (v *FastCounterVec) register(key string) *Counter {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	old := *v.index.Load()
+	if c, ok := old[key]; ok {
+		return c // Registered by another goroutine while we waited
+	}
+	next := make(map[string]*Counter, len(old)+1)
+	for k, c := range old {
+		next[k] = c
+	}
+	c := &Counter{}
+	next[key] = c
+	v.index.Store(&next)
+	return c
+}
+
+// Snapshot returns every labeled counter, sorted by label values
+func (v *FastCounterVec) Snapshot() []CounterSample {
+	index := *v.index.Load()
+	values := make(map[string]int64, len(index))
+	for key, c := range index {
+		values[key] = c.Value()
+	}
+	return v.samples(values)
+}
+
+// WritePrometheus renders every labeled counter as one Prometheus counter family
+func (v *FastCounterVec) WritePrometheus(w io.Writer) error {
+	return v.writePrometheus(w, v.Snapshot())
+}
@@ -0,0 +1,81 @@
+package examples
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestFastCounterVec(t *testing.T) {
+	g := NewWithT(t)
+
+	v := NewFastCounterVec("http_requests_total", "Requests by method and status.", "method", "status")
+	v.With("GET", "200").Inc()
+	v.With("GET", "200").Inc()
+	v.With("POST", "500").Add(3)
+
+	g.Expect(v.With("GET", "200")).To(BeIdenticalTo(v.With("GET", "200")))
+	g.Expect(v.Snapshot()).To(Equal([]CounterSample{
+		{Labels: map[string]string{"method": "GET", "status": "200"}, Value: 2},
+		{Labels: map[string]string{"method": "POST", "status": "500"}, Value: 3},
+	}))
+	g.Expect(func() { v.With("GET") }).To(Panic())
+
+	var buf strings.Builder
+	g.Expect(v.WritePrometheus(&buf)).To(Succeed())
+	g.Expect(buf.String()).To(ContainSubstring(`http_requests_total{method="POST",status="500"} 3`))
+}
+
+func TestFastCounterVecConcurrentRegistration(t *testing.T) {
+	g := NewWithT(t)
+
+	// Goroutines racing to register the same labels must share one counter
+	v := NewFastCounterVec("hits", "Hits by shard.", "shard")
+	labels := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				v.With(labels[j%len(labels)]).Inc()
+			}
+		}()
+	}
+	wg.Wait()
+
+	samples := v.Snapshot()
+	g.Expect(samples).To(HaveLen(len(labels)))
+	for _, s := range samples {
+		g.Expect(s.Value).To(Equal(int64(2000)), s.Labels["shard"])
+	}
+}
+
+var benchLabels = [][]string{
+	{"GET", "200"}, {"GET", "404"}, {"POST", "201"}, {"POST", "500"},
+}
+
+func BenchmarkCounterVecWith(b *testing.B) {
+	v := NewCounterVec("requests", "Requests.", "method", "status")
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			v.With(benchLabels[i%len(benchLabels)]...).Inc()
+			i++
+		}
+	})
+}
+
+func BenchmarkFastCounterVecWith(b *testing.B) {
+	v := NewFastCounterVec("requests", "Requests.", "method", "status")
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			v.With(benchLabels[i%len(benchLabels)]...).Inc()
+			i++
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package examples
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoReplicas is returned by First when it is given no functions to race
+var ErrNoReplicas = errors.New("first: no functions to call")
+
+// First runs every fn concurrently against the same request and returns the
+// first successful result, cancelling the context passed to the others so
+// redundant replicas stop working. If every fn fails, the errors are joined in
+// the order the calls were given. If ctx is done first, its error is returned
+// without waiting for the stragglers, which exit through their own contexts.
+func First[T any](ctx context.Context, fns ...func(context.Context) (T, error)) (T, error) {
+	var zero T
+	if len(fns) == 0 {
+		return zero, ErrNoReplicas
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		i   int
+		v   T
+		err error
+	}
+	// Buffered so losers never block on a receiver that has already returned
+	results := make(chan result, len(fns))
+	for i, fn := range fns {
+		go func(i int, fn func(context.Context) (T, error)) {
+			v, err := fn(ctx)
+			results <- result{i, v, err}
+		}(i, fn)
+	}
+
+	errs := make([]error, len(fns))
+	for range fns {
+		select {
+		case r := <-results:
+			if r.err == nil {
+				return r.v, nil
+			}
+			errs[r.i] = r.err
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		}
+	}
+	return zero, errors.Join(errs...)
+}
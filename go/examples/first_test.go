@@ -0,0 +1,81 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// replica answers v after delay, or gives up with ctx's error when cancelled
+// first; cancelled counts the replicas that were cut short
+func replica(v int, delay time.Duration, cancelled *int32) func(context.Context) (int, error) {
+	return func(ctx context.Context) (int, error) {
+		select {
+		case <-time.After(delay):
+			return v, nil
+		case <-ctx.Done():
+			atomic.AddInt32(cancelled, 1)
+			return 0, ctx.Err()
+		}
+	}
+}
+
+func TestFirstFastestWins(t *testing.T) {
+	g := NewWithT(t)
+
+	var cancelled int32
+	start := time.Now()
+	v, err := First(context.Background(),
+		replica(1, time.Second, &cancelled),
+		replica(2, 10*time.Millisecond, &cancelled),
+		replica(3, time.Second, &cancelled),
+	)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(v).To(Equal(2))
+	g.Expect(time.Since(start)).To(BeNumerically("<", 500*time.Millisecond))
+
+	// The slow replicas see the cancellation instead of running to completion
+	g.Eventually(func() int32 { return atomic.LoadInt32(&cancelled) }).Should(Equal(int32(2)))
+}
+
+func TestFirstSuccessBeatsEarlierFailure(t *testing.T) {
+	g := NewWithT(t)
+
+	var cancelled int32
+	fail := func(context.Context) (int, error) { return 0, errors.New("boom") }
+	v, err := First(context.Background(), fail, replica(7, 20*time.Millisecond, &cancelled))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(v).To(Equal(7))
+}
+
+func TestFirstAllFail(t *testing.T) {
+	g := NewWithT(t)
+
+	errA, errB := errors.New("a"), errors.New("b")
+	v, err := First(context.Background(),
+		func(context.Context) (int, error) { time.Sleep(10 * time.Millisecond); return 1, errA },
+		func(context.Context) (int, error) { return 2, errB },
+	)
+	g.Expect(v).To(BeZero())
+	g.Expect(err).To(MatchError(errA))
+	g.Expect(err).To(MatchError(errB))
+	g.Expect(err.Error()).To(Equal("a\nb"))
+
+	_, err = First[int](context.Background())
+	g.Expect(err).To(MatchError(ErrNoReplicas))
+}
+
+func TestFirstCancellation(t *testing.T) {
+	g := NewWithT(t)
+
+	var cancelled int32
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := First(ctx, replica(1, time.Second, &cancelled), replica(2, time.Second, &cancelled))
+	g.Expect(err).To(MatchError(context.DeadlineExceeded))
+	g.Eventually(func() int32 { return atomic.LoadInt32(&cancelled) }).Should(Equal(int32(2)))
+}
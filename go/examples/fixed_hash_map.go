@@ -0,0 +1,149 @@
+package examples
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// FixedHashMap bucket states. Any other value means the bucket is being
+// claimed, and holds a fingerprint of the key being written into it.
+const (
+	bucketEmpty   uint32 = iota // Never used
+	bucketClaimed               // Key set for good
+)
+
+// claimingState is the state of a bucket while key, with hash h, is being
+// written into it: bit 1 is always set, so it is neither empty nor claimed,
+// and the rest is a fingerprint that tells other inserters whether the key
+// could be theirs
+func claimingState(h uint64) uint32 {
+	return uint32(h>>32) | 2
+}
+
+// fixedBucket is one slot of the table. Its key never changes once claimed,
+// so a deleted key keeps its bucket and reuses it when it is put again.
+type fixedBucket[V any] struct {
+	state atomic.Uint32
+	key   atomic.Uint64
+	value atomic.Pointer[V] // Nil when the key is absent
+}
+
+// FixedHashMap is a concurrent open-addressing hash table with uint64 keys and
+// a capacity fixed at creation. Collisions probe linearly to the next bucket.
+// Get takes no locks and writes nothing; Put and Delete only CAS the bucket
+// they touch, so nothing ever waits on a lock and there is no resizing pause.
+//
+// A bucket is claimed by the first key put into it and keeps that key for
+// good: Delete clears only the value. This is what lets lookups stop at the
+// first empty bucket without tombstones, and it suits what the map is for, a
+// small bounded set of keys such as worker or shard IDs. A workload with
+// ever-changing keys fills the table and Put starts failing.
+type FixedHashMap[V any] struct {
+	buckets []fixedBucket[V]
+	mask    uint64
+	size    atomic.Int64
+}
+
+// NewFixedHashMap creates a map able to hold capacity distinct keys, rounded
+// up to a power of two. Probe chains stay short while under about 70% full.
+func NewFixedHashMap[V any](capacity int) *FixedHashMap[V] {
+	n := 1
+	for n < capacity {
+		n <<= 1
+	}
+	return &FixedHashMap[V]{buckets: make([]fixedBucket[V], n), mask: uint64(n - 1)}
+}
+
+// hashKey scrambles key so that sequential IDs spread over the table
+// (splitmix64's finaliser)
+func hashKey(key uint64) uint64 {
+	key ^= key >> 30
+	key *= 0xbf58476d1ce4e5b9
+	key ^= key >> 27
+	key *= 0x94d049bb133111eb
+	key ^= key >> 31
+	return key
+}
+
+// find returns key's bucket, claiming an empty one for it if claim is set, or
+// nil if the key has no bucket (or, when claiming, the table is full).
+//
+// A bucket being claimed has no key yet, so a lookup treats it as some other
+// key's and probes on: the key it may become is not present until its value
+// is stored. Only an inserter whose key has the same fingerprint waits for
+// the claim to finish, since it may be racing to insert the same key.
+func (m *FixedHashMap[V]) find(key uint64, claim bool) *fixedBucket[V] {
+	h := hashKey(key)
+	claiming := claimingState(h)
+	i := h
+	for probes := 0; probes < len(m.buckets); probes++ {
+		b := &m.buckets[i&m.mask]
+		state := b.state.Load()
+		if state == bucketEmpty {
+			if !claim {
+				return nil // Key would have been placed here or earlier
+			}
+			if b.state.CompareAndSwap(bucketEmpty, claiming) {
+				b.key.Store(key)
+				b.state.Store(bucketClaimed)
+				return b
+			}
+			state = b.state.Load()
+		}
+		for claim && state == claiming {
+			// Possibly our key, claimed by a concurrent Put: a claim only
+			// takes a moment, so wait to see it
+			runtime.Gosched()
+			state = b.state.Load()
+		}
+		if state == bucketClaimed && b.key.Load() == key {
+			return b
+		}
+		i++
+	}
+	return nil
+}
+
+// Get returns the value for key
+func (m *FixedHashMap[V]) Get(key uint64) (V, bool) {
+	if b := m.find(key, false); b != nil {
+		if p := b.value.Load(); p != nil {
+			return *p, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Put sets key to value. It reports false, storing nothing, if key is new and
+// every bucket is already claimed by another key.
+func (m *FixedHashMap[V]) Put(key uint64, value V) bool {
+	b := m.find(key, true)
+	if b == nil {
+		return false
+	}
+	if b.value.Swap(&value) == nil {
+		m.size.Add(1)
+	}
+	return true
+}
+
+// Delete removes key, reporting whether it was present
+func (m *FixedHashMap[V]) Delete(key uint64) bool {
+	b := m.find(key, false)
+	if b == nil || b.value.Swap(nil) == nil {
+		return false
+	}
+	m.size.Add(-1)
+	return true
+}
+
+// Len returns the number of keys present; under concurrent use it may be briefly stale
+func (m *FixedHashMap[V]) Len() int {
+	return int(m.size.Load())
+}
+
+// Cap returns the number of buckets, the most distinct keys the map can ever hold
+func (m *FixedHashMap[V]) Cap() int {
+	return len(m.buckets)
+}
@@ -0,0 +1,161 @@
+package examples
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestFixedHashMap(t *testing.T) {
+	g := NewWithT(t)
+
+	m := NewFixedHashMap[string](3)
+	g.Expect(m.Cap()).To(Equal(4))
+	g.Expect(m.Put(1, "a")).To(BeTrue())
+	g.Expect(m.Put(2, "b")).To(BeTrue())
+	g.Expect(m.Put(1, "A")).To(BeTrue())
+	g.Expect(m.Len()).To(Equal(2))
+
+	v, ok := m.Get(1)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(v).To(Equal("A"))
+	_, ok = m.Get(3)
+	g.Expect(ok).To(BeFalse())
+
+	g.Expect(m.Delete(2)).To(BeTrue())
+	g.Expect(m.Delete(2)).To(BeFalse())
+	_, ok = m.Get(2)
+	g.Expect(ok).To(BeFalse())
+	g.Expect(m.Len()).To(Equal(1))
+
+	// Deleted keys keep their bucket, so the table fills by distinct keys ever put
+	g.Expect(m.Put(3, "c")).To(BeTrue())
+	g.Expect(m.Put(4, "d")).To(BeTrue())
+	g.Expect(m.Put(5, "e")).To(BeFalse())
+	g.Expect(m.Put(2, "b")).To(BeTrue())
+	g.Expect(m.Len()).To(Equal(4))
+}
+
+func TestFixedHashMapConcurrent(t *testing.T) {
+	g := NewWithT(t)
+
+	// Writers race to claim buckets for overlapping keys; every key must end
+	// up in exactly one bucket, holding some writer's value for it
+	const keys = 200
+	m := NewFixedHashMap[int](256)
+	var failedPuts atomic.Int64
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(int64(w)))
+			for i := 0; i < 2000; i++ {
+				k := uint64(rng.Intn(keys))
+				switch rng.Intn(4) {
+				case 0:
+					m.Delete(k)
+				case 1:
+					m.Get(k)
+				default:
+					if !m.Put(k, int(k)*10) {
+						failedPuts.Add(1)
+					}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	g.Expect(failedPuts.Load()).To(BeZero())
+
+	present := 0
+	for k := uint64(0); k < keys; k++ {
+		if v, ok := m.Get(k); ok {
+			g.Expect(v).To(Equal(int(k) * 10))
+			present++
+		}
+	}
+	g.Expect(m.Len()).To(Equal(present))
+
+	claimed := map[uint64]int{}
+	for i := range m.buckets {
+		if m.buckets[i].state.Load() == bucketClaimed {
+			claimed[m.buckets[i].key.Load()]++
+		}
+	}
+	for k, n := range claimed {
+		g.Expect(n).To(Equal(1), "key %d claimed %d buckets", k, n)
+	}
+}
+
+// BenchmarkFixedHashMap compares FixedHashMap with sync.Map on a small fixed
+// key set, 90% reads
+func BenchmarkFixedHashMap(b *testing.B) {
+	for _, keys := range []int{64, 1024} {
+		b.Run(fmt.Sprintf("keys=%d/FixedHashMap", keys), func(b *testing.B) {
+			m := NewFixedHashMap[int](keys * 2)
+			for k := 0; k < keys; k++ {
+				m.Put(uint64(k), k)
+			}
+			b.RunParallel(func(pb *testing.PB) {
+				rng := rand.New(rand.NewSource(rand.Int63()))
+				for pb.Next() {
+					k := uint64(rng.Intn(keys))
+					if rng.Intn(10) == 0 {
+						m.Put(k, int(k))
+					} else {
+						m.Get(k)
+					}
+				}
+			})
+		})
+		b.Run(fmt.Sprintf("keys=%d/SyncMap", keys), func(b *testing.B) {
+			var m sync.Map
+			for k := 0; k < keys; k++ {
+				m.Store(uint64(k), k)
+			}
+			b.RunParallel(func(pb *testing.PB) {
+				rng := rand.New(rand.NewSource(rand.Int63()))
+				for pb.Next() {
+					k := uint64(rng.Intn(keys))
+					if rng.Intn(10) == 0 {
+						m.Store(k, int(k))
+					} else {
+						m.Load(k)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestFixedHashMapStalledClaim(t *testing.T) {
+	g := NewWithT(t)
+
+	// Leave key 1's bucket mid-claim, as an inserter preempted between
+	// claiming it and writing the key would
+	m := NewFixedHashMap[string](4)
+	h := hashKey(1)
+	m.buckets[h&m.mask].state.Store(claimingState(h))
+	g.Expect(claimingState(hashKey(2))).NotTo(Equal(claimingState(h)))
+
+	// Lookups and other keys' inserts probe past it instead of waiting
+	type result struct {
+		found, deleted, put bool
+		got                 string
+	}
+	done := make(chan result, 1)
+	go func() {
+		var r result
+		_, r.found = m.Get(1)
+		r.deleted = m.Delete(1)
+		r.put = m.Put(2, "two")
+		r.got, _ = m.Get(2)
+		done <- r
+	}()
+	g.Eventually(done).Should(Receive(Equal(result{put: true, got: "two"})))
+}
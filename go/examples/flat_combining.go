@@ -0,0 +1,185 @@
+package examples
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Flat combining slot states
+const (
+	fcFree    uint32 = iota // Unowned
+	fcClaimed               // Owned, argument being written
+	fcPending               // Argument published, waiting for a combiner
+	fcDone                  // Result written by a combiner
+)
+
+// fcSlot is one publication record. Slots are padded so that goroutines
+// publishing requests do not falsely share lines with each other.
+type fcSlot[A, R any] struct {
+	state  atomic.Uint32
+	arg    A
+	result R
+	_      [cacheLinePad]byte
+}
+
+// FlatCombiner serialises operations on a shared state S without every
+// goroutine taking a lock. A caller publishes its argument in a slot and then
+// tries the combiner lock; whoever gets it becomes the combiner and applies
+// every published operation in one pass, while the others just wait for their
+// result. Under heavy contention the state stays in the combiner's cache and
+// the lock changes hands once per batch rather than once per operation, which
+// can beat a plain mutex when many cores hammer one small structure. With few
+// goroutines the extra hand-off only adds latency; see BenchmarkFlatCombining.
+type FlatCombiner[S, A, R any] struct {
+	mu    sync.Mutex // Held by the current combiner
+	state S
+	apply func(s *S, arg A) R
+	slots []fcSlot[A, R]
+	next  atomic.Uint32 // Where the next caller starts looking for a free slot
+}
+
+// NewFlatCombiner creates a combiner over state, applying operations with
+// apply. slots bounds how many operations can be waiting at once; 0 means
+// twice GOMAXPROCS. Callers that find every slot taken apply their operation
+// under the lock directly.
+func NewFlatCombiner[S, A, R any](state S, slots int, apply func(s *S, arg A) R) *FlatCombiner[S, A, R] {
+	if slots <= 0 {
+		slots = 2 * runtime.GOMAXPROCS(0)
+	}
+	return &FlatCombiner[S, A, R]{
+		state: state,
+		apply: apply,
+		slots: make([]fcSlot[A, R], slots),
+	}
+}
+
+// Do applies arg to the state and returns the result. Operations are
+// linearised in the order the combiners apply them.
+func (c *FlatCombiner[S, A, R]) Do(arg A) R {
+	slot := c.claim()
+	if slot == nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.apply(&c.state, arg)
+	}
+	slot.arg = arg
+	slot.state.Store(fcPending)
+
+	for {
+		if slot.state.Load() == fcDone {
+			break
+		}
+		if c.mu.TryLock() {
+			c.combine()
+			c.mu.Unlock()
+			break // Our own slot was pending, so combine served it
+		}
+		runtime.Gosched()
+	}
+	result := slot.result
+	var zero R
+	slot.result = zero
+	slot.state.Store(fcFree)
+	return result
+}
+
+// claim finds a free slot and marks it as ours, or returns nil if all are taken
+func (c *FlatCombiner[S, A, R]) claim() *fcSlot[A, R] {
+	n := uint32(len(c.slots))
+	start := c.next.Add(1)
+	for i := uint32(0); i < n; i++ {
+		s := &c.slots[(start+i)%n]
+		if s.state.CompareAndSwap(fcFree, fcClaimed) {
+			return s
+		}
+	}
+	return nil
+}
+
+// combine applies every pending operation. Called with mu held.
+func (c *FlatCombiner[S, A, R]) combine() {
+	for i := range c.slots {
+		s := &c.slots[i]
+		if s.state.Load() != fcPending {
+			continue
+		}
+		s.result = c.apply(&c.state, s.arg)
+		var zero A
+		s.arg = zero
+		s.state.Store(fcDone)
+	}
+}
+
+// FCCounter is a counter whose updates go through a FlatCombiner
+type FCCounter struct {
+	fc *FlatCombiner[int64, int64, int64]
+}
+
+// NewFCCounter creates a counter with the given number of publication slots
+// (0 for the default)
+func NewFCCounter(slots int) *FCCounter {
+	return &FCCounter{fc: NewFlatCombiner(int64(0), slots, func(n *int64, delta int64) int64 {
+		*n += delta
+		return *n
+	})}
+}
+
+// Add adds delta and returns the new value
+func (c *FCCounter) Add(delta int64) int64 {
+	return c.fc.Do(delta)
+}
+
+// Load returns the current value
+func (c *FCCounter) Load() int64 {
+	return c.fc.Do(0)
+}
+
+// fcQueueOp is an FCQueue operation: an enqueue of v, or a dequeue
+type fcQueueOp[T any] struct {
+	enqueue bool
+	v       T
+}
+
+// fcQueueResult is a dequeued value, or ok false for an empty queue
+type fcQueueResult[T any] struct {
+	v  T
+	ok bool
+}
+
+// FCQueue is an unbounded FIFO queue whose operations go through a
+// FlatCombiner. The combiner owns the backing slice, so the queue itself is a
+// plain sequential one.
+type FCQueue[T any] struct {
+	fc *FlatCombiner[[]T, fcQueueOp[T], fcQueueResult[T]]
+}
+
+// NewFCQueue creates an empty queue with the given number of publication
+// slots (0 for the default)
+func NewFCQueue[T any](slots int) *FCQueue[T] {
+	return &FCQueue[T]{fc: NewFlatCombiner([]T(nil), slots, func(items *[]T, op fcQueueOp[T]) fcQueueResult[T] {
+		if op.enqueue {
+			*items = append(*items, op.v)
+			return fcQueueResult[T]{}
+		}
+		if len(*items) == 0 {
+			return fcQueueResult[T]{}
+		}
+		v := (*items)[0]
+		var zero T
+		(*items)[0] = zero
+		*items = (*items)[1:]
+		return fcQueueResult[T]{v: v, ok: true}
+	})}
+}
+
+// Enqueue adds v to the back of the queue
+func (q *FCQueue[T]) Enqueue(v T) {
+	q.fc.Do(fcQueueOp[T]{enqueue: true, v: v})
+}
+
+// Dequeue removes and returns the front item, with ok false if the queue is empty
+func (q *FCQueue[T]) Dequeue() (T, bool) {
+	r := q.fc.Do(fcQueueOp[T]{})
+	return r.v, r.ok
+}
@@ -0,0 +1,152 @@
+package examples
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestFCCounter(t *testing.T) {
+	g := NewWithT(t)
+
+	// One slot forces most callers onto the direct-lock fallback as well
+	for _, slots := range []int{0, 1} {
+		c := NewFCCounter(slots)
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					c.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+		g.Expect(c.Load()).To(Equal(int64(8000)))
+	}
+}
+
+func TestFCQueue(t *testing.T) {
+	g := NewWithT(t)
+
+	q := NewFCQueue[int](0)
+	_, ok := q.Dequeue()
+	g.Expect(ok).To(BeFalse())
+
+	const producers, perProducer = 4, 500
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				q.Enqueue(p*perProducer + i)
+			}
+		}(p)
+	}
+
+	// Each producer's items come out in the order it enqueued them
+	last := make([]int, producers)
+	for i := range last {
+		last[i] = -1
+	}
+	for got := 0; got < producers*perProducer; {
+		v, ok := q.Dequeue()
+		if !ok {
+			continue
+		}
+		p, i := v/perProducer, v%perProducer
+		g.Expect(i).To(BeNumerically(">", last[p]))
+		last[p] = i
+		got++
+	}
+	wg.Wait()
+	_, ok = q.Dequeue()
+	g.Expect(ok).To(BeFalse())
+}
+
+// mutexCounter is the plain-mutex baseline for FCCounter
+type mutexCounter struct {
+	mu sync.Mutex
+	n  int64
+}
+
+func (c *mutexCounter) Add(delta int64) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.n += delta
+	return c.n
+}
+
+// mutexQueue is the plain-mutex baseline for FCQueue
+type mutexQueue[T any] struct {
+	mu    sync.Mutex
+	items []T
+}
+
+func (q *mutexQueue[T]) Enqueue(v T) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.items = append(q.items, v)
+}
+
+func (q *mutexQueue[T]) Dequeue() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var zero T
+	if len(q.items) == 0 {
+		return zero, false
+	}
+	v := q.items[0]
+	q.items = q.items[1:]
+	return v, true
+}
+
+// BenchmarkFlatCombining runs every goroutine against one counter or queue.
+// Flat combining only pays off with many goroutines on many cores, where a
+// mutex's lock word and the data bounce between caches on every operation;
+// with parallelism 1 the mutex is faster.
+func BenchmarkFlatCombining(b *testing.B) {
+	type counter interface{ Add(int64) int64 }
+	type queue interface {
+		Enqueue(int)
+		Dequeue() (int, bool)
+	}
+	for _, par := range []int{1, 4, 16} {
+		counters := []struct {
+			name string
+			c    counter
+		}{{"Mutex", &mutexCounter{}}, {"FlatCombining", NewFCCounter(0)}}
+		for _, impl := range counters {
+			b.Run(fmt.Sprintf("Counter/par=%d/%s", par, impl.name), func(b *testing.B) {
+				b.SetParallelism(par)
+				b.RunParallel(func(pb *testing.PB) {
+					for pb.Next() {
+						impl.c.Add(1)
+					}
+				})
+			})
+		}
+		queues := []struct {
+			name string
+			q    queue
+		}{{"Mutex", &mutexQueue[int]{}}, {"FlatCombining", NewFCQueue[int](0)}}
+		for _, impl := range queues {
+			b.Run(fmt.Sprintf("Queue/par=%d/%s", par, impl.name), func(b *testing.B) {
+				b.SetParallelism(par)
+				b.RunParallel(func(pb *testing.PB) {
+					for i := 0; pb.Next(); i++ {
+						if i%2 == 0 {
+							impl.q.Enqueue(i)
+						} else {
+							impl.q.Dequeue()
+						}
+					}
+				})
+			})
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package examples
+
+import "sync/atomic"
+
+// Tagged references pack a 32-bit generation above a 32-bit slot index plus
+// one, so zero means empty
+func packTagged(gen, ref uint32) uint64       { return uint64(gen)<<32 | uint64(ref) }
+func unpackTagged(t uint64) (gen, ref uint32) { return uint32(t >> 32), uint32(t) }
+
+type freeNode[T any] struct {
+	value T
+	next  uint32 // Index plus one of the next free node; accessed atomically
+}
+
+// FreeList hands out and takes back slots of a fixed slab with CAS on a
+// linked list of free slots, so lock-free structures can recycle nodes
+// instead of allocating one per operation.
+//
+// Recycling is what exposes a CAS list to ABA: Alloc reads head A and A's
+// next B, other goroutines take A and B and free A again, and a CAS that only
+// compared A would install B, which is in use. So the head carries a
+// generation bumped on every change, and the CAS compares both: the second A
+// has a new generation and the stale CAS fails. A 32-bit generation only
+// wraps after 4 billion changes during one Alloc.
+type FreeList[T any] struct {
+	nodes []freeNode[T]
+	head  uint64 // Tagged reference to the first free slot
+	free  int64
+}
+
+// NewFreeList creates a list of capacity slots, all free
+func NewFreeList[T any](capacity int) *FreeList[T] {
+	f := &FreeList[T]{nodes: make([]freeNode[T], capacity), free: int64(capacity)}
+	for i := range f.nodes {
+		if i+1 < capacity {
+			f.nodes[i].next = uint32(i + 2)
+		}
+	}
+	if capacity > 0 {
+		f.head = packTagged(0, 1)
+	}
+	return f
+}
+
+// Alloc takes a free slot and returns its index, or reports false if none is left
+func (f *FreeList[T]) Alloc() (int, bool) {
+	for {
+		head := atomic.LoadUint64(&f.head)
+		gen, ref := unpackTagged(head)
+		if ref == 0 {
+			return 0, false
+		}
+		next := atomic.LoadUint32(&f.nodes[ref-1].next)
+		if atomic.CompareAndSwapUint64(&f.head, head, packTagged(gen+1, next)) {
+			atomic.AddInt64(&f.free, -1)
+			return int(ref - 1), true
+		}
+	}
+}
+
+// Free returns slot i to the list. The caller must not touch it afterwards.
+func (f *FreeList[T]) Free(i int) {
+	for {
+		head := atomic.LoadUint64(&f.head)
+		gen, ref := unpackTagged(head)
+		atomic.StoreUint32(&f.nodes[i].next, ref)
+		if atomic.CompareAndSwapUint64(&f.head, head, packTagged(gen+1, uint32(i+1))) {
+			atomic.AddInt64(&f.free, 1)
+			return
+		}
+	}
+}
+
+// At returns the value in slot i, for the slot's current owner to use
+func (f *FreeList[T]) At(i int) *T {
+	return &f.nodes[i].value
+}
+
+// Available returns the number of free slots
+func (f *FreeList[T]) Available() int {
+	return int(atomic.LoadInt64(&f.free))
+}
+
+// Cap returns the number of slots
+func (f *FreeList[T]) Cap() int {
+	return len(f.nodes)
+}
+
+type pooledStackNode[T any] struct {
+	value T
+	next  uint32 // Index plus one of the node below; accessed atomically
+}
+
+// PooledStack is LockFreeStack with its nodes drawn from a FreeList, so Push
+// and Pop allocate nothing. The price is a fixed capacity and, since nodes
+// are now reused, the same generation-tagged head as the free list to stop ABA.
+type PooledStack[T any] struct {
+	nodes *FreeList[pooledStackNode[T]]
+	head  uint64 // Tagged reference to the top node
+	size  int64
+}
+
+// NewPooledStack creates a stack holding at most capacity values
+func NewPooledStack[T any](capacity int) *PooledStack[T] {
+	return &PooledStack[T]{nodes: NewFreeList[pooledStackNode[T]](capacity)}
+}
+
+// Push adds v on top, or reports false if the stack is full
+func (s *PooledStack[T]) Push(v T) bool {
+	i, ok := s.nodes.Alloc()
+	if !ok {
+		return false
+	}
+	n := s.nodes.At(i)
+	n.value = v
+	for {
+		head := atomic.LoadUint64(&s.head)
+		gen, ref := unpackTagged(head)
+		atomic.StoreUint32(&n.next, ref)
+		if atomic.CompareAndSwapUint64(&s.head, head, packTagged(gen+1, uint32(i+1))) {
+			atomic.AddInt64(&s.size, 1)
+			return true
+		}
+	}
+}
+
+// Pop removes and returns the top value, or reports false if the stack is empty
+func (s *PooledStack[T]) Pop() (T, bool) {
+	for {
+		head := atomic.LoadUint64(&s.head)
+		gen, ref := unpackTagged(head)
+		if ref == 0 {
+			var zero T
+			return zero, false
+		}
+		n := s.nodes.At(int(ref - 1))
+		next := atomic.LoadUint32(&n.next)
+		if atomic.CompareAndSwapUint64(&s.head, head, packTagged(gen+1, next)) {
+			atomic.AddInt64(&s.size, -1)
+			v := n.value
+			var zero T
+			n.value = zero
+			s.nodes.Free(int(ref - 1))
+			return v, true
+		}
+	}
+}
+
+// Len returns the number of values; under concurrent use it may be briefly stale
+func (s *PooledStack[T]) Len() int {
+	return int(atomic.LoadInt64(&s.size))
+}
@@ -0,0 +1,158 @@
+package examples
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestFreeList(t *testing.T) {
+	g := NewWithT(t)
+
+	f := NewFreeList[string](3)
+	var got []int
+	for i := 0; i < 3; i++ {
+		slot, ok := f.Alloc()
+		g.Expect(ok).To(BeTrue())
+		got = append(got, slot)
+	}
+	g.Expect(got).To(ConsistOf(0, 1, 2))
+	_, ok := f.Alloc()
+	g.Expect(ok).To(BeFalse())
+	g.Expect(f.Available()).To(BeZero())
+
+	// A freed slot is the next one handed out
+	*f.At(1) = "reused"
+	f.Free(1)
+	slot, ok := f.Alloc()
+	g.Expect(ok).To(BeTrue())
+	g.Expect(slot).To(Equal(1))
+	g.Expect(*f.At(slot)).To(Equal("reused"))
+
+	_, ok = NewFreeList[int](0).Alloc()
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestFreeListNeverSharesASlot(t *testing.T) {
+	g := NewWithT(t)
+
+	// Many goroutines churn a small list; an owner flag per slot catches any
+	// slot handed to two goroutines at once, which is what ABA would cause
+	const slots = 8
+	f := NewFreeList[int32](slots)
+	owners := make([]int32, slots)
+	var doubled int32
+	var wg sync.WaitGroup
+	for w := 0; w < 16; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 5000; i++ {
+				slot, ok := f.Alloc()
+				if !ok {
+					continue
+				}
+				if !atomic.CompareAndSwapInt32(&owners[slot], 0, 1) {
+					atomic.AddInt32(&doubled, 1)
+				}
+				atomic.StoreInt32(&owners[slot], 0)
+				f.Free(slot)
+			}
+		}()
+	}
+	wg.Wait()
+	g.Expect(doubled).To(BeZero())
+	g.Expect(f.Available()).To(Equal(slots))
+}
+
+func TestPooledStack(t *testing.T) {
+	g := NewWithT(t)
+
+	s := NewPooledStack[int](2)
+	g.Expect(s.Push(1)).To(BeTrue())
+	g.Expect(s.Push(2)).To(BeTrue())
+	g.Expect(s.Push(3)).To(BeFalse())
+	var got []int
+	v, _ := s.Pop()
+	got = append(got, v)
+	g.Expect(s.Push(4)).To(BeTrue())
+	for {
+		v, ok := s.Pop()
+		if !ok {
+			break
+		}
+		got = append(got, v)
+	}
+	g.Expect(got).To(Equal([]int{2, 4, 1}))
+}
+
+func TestPooledStackStress(t *testing.T) {
+	g := NewWithT(t)
+
+	// A capacity well below the number of values forces constant node reuse
+	const workers, perWorker = 8, 2000
+	s := NewPooledStack[int](16)
+	popped := make([][]int, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; {
+				if s.Push(w*perWorker + i) {
+					i++
+				} else {
+					runtime.Gosched() // Full: let a popper run
+				}
+			}
+		}(w)
+		go func(w int) {
+			defer wg.Done()
+			for len(popped[w]) < perWorker {
+				if v, ok := s.Pop(); ok {
+					popped[w] = append(popped[w], v)
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	seen := make([]bool, workers*perWorker)
+	for _, vs := range popped {
+		for _, v := range vs {
+			g.Expect(seen[v]).To(BeFalse(), "value %d popped twice", v)
+			seen[v] = true
+		}
+	}
+	g.Expect(seen).NotTo(ContainElement(false))
+}
+
+// BenchmarkStackAllocs contrasts a push/pop pair on the allocating Treiber
+// stack with the pooled one, which should report zero allocs/op
+func BenchmarkStackAllocs(b *testing.B) {
+	b.Run("LockFree", func(b *testing.B) {
+		var s LockFreeStack[int]
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				s.Push(1)
+				s.Pop()
+			}
+		})
+	})
+	b.Run("Pooled", func(b *testing.B) {
+		s := NewPooledStack[int](1024)
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				s.Push(1)
+				s.Pop()
+			}
+		})
+	})
+}
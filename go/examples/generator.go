@@ -0,0 +1,54 @@
+package examples
+
+import "context"
+
+// Generator returns a channel yielding vals in order, closed after the last
+// one or when ctx is done
+func Generator[T any](ctx context.Context, vals ...T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for _, v := range vals {
+			if SendCtx(ctx, out, v) != nil {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Repeat yields vals over and over until ctx is done; pair it with Take for a
+// finite stream. With no vals the output closes at once rather than spinning.
+func Repeat[T any](ctx context.Context, vals ...T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		if len(vals) == 0 {
+			return
+		}
+		for {
+			for _, v := range vals {
+				if SendCtx(ctx, out, v) != nil {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// RepeatFn yields the result of calling fn until ctx is done, calling fn
+// once per value the consumer takes, plus at most one whose value is dropped on
+// cancellation
+func RepeatFn[T any](ctx context.Context, fn func() T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for {
+			if SendCtx(ctx, out, fn()) != nil {
+				return
+			}
+		}
+	}()
+	return out
+}
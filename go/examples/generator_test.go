@@ -0,0 +1,67 @@
+package examples
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// drain collects every value from in
+func drain[T any](in <-chan T) []T {
+	var got []T
+	for v := range in {
+		got = append(got, v)
+	}
+	return got
+}
+
+func TestGenerator(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(drain(Generator(context.Background(), 1, 2, 3))).To(Equal([]int{1, 2, 3}))
+	g.Expect(drain(Generator[int](context.Background()))).To(BeEmpty())
+}
+
+func TestRepeat(t *testing.T) {
+	g := NewWithT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	g.Expect(drain(Take(ctx, Repeat(ctx, 1, 2, 3), 7))).To(Equal([]int{1, 2, 3, 1, 2, 3, 1}))
+	g.Expect(drain(Repeat[int](ctx))).To(BeEmpty())
+}
+
+func TestRepeatFn(t *testing.T) {
+	g := NewWithT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	n := 0
+	next := func() int { n++; return n * n }
+	g.Expect(drain(Take(ctx, RepeatFn(ctx, next), 4))).To(Equal([]int{1, 4, 9, 16}))
+}
+
+func TestSourcesCancellation(t *testing.T) {
+	g := NewWithT(t)
+	before := runtime.NumGoroutine()
+
+	// Each source blocks on a consumer that stops reading; cancelling frees it
+	ctx, cancel := context.WithCancel(context.Background())
+	sources := []<-chan int{
+		Generator(ctx, 1, 2, 3),
+		Repeat(ctx, 1),
+		RepeatFn(ctx, func() int { return 1 }),
+	}
+	for _, src := range sources {
+		g.Expect(<-src).To(Equal(1))
+	}
+	cancel()
+
+	for _, src := range sources {
+		g.Eventually(src).Should(BeClosed())
+	}
+	g.Eventually(runtime.NumGoroutine, time.Second).Should(BeNumerically("<=", before))
+}
@@ -0,0 +1,192 @@
+package examples
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// HazardRecord is one published hazard pointer. A goroutine acquires a record,
+// stores in it the node it is about to dereference, and releases it when done;
+// while the pointer is set, the node will not be reused.
+type HazardRecord[N any] struct {
+	ptr    atomic.Pointer[N]
+	active int32 // 1 while owned by a goroutine
+	next   *HazardRecord[N]
+}
+
+// Protect publishes p as in use
+func (r *HazardRecord[N]) Protect(p *N) {
+	r.ptr.Store(p)
+}
+
+// Clear withdraws the published pointer
+func (r *HazardRecord[N]) Clear() {
+	r.ptr.Store(nil)
+}
+
+// HazardDomain implements hazard pointers (Michael, 2004) for nodes of type N
+// that are recycled by hand. Readers publish the node they are about to read
+// in a HazardRecord; a node removed from a structure is retired rather than
+// freed, and only goes back to the free pool once no record points at it.
+//
+// The garbage collector already gives Go this guarantee for nodes that are
+// simply dropped, so hazard pointers matter only once nodes are reused to
+// save allocation, as here, where reuse without them corrupts the structure.
+type HazardDomain[N any] struct {
+	records atomic.Pointer[HazardRecord[N]] // Grows by prepending, never shrinks
+
+	mu      sync.Mutex
+	retired []*N
+	free    []*N // Reused oldest first
+
+	noHazards bool // For demonstrating the corruption hazard pointers prevent
+}
+
+// NewHazardDomain creates a domain with no records and an empty free pool
+func NewHazardDomain[N any]() *HazardDomain[N] {
+	return &HazardDomain[N]{}
+}
+
+// Acquire returns an unused record, reusing a released one or adding a new one
+func (d *HazardDomain[N]) Acquire() *HazardRecord[N] {
+	for r := d.records.Load(); r != nil; r = r.next {
+		if atomic.CompareAndSwapInt32(&r.active, 0, 1) {
+			return r
+		}
+	}
+	r := &HazardRecord[N]{active: 1}
+	for {
+		r.next = d.records.Load()
+		if d.records.CompareAndSwap(r.next, r) {
+			return r
+		}
+	}
+}
+
+// Release clears r and returns it for another goroutine to acquire
+func (d *HazardDomain[N]) Release(r *HazardRecord[N]) {
+	r.Clear()
+	atomic.StoreInt32(&r.active, 0)
+}
+
+// Retire hands over a node that has been unlinked. It is moved to the free
+// pool at once if no record protects it, or at a later Retire once none does.
+// Scanning on every call keeps the demo simple; real implementations batch.
+func (d *HazardDomain[N]) Retire(p *N) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.retired = append(d.retired, p)
+
+	hazards := make(map[*N]bool)
+	if !d.noHazards {
+		for r := d.records.Load(); r != nil; r = r.next {
+			if h := r.ptr.Load(); h != nil {
+				hazards[h] = true
+			}
+		}
+	}
+	kept := d.retired[:0]
+	for _, n := range d.retired {
+		if hazards[n] {
+			kept = append(kept, n)
+		} else {
+			d.free = append(d.free, n)
+		}
+	}
+	d.retired = kept
+}
+
+// Alloc returns a node from the free pool, or a new one if the pool is empty
+func (d *HazardDomain[N]) Alloc() *N {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.free) == 0 {
+		return new(N)
+	}
+	n := d.free[0]
+	d.free = d.free[1:]
+	return n
+}
+
+// Retired returns the number of nodes waiting for their hazards to clear
+func (d *HazardDomain[N]) Retired() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.retired)
+}
+
+type hazardNode[T any] struct {
+	value T
+	next  atomic.Pointer[hazardNode[T]]
+}
+
+// HazardStack is a Treiber stack that recycles its nodes through a
+// HazardDomain. Pop protects the head before reading its next pointer, so a
+// node cannot be reused, and pushed back at the head with a different next,
+// between that read and the CAS.
+type HazardStack[T any] struct {
+	head   atomic.Pointer[hazardNode[T]]
+	domain *HazardDomain[hazardNode[T]]
+}
+
+// NewHazardStack creates an empty stack
+func NewHazardStack[T any]() *HazardStack[T] {
+	return &HazardStack[T]{domain: NewHazardDomain[hazardNode[T]]()}
+}
+
+// Push adds v on top, reusing a retired node when one is free
+func (s *HazardStack[T]) Push(v T) {
+	n := s.domain.Alloc()
+	n.value = v
+	for {
+		top := s.head.Load()
+		n.next.Store(top)
+		if s.head.CompareAndSwap(top, n) {
+			return
+		}
+	}
+}
+
+// Pop removes and returns the top value, or reports false if the stack is empty
+func (s *HazardStack[T]) Pop() (T, bool) {
+	r := s.domain.Acquire()
+	defer s.domain.Release(r)
+	for {
+		top, next, ok := s.popBegin(r)
+		if !ok {
+			var zero T
+			return zero, false
+		}
+		if v, ok := s.popCommit(r, top, next); ok {
+			return v, true
+		}
+	}
+}
+
+// popBegin protects the head and reads its successor, the part of Pop that a
+// recycled node can invalidate
+func (s *HazardStack[T]) popBegin(r *HazardRecord[hazardNode[T]]) (top, next *hazardNode[T], ok bool) {
+	for {
+		top = s.head.Load()
+		if top == nil {
+			return nil, nil, false
+		}
+		r.Protect(top)
+		// Re-check: top may have been retired before the hazard was visible
+		if s.head.Load() == top {
+			return top, top.next.Load(), true
+		}
+	}
+}
+
+// popCommit swings the head from top to next and retires top
+func (s *HazardStack[T]) popCommit(r *HazardRecord[hazardNode[T]], top, next *hazardNode[T]) (T, bool) {
+	if !s.head.CompareAndSwap(top, next) {
+		var zero T
+		return zero, false
+	}
+	v := top.value
+	r.Clear()
+	s.domain.Retire(top)
+	return v, true
+}
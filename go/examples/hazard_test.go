@@ -0,0 +1,101 @@
+package examples
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// interruptedPop replays the ABA interleaving on a stack holding 1, 2 and 3:
+// goroutine X reads head 3 and its successor 2, then stalls while Y pops 3
+// and 2 and pushes 4, which lands in a recycled node. X then completes, and
+// the stack is drained. It returns every value popped, X's first.
+func interruptedPop(s *HazardStack[int]) []int {
+	for i := 1; i <= 3; i++ {
+		s.Push(i)
+	}
+	x := s.domain.Acquire()
+	top, next, _ := s.popBegin(x)
+
+	y1, _ := s.Pop()
+	y2, _ := s.Pop()
+	s.Push(4)
+
+	var popped []int
+	for {
+		if v, ok := s.popCommit(x, top, next); ok {
+			popped = append(popped, v)
+			break
+		}
+		top, next, _ = s.popBegin(x)
+	}
+	s.domain.Release(x)
+	popped = append(popped, y1, y2)
+	for {
+		v, ok := s.Pop()
+		if !ok {
+			return popped
+		}
+		popped = append(popped, v)
+	}
+}
+
+func TestHazardStackABA(t *testing.T) {
+	g := NewWithT(t)
+
+	// X's hazard keeps node 3 out of the free pool, so 4 goes into node 2's
+	// slot, the head no longer matches, and X's CAS fails and retries
+	g.Expect(interruptedPop(NewHazardStack[int]())).To(ConsistOf(1, 2, 3, 4))
+
+	// Without hazards node 3 is reused for 4 and pushed back at the head, so
+	// X's stale CAS succeeds: it takes 4 and installs node 2, which Y had
+	// already popped, and 2 comes out twice
+	s := NewHazardStack[int]()
+	s.domain.noHazards = true
+	g.Expect(interruptedPop(s)).To(Equal([]int{4, 3, 2, 2, 1}))
+}
+
+func TestHazardStackStress(t *testing.T) {
+	g := NewWithT(t)
+
+	s := NewHazardStack[int]()
+	const workers, perWorker = 8, 2000
+	popped := make([][]int, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				s.Push(w*perWorker + i)
+			}
+		}(w)
+		go func(w int) {
+			defer wg.Done()
+			for len(popped[w]) < perWorker {
+				if v, ok := s.Pop(); ok {
+					popped[w] = append(popped[w], v)
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	seen := make([]bool, workers*perWorker)
+	for _, vs := range popped {
+		for _, v := range vs {
+			g.Expect(seen[v]).To(BeFalse(), "value %d popped twice", v)
+			seen[v] = true
+		}
+	}
+	g.Expect(seen).NotTo(ContainElement(false))
+
+	// With every hazard cleared, the last retire freed everything it could
+	s.Push(0)
+	s.Pop()
+	g.Expect(s.domain.Retired()).To(BeZero())
+}
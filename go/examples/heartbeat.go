@@ -0,0 +1,187 @@
+package examples
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Heartbeat records when a component last reported itself alive. The owner
+// calls Beat at least every Interval; a Monitor treats a heartbeat that has
+// not been touched for longer than its timeout as a sign the owner is stuck.
+type Heartbeat struct {
+	clock    Clock
+	interval time.Duration
+	last     int64 // Unix nanoseconds of the last Beat
+}
+
+// NewHeartbeat creates a heartbeat that should be touched every interval of
+// clock (RealClock if nil). It starts out freshly beaten.
+func NewHeartbeat(interval time.Duration, clock Clock) *Heartbeat {
+	if clock == nil {
+		clock = RealClock
+	}
+	h := &Heartbeat{clock: clock, interval: interval}
+	h.Beat()
+	return h
+}
+
+// Beat marks the owner alive now
+func (h *Heartbeat) Beat() {
+	atomic.StoreInt64(&h.last, h.clock.Now().UnixNano())
+}
+
+// Last returns the time of the last Beat
+func (h *Heartbeat) Last() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&h.last))
+}
+
+// Age returns how long ago the last Beat was
+func (h *Heartbeat) Age() time.Duration {
+	return h.clock.Since(h.Last())
+}
+
+// Interval returns how often the owner is expected to Beat
+func (h *Heartbeat) Interval() time.Duration {
+	return h.interval
+}
+
+// MinCheckInterval is the shortest CheckInterval a Monitor uses, so a tiny or
+// zero Timeout still gives Start a valid ticker period
+const MinCheckInterval = time.Millisecond
+
+// MonitorOptions configures a Monitor
+type MonitorOptions struct {
+	Timeout       time.Duration // Heartbeats older than this are unhealthy
+	CheckInterval time.Duration // How often Start checks; defaults to Timeout/2, at least MinCheckInterval
+	Clock         Clock         // RealClock if nil
+
+	// Called from the checking goroutine when a component changes state,
+	// never while the Monitor's lock is held. Both are optional.
+	OnUnhealthy func(name string, age time.Duration)
+	OnHealthy   func(name string)
+}
+
+// Monitor watches named heartbeats and reports components whose heartbeat
+// went stale, firing a callback only on each healthy/unhealthy transition
+type Monitor struct {
+	opts MonitorOptions
+
+	mu         sync.Mutex
+	components map[string]*monitored
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	done      chan struct{}
+	wg        sync.WaitGroup
+}
+
+type monitored struct {
+	heartbeat *Heartbeat
+	healthy   bool
+}
+
+// NewMonitor creates a monitor; call Start to check periodically or Check to check once
+func NewMonitor(opts MonitorOptions) *Monitor {
+	if opts.Clock == nil {
+		opts.Clock = RealClock
+	}
+	if opts.CheckInterval <= 0 {
+		opts.CheckInterval = opts.Timeout / 2
+	}
+	if opts.CheckInterval < MinCheckInterval {
+		opts.CheckInterval = MinCheckInterval
+	}
+	return &Monitor{
+		opts:       opts,
+		components: make(map[string]*monitored),
+		done:       make(chan struct{}),
+	}
+}
+
+// Register watches h under name, replacing any heartbeat already registered there.
+// Components start out healthy.
+func (m *Monitor) Register(name string, h *Heartbeat) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.components[name] = &monitored{heartbeat: h, healthy: true}
+}
+
+// Unregister stops watching name
+func (m *Monitor) Unregister(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.components, name)
+}
+
+// Check compares every heartbeat against the timeout now and fires the
+// callbacks for components that changed state since the last check
+func (m *Monitor) Check() {
+	type transition struct {
+		name    string
+		healthy bool
+		age     time.Duration
+	}
+	var transitions []transition
+
+	m.mu.Lock()
+	for name, c := range m.components {
+		age := c.heartbeat.Age()
+		healthy := age <= m.opts.Timeout
+		if healthy != c.healthy {
+			c.healthy = healthy
+			transitions = append(transitions, transition{name, healthy, age})
+		}
+	}
+	m.mu.Unlock()
+
+	sort.Slice(transitions, func(i, j int) bool { return transitions[i].name < transitions[j].name })
+	for _, t := range transitions {
+		if t.healthy && m.opts.OnHealthy != nil {
+			m.opts.OnHealthy(t.name)
+		} else if !t.healthy && m.opts.OnUnhealthy != nil {
+			m.opts.OnUnhealthy(t.name, t.age)
+		}
+	}
+}
+
+// Unhealthy returns the sorted names of components found stale by the last check
+func (m *Monitor) Unhealthy() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var names []string
+	for name, c := range m.components {
+		if !c.healthy {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Start checks every CheckInterval in the background; later calls do nothing
+func (m *Monitor) Start() {
+	m.startOnce.Do(func() {
+		ticker := m.opts.Clock.NewTicker(m.opts.CheckInterval)
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C():
+					m.Check()
+				case <-m.done:
+					return
+				}
+			}
+		}()
+	})
+}
+
+// Stop halts periodic checks and waits for a running check to finish
+func (m *Monitor) Stop() {
+	m.stopOnce.Do(func() { close(m.done) })
+	m.wg.Wait()
+}
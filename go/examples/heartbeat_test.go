@@ -0,0 +1,134 @@
+package examples
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// transitionRecorder collects Monitor callbacks as "name unhealthy"/"name healthy"
+type transitionRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *transitionRecorder) options(timeout time.Duration, clock Clock) MonitorOptions {
+	return MonitorOptions{
+		Timeout: timeout,
+		Clock:   clock,
+		OnUnhealthy: func(name string, age time.Duration) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.events = append(r.events, name+" unhealthy "+age.String())
+		},
+		OnHealthy: func(name string) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.events = append(r.events, name+" healthy")
+		},
+	}
+}
+
+func (r *transitionRecorder) Events() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+func TestHeartbeat(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(100, 0))
+	h := NewHeartbeat(time.Second, clock)
+	g.Expect(h.Last()).To(Equal(clock.Now()))
+	g.Expect(h.Interval()).To(Equal(time.Second))
+
+	clock.Advance(3 * time.Second)
+	g.Expect(h.Age()).To(Equal(3 * time.Second))
+	h.Beat()
+	g.Expect(h.Age()).To(BeZero())
+}
+
+func TestMonitorTransitions(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	var rec transitionRecorder
+	m := NewMonitor(rec.options(5*time.Second, clock))
+	a := NewHeartbeat(time.Second, clock)
+	b := NewHeartbeat(time.Second, clock)
+	m.Register("a", a)
+	m.Register("b", b)
+
+	// Exactly at the timeout is still healthy
+	clock.Advance(5 * time.Second)
+	b.Beat()
+	m.Check()
+	g.Expect(rec.Events()).To(BeEmpty())
+
+	clock.Advance(time.Second)
+	m.Check()
+	m.Check() // Still stale: no second callback
+	g.Expect(rec.Events()).To(Equal([]string{"a unhealthy 6s"}))
+	g.Expect(m.Unhealthy()).To(Equal([]string{"a"}))
+
+	a.Beat()
+	m.Check()
+	g.Expect(rec.Events()).To(Equal([]string{"a unhealthy 6s", "a healthy"}))
+	g.Expect(m.Unhealthy()).To(BeEmpty())
+
+	m.Unregister("b")
+	clock.Advance(time.Minute)
+	m.Check()
+	g.Expect(m.Unhealthy()).To(Equal([]string{"a"}))
+}
+
+// TestMonitorZeroTimeout checks that a zero Timeout still starts with a
+// positive check interval instead of a ticker that panics
+func TestMonitorZeroTimeout(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	var rec transitionRecorder
+	m := NewMonitor(rec.options(0, clock))
+	m.Register("a", NewHeartbeat(time.Second, clock))
+	g.Expect(m.Start).NotTo(Panic())
+	defer m.Stop()
+
+	clock.BlockUntil(1)
+	clock.Advance(MinCheckInterval)
+	g.Eventually(rec.Events).Should(Equal([]string{"a unhealthy " + MinCheckInterval.String()}))
+}
+
+func TestMonitorWorkerHeartbeat(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	var rec transitionRecorder
+	m := NewMonitor(rec.options(3*time.Second, clock))
+
+	w := NewWorker().WithHeartbeat(NewHeartbeat(time.Second, clock))
+	m.Register("worker", w.heartbeat)
+	w.Start()
+	m.Start()
+	defer m.Stop()
+	clock.BlockUntil(2) // Worker heartbeat ticker and monitor check ticker
+
+	// A running worker beats every second even when idle
+	for i := 0; i < 5; i++ {
+		clock.Advance(time.Second)
+		g.Eventually(w.heartbeat.Last).Should(Equal(clock.Now()))
+	}
+	g.Expect(m.Unhealthy()).To(BeEmpty())
+
+	// Once stopped its heartbeat goes stale and the monitor notices
+	w.Stop()
+	g.Eventually(clock.Waiters).Should(Equal(1))
+	for i := 0; i < 4; i++ {
+		clock.Advance(time.Second)
+	}
+	g.Eventually(rec.Events).Should(Equal([]string{"worker unhealthy 4s"}))
+	g.Expect(m.Unhealthy()).To(Equal([]string{"worker"}))
+}
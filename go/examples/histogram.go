@@ -0,0 +1,169 @@
+package examples
+
+import (
+	"math"
+	"sort"
+	"sync/atomic"
+)
+
+// AtomicHistogram counts observations into fixed buckets using only atomic adds,
+// so recording never blocks and memory stays constant no matter how many values arrive
+type AtomicHistogram struct {
+	bounds []int64 // Inclusive upper bound of each bucket, ascending
+	counts []int64 // One per bound plus a final overflow bucket
+	sum    int64
+}
+
+// NewAtomicHistogram creates a histogram with the given ascending bucket upper bounds
+func NewAtomicHistogram(bounds []int64) *AtomicHistogram {
+	b := append([]int64(nil), bounds...)
+	sort.Slice(b, func(i, j int) bool { return b[i] < b[j] })
+	return &AtomicHistogram{
+		bounds: b,
+		counts: make([]int64, len(b)+1),
+	}
+}
+
+// ExponentialBounds returns n bucket bounds starting at start, each factor times the previous
+func ExponentialBounds(start int64, factor float64, n int) []int64 {
+	bounds := make([]int64, 0, n)
+	v := float64(start)
+	for i := 0; i < n; i++ {
+		b := int64(math.Round(v))
+		if len(bounds) > 0 && b <= bounds[len(bounds)-1] {
+			b = bounds[len(bounds)-1] + 1
+		}
+		bounds = append(bounds, b)
+		v *= factor
+	}
+	return bounds
+}
+
+// Observe records one value
+func (h *AtomicHistogram) Observe(v int64) {
+	i := sort.Search(len(h.bounds), func(i int) bool { return v <= h.bounds[i] })
+	atomic.AddInt64(&h.counts[i], 1)
+	atomic.AddInt64(&h.sum, v)
+}
+
+// Snapshot copies the current bucket counts.
+// Buckets are read one by one, so a snapshot taken during writes may be
+// off by the few observations that landed mid-copy.
+func (h *AtomicHistogram) Snapshot() HistogramSnapshot {
+	s := HistogramSnapshot{
+		Bounds: h.bounds,
+		Counts: make([]int64, len(h.counts)),
+		Sum:    atomic.LoadInt64(&h.sum),
+	}
+	for i := range h.counts {
+		s.Counts[i] = atomic.LoadInt64(&h.counts[i])
+		s.Count += s.Counts[i]
+	}
+	return s
+}
+
+// SnapshotAndReset copies and zeroes every bucket with atomic swaps, so each
+// observation lands in exactly one returned snapshot even under concurrent writes
+func (h *AtomicHistogram) SnapshotAndReset() HistogramSnapshot {
+	s := HistogramSnapshot{
+		Bounds: h.bounds,
+		Counts: make([]int64, len(h.counts)),
+		Sum:    atomic.SwapInt64(&h.sum, 0),
+	}
+	for i := range h.counts {
+		s.Counts[i] = atomic.SwapInt64(&h.counts[i], 0)
+		s.Count += s.Counts[i]
+	}
+	return s
+}
+
+// Merge adds the observations in s, which must use the same bounds
+func (h *AtomicHistogram) Merge(s HistogramSnapshot) {
+	for i, c := range s.Counts {
+		if c != 0 {
+			atomic.AddInt64(&h.counts[i], c)
+		}
+	}
+	atomic.AddInt64(&h.sum, s.Sum)
+}
+
+// Reset zeroes all buckets
+func (h *AtomicHistogram) Reset() {
+	for i := range h.counts {
+		atomic.StoreInt64(&h.counts[i], 0)
+	}
+	atomic.StoreInt64(&h.sum, 0)
+}
+
+// HistogramSnapshot is an immutable copy of an AtomicHistogram
+type HistogramSnapshot struct {
+	Bounds []int64 `json:"bounds"`
+	Counts []int64 `json:"counts"` // len(Bounds)+1; the last entry counts values above every bound
+	Count  int64   `json:"count"`
+	Sum    int64   `json:"sum"`
+}
+
+// Sub returns the observations recorded since prev, which must use the same bounds.
+// If the histogram was reset in between, s is returned unchanged.
+func (s HistogramSnapshot) Sub(prev HistogramSnapshot) HistogramSnapshot {
+	if len(prev.Counts) != len(s.Counts) || prev.Count > s.Count {
+		return s
+	}
+	d := HistogramSnapshot{
+		Bounds: s.Bounds,
+		Counts: make([]int64, len(s.Counts)),
+		Count:  s.Count - prev.Count,
+		Sum:    s.Sum - prev.Sum,
+	}
+	for i := range s.Counts {
+		d.Counts[i] = s.Counts[i] - prev.Counts[i]
+		if d.Counts[i] < 0 {
+			return s
+		}
+	}
+	return d
+}
+
+// Mean returns the average observed value, or 0 when empty
+func (s HistogramSnapshot) Mean() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return float64(s.Sum) / float64(s.Count)
+}
+
+// Quantile estimates the q-th quantile (0 <= q <= 1) by locating the bucket
+// holding that rank and interpolating linearly inside it. Values in the
+// overflow bucket are reported as the largest bound.
+func (s HistogramSnapshot) Quantile(q float64) int64 {
+	if s.Count == 0 {
+		return 0
+	}
+	if len(s.Bounds) == 0 {
+		return int64(s.Mean())
+	}
+	q = math.Max(0, math.Min(1, q))
+	rank := int64(math.Ceil(q * float64(s.Count)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	var cumulative int64
+	for i, c := range s.Counts {
+		if c == 0 || cumulative+c < rank {
+			cumulative += c
+			continue
+		}
+		if i == len(s.Bounds) {
+			return s.Bounds[len(s.Bounds)-1]
+		}
+		var lower int64
+		if i > 0 {
+			lower = s.Bounds[i-1]
+		}
+		upper := s.Bounds[i]
+		fraction := float64(rank-cumulative) / float64(c)
+		return lower + int64(fraction*float64(upper-lower))
+	}
+	return s.Bounds[len(s.Bounds)-1]
+}
@@ -0,0 +1,125 @@
+package examples
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestExponentialBounds(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(ExponentialBounds(1, 2, 5)).To(Equal([]int64{1, 2, 4, 8, 16}))
+
+	// Rounding never produces duplicate bounds
+	g.Expect(ExponentialBounds(1, 1.1, 4)).To(Equal([]int64{1, 2, 3, 4}))
+}
+
+func TestAtomicHistogram(t *testing.T) {
+	g := NewWithT(t)
+
+	h := NewAtomicHistogram([]int64{10, 20, 30})
+
+	// Empty histogram
+	g.Expect(h.Snapshot().Quantile(0.5)).To(Equal(int64(0)))
+
+	for _, v := range []int64{5, 10, 15, 25, 100} {
+		h.Observe(v)
+	}
+	snap := h.Snapshot()
+	g.Expect(snap.Counts).To(Equal([]int64{2, 1, 1, 1}))
+	g.Expect(snap.Count).To(Equal(int64(5)))
+	g.Expect(snap.Sum).To(Equal(int64(155)))
+	g.Expect(snap.Mean()).To(BeNumerically("~", 31.0, 0.001))
+
+	// Quantiles land in the right bucket
+	g.Expect(snap.Quantile(0.2)).To(BeNumerically("<=", 10))
+	g.Expect(snap.Quantile(0.6)).To(BeNumerically("~", 20, 10))
+	g.Expect(snap.Quantile(0.8)).To(BeNumerically("~", 30, 10))
+	// Overflow reports the largest bound
+	g.Expect(snap.Quantile(1)).To(Equal(int64(30)))
+
+	h.Reset()
+	g.Expect(h.Snapshot().Count).To(Equal(int64(0)))
+}
+
+func TestAtomicHistogramQuantileAccuracy(t *testing.T) {
+	g := NewWithT(t)
+
+	h := NewAtomicHistogram(ExponentialBounds(1, 1.1, 120))
+	for v := int64(1); v <= 10000; v++ {
+		h.Observe(v)
+	}
+	snap := h.Snapshot()
+
+	// With 10% bucket growth, estimates stay within 10% of the truth
+	g.Expect(snap.Quantile(0.5)).To(BeNumerically("~", 5000, 500))
+	g.Expect(snap.Quantile(0.95)).To(BeNumerically("~", 9500, 950))
+	g.Expect(snap.Quantile(0.99)).To(BeNumerically("~", 9900, 990))
+}
+
+func TestAtomicHistogramConcurrency(t *testing.T) {
+	g := NewWithT(t)
+
+	h := NewAtomicHistogram(ExponentialBounds(1, 2, 10))
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := int64(0); j < 1000; j++ {
+				h.Observe(j)
+			}
+		}()
+	}
+	wg.Wait()
+
+	snap := h.Snapshot()
+	g.Expect(snap.Count).To(Equal(int64(100000)))
+	g.Expect(snap.Sum).To(Equal(int64(100 * 999 * 1000 / 2)))
+}
+
+func TestMetricsPercentiles(t *testing.T) {
+	g := NewWithT(t)
+
+	metrics := &Metrics{}
+
+	// No data yet
+	g.Expect(metrics.Percentiles(0.5)[0.5]).To(Equal(time.Duration(0)))
+
+	// 1ms..100ms in 1ms steps
+	for i := 1; i <= 100; i++ {
+		metrics.RecordLatency(time.Duration(i) * time.Millisecond)
+	}
+
+	p := metrics.Percentiles(0.5, 0.95, 0.99)
+	g.Expect(p).To(HaveLen(3))
+	g.Expect(p[0.5]).To(BeNumerically("~", 50*time.Millisecond, 15*time.Millisecond))
+	g.Expect(p[0.95]).To(BeNumerically("~", 95*time.Millisecond, 25*time.Millisecond))
+	g.Expect(p[0.99]).To(BeNumerically("~", 99*time.Millisecond, 25*time.Millisecond))
+	g.Expect(p[0.5]).To(BeNumerically("<=", p[0.95]))
+	g.Expect(p[0.95]).To(BeNumerically("<=", p[0.99]))
+
+	// Reset clears latencies too
+	metrics.Reset()
+	g.Expect(metrics.Percentiles(0.99)[0.99]).To(Equal(time.Duration(0)))
+}
+
+func TestAtomicHistogramSnapshotAndReset(t *testing.T) {
+	g := NewWithT(t)
+
+	h := NewAtomicHistogram([]int64{10, 20})
+	h.Observe(5)
+	h.Observe(15)
+	h.Observe(50)
+
+	snap := h.SnapshotAndReset()
+	g.Expect(snap.Counts).To(Equal([]int64{1, 1, 1}))
+	g.Expect(snap.Count).To(Equal(int64(3)))
+	g.Expect(snap.Sum).To(Equal(int64(70)))
+
+	g.Expect(h.Snapshot().Count).To(Equal(int64(0)))
+	g.Expect(h.Snapshot().Sum).To(Equal(int64(0)))
+}
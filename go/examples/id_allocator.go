@@ -0,0 +1,83 @@
+package examples
+
+import (
+	"math/bits"
+	"sync/atomic"
+)
+
+// IDAllocator hands out small integer IDs in [0, capacity) and takes them
+// back for reuse, without locks. Each ID is one bit in a bitmap of atomic
+// words: Acquire finds a clear bit and sets it with a CAS on its word, and
+// Release clears it. Handy for indexes that must stay small and dense, such
+// as which LocalMetrics buffer a worker writes to.
+type IDAllocator struct {
+	words    []atomic.Uint64
+	capacity int
+	next     atomic.Uint64 // Word to start the next search at, to spread callers out
+}
+
+// NewIDAllocator creates an allocator for IDs 0 to capacity-1
+func NewIDAllocator(capacity int) *IDAllocator {
+	a := &IDAllocator{
+		words:    make([]atomic.Uint64, (capacity+63)/64),
+		capacity: capacity,
+	}
+	// Mark the bits past capacity in the last word as taken, so they are never handed out
+	if extra := len(a.words)*64 - capacity; extra > 0 {
+		a.words[len(a.words)-1].Store(^uint64(0) << (64 - extra))
+	}
+	return a
+}
+
+// Acquire returns a free ID and marks it held, or false if all are held
+func (a *IDAllocator) Acquire() (int, bool) {
+	n := uint64(len(a.words))
+	if n == 0 {
+		return 0, false
+	}
+	start := a.next.Load()
+	for i := uint64(0); i < n; i++ {
+		w := (start + i) % n
+		word := &a.words[w]
+		for {
+			old := word.Load()
+			if old == ^uint64(0) {
+				break // Full: try the next word
+			}
+			bit := bits.TrailingZeros64(^old)
+			if word.CompareAndSwap(old, old|1<<bit) {
+				if old|1<<bit == ^uint64(0) {
+					a.next.CompareAndSwap(start, (w+1)%n)
+				}
+				return int(w)*64 + bit, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// Release frees id for reuse. Releasing an ID that is not held is a bug in
+// the caller and panics, since it could hand the same ID out twice.
+func (a *IDAllocator) Release(id int) {
+	if id < 0 || id >= a.capacity {
+		panic("idallocator: id out of range")
+	}
+	mask := uint64(1) << (id % 64)
+	if old := a.words[id/64].And(^mask); old&mask == 0 {
+		panic("idallocator: release of free id")
+	}
+}
+
+// Cap returns the number of IDs
+func (a *IDAllocator) Cap() int {
+	return a.capacity
+}
+
+// InUse returns how many IDs are held. Under concurrent use it is approximate.
+func (a *IDAllocator) InUse() int {
+	held := 0
+	for i := range a.words {
+		held += bits.OnesCount64(a.words[i].Load())
+	}
+	return held - (len(a.words)*64 - a.capacity)
+}
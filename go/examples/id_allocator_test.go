@@ -0,0 +1,84 @@
+package examples
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestIDAllocator(t *testing.T) {
+	g := NewWithT(t)
+
+	a := NewIDAllocator(70) // Spans two words, the second partly used
+	seen := map[int]bool{}
+	for i := 0; i < 70; i++ {
+		id, ok := a.Acquire()
+		g.Expect(ok).To(BeTrue())
+		g.Expect(id).To(BeNumerically("<", 70))
+		g.Expect(seen).NotTo(HaveKey(id))
+		seen[id] = true
+	}
+	_, ok := a.Acquire()
+	g.Expect(ok).To(BeFalse())
+	g.Expect(a.InUse()).To(Equal(70))
+
+	a.Release(42)
+	id, ok := a.Acquire()
+	g.Expect(ok).To(BeTrue())
+	g.Expect(id).To(Equal(42))
+
+	a.Release(3)
+	g.Expect(func() { a.Release(3) }).To(PanicWith("idallocator: release of free id"))
+	g.Expect(func() { a.Release(70) }).To(Panic())
+	g.Expect(a.InUse()).To(Equal(69))
+
+	_, ok = NewIDAllocator(0).Acquire()
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestIDAllocatorNeverDoubleHolds(t *testing.T) {
+	g := NewWithT(t)
+
+	const capacity = 100
+	a := NewIDAllocator(capacity)
+	var holders [capacity]atomic.Int32 // Goroutines holding each ID; must never exceed 1
+	var doubled atomic.Int64
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var mine []int
+			for i := 0; i < 5000; i++ {
+				if id, ok := a.Acquire(); ok {
+					if holders[id].Add(1) != 1 {
+						doubled.Add(1)
+					}
+					mine = append(mine, id)
+				}
+				// Hold up to 20 IDs each, so 8 workers can exhaust the allocator
+				if len(mine) > 0 && (len(mine) >= 20 || i%3 == 0) {
+					id := mine[0]
+					mine = mine[1:]
+					holders[id].Add(-1)
+					a.Release(id)
+				}
+				if i%64 == 0 {
+					runtime.Gosched()
+				}
+			}
+			for _, id := range mine {
+				holders[id].Add(-1)
+				a.Release(id)
+			}
+		}()
+	}
+	wg.Wait()
+
+	g.Expect(doubled.Load()).To(BeZero())
+	g.Expect(a.InUse()).To(BeZero())
+}
@@ -0,0 +1,196 @@
+package examples
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MutexStats is a point-in-time view of an InstrumentedMutex
+type MutexStats struct {
+	Acquisitions int64
+	Contended    int64         // Acquisitions that had to wait
+	WaitTime     time.Duration // Total time spent waiting to acquire
+}
+
+// InstrumentedMutex is a sync.Mutex that counts acquisitions and the time
+// callers spent waiting for it. Given a DeadlockDetector, it also reports
+// lock-ordering deadlocks the moment the cycle closes, instead of hanging.
+type InstrumentedMutex struct {
+	name     string
+	detector *DeadlockDetector
+
+	mu           sync.Mutex
+	acquisitions int64
+	contended    int64
+	waitNanos    int64
+}
+
+// NewInstrumentedMutex creates a mutex called name, which identifies it in
+// deadlock reports. detector may be nil to skip deadlock detection, which
+// costs a stack capture per Lock and suits debug builds and tests.
+func NewInstrumentedMutex(name string, detector *DeadlockDetector) *InstrumentedMutex {
+	return &InstrumentedMutex{name: name, detector: detector}
+}
+
+// Name returns the mutex's name
+func (m *InstrumentedMutex) Name() string {
+	return m.name
+}
+
+// Lock acquires the mutex. With a detector, if waiting would complete a cycle
+// of goroutines each waiting for a lock held by the next, Lock panics with a
+// *DeadlockError instead; deferred Unlocks then run as the panic unwinds,
+// which frees the other goroutines in the cycle.
+func (m *InstrumentedMutex) Lock() {
+	var gid uint64
+	var stack string
+	if m.detector != nil {
+		gid, stack = currentGoroutine()
+		if err := m.detector.wait(m, gid, stack); err != nil {
+			panic(err)
+		}
+	}
+
+	if !m.mu.TryLock() {
+		start := time.Now()
+		m.mu.Lock()
+		atomic.AddInt64(&m.contended, 1)
+		atomic.AddInt64(&m.waitNanos, int64(time.Since(start)))
+	}
+	atomic.AddInt64(&m.acquisitions, 1)
+	if m.detector != nil {
+		m.detector.acquired(m, gid, stack)
+	}
+}
+
+// Unlock releases the mutex
+func (m *InstrumentedMutex) Unlock() {
+	if m.detector != nil {
+		m.detector.released(m)
+	}
+	m.mu.Unlock()
+}
+
+// Stats returns the mutex's counters
+func (m *InstrumentedMutex) Stats() MutexStats {
+	return MutexStats{
+		Acquisitions: atomic.LoadInt64(&m.acquisitions),
+		Contended:    atomic.LoadInt64(&m.contended),
+		WaitTime:     time.Duration(atomic.LoadInt64(&m.waitNanos)),
+	}
+}
+
+// LockWait is one edge of a wait-for cycle: Goroutine is waiting for Lock,
+// which Holder holds
+type LockWait struct {
+	Goroutine uint64
+	Lock      string
+	Holder    uint64
+	WaitStack string // Where Goroutine called Lock
+	HoldStack string // Where Holder acquired Lock
+}
+
+// DeadlockError describes a cycle of goroutines each waiting for a lock held
+// by the next; the last edge's Holder is the first edge's Goroutine
+type DeadlockError struct {
+	Cycle []LockWait
+}
+
+func (e *DeadlockError) Error() string {
+	var b strings.Builder
+	b.WriteString("deadlock:")
+	for i, w := range e.Cycle {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, " goroutine %d waits for %s held by goroutine %d", w.Goroutine, w.Lock, w.Holder)
+	}
+	return b.String()
+}
+
+// DeadlockDetector keeps the wait-for graph of the InstrumentedMutexes that
+// share it: which goroutine holds each lock and which lock each goroutine is
+// waiting for. A goroutine about to wait walks the graph from the lock it
+// wants; reaching itself means the wait would never end. Each goroutine checks
+// after registering the locks it holds, so whichever closes a cycle sees it.
+type DeadlockDetector struct {
+	mu      sync.Mutex
+	holders map[*InstrumentedMutex]lockHold
+	waiting map[uint64]lockWant
+}
+
+type lockHold struct {
+	gid   uint64
+	stack string
+}
+
+type lockWant struct {
+	lock  *InstrumentedMutex
+	stack string
+}
+
+// NewDeadlockDetector creates an empty detector
+func NewDeadlockDetector() *DeadlockDetector {
+	return &DeadlockDetector{
+		holders: make(map[*InstrumentedMutex]lockHold),
+		waiting: make(map[uint64]lockWant),
+	}
+}
+
+// wait records that gid is about to wait for m, or returns the cycle that
+// waiting would close
+func (d *DeadlockDetector) wait(m *InstrumentedMutex, gid uint64, stack string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var cycle []LockWait
+	mine := lockWant{lock: m, stack: stack}
+	want, waiter := mine, gid
+	for {
+		hold, ok := d.holders[want.lock]
+		if !ok {
+			break
+		}
+		cycle = append(cycle, LockWait{
+			Goroutine: waiter, Lock: want.lock.name, Holder: hold.gid,
+			WaitStack: want.stack, HoldStack: hold.stack,
+		})
+		if hold.gid == gid {
+			return &DeadlockError{Cycle: cycle}
+		}
+		if want, ok = d.waiting[hold.gid]; !ok {
+			break
+		}
+		waiter = hold.gid
+	}
+	d.waiting[gid] = mine
+	return nil
+}
+
+func (d *DeadlockDetector) acquired(m *InstrumentedMutex, gid uint64, stack string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.waiting, gid)
+	d.holders[m] = lockHold{gid: gid, stack: stack}
+}
+
+func (d *DeadlockDetector) released(m *InstrumentedMutex) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.holders, m)
+}
+
+// currentGoroutine returns the calling goroutine's ID, parsed from the
+// "goroutine N [" header of its stack, and the stack itself
+func currentGoroutine() (uint64, string) {
+	buf := make([]byte, 4096)
+	buf = buf[:runtime.Stack(buf, false)]
+	header, _, _ := bytes.Cut(buf, []byte(" ["))
+	id, _ := strconv.ParseUint(string(bytes.TrimPrefix(header, []byte("goroutine "))), 10, 64)
+	return id, string(buf)
+}
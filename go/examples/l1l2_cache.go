@@ -0,0 +1,185 @@
+package examples
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// L2Loader looks a key up in the slower second level.
+// found is false when the key does not exist there either.
+type L2Loader func(key string) (value interface{}, found bool, err error)
+
+// L1L2Stats is a point-in-time view of per-level cache activity
+type L1L2Stats struct {
+	L1Hits   int64
+	L1Misses int64
+	L2Hits   int64
+	L2Misses int64
+	L2Errors int64
+}
+
+// L1HitRatio returns the fraction of lookups served from L1
+func (s L1L2Stats) L1HitRatio() float64 {
+	return ratio(s.L1Hits, s.L1Hits+s.L1Misses)
+}
+
+// L2HitRatio returns the fraction of L1 misses that were found in L2
+func (s L1L2Stats) L2HitRatio() float64 {
+	return ratio(s.L2Hits, s.L2Hits+s.L2Misses+s.L2Errors)
+}
+
+func ratio(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
+
+// L1L2Cache layers a fast in-memory L1 over a slow L2 loader.
+// L1 misses go to L2 through a semaphore that caps concurrent L2 calls,
+// and values found in L2 are promoted back into L1.
+type L1L2Cache struct {
+	l1  cacheStore
+	l2  L2Loader
+	sem chan struct{}
+
+	l1Hits   int64
+	l1Misses int64
+	l2Hits   int64
+	l2Misses int64
+	l2Errors int64
+
+	counters cacheCounters // L2 calls, which are the cache's loads
+}
+
+// NewL1L2Cache creates a two-level cache allowing at most maxL2Loads concurrent L2 calls
+func NewL1L2Cache(l2 L2Loader, maxL2Loads int) *L1L2Cache {
+	return newL1L2Cache(&SafeMap{}, l2, maxL2Loads)
+}
+
+// NewBoundedL1L2Cache creates a two-level cache whose L1 is a BoundedCache
+func NewBoundedL1L2Cache(l2 L2Loader, maxL2Loads int, opts CacheOptions) *L1L2Cache {
+	return newL1L2Cache(NewBoundedCache(opts), l2, maxL2Loads)
+}
+
+func newL1L2Cache(l1 cacheStore, l2 L2Loader, maxL2Loads int) *L1L2Cache {
+	if maxL2Loads < 1 {
+		maxL2Loads = 1
+	}
+	return &L1L2Cache{
+		l1:  l1,
+		l2:  l2,
+		sem: make(chan struct{}, maxL2Loads),
+	}
+}
+
+// Get looks key up in L1, then L2, promoting L2 hits into L1
+func (c *L1L2Cache) Get(key string) (interface{}, bool, error) {
+	if val, ok := c.l1.Get(key); ok {
+		atomic.AddInt64(&c.l1Hits, 1)
+		return val, true, nil
+	}
+	atomic.AddInt64(&c.l1Misses, 1)
+
+	// Limit how many goroutines may hit the slow level at once
+	c.sem <- struct{}{}
+	start := time.Now()
+	val, found, err := c.l2(key)
+	c.counters.load(time.Since(start), err)
+	<-c.sem
+
+	switch {
+	case err != nil:
+		atomic.AddInt64(&c.l2Errors, 1)
+		return nil, false, err
+	case !found:
+		atomic.AddInt64(&c.l2Misses, 1)
+		return nil, false, nil
+	}
+	atomic.AddInt64(&c.l2Hits, 1)
+	c.l1.Set(key, val) // Promote
+	return val, true, nil
+}
+
+// Set writes a value directly into L1
+func (c *L1L2Cache) Set(key string, value interface{}) {
+	c.l1.Set(key, value)
+}
+
+// Evict drops key from L1 only; the next Get falls through to L2
+func (c *L1L2Cache) Evict(key string) {
+	c.l1.Delete(key)
+}
+
+// L1Size returns the number of entries held in L1
+func (c *L1L2Cache) L1Size() int64 {
+	return c.l1.Size()
+}
+
+// Stats returns a snapshot of per-level counters
+func (c *L1L2Cache) Stats() L1L2Stats {
+	return L1L2Stats{
+		L1Hits:   atomic.LoadInt64(&c.l1Hits),
+		L1Misses: atomic.LoadInt64(&c.l1Misses),
+		L2Hits:   atomic.LoadInt64(&c.l2Hits),
+		L2Misses: atomic.LoadInt64(&c.l2Misses),
+		L2Errors: atomic.LoadInt64(&c.l2Errors),
+	}
+}
+
+// CacheStats returns the shared stats view: a hit at either level is a hit,
+// and every L2 call is a load
+func (c *L1L2Cache) CacheStats() CacheStats {
+	stats := c.Stats()
+	l1 := c.l1.CacheStats()
+	result := c.counters.snapshot(l1.Entries)
+	result.Hits = stats.L1Hits + stats.L2Hits
+	result.Misses = stats.L2Misses + stats.L2Errors
+	result.Evictions = l1.Evictions
+	return result
+}
+
+// SimulatedL2 is a slow backing store used to play the part of L2
+type SimulatedL2 struct {
+	mu      sync.RWMutex
+	data    map[string]interface{}
+	latency time.Duration
+
+	active    int64
+	maxActive int64
+}
+
+// NewSimulatedL2 creates a store where every lookup takes latency
+func NewSimulatedL2(latency time.Duration) *SimulatedL2 {
+	return &SimulatedL2{
+		data:    make(map[string]interface{}),
+		latency: latency,
+	}
+}
+
+// Put stores a value in the simulated L2
+func (s *SimulatedL2) Put(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = value
+}
+
+// Load implements L2Loader, sleeping to simulate a network round trip
+func (s *SimulatedL2) Load(key string) (interface{}, bool, error) {
+	active := atomic.AddInt64(&s.active, 1)
+	defer atomic.AddInt64(&s.active, -1)
+	AtomicMax(&s.maxActive, active)
+
+	time.Sleep(s.latency)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	val, ok := s.data[key]
+	return val, ok, nil
+}
+
+// MaxConcurrentLoads returns the highest number of overlapping Load calls seen
+func (s *SimulatedL2) MaxConcurrentLoads() int64 {
+	return atomic.LoadInt64(&s.maxActive)
+}
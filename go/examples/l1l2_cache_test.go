@@ -0,0 +1,97 @@
+package examples
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestL1L2Cache(t *testing.T) {
+	g := NewWithT(t)
+
+	l2 := NewSimulatedL2(time.Millisecond)
+	l2.Put("a", 1)
+	cache := NewL1L2Cache(l2.Load, 4)
+
+	// First lookup misses L1 and is served by L2
+	val, found, err := cache.Get("a")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(found).To(BeTrue())
+	g.Expect(val).To(Equal(1))
+	g.Expect(cache.L1Size()).To(Equal(int64(1)))
+
+	// Second lookup is promoted to L1
+	_, found, _ = cache.Get("a")
+	g.Expect(found).To(BeTrue())
+
+	// Unknown key misses both levels and is not promoted
+	_, found, err = cache.Get("b")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(found).To(BeFalse())
+	g.Expect(cache.L1Size()).To(Equal(int64(1)))
+
+	// Evicting from L1 falls back to L2
+	cache.Evict("a")
+	_, found, _ = cache.Get("a")
+	g.Expect(found).To(BeTrue())
+
+	stats := cache.Stats()
+	g.Expect(stats.L1Hits).To(Equal(int64(1)))
+	g.Expect(stats.L1Misses).To(Equal(int64(3)))
+	g.Expect(stats.L2Hits).To(Equal(int64(2)))
+	g.Expect(stats.L2Misses).To(Equal(int64(1)))
+	g.Expect(stats.L1HitRatio()).To(BeNumerically("~", 0.25, 0.001))
+	g.Expect(stats.L2HitRatio()).To(BeNumerically("~", 2.0/3.0, 0.001))
+}
+
+func TestL1L2CacheL2Error(t *testing.T) {
+	g := NewWithT(t)
+
+	cache := NewL1L2Cache(func(key string) (interface{}, bool, error) {
+		return nil, false, errors.New("l2 down")
+	}, 1)
+
+	_, found, err := cache.Get("a")
+	g.Expect(err).To(MatchError("l2 down"))
+	g.Expect(found).To(BeFalse())
+	g.Expect(cache.Stats().L2Errors).To(Equal(int64(1)))
+	g.Expect(cache.Stats().L2HitRatio()).To(Equal(0.0))
+}
+
+func TestL1L2CacheConcurrencyLimit(t *testing.T) {
+	g := NewWithT(t)
+
+	l2 := NewSimulatedL2(5 * time.Millisecond)
+	for i := 0; i < 50; i++ {
+		l2.Put(fmt.Sprintf("key_%d", i), i)
+	}
+	cache := NewL1L2Cache(l2.Load, 3)
+
+	// 50 distinct cold keys requested at once
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			cache.Get(fmt.Sprintf("key_%d", id))
+		}(i)
+	}
+	wg.Wait()
+
+	// L2 never saw more than the configured number of concurrent loads
+	g.Expect(l2.MaxConcurrentLoads()).To(BeNumerically("<=", int64(3)))
+	g.Expect(cache.L1Size()).To(Equal(int64(50)))
+
+	// Everything is now served from L1
+	for i := 0; i < 50; i++ {
+		cache.Get(fmt.Sprintf("key_%d", i))
+	}
+	stats := cache.Stats()
+	g.Expect(stats.L1Hits).To(Equal(int64(50)))
+	g.Expect(stats.L2Hits).To(Equal(int64(50)))
+	g.Expect(stats.L1HitRatio()).To(BeNumerically("~", 0.5, 0.001))
+}
@@ -0,0 +1,68 @@
+package examples
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// LatencyStats is the count, fastest and slowest of the recorded latencies.
+// Cheaper to read than percentiles and enough for a quick sanity check;
+// Min and Max are zero when nothing was recorded.
+type LatencyStats struct {
+	Count int64         `json:"count"`
+	Min   time.Duration `json:"min"`
+	Max   time.Duration `json:"max"`
+}
+
+// latencyRange tracks LatencyStats with AtomicMin/AtomicMax, so recording never locks.
+// It must be reset once before use so that min starts above every observation.
+type latencyRange struct {
+	count int64
+	min   int64
+	max   int64
+}
+
+func (r *latencyRange) observe(v int64) {
+	AtomicMin(&r.min, v)
+	AtomicMax(&r.max, v)
+	atomic.AddInt64(&r.count, 1)
+}
+
+func (r *latencyRange) reset() {
+	atomic.StoreInt64(&r.count, 0)
+	atomic.StoreInt64(&r.min, math.MaxInt64)
+	atomic.StoreInt64(&r.max, 0)
+}
+
+func (r *latencyRange) load() LatencyStats {
+	return latencyStats(atomic.LoadInt64(&r.count), atomic.LoadInt64(&r.min), atomic.LoadInt64(&r.max))
+}
+
+// swap reads and resets the range. The three fields are swapped one at a
+// time, so an observation racing with swap may count in one interval and
+// move min or max in the next.
+func (r *latencyRange) swap() LatencyStats {
+	return latencyStats(
+		atomic.SwapInt64(&r.count, 0),
+		atomic.SwapInt64(&r.min, math.MaxInt64),
+		atomic.SwapInt64(&r.max, 0),
+	)
+}
+
+// merge folds stats recorded elsewhere into the range
+func (r *latencyRange) merge(s LatencyStats) {
+	if s.Count == 0 {
+		return
+	}
+	AtomicMin(&r.min, int64(s.Min))
+	AtomicMax(&r.max, int64(s.Max))
+	atomic.AddInt64(&r.count, s.Count)
+}
+
+func latencyStats(count, min, max int64) LatencyStats {
+	if count == 0 || min == math.MaxInt64 {
+		return LatencyStats{Count: count}
+	}
+	return LatencyStats{Count: count, Min: time.Duration(min), Max: time.Duration(max)}
+}
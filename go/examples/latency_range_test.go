@@ -0,0 +1,85 @@
+package examples
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestMetricsLatencyStats(t *testing.T) {
+	g := NewWithT(t)
+
+	m := &Metrics{}
+	g.Expect(m.Snapshot().Latency).To(Equal(LatencyStats{}))
+
+	for _, d := range []time.Duration{30 * time.Millisecond, 2 * time.Millisecond, 0, 500 * time.Millisecond} {
+		m.RecordLatency(d)
+	}
+	g.Expect(m.Snapshot().Latency).To(Equal(LatencyStats{Count: 4, Min: 0, Max: 500 * time.Millisecond}))
+
+	// SwapAndReset starts a fresh range; only later latencies count
+	g.Expect(m.SwapAndReset().Latency.Count).To(Equal(int64(4)))
+	m.RecordLatency(7 * time.Millisecond)
+	g.Expect(m.Snapshot().Latency).To(Equal(LatencyStats{Count: 1, Min: 7 * time.Millisecond, Max: 7 * time.Millisecond}))
+
+	m.Reset()
+	g.Expect(m.Snapshot().Latency).To(Equal(LatencyStats{}))
+}
+
+func TestMetricsLatencyStatsDiff(t *testing.T) {
+	g := NewWithT(t)
+
+	m := &Metrics{}
+	m.RecordLatency(time.Millisecond)
+	prev := m.Snapshot()
+	m.RecordLatency(3 * time.Millisecond)
+	m.RecordLatency(2 * time.Millisecond)
+
+	d := m.Snapshot().Diff(prev)
+	g.Expect(d.Latency).To(Equal(LatencyStats{Count: 2, Min: time.Millisecond, Max: 3 * time.Millisecond}))
+
+	data, err := json.Marshal(d)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(data)).To(ContainSubstring(`"latency":{"count":2,"min":1000000,"max":3000000}`))
+}
+
+func TestMetricsLatencyStatsConcurrent(t *testing.T) {
+	g := NewWithT(t)
+
+	m := &Metrics{}
+	done := make(chan bool)
+	for i := 1; i <= 50; i++ {
+		go func(i int) {
+			for j := 0; j < 100; j++ {
+				m.RecordLatency(time.Duration(i) * time.Microsecond)
+			}
+			done <- true
+		}(i)
+	}
+	for i := 0; i < 50; i++ {
+		<-done
+	}
+
+	g.Expect(m.Snapshot().Latency).To(Equal(LatencyStats{Count: 5000, Min: time.Microsecond, Max: 50 * time.Microsecond}))
+}
+
+func TestLocalMetricsLatencyStats(t *testing.T) {
+	g := NewWithT(t)
+
+	global := &Metrics{}
+	global.RecordLatency(10 * time.Millisecond)
+	mm := NewMetricsMerger(global, time.Second, NewFakeClock(time.Unix(0, 0)))
+
+	a, b := mm.Local(), mm.Local()
+	a.RecordLatency(time.Millisecond)
+	b.RecordLatency(40 * time.Millisecond)
+	mm.Merge()
+
+	g.Expect(global.Snapshot().Latency).To(Equal(LatencyStats{Count: 3, Min: time.Millisecond, Max: 40 * time.Millisecond}))
+
+	// Merging again adds nothing, since the local ranges were swapped out
+	mm.Merge()
+	g.Expect(global.Snapshot().Latency.Count).To(Equal(int64(3)))
+}
@@ -0,0 +1,50 @@
+package examples
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// goroutineStacks returns the stack of every goroutine, keyed by the
+// "goroutine N" header that identifies it
+func goroutineStacks() map[string]string {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	stacks := make(map[string]string)
+	for _, s := range bytes.Split(buf, []byte("\n\n")) {
+		header, _, _ := strings.Cut(string(s), " [")
+		stacks[header] = string(s)
+	}
+	return stacks
+}
+
+// expectNoLeaks fails the test if goroutines started during it are still
+// running shortly after it ends, listing their stacks. Call it first, so its
+// cleanup runs after every other. Tests using it must not run in parallel.
+func expectNoLeaks(t *testing.T) {
+	before := goroutineStacks()
+	t.Cleanup(func() {
+		leaked := func() []string {
+			var stacks []string
+			for id, s := range goroutineStacks() {
+				if _, ok := before[id]; !ok && !strings.Contains(s, "expectNoLeaks") {
+					stacks = append(stacks, s)
+				}
+			}
+			return stacks
+		}
+		NewWithT(t).Eventually(leaked, time.Second, 5*time.Millisecond).Should(BeEmpty(), "leaked goroutines")
+	})
+}
@@ -0,0 +1,230 @@
+package examples
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrLeaseHeld is returned by Lease.Acquire while another holder's grant is live
+	ErrLeaseHeld = errors.New("lease: held by another holder")
+	// ErrLeaseLost is returned by LeaseGrant.Renew once the grant has expired or been revoked
+	ErrLeaseLost = errors.New("lease: lost")
+)
+
+// Lease grants exclusive, time-limited ownership of something, such as
+// leadership or a lock, to one holder at a time. A grant lasts ttl past its
+// last renewal, so a holder that crashes or stalls stops renewing and loses
+// the lease on its own, where a mutex would stay locked forever. Holders
+// renew by hand with Renew or in the background with KeepAlive.
+type Lease struct {
+	clock Clock
+
+	mu       sync.Mutex
+	current  *LeaseGrant
+	onExpire []func(holder string)
+}
+
+// LeaseGrant is one holder's tenure of a Lease
+type LeaseGrant struct {
+	lease   *Lease
+	holder  string
+	ttl     time.Duration // Guarded by lease.mu
+	expires time.Time     // Guarded by lease.mu
+	timer   Timer         // Fires at or before expires; reset under lease.mu
+	lost    chan struct{} // Closed on expiry or revocation
+	ended   bool          // Guarded by lease.mu
+}
+
+// NewLease creates an unheld lease driven by clock (RealClock if nil)
+func NewLease(clock Clock) *Lease {
+	if clock == nil {
+		clock = RealClock
+	}
+	return &Lease{clock: clock}
+}
+
+// OnExpire registers fn to run whenever a grant expires for lack of renewal;
+// it is not called for revoked grants. It runs outside the lease's lock, on
+// whichever goroutine noticed the expiry: the lease's watcher, or an Acquire
+// or Renew that found the grant past its expiry first.
+func (l *Lease) OnExpire(fn func(holder string)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onExpire = append(l.onExpire, fn)
+}
+
+// Acquire grants the lease to holder for ttl, or returns ErrLeaseHeld if
+// another holder's grant is live. Acquiring a lease one already holds
+// renews it with the new ttl.
+func (l *Lease) Acquire(holder string, ttl time.Duration) (*LeaseGrant, error) {
+	l.mu.Lock()
+	now := l.clock.Now()
+	var expired []func(string)
+	var expiredHolder string
+	if g := l.current; g != nil && !now.Before(g.expires) {
+		// Past its expiry, even if the watcher has not run yet
+		expired, expiredHolder = g.expireLocked(), g.holder
+	}
+	g, err := l.acquireLocked(holder, ttl, now)
+	l.mu.Unlock()
+
+	for _, fn := range expired {
+		fn(expiredHolder)
+	}
+	return g, err
+}
+
+func (l *Lease) acquireLocked(holder string, ttl time.Duration, now time.Time) (*LeaseGrant, error) {
+	if g := l.current; g != nil {
+		if g.holder != holder {
+			return nil, ErrLeaseHeld
+		}
+		earlier := now.Add(ttl).Before(g.expires)
+		g.ttl, g.expires = ttl, now.Add(ttl)
+		if earlier {
+			// The watcher's timer is set for the old expiry; bring it forward
+			g.timer.Reset(ttl)
+		}
+		return g, nil
+	}
+	g := &LeaseGrant{lease: l, holder: holder, ttl: ttl, expires: now.Add(ttl), lost: make(chan struct{})}
+	g.timer = l.clock.NewTimer(ttl)
+	l.current = g
+	go g.watch()
+	return g, nil
+}
+
+// Holder returns who holds the lease, if anyone
+func (l *Lease) Holder() (string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.current == nil {
+		return "", false
+	}
+	return l.current.holder, true
+}
+
+// Holder returns the grant's holder
+func (g *LeaseGrant) Holder() string {
+	return g.holder
+}
+
+// Expires returns when the grant ends unless renewed
+func (g *LeaseGrant) Expires() time.Time {
+	g.lease.mu.Lock()
+	defer g.lease.mu.Unlock()
+	return g.expires
+}
+
+// Lost returns a channel closed when the grant expires or is revoked; a
+// holder should stop acting as owner as soon as it is closed
+func (g *LeaseGrant) Lost() <-chan struct{} {
+	return g.lost
+}
+
+// Renew extends the grant to ttl from now, or returns ErrLeaseLost if it
+// has already ended or is past its expiry
+func (g *LeaseGrant) Renew() error {
+	l := g.lease
+	l.mu.Lock()
+	if g.ended {
+		l.mu.Unlock()
+		return ErrLeaseLost
+	}
+	now := l.clock.Now()
+	if !now.Before(g.expires) {
+		expired := g.expireLocked()
+		l.mu.Unlock()
+		for _, fn := range expired {
+			fn(g.holder)
+		}
+		return ErrLeaseLost
+	}
+	g.expires = now.Add(g.ttl)
+	l.mu.Unlock()
+	return nil
+}
+
+// KeepAlive renews the grant every interval, which should be well under the
+// ttl, until the grant ends or stop is called
+func (g *LeaseGrant) KeepAlive(interval time.Duration) (stop func()) {
+	ticker := g.lease.clock.NewTicker(interval)
+	done := make(chan struct{})
+	var once sync.Once
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C():
+				if g.Renew() != nil {
+					return
+				}
+			case <-g.lost:
+				return
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// Revoke gives the lease up at once, so another holder can acquire it
+// without waiting out the ttl
+func (g *LeaseGrant) Revoke() {
+	l := g.lease
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	g.endLocked()
+}
+
+// watch ends the grant once the clock passes its expiry, which each renewal
+// pushes back, then runs the expiry callbacks
+func (g *LeaseGrant) watch() {
+	l := g.lease
+	for {
+		select {
+		case <-g.timer.C():
+		case <-g.lost:
+			g.timer.Stop()
+			return
+		}
+		l.mu.Lock()
+		if g.ended {
+			l.mu.Unlock()
+			return
+		}
+		if left := g.expires.Sub(l.clock.Now()); left > 0 {
+			g.timer.Reset(left)
+			l.mu.Unlock()
+			continue
+		}
+		callbacks := g.expireLocked()
+		l.mu.Unlock()
+
+		for _, fn := range callbacks {
+			fn(g.holder)
+		}
+		return
+	}
+}
+
+// expireLocked ends the grant for lack of renewal and returns the callbacks
+// to run once the lock is released
+func (g *LeaseGrant) expireLocked() []func(holder string) {
+	g.endLocked()
+	return g.lease.onExpire
+}
+
+func (g *LeaseGrant) endLocked() {
+	if g.ended {
+		return
+	}
+	g.ended = true
+	close(g.lost)
+	if g.lease.current == g {
+		g.lease.current = nil
+	}
+}
@@ -0,0 +1,137 @@
+package examples
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestLeaseKeepAliveAndExpiry(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	lease := NewLease(clock)
+	var mu sync.Mutex
+	var expired []string
+	lease.OnExpire(func(holder string) {
+		mu.Lock()
+		defer mu.Unlock()
+		expired = append(expired, holder)
+	})
+
+	a, err := lease.Acquire("a", time.Second)
+	g.Expect(err).NotTo(HaveOccurred())
+	stop := a.KeepAlive(300 * time.Millisecond)
+	clock.BlockUntil(2) // Expiry timer and renewal ticker
+
+	// Renewals carry a well past its first ttl, and b is refused throughout
+	for i := 1; i <= 10; i++ {
+		clock.Advance(300 * time.Millisecond)
+		want := time.Unix(0, 0).Add(time.Duration(i)*300*time.Millisecond + time.Second)
+		g.Eventually(a.Expires).Should(Equal(want))
+		_, err := lease.Acquire("b", time.Second)
+		g.Expect(err).To(MatchError(ErrLeaseHeld))
+	}
+	holder, held := lease.Holder()
+	g.Expect(held).To(BeTrue())
+	g.Expect(holder).To(Equal("a"))
+
+	// a stalls: a ttl after its last renewal the lease expires
+	stop()
+	clock.Advance(999 * time.Millisecond)
+	g.Consistently(a.Lost(), 20*time.Millisecond).ShouldNot(BeClosed())
+	clock.BlockUntil(1)
+	clock.Advance(time.Millisecond)
+	g.Eventually(a.Lost()).Should(BeClosed())
+	g.Eventually(func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return expired
+	}).Should(Equal([]string{"a"}))
+	g.Expect(a.Renew()).To(MatchError(ErrLeaseLost))
+
+	b, err := lease.Acquire("b", time.Second)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(b.Holder()).To(Equal("b"))
+}
+
+func TestLeaseRevoke(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	lease := NewLease(clock)
+	expirations := 0
+	lease.OnExpire(func(string) { expirations++ })
+
+	a, err := lease.Acquire("a", time.Minute)
+	g.Expect(err).NotTo(HaveOccurred())
+	again, err := lease.Acquire("a", 2*time.Minute) // Re-acquiring renews
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(again).To(BeIdenticalTo(a))
+	g.Expect(a.Expires()).To(Equal(time.Unix(0, 0).Add(2 * time.Minute)))
+
+	a.Revoke()
+	g.Expect(a.Lost()).To(BeClosed())
+	_, held := lease.Holder()
+	g.Expect(held).To(BeFalse())
+	g.Eventually(clock.Waiters).Should(BeZero())
+
+	// The next holder does not wait out a's ttl, and revocation is not expiry
+	_, err = lease.Acquire("b", time.Minute)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(expirations).To(BeZero())
+}
+
+func TestLeaseShorterTTLOnReacquire(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	lease := NewLease(clock)
+	a, err := lease.Acquire("a", time.Minute)
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = lease.Acquire("a", time.Second)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// The grant ends a second in, not when the first minute would have
+	clock.Advance(time.Second)
+	g.Eventually(a.Lost()).Should(BeClosed())
+	_, err = lease.Acquire("b", time.Second)
+	g.Expect(err).NotTo(HaveOccurred())
+}
+
+func TestLeaseExpiryWithoutWatcher(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	lease := NewLease(clock)
+	var mu sync.Mutex
+	var expired []string
+	lease.OnExpire(func(holder string) {
+		mu.Lock()
+		defer mu.Unlock()
+		expired = append(expired, holder)
+	})
+
+	// Straight after the clock passes the expiry the watcher may not have run
+	// yet; the grant must count as lost regardless
+	a, _ := lease.Acquire("a", time.Second)
+	clock.Advance(time.Second)
+	g.Expect(a.Renew()).To(MatchError(ErrLeaseLost))
+	g.Expect(a.Lost()).To(BeClosed())
+
+	c, _ := lease.Acquire("c", time.Second)
+	clock.Advance(time.Second)
+	b, err := lease.Acquire("b", time.Second)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(b.Holder()).To(Equal("b"))
+	g.Expect(c.Lost()).To(BeClosed())
+
+	// Each grant's expiry is reported once, whoever noticed it
+	g.Consistently(func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return expired
+	}, 20*time.Millisecond).Should(Equal([]string{"a", "c"}))
+}
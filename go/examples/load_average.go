@@ -0,0 +1,91 @@
+package examples
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LoadSampleInterval is how often LoadAverage samples, as in the Unix kernel
+const LoadSampleInterval = 5 * time.Second
+
+// LoadAverage tracks 1, 5 and 15 minute exponentially-decaying averages of a
+// sampled quantity, like the load averages printed by uptime. Each sample n
+// moves an average toward n by load = load*e + n*(1-e), where e = exp(-interval/window).
+type LoadAverage struct {
+	sample   func() float64
+	interval time.Duration
+	decay    [3]float64
+	loads    [3]uint64 // float64 bits, read with atomic loads
+
+	ticker   Ticker
+	done     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// LoadAverages is a reading of a LoadAverage
+type LoadAverages struct {
+	Load1  float64
+	Load5  float64
+	Load15 float64
+}
+
+// NewLoadAverage samples sample every LoadSampleInterval of clock (RealClock if nil)
+// until Stop is called
+func NewLoadAverage(sample func() float64, clock Clock) *LoadAverage {
+	if clock == nil {
+		clock = RealClock
+	}
+	l := &LoadAverage{
+		sample:   sample,
+		interval: LoadSampleInterval,
+		ticker:   clock.NewTicker(LoadSampleInterval),
+		done:     make(chan struct{}),
+	}
+	for i, window := range []time.Duration{time.Minute, 5 * time.Minute, 15 * time.Minute} {
+		l.decay[i] = math.Exp(-l.interval.Seconds() / window.Seconds())
+	}
+	l.wg.Add(1)
+	go l.run()
+	return l
+}
+
+func (l *LoadAverage) run() {
+	defer l.wg.Done()
+	for {
+		select {
+		case <-l.ticker.C():
+			l.update(l.sample())
+		case <-l.done:
+			return
+		}
+	}
+}
+
+// update folds one sample into all three averages; only the ticker goroutine writes
+func (l *LoadAverage) update(n float64) {
+	for i, e := range l.decay {
+		old := math.Float64frombits(atomic.LoadUint64(&l.loads[i]))
+		atomic.StoreUint64(&l.loads[i], math.Float64bits(old*e+n*(1-e)))
+	}
+}
+
+// Load returns the current averages
+func (l *LoadAverage) Load() LoadAverages {
+	return LoadAverages{
+		Load1:  math.Float64frombits(atomic.LoadUint64(&l.loads[0])),
+		Load5:  math.Float64frombits(atomic.LoadUint64(&l.loads[1])),
+		Load15: math.Float64frombits(atomic.LoadUint64(&l.loads[2])),
+	}
+}
+
+// Stop halts sampling; Load keeps returning the last averages
+func (l *LoadAverage) Stop() {
+	l.stopOnce.Do(func() {
+		l.ticker.Stop()
+		close(l.done)
+	})
+	l.wg.Wait()
+}
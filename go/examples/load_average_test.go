@@ -0,0 +1,51 @@
+package examples
+
+import (
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestLoadAverageDecay(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	var depth int64 = 10
+	var samples int64
+	l := NewLoadAverage(func() float64 {
+		atomic.AddInt64(&samples, 1)
+		return float64(atomic.LoadInt64(&depth))
+	}, clock)
+	defer l.Stop()
+
+	tick := func(n int) {
+		for i := 0; i < n; i++ {
+			want := atomic.LoadInt64(&samples) + 1
+			clock.Advance(LoadSampleInterval)
+			g.Eventually(func() int64 { return atomic.LoadInt64(&samples) }).Should(Equal(want))
+		}
+	}
+
+	// One sample moves each average by (1-e) of the way
+	tick(1)
+	g.Eventually(func() float64 { return l.Load().Load1 }).Should(BeNumerically("~", 10*(1-math.Exp(-5.0/60)), 1e-9))
+	load := l.Load()
+	g.Expect(load.Load5).To(BeNumerically("~", 10*(1-math.Exp(-5.0/300)), 1e-9))
+	g.Expect(load.Load1).To(BeNumerically(">", load.Load5))
+	g.Expect(load.Load5).To(BeNumerically(">", load.Load15))
+
+	// After a minute of constant load, the 1m average is ~63% of the way there
+	tick(11)
+	g.Eventually(func() float64 { return l.Load().Load1 }).Should(BeNumerically("~", 10*(1-math.Exp(-1)), 1e-6))
+
+	// When load drops to zero the short window falls fastest
+	atomic.StoreInt64(&depth, 0)
+	before := l.Load()
+	tick(12)
+	after := l.Load()
+	g.Expect(after.Load1).To(BeNumerically("<", before.Load1*0.4))
+	g.Expect(after.Load15).To(BeNumerically(">", before.Load15*0.9))
+}
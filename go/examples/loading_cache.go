@@ -0,0 +1,157 @@
+package examples
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/camilbenameur/learning/go/singleflight"
+)
+
+// LoaderFunc fetches the value for a key on a cache miss
+type LoaderFunc func(key string) (interface{}, error)
+
+// LoadingCacheStats is a point-in-time view of LoadingCache activity
+type LoadingCacheStats struct {
+	Hits         int64 // Served from cache
+	NegativeHits int64 // Served a cached loader error
+	Misses       int64 // Not in cache, had to load or wait for a load
+	Loads        int64 // Actual loader invocations
+	LoadErrors   int64 // Loader invocations that returned an error
+	Coalesced    int64 // Misses that waited on another caller's load (stampede avoided)
+}
+
+// negativeEntry caches a loader error until it expires
+type negativeEntry struct {
+	err     error
+	expires time.Time
+}
+
+// LoadingCache combines SafeMap with singleflight duplicate suppression.
+// Unlike BadCache it never holds a lock while fetching, and unlike GoodCache
+// concurrent misses for the same key share one fetch instead of stampeding.
+type LoadingCache struct {
+	values      cacheStore
+	negatives   SafeMap
+	loader      LoaderFunc
+	negativeTTL time.Duration
+	now         func() time.Time
+
+	flight singleflight.Group // One load per key at a time
+
+	hits         int64
+	negativeHits int64
+	misses       int64
+	coalesced    int64
+	counters     cacheCounters // Loads only; hits and misses are counted above
+}
+
+// NewLoadingCache creates a cache that calls loader on misses.
+// Loader errors are cached for negativeTTL; zero disables negative caching.
+func NewLoadingCache(loader LoaderFunc, negativeTTL time.Duration) *LoadingCache {
+	return newLoadingCache(&SafeMap{}, loader, negativeTTL)
+}
+
+// NewBoundedLoadingCache creates a LoadingCache whose values are held in a BoundedCache
+func NewBoundedLoadingCache(loader LoaderFunc, negativeTTL time.Duration, opts CacheOptions) *LoadingCache {
+	return newLoadingCache(NewBoundedCache(opts), loader, negativeTTL)
+}
+
+func newLoadingCache(values cacheStore, loader LoaderFunc, negativeTTL time.Duration) *LoadingCache {
+	return &LoadingCache{
+		values:      values,
+		loader:      loader,
+		negativeTTL: negativeTTL,
+		now:         time.Now,
+	}
+}
+
+// Get returns the cached value for key, loading it at most once concurrently
+func (c *LoadingCache) Get(key string) (interface{}, error) {
+	if val, ok := c.values.Get(key); ok {
+		atomic.AddInt64(&c.hits, 1)
+		return val, nil
+	}
+	if err, ok := c.negative(key); ok {
+		atomic.AddInt64(&c.negativeHits, 1)
+		return nil, err
+	}
+	atomic.AddInt64(&c.misses, 1)
+
+	led := false
+	val, err, _ := c.flight.Do(key, func() (any, error) {
+		led = true
+		// Re-check: a load may have finished since the fast path
+		if val, ok := c.values.Get(key); ok {
+			return val, nil
+		}
+		return c.load(key)
+	})
+	if !led {
+		// Someone else was already loading this key and we shared their result
+		atomic.AddInt64(&c.coalesced, 1)
+	}
+	return val, err
+}
+
+// load calls the loader and caches its value, or its error for negativeTTL
+func (c *LoadingCache) load(key string) (interface{}, error) {
+	start := time.Now()
+	val, err := c.loader(key)
+	c.counters.load(time.Since(start), err)
+	if err == nil {
+		c.values.Set(key, val)
+	} else {
+		if c.negativeTTL > 0 {
+			c.negatives.Set(key, negativeEntry{err: err, expires: c.now().Add(c.negativeTTL)})
+		}
+	}
+	return val, err
+}
+
+// negative returns a cached loader error for key if one has not expired
+func (c *LoadingCache) negative(key string) (error, bool) {
+	v, ok := c.negatives.Get(key)
+	if !ok {
+		return nil, false
+	}
+	entry := v.(negativeEntry)
+	if !c.now().Before(entry.expires) {
+		c.negatives.Delete(key)
+		return nil, false
+	}
+	return entry.err, true
+}
+
+// Invalidate removes key (and any cached error) so the next Get reloads it
+func (c *LoadingCache) Invalidate(key string) {
+	c.values.Delete(key)
+	c.negatives.Delete(key)
+}
+
+// Size returns the number of cached values
+func (c *LoadingCache) Size() int64 {
+	return c.values.Size()
+}
+
+// Stats returns a snapshot of cache activity counters
+func (c *LoadingCache) Stats() LoadingCacheStats {
+	loads := c.counters.snapshot(0)
+	return LoadingCacheStats{
+		Hits:         atomic.LoadInt64(&c.hits),
+		NegativeHits: atomic.LoadInt64(&c.negativeHits),
+		Misses:       atomic.LoadInt64(&c.misses),
+		Loads:        loads.Loads,
+		LoadErrors:   loads.LoadErrors,
+		Coalesced:    atomic.LoadInt64(&c.coalesced),
+	}
+}
+
+// CacheStats returns the shared stats view; cached errors count as hits
+func (c *LoadingCache) CacheStats() CacheStats {
+	store := c.values.CacheStats()
+	stats := c.counters.snapshot(store.Entries)
+	stats.Hits = atomic.LoadInt64(&c.hits) + atomic.LoadInt64(&c.negativeHits)
+	stats.Misses = atomic.LoadInt64(&c.misses)
+	stats.Evictions = store.Evictions
+	return stats
+}
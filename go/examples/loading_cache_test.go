@@ -0,0 +1,111 @@
+package examples
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestLoadingCache(t *testing.T) {
+	g := NewWithT(t)
+
+	var calls int64
+	cache := NewLoadingCache(func(key string) (interface{}, error) {
+		atomic.AddInt64(&calls, 1)
+		return "fetched-" + key, nil
+	}, 0)
+
+	// First Get loads
+	val, err := cache.Get("a")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(val).To(Equal("fetched-a"))
+	g.Expect(cache.Size()).To(Equal(int64(1)))
+
+	// Second Get is a hit
+	val, err = cache.Get("a")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(val).To(Equal("fetched-a"))
+	g.Expect(atomic.LoadInt64(&calls)).To(Equal(int64(1)))
+
+	// Invalidate forces a reload
+	cache.Invalidate("a")
+	g.Expect(cache.Size()).To(Equal(int64(0)))
+	_, _ = cache.Get("a")
+	g.Expect(atomic.LoadInt64(&calls)).To(Equal(int64(2)))
+
+	stats := cache.Stats()
+	g.Expect(stats.Hits).To(Equal(int64(1)))
+	g.Expect(stats.Misses).To(Equal(int64(2)))
+	g.Expect(stats.Loads).To(Equal(int64(2)))
+}
+
+func TestLoadingCacheStampede(t *testing.T) {
+	g := NewWithT(t)
+
+	var calls int64
+	release := make(chan struct{})
+	cache := NewLoadingCache(func(key string) (interface{}, error) {
+		atomic.AddInt64(&calls, 1)
+		<-release // Simulate a slow backend
+		return "fetched-" + key, nil
+	}, 0)
+
+	var wg sync.WaitGroup
+	var correct int64
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if val, err := cache.Get("hot"); err == nil && val == "fetched-hot" {
+				atomic.AddInt64(&correct, 1)
+			}
+		}()
+	}
+
+	// Wait until everyone is queued behind the single load
+	g.Eventually(func() int {
+		return cache.flight.Duplicates("hot")
+	}, "2s", "10ms").Should(Equal(99))
+	close(release)
+	wg.Wait()
+	g.Expect(correct).To(Equal(int64(100)))
+
+	// Exactly one fetch reached the backend
+	g.Expect(atomic.LoadInt64(&calls)).To(Equal(int64(1)))
+	stats := cache.Stats()
+	g.Expect(stats.Loads).To(Equal(int64(1)))
+	g.Expect(stats.Misses).To(Equal(int64(100)))
+	g.Expect(stats.Coalesced).To(Equal(int64(99)))
+}
+
+func TestLoadingCacheNegativeTTL(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Unix(0, 0)
+	var calls int64
+	cache := NewLoadingCache(func(key string) (interface{}, error) {
+		atomic.AddInt64(&calls, 1)
+		return nil, errors.New("not found")
+	}, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	// Error is returned and cached
+	_, err := cache.Get("missing")
+	g.Expect(err).To(MatchError("not found"))
+	_, err = cache.Get("missing")
+	g.Expect(err).To(MatchError("not found"))
+	g.Expect(atomic.LoadInt64(&calls)).To(Equal(int64(1)))
+	g.Expect(cache.Stats().NegativeHits).To(Equal(int64(1)))
+
+	// After the TTL the loader is retried
+	now = now.Add(time.Minute)
+	_, err = cache.Get("missing")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(atomic.LoadInt64(&calls)).To(Equal(int64(2)))
+	g.Expect(cache.Stats().LoadErrors).To(Equal(int64(2)))
+	g.Expect(cache.Size()).To(Equal(int64(0)))
+}
@@ -0,0 +1,130 @@
+package examples
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LocalMetrics is a per-worker buffer for Metrics updates. Each worker writes
+// only its own LocalMetrics, so its atomics never bounce between CPU caches the
+// way a single shared counter does; a MetricsMerger periodically folds every
+// buffer into the global Metrics with atomic swaps, losing nothing.
+type LocalMetrics struct {
+	requests     int64
+	errors       int64
+	totalBytes   int64
+	latency      *AtomicHistogram
+	latencyRange latencyRange
+
+	_ [cacheLinePad]byte // Keeps the next worker's counters off this cache line
+}
+
+// RecordRequest counts one request locally
+func (l *LocalMetrics) RecordRequest() {
+	atomic.AddInt64(&l.requests, 1)
+}
+
+// RecordError counts one error locally
+func (l *LocalMetrics) RecordError() {
+	atomic.AddInt64(&l.errors, 1)
+}
+
+// RecordBytes adds bytes locally
+func (l *LocalMetrics) RecordBytes(bytes int64) {
+	atomic.AddInt64(&l.totalBytes, bytes)
+}
+
+// RecordLatency records a duration in the local histogram
+func (l *LocalMetrics) RecordLatency(d time.Duration) {
+	l.latency.Observe(int64(d))
+	l.latencyRange.observe(int64(d))
+}
+
+// mergeInto moves everything recorded so far into m
+func (l *LocalMetrics) mergeInto(m *Metrics) {
+	atomic.AddInt64(&m.requests, atomic.SwapInt64(&l.requests, 0))
+	atomic.AddInt64(&m.errors, atomic.SwapInt64(&l.errors, 0))
+	atomic.AddInt64(&m.totalBytes, atomic.SwapInt64(&l.totalBytes, 0))
+	m.latencyHistogram().Merge(l.latency.SnapshotAndReset())
+	m.latencyRange.merge(l.latencyRange.swap())
+}
+
+// MetricsMerger hands out LocalMetrics and merges them into a global Metrics
+// every interval and once more on Stop. Between merges the global view lags
+// by at most one interval.
+type MetricsMerger struct {
+	global   *Metrics
+	interval time.Duration
+	clock    Clock
+
+	mu     sync.Mutex
+	locals []*LocalMetrics
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	done      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewMetricsMerger creates a merger into global driven by clock (RealClock if nil)
+func NewMetricsMerger(global *Metrics, interval time.Duration, clock Clock) *MetricsMerger {
+	if clock == nil {
+		clock = RealClock
+	}
+	return &MetricsMerger{
+		global:   global,
+		interval: interval,
+		clock:    clock,
+		done:     make(chan struct{}),
+	}
+}
+
+// Local returns a new buffer for one worker
+func (mm *MetricsMerger) Local() *LocalMetrics {
+	l := &LocalMetrics{latency: NewAtomicHistogram(DefaultLatencyBounds)}
+	l.latencyRange.reset()
+	mm.mu.Lock()
+	mm.locals = append(mm.locals, l)
+	mm.mu.Unlock()
+	return l
+}
+
+// Merge folds every local buffer into the global Metrics now
+func (mm *MetricsMerger) Merge() {
+	mm.mu.Lock()
+	locals := append([]*LocalMetrics(nil), mm.locals...)
+	mm.mu.Unlock()
+	for _, l := range locals {
+		l.mergeInto(mm.global)
+	}
+}
+
+// Start merges every interval in the background; later calls do nothing
+func (mm *MetricsMerger) Start() {
+	mm.startOnce.Do(func() {
+		ticker := mm.clock.NewTicker(mm.interval)
+		mm.wg.Add(1)
+		go func() {
+			defer mm.wg.Done()
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C():
+					mm.Merge()
+				case <-mm.done:
+					return
+				}
+			}
+		}()
+	})
+}
+
+// Stop halts periodic merging and performs a final merge
+func (mm *MetricsMerger) Stop() {
+	mm.stopOnce.Do(func() {
+		close(mm.done)
+		mm.wg.Wait()
+		mm.Merge()
+	})
+}
@@ -0,0 +1,113 @@
+package examples
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestMetricsMerger(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	global := &Metrics{}
+	merger := NewMetricsMerger(global, time.Second, clock)
+	a, b := merger.Local(), merger.Local()
+
+	a.RecordRequest()
+	a.RecordBytes(10)
+	b.RecordRequest()
+	b.RecordError()
+	b.RecordLatency(time.Millisecond)
+
+	// Nothing reaches the global metrics until a merge
+	req, _, _ := global.GetSnapshot()
+	g.Expect(req).To(Equal(int64(0)))
+
+	merger.Start()
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	g.Eventually(func() int64 {
+		req, _, _ := global.GetSnapshot()
+		return req
+	}).Should(Equal(int64(2)))
+
+	s := global.Snapshot()
+	g.Expect(s.Counters).To(Equal(map[string]int64{CounterRequests: 2, CounterErrors: 1, CounterBytes: 10}))
+	g.Expect(s.Histograms[HistogramLatency].Count).To(Equal(int64(1)))
+
+	// Stop merges whatever was recorded since the last tick
+	a.RecordRequest()
+	merger.Stop()
+	g.Expect(global.Snapshot().Counters[CounterRequests]).To(Equal(int64(3)))
+}
+
+func TestMetricsMergerConcurrent(t *testing.T) {
+	g := NewWithT(t)
+
+	global := &Metrics{}
+	merger := NewMetricsMerger(global, time.Millisecond, nil)
+	merger.Start()
+
+	const workers, perWorker = 8, 10000
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		local := merger.Local()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perWorker; j++ {
+				local.RecordRequest()
+				local.RecordBytes(2)
+			}
+		}()
+	}
+	wg.Wait()
+	merger.Stop()
+
+	s := global.Snapshot()
+	g.Expect(s.Counters[CounterRequests]).To(Equal(int64(workers * perWorker)))
+	g.Expect(s.Counters[CounterBytes]).To(Equal(int64(2 * workers * perWorker)))
+}
+
+func TestAtomicHistogramMerge(t *testing.T) {
+	g := NewWithT(t)
+
+	a := NewAtomicHistogram([]int64{10, 20})
+	b := NewAtomicHistogram([]int64{10, 20})
+	a.Observe(5)
+	b.Observe(15)
+	b.Observe(25)
+
+	a.Merge(b.Snapshot())
+	snap := a.Snapshot()
+	g.Expect(snap.Counts).To(Equal([]int64{1, 1, 1}))
+	g.Expect(snap.Sum).To(Equal(int64(45)))
+}
+
+// BenchmarkMetricsSharedAtomic has every goroutine update one shared Metrics
+func BenchmarkMetricsSharedAtomic(b *testing.B) {
+	m := &Metrics{}
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			m.RecordRequest()
+			m.RecordBytes(64)
+		}
+	})
+}
+
+// BenchmarkMetricsLocalMerged gives each goroutine its own LocalMetrics
+func BenchmarkMetricsLocalMerged(b *testing.B) {
+	merger := NewMetricsMerger(&Metrics{}, 10*time.Millisecond, nil)
+	merger.Start()
+	defer merger.Stop()
+	b.RunParallel(func(pb *testing.PB) {
+		local := merger.Local()
+		for pb.Next() {
+			local.RecordRequest()
+			local.RecordBytes(64)
+		}
+	})
+}
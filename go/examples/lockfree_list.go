@@ -0,0 +1,131 @@
+package examples
+
+import (
+	"cmp"
+	"sync/atomic"
+)
+
+// markedRef is a next pointer together with its node's deletion mark. Go
+// cannot steal a pointer bit for the mark as C does, so each change installs
+// a fresh immutable markedRef and CAS compares the whole pair by identity.
+type markedRef[K cmp.Ordered] struct {
+	node   *listNode[K]
+	marked bool // The node owning this ref is logically deleted
+}
+
+type listNode[K cmp.Ordered] struct {
+	key  K
+	next atomic.Pointer[markedRef[K]]
+}
+
+// LockFreeList is a sorted set in a singly linked list after Harris (2001).
+// Delete first marks a node's next pointer, which logically removes it and
+// makes any CAS that would link a node after it fail, then tries to unlink
+// it; traversals that meet marked nodes unlink them on the way. Contains
+// never writes and never retries.
+type LockFreeList[K cmp.Ordered] struct {
+	head *listNode[K] // Sentinel before the smallest key
+	size int64
+}
+
+// NewLockFreeList creates an empty set
+func NewLockFreeList[K cmp.Ordered]() *LockFreeList[K] {
+	head := &listNode[K]{}
+	head.next.Store(&markedRef[K]{})
+	return &LockFreeList[K]{head: head}
+}
+
+// find returns the last node before key, the ref it was read through, and the
+// first node with a key not below key (nil at the end), unlinking marked
+// nodes in between
+func (l *LockFreeList[K]) find(key K) (pred *listNode[K], predRef *markedRef[K], curr *listNode[K]) {
+retry:
+	for {
+		pred = l.head
+		predRef = pred.next.Load()
+		curr = predRef.node
+		for curr != nil {
+			currRef := curr.next.Load()
+			if currRef.marked {
+				// curr is deleted: unlink it, or start over if pred changed
+				unlinked := &markedRef[K]{node: currRef.node}
+				if !pred.next.CompareAndSwap(predRef, unlinked) {
+					continue retry
+				}
+				predRef, curr = unlinked, currRef.node
+				continue
+			}
+			if curr.key >= key {
+				return pred, predRef, curr
+			}
+			pred, predRef, curr = curr, currRef, currRef.node
+		}
+		return pred, predRef, nil
+	}
+}
+
+// Insert adds key, reporting false if it was already present
+func (l *LockFreeList[K]) Insert(key K) bool {
+	for {
+		pred, predRef, curr := l.find(key)
+		if curr != nil && curr.key == key {
+			return false
+		}
+		n := &listNode[K]{key: key}
+		n.next.Store(&markedRef[K]{node: curr})
+		if pred.next.CompareAndSwap(predRef, &markedRef[K]{node: n}) {
+			atomic.AddInt64(&l.size, 1)
+			return true
+		}
+	}
+}
+
+// Delete removes key, reporting false if it was not present
+func (l *LockFreeList[K]) Delete(key K) bool {
+	for {
+		pred, predRef, curr := l.find(key)
+		if curr == nil || curr.key != key {
+			return false
+		}
+		currRef := curr.next.Load()
+		if currRef.marked {
+			continue // Another Delete got there first; find will unlink it
+		}
+		if !curr.next.CompareAndSwap(currRef, &markedRef[K]{node: currRef.node, marked: true}) {
+			continue
+		}
+		atomic.AddInt64(&l.size, -1)
+		// Best effort: if this fails, a later find unlinks it
+		pred.next.CompareAndSwap(predRef, &markedRef[K]{node: currRef.node})
+		return true
+	}
+}
+
+// Contains reports whether key is present
+func (l *LockFreeList[K]) Contains(key K) bool {
+	curr := l.head.next.Load().node
+	for curr != nil && curr.key < key {
+		curr = curr.next.Load().node
+	}
+	return curr != nil && curr.key == key && !curr.next.Load().marked
+}
+
+// Len returns the number of keys; under concurrent use it may be briefly stale
+func (l *LockFreeList[K]) Len() int {
+	return int(atomic.LoadInt64(&l.size))
+}
+
+// Keys returns the keys present in ascending order. Under concurrent updates
+// it is not a snapshot: keys inserted or deleted during the walk may or may
+// not appear.
+func (l *LockFreeList[K]) Keys() []K {
+	var keys []K
+	for curr := l.head.next.Load().node; curr != nil; {
+		ref := curr.next.Load()
+		if !ref.marked {
+			keys = append(keys, curr.key)
+		}
+		curr = ref.node
+	}
+	return keys
+}
@@ -0,0 +1,135 @@
+package examples
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestLockFreeList(t *testing.T) {
+	g := NewWithT(t)
+
+	l := NewLockFreeList[int]()
+	for _, k := range []int{5, 1, 3} {
+		g.Expect(l.Insert(k)).To(BeTrue())
+	}
+	g.Expect(l.Insert(3)).To(BeFalse())
+	g.Expect(l.Keys()).To(Equal([]int{1, 3, 5}))
+	g.Expect(l.Contains(3)).To(BeTrue())
+	g.Expect(l.Contains(4)).To(BeFalse())
+
+	g.Expect(l.Delete(3)).To(BeTrue())
+	g.Expect(l.Delete(3)).To(BeFalse())
+	g.Expect(l.Delete(9)).To(BeFalse())
+	g.Expect(l.Contains(3)).To(BeFalse())
+	g.Expect(l.Keys()).To(Equal([]int{1, 5}))
+	g.Expect(l.Len()).To(Equal(2))
+}
+
+// referenceSet is the mutex-protected model the lock-free list is checked against
+type referenceSet struct {
+	mu   sync.Mutex
+	keys map[int]bool
+}
+
+func (r *referenceSet) apply(op, key int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch op {
+	case 0:
+		if r.keys[key] {
+			return false
+		}
+		r.keys[key] = true
+		return true
+	case 1:
+		if !r.keys[key] {
+			return false
+		}
+		delete(r.keys, key)
+		return true
+	default:
+		return r.keys[key]
+	}
+}
+
+func TestLockFreeListAgainstModel(t *testing.T) {
+	g := NewWithT(t)
+
+	// Each worker owns the keys congruent to it modulo workers, so the model
+	// can predict every result exactly, while all workers splice the same
+	// list and contend on neighbouring nodes
+	const workers, ops, keySpace = 8, 3000, 64
+	l := NewLockFreeList[int]()
+	model := &referenceSet{keys: map[int]bool{}}
+	mismatches := make(chan int, workers*ops)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(int64(w)))
+			for i := 0; i < ops; i++ {
+				key := rng.Intn(keySpace/workers)*workers + w
+				op := rng.Intn(3)
+				var got bool
+				switch op {
+				case 0:
+					got = l.Insert(key)
+				case 1:
+					got = l.Delete(key)
+				default:
+					got = l.Contains(key)
+				}
+				if want := model.apply(op, key); got != want {
+					mismatches <- key
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(mismatches)
+	g.Expect(mismatches).To(BeEmpty())
+
+	var want []int
+	for k := range model.keys {
+		want = append(want, k)
+	}
+	sort.Ints(want)
+	g.Expect(l.Keys()).To(Equal(want))
+	g.Expect(l.Len()).To(Equal(len(want)))
+}
+
+func TestLockFreeListContendedKey(t *testing.T) {
+	g := NewWithT(t)
+
+	// Everyone fights over one key: successful inserts and deletes must alternate
+	l := NewLockFreeList[int]()
+	var mu sync.Mutex
+	inserts, deletes := 0, 0
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				ins, del := l.Insert(7), l.Delete(7)
+				mu.Lock()
+				if ins {
+					inserts++
+				}
+				if del {
+					deletes++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	g.Expect(inserts).To(Equal(deletes))
+	g.Expect(l.Contains(7)).To(BeFalse())
+	g.Expect(l.Len()).To(BeZero())
+}
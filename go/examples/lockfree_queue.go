@@ -0,0 +1,169 @@
+package examples
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/camilbenameur/learning/go/ebr"
+)
+
+// lockFreeQueueSlab is how many nodes a LockFreeQueue keeps in its FreeList
+const lockFreeQueueSlab = 1024
+
+type queueNode[T any] struct {
+	value T
+	next  atomic.Pointer[queueNode[T]]
+	slot  int // Index in the queue's FreeList, or -1 if allocated on the heap
+}
+
+// LockFreeQueue is a Michael-Scott queue: a linked list with a dummy node at
+// the head, where Enqueue CASes a node onto the tail's next pointer and
+// Dequeue CASes the head forward. Any goroutine that finds the tail lagging
+// swings it on, so no operation waits for another to finish.
+//
+// Nodes come from a FreeList slab and are recycled into it once dequeued,
+// so a queue that stays under the slab's size allocates nothing; past it,
+// nodes come from the heap and are left to the garbage collector. Recycling
+// would let a slow Dequeue read a node after it has been reused, so epoch-based
+// reclamation holds each node back until no operation that could have seen it
+// is still running. The queue keeps its own limbo of slot indexes per epoch
+// rather than handing ebr a closure per node, which would allocate.
+type LockFreeQueue[T any] struct {
+	head atomic.Pointer[queueNode[T]]
+	tail atomic.Pointer[queueNode[T]]
+	size int64
+
+	epochs *ebr.Domain
+	nodes  *FreeList[queueNode[T]]
+
+	mu    sync.Mutex
+	limbo [3]queueLimbo // Slots retired in each epoch, modulo 3
+}
+
+// queueLimbo is the slots retired during one epoch; the slice is reused
+type queueLimbo struct {
+	epoch int64
+	slots []int
+}
+
+// NewLockFreeQueue creates an empty queue
+func NewLockFreeQueue[T any]() *LockFreeQueue[T] {
+	q := &LockFreeQueue[T]{epochs: ebr.New(), nodes: NewFreeList[queueNode[T]](lockFreeQueueSlab)}
+	dummy := &queueNode[T]{slot: -1}
+	q.head.Store(dummy)
+	q.tail.Store(dummy)
+	return q
+}
+
+// Enqueue adds v at the tail
+func (q *LockFreeQueue[T]) Enqueue(v T) {
+	n := q.alloc()
+	n.value = v
+	guard := q.epochs.Enter()
+	defer guard.Exit()
+	for {
+		tail := q.tail.Load()
+		next := tail.next.Load()
+		if tail != q.tail.Load() {
+			continue
+		}
+		if next != nil {
+			q.tail.CompareAndSwap(tail, next) // Help a lagging Enqueue
+			continue
+		}
+		if tail.next.CompareAndSwap(nil, n) {
+			q.tail.CompareAndSwap(tail, n)
+			atomic.AddInt64(&q.size, 1)
+			return
+		}
+	}
+}
+
+// Dequeue removes and returns the value at the head, or reports false if the
+// queue is empty
+func (q *LockFreeQueue[T]) Dequeue() (T, bool) {
+	guard := q.epochs.Enter()
+	defer guard.Exit()
+	for {
+		head := q.head.Load()
+		tail := q.tail.Load()
+		next := head.next.Load()
+		if head != q.head.Load() {
+			continue
+		}
+		if next == nil {
+			var zero T
+			return zero, false
+		}
+		if head == tail {
+			q.tail.CompareAndSwap(tail, next)
+			continue
+		}
+		// Read before the CAS: afterwards next is the new dummy and may be dequeued past
+		v := next.value
+		if q.head.CompareAndSwap(head, next) {
+			atomic.AddInt64(&q.size, -1)
+			q.retire(head)
+			return v, true
+		}
+	}
+}
+
+// Len returns the number of values; under concurrent use it may be briefly stale
+func (q *LockFreeQueue[T]) Len() int {
+	return int(atomic.LoadInt64(&q.size))
+}
+
+func (q *LockFreeQueue[T]) alloc() *queueNode[T] {
+	i, ok := q.nodes.Alloc()
+	if !ok {
+		return &queueNode[T]{slot: -1}
+	}
+	n := q.nodes.At(i)
+	n.slot = i
+	n.next.Store(nil)
+	return n
+}
+
+// retire hands a dequeued node back to the slab once no critical section
+// can still hold it. Slots are tagged with the epoch read after the node was
+// unlinked: every reader that could have reached it entered at that epoch or
+// earlier, so all of them have exited once the global epoch is two past it.
+func (q *LockFreeQueue[T]) retire(n *queueNode[T]) {
+	if n.slot < 0 {
+		return // The garbage collector has it
+	}
+	e := q.epochs.Epoch()
+	q.mu.Lock()
+	b := &q.limbo[e%3]
+	if b.epoch < e {
+		q.freeLocked(b) // Left over from epoch e-3 or earlier
+		b.epoch = e
+	} // A later tag only delays the free, so the slot can join it
+	b.slots = append(b.slots, n.slot)
+	q.mu.Unlock()
+	if q.epochs.TryAdvance() {
+		q.reclaim()
+	}
+}
+
+// reclaim frees every limbo slot whose epoch is at least two behind
+func (q *LockFreeQueue[T]) reclaim() {
+	e := q.epochs.Epoch()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i := range q.limbo {
+		if b := &q.limbo[i]; b.epoch <= e-2 {
+			q.freeLocked(b)
+		}
+	}
+}
+
+func (q *LockFreeQueue[T]) freeLocked(b *queueLimbo) {
+	var zero T
+	for _, slot := range b.slots {
+		q.nodes.At(slot).value = zero
+		q.nodes.Free(slot)
+	}
+	b.slots = b.slots[:0]
+}
@@ -0,0 +1,188 @@
+package examples
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestLockFreeQueueFIFO(t *testing.T) {
+	g := NewWithT(t)
+
+	q := NewLockFreeQueue[int]()
+	_, ok := q.Dequeue()
+	g.Expect(ok).To(BeFalse())
+	for i := 1; i <= 3; i++ {
+		q.Enqueue(i)
+	}
+	g.Expect(q.Len()).To(Equal(3))
+	for want := 1; want <= 3; want++ {
+		v, ok := q.Dequeue()
+		g.Expect(ok).To(BeTrue())
+		g.Expect(v).To(Equal(want))
+	}
+	g.Expect(q.Len()).To(BeZero())
+}
+
+func TestLockFreeQueueStress(t *testing.T) {
+	g := NewWithT(t)
+
+	q := NewLockFreeQueue[int]()
+	const producers, perProducer = 4, 3000
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				q.Enqueue(p*perProducer + i)
+			}
+		}(p)
+	}
+
+	// Each consumer sees every producer's values in the order they were sent
+	const consumers = 4
+	results := make([][]int, consumers)
+	var remaining int64 = producers * perProducer
+	var mu sync.Mutex
+	for c := 0; c < consumers; c++ {
+		wg.Add(1)
+		go func(c int) {
+			defer wg.Done()
+			for {
+				mu.Lock()
+				done := remaining == 0
+				mu.Unlock()
+				if done {
+					return
+				}
+				v, ok := q.Dequeue()
+				if !ok {
+					runtime.Gosched()
+					continue
+				}
+				mu.Lock()
+				remaining--
+				mu.Unlock()
+				results[c] = append(results[c], v)
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	seen := make([]bool, producers*perProducer)
+	for _, vs := range results {
+		last := make([]int, producers)
+		for i := range last {
+			last[i] = -1
+		}
+		for _, v := range vs {
+			g.Expect(seen[v]).To(BeFalse(), "value %d dequeued twice", v)
+			seen[v] = true
+			p := v / perProducer
+			g.Expect(v).To(BeNumerically(">", last[p]), "producer %d out of order", p)
+			last[p] = v
+		}
+	}
+	g.Expect(seen).NotTo(ContainElement(false))
+
+	// Every slab node came back once nobody could still hold it
+	drainQueueLimbo(q)
+	g.Expect(q.nodes.Available()).To(Equal(lockFreeQueueSlab))
+}
+
+// drainQueueLimbo advances q's epoch, with nobody inside the queue, until
+// every retired slot is back in the slab
+func drainQueueLimbo[T any](q *LockFreeQueue[T]) {
+	for i := 0; i < 3; i++ {
+		q.epochs.TryAdvance()
+	}
+	q.reclaim()
+}
+
+// TestLockFreeQueueSlab checks that nodes come from the FreeList slab, that
+// the queue spills onto the heap once the slab is used up, and that dequeued
+// slab nodes go back to it
+func TestLockFreeQueueSlab(t *testing.T) {
+	g := NewWithT(t)
+
+	q := NewLockFreeQueue[int]()
+	n := lockFreeQueueSlab + 10
+	for i := 0; i < n; i++ {
+		q.Enqueue(i)
+	}
+	g.Expect(q.nodes.Available()).To(BeZero())
+	for want := 0; want < n; want++ {
+		v, ok := q.Dequeue()
+		g.Expect(ok).To(BeTrue())
+		g.Expect(v).To(Equal(want))
+	}
+
+	drainQueueLimbo(q)
+	g.Expect(q.nodes.Available()).To(Equal(lockFreeQueueSlab))
+
+	// Once warm, an Enqueue and Dequeue pair allocates nothing
+	g.Expect(testing.AllocsPerRun(1000, func() {
+		q.Enqueue(1)
+		q.Dequeue()
+	})).To(BeZero())
+}
+
+// BenchmarkQueueAllocs reports what an Enqueue and Dequeue pair allocates:
+// nothing once the slab and limbo are warm
+func BenchmarkQueueAllocs(b *testing.B) {
+	q := NewLockFreeQueue[int]()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			q.Enqueue(1)
+			q.Dequeue()
+		}
+	})
+}
+
+// BenchmarkReclamationReadPath compares what a reader pays to protect one
+// node under each scheme: hazard pointers publish and re-check the node,
+// epochs announce the reader once for however many nodes it then touches
+func BenchmarkReclamationReadPath(b *testing.B) {
+	b.Run("None", func(b *testing.B) {
+		s := NewHazardStack[int]()
+		s.Push(1)
+		b.RunParallel(func(pb *testing.PB) {
+			sum := 0
+			for pb.Next() {
+				sum += s.head.Load().value
+			}
+			_ = sum
+		})
+	})
+	b.Run("HazardPointer", func(b *testing.B) {
+		s := NewHazardStack[int]()
+		s.Push(1)
+		b.RunParallel(func(pb *testing.PB) {
+			sum := 0
+			for pb.Next() {
+				r := s.domain.Acquire()
+				top, _, _ := s.popBegin(r)
+				sum += top.value
+				s.domain.Release(r)
+			}
+			_ = sum
+		})
+	})
+	b.Run("Epoch", func(b *testing.B) {
+		q := NewLockFreeQueue[int]()
+		q.Enqueue(1)
+		b.RunParallel(func(pb *testing.PB) {
+			sum := 0
+			for pb.Next() {
+				guard := q.epochs.Enter()
+				sum += q.head.Load().next.Load().value
+				guard.Exit()
+			}
+			_ = sum
+		})
+	})
+}
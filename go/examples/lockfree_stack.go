@@ -0,0 +1,92 @@
+package examples
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+type stackNode[T any] struct {
+	value T
+	next  *stackNode[T]
+}
+
+// LockFreeStack is a Treiber stack: the top of the stack is an atomic pointer,
+// and Push and Pop loop on compare-and-swap until they install their new head
+// without another goroutine having moved it in between.
+//
+// A CAS-based stack in C must defend against ABA: Pop reads head A and its
+// next B, another thread pops A and B and pushes A back, and the CAS from A
+// to B succeeds, linking in the freed B. In Go this cannot happen, because a
+// node is never freed and reused while Pop still holds a pointer to it: the
+// garbage collector keeps it alive, so a recycled A is always a new node with
+// a new address. Reusing nodes by hand, as a free list does, brings ABA back.
+type LockFreeStack[T any] struct {
+	head atomic.Pointer[stackNode[T]]
+	size int64
+}
+
+// Push adds v on top
+func (s *LockFreeStack[T]) Push(v T) {
+	n := &stackNode[T]{value: v}
+	for {
+		n.next = s.head.Load()
+		if s.head.CompareAndSwap(n.next, n) {
+			atomic.AddInt64(&s.size, 1)
+			return
+		}
+	}
+}
+
+// Pop removes and returns the top value, or reports false if the stack is empty
+func (s *LockFreeStack[T]) Pop() (T, bool) {
+	for {
+		top := s.head.Load()
+		if top == nil {
+			var zero T
+			return zero, false
+		}
+		if s.head.CompareAndSwap(top, top.next) {
+			atomic.AddInt64(&s.size, -1)
+			return top.value, true
+		}
+	}
+}
+
+// Len returns the number of values; under concurrent use it may be briefly stale
+func (s *LockFreeStack[T]) Len() int {
+	return int(atomic.LoadInt64(&s.size))
+}
+
+// MutexStack is the lock-based baseline for LockFreeStack: a slice under a mutex
+type MutexStack[T any] struct {
+	mu    sync.Mutex
+	items []T
+}
+
+// Push adds v on top
+func (s *MutexStack[T]) Push(v T) {
+	s.mu.Lock()
+	s.items = append(s.items, v)
+	s.mu.Unlock()
+}
+
+// Pop removes and returns the top value, or reports false if the stack is empty
+func (s *MutexStack[T]) Pop() (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var zero T
+	if len(s.items) == 0 {
+		return zero, false
+	}
+	v := s.items[len(s.items)-1]
+	s.items[len(s.items)-1] = zero
+	s.items = s.items[:len(s.items)-1]
+	return v, true
+}
+
+// Len returns the number of values
+func (s *MutexStack[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.items)
+}
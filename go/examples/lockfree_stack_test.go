@@ -0,0 +1,112 @@
+package examples
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// stack is the interface every stack implementation satisfies
+type stack[T any] interface {
+	Push(v T)
+	Pop() (T, bool)
+	Len() int
+}
+
+var stackImpls = []struct {
+	name string
+	new  func() stack[int]
+}{
+	{"LockFree", func() stack[int] { return &LockFreeStack[int]{} }},
+	{"Mutex", func() stack[int] { return &MutexStack[int]{} }},
+	{"Elimination", func() stack[int] { return NewEliminationStack[int](0) }},
+}
+
+func TestStackLIFO(t *testing.T) {
+	for _, impl := range stackImpls {
+		t.Run(impl.name, func(t *testing.T) {
+			g := NewWithT(t)
+			s := impl.new()
+			_, ok := s.Pop()
+			g.Expect(ok).To(BeFalse())
+
+			for i := 1; i <= 3; i++ {
+				s.Push(i)
+			}
+			g.Expect(s.Len()).To(Equal(3))
+			for want := 3; want >= 1; want-- {
+				v, ok := s.Pop()
+				g.Expect(ok).To(BeTrue())
+				g.Expect(v).To(Equal(want))
+			}
+			g.Expect(s.Len()).To(BeZero())
+		})
+	}
+}
+
+func TestStackStress(t *testing.T) {
+	for _, impl := range stackImpls {
+		t.Run(impl.name, func(t *testing.T) {
+			g := NewWithT(t)
+			s := impl.new()
+
+			// Pushers and poppers race; every value pushed is popped exactly once
+			const workers, perWorker = 8, 2000
+			popped := make([][]int, workers)
+			var wg sync.WaitGroup
+			for w := 0; w < workers; w++ {
+				wg.Add(2)
+				go func(w int) {
+					defer wg.Done()
+					for i := 0; i < perWorker; i++ {
+						s.Push(w*perWorker + i)
+					}
+				}(w)
+				go func(w int) {
+					defer wg.Done()
+					for len(popped[w]) < perWorker {
+						if v, ok := s.Pop(); ok {
+							popped[w] = append(popped[w], v)
+						}
+					}
+				}(w)
+			}
+			wg.Wait()
+
+			seen := make([]bool, workers*perWorker)
+			for _, vs := range popped {
+				for _, v := range vs {
+					g.Expect(seen[v]).To(BeFalse(), "value %d popped twice", v)
+					seen[v] = true
+				}
+			}
+			g.Expect(seen).NotTo(ContainElement(false))
+			g.Expect(s.Len()).To(BeZero())
+		})
+	}
+}
+
+// BenchmarkStack measures push/pop pairs at increasing parallelism (goroutines
+// per GOMAXPROCS). The elimination stack only overtakes the plain lock-free one
+// at high parallelism on many cores, where most head CASes fail.
+func BenchmarkStack(b *testing.B) {
+	for _, impl := range stackImpls {
+		for _, procs := range []int{1, 4, 16, 64} {
+			b.Run(fmt.Sprintf("%s/parallelism=%d", impl.name, procs), func(b *testing.B) {
+				s := impl.new()
+				b.SetParallelism(procs)
+				b.RunParallel(func(pb *testing.PB) {
+					for pb.Next() {
+						s.Push(1)
+						s.Pop()
+					}
+				})
+				if e, ok := s.(*EliminationStack[int]); ok {
+					b.ReportMetric(float64(e.Eliminated())/float64(b.N), "eliminated/op")
+				}
+			})
+		}
+	}
+}
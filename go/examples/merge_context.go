@@ -0,0 +1,70 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// mergedContext is cancelled by its own cancel, its deadline, or any parent,
+// and looks up values in each parent in turn
+type mergedContext struct {
+	context.Context
+	parents []context.Context
+}
+
+func (c *mergedContext) Value(key any) any {
+	// Our own chain first, so the context package finds the cancel context
+	// it created instead of walking into a parent
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+	for _, p := range c.parents {
+		if v := p.Value(key); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// MergeContexts returns a context that is done as soon as any of ctxs is,
+// whose deadline is the earliest of theirs, and whose Value checks ctxs in
+// order and returns the first match. context.Cause of the result is the cause
+// of the parent that ended it. This lets a worker that serves two lifetimes,
+// e.g. a request's and its pool's, stop when either ends.
+//
+// The caller must call cancel once the merged context is no longer needed,
+// to release the watches it registers on the parents.
+func MergeContexts(ctxs ...context.Context) (context.Context, context.CancelFunc) {
+	base := context.Background()
+	cancelDeadline := context.CancelFunc(func() {})
+	var earliest time.Time
+	for _, p := range ctxs {
+		if d, ok := p.Deadline(); ok && (earliest.IsZero() || d.Before(earliest)) {
+			earliest = d
+		}
+	}
+	if !earliest.IsZero() {
+		base, cancelDeadline = context.WithDeadline(base, earliest)
+	}
+	ctx, cancelCause := context.WithCancelCause(base)
+
+	stops := make([]func() bool, 0, len(ctxs))
+	for _, p := range ctxs {
+		stops = append(stops, context.AfterFunc(p, func() {
+			// A parent past its deadline means ours, which is no later, has
+			// passed too; leave it to our timer so Err is DeadlineExceeded
+			if errors.Is(p.Err(), context.DeadlineExceeded) {
+				return
+			}
+			cancelCause(context.Cause(p))
+		}))
+	}
+	return &mergedContext{Context: ctx, parents: ctxs}, func() {
+		for _, stop := range stops {
+			stop()
+		}
+		cancelCause(context.Canceled)
+		cancelDeadline()
+	}
+}
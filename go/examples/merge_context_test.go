@@ -0,0 +1,90 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type mergeKey string
+
+func TestMergeContextsCancelsWithAnyParent(t *testing.T) {
+	g := NewWithT(t)
+
+	a, cancelA := context.WithCancel(context.Background())
+	defer cancelA()
+	errShutdown := errors.New("pool shutting down")
+	b, cancelB := context.WithCancelCause(context.Background())
+	ctx, cancel := MergeContexts(a, b)
+	defer cancel()
+
+	g.Consistently(ctx.Done(), 10*time.Millisecond).ShouldNot(BeClosed())
+	cancelB(errShutdown)
+	g.Eventually(ctx.Done()).Should(BeClosed())
+	g.Expect(ctx.Err()).To(MatchError(context.Canceled))
+	g.Expect(context.Cause(ctx)).To(MatchError(errShutdown))
+
+	// Children of the merged context are cancelled with it
+	child, cancelChild := context.WithCancel(ctx)
+	defer cancelChild()
+	g.Expect(child.Done()).To(BeClosed())
+}
+
+func TestMergeContextsEarliestDeadline(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	late, cancelLate := context.WithDeadline(context.Background(), now.Add(time.Hour))
+	defer cancelLate()
+	early, cancelEarly := context.WithDeadline(context.Background(), now.Add(20*time.Millisecond))
+	defer cancelEarly()
+
+	ctx, cancel := MergeContexts(late, context.Background(), early)
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	g.Expect(ok).To(BeTrue())
+	g.Expect(deadline).To(Equal(now.Add(20 * time.Millisecond)))
+	g.Eventually(ctx.Done()).Should(BeClosed())
+	g.Expect(ctx.Err()).To(MatchError(context.DeadlineExceeded))
+
+	// Without any parent deadline there is none
+	ctx, cancel = MergeContexts(context.Background(), context.TODO())
+	defer cancel()
+	_, ok = ctx.Deadline()
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestMergeContextsValues(t *testing.T) {
+	g := NewWithT(t)
+
+	a := context.WithValue(context.Background(), mergeKey("request"), "req-1")
+	a = context.WithValue(a, mergeKey("shared"), "from a")
+	b := context.WithValue(context.Background(), mergeKey("pool"), "workers")
+	b = context.WithValue(b, mergeKey("shared"), "from b")
+
+	ctx, cancel := MergeContexts(a, b)
+	defer cancel()
+	g.Expect(ctx.Value(mergeKey("request"))).To(Equal("req-1"))
+	g.Expect(ctx.Value(mergeKey("pool"))).To(Equal("workers"))
+	g.Expect(ctx.Value(mergeKey("shared"))).To(Equal("from a")) // First parent wins
+	g.Expect(ctx.Value(mergeKey("missing"))).To(BeNil())
+
+	// Values stay visible through contexts derived from the merge
+	child := context.WithValue(ctx, mergeKey("child"), 1)
+	g.Expect(child.Value(mergeKey("pool"))).To(Equal("workers"))
+}
+
+func TestMergeContextsCancel(t *testing.T) {
+	g := NewWithT(t)
+
+	parent, cancelParent := context.WithCancel(context.Background())
+	defer cancelParent()
+	ctx, cancel := MergeContexts(parent)
+	cancel()
+	g.Expect(ctx.Err()).To(MatchError(context.Canceled))
+	g.Expect(context.Cause(ctx)).To(MatchError(context.Canceled))
+	g.Expect(parent.Err()).NotTo(HaveOccurred()) // Cancelling the merge leaves parents alone
+}
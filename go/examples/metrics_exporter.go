@@ -0,0 +1,178 @@
+package examples
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricsExporter ships a snapshot somewhere: a log, a file, a collector.
+// Collection stays on the atomics in Metrics; only exporters do I/O.
+type MetricsExporter interface {
+	Export(s MetricsSnapshot) error
+}
+
+// ExporterFunc adapts a function to MetricsExporter
+type ExporterFunc func(s MetricsSnapshot) error
+
+// Export calls f
+func (f ExporterFunc) Export(s MetricsSnapshot) error {
+	return f(s)
+}
+
+// PushLoop periodically snapshots a Metrics and hands the same snapshot to every
+// registered exporter. Exporters run one after another on the loop goroutine,
+// so a slow exporter delays the next push rather than piling up goroutines.
+type PushLoop struct {
+	metrics  *Metrics
+	interval time.Duration
+	clock    Clock
+	onError  func(error)
+
+	mu        sync.Mutex
+	exporters []MetricsExporter
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	done      chan struct{}
+	wg        sync.WaitGroup
+	stopErr   error
+}
+
+// NewPushLoop creates a loop exporting m every interval, driven by clock
+// (RealClock if nil). onError (optional) receives failed periodic pushes.
+func NewPushLoop(m *Metrics, interval time.Duration, clock Clock, onError func(error)) *PushLoop {
+	if clock == nil {
+		clock = RealClock
+	}
+	return &PushLoop{
+		metrics:  m,
+		interval: interval,
+		clock:    clock,
+		onError:  onError,
+		done:     make(chan struct{}),
+	}
+}
+
+// Register adds exporters; it may be called while the loop is running
+func (p *PushLoop) Register(exporters ...MetricsExporter) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.exporters = append(p.exporters, exporters...)
+}
+
+// Push exports one snapshot immediately and returns every exporter's error joined
+func (p *PushLoop) Push() error {
+	p.mu.Lock()
+	exporters := append([]MetricsExporter(nil), p.exporters...)
+	p.mu.Unlock()
+
+	s := p.metrics.Snapshot()
+	var errs []error
+	for _, e := range exporters {
+		if err := e.Export(s); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Start begins pushing in the background; later calls do nothing
+func (p *PushLoop) Start() {
+	p.startOnce.Do(func() {
+		ticker := p.clock.NewTicker(p.interval)
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C():
+					if err := p.Push(); err != nil && p.onError != nil {
+						p.onError(err)
+					}
+				case <-p.done:
+					return
+				}
+			}
+		}()
+	})
+}
+
+// Stop halts the loop and performs a final push so nothing recorded since the
+// last tick is lost. It returns the final push's error.
+func (p *PushLoop) Stop() error {
+	p.stopOnce.Do(func() {
+		close(p.done)
+		p.wg.Wait()
+		p.stopErr = p.Push()
+	})
+	return p.stopErr
+}
+
+// TextExporter writes one human-readable line per snapshot, e.g. to os.Stdout
+type TextExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewTextExporter creates an exporter writing to w
+func NewTextExporter(w io.Writer) *TextExporter {
+	return &TextExporter{w: w}
+}
+
+// Export writes counters, latency percentiles and gauges in key=value form
+func (e *TextExporter) Export(s MetricsSnapshot) error {
+	var b strings.Builder
+	b.WriteString(s.Timestamp.UTC().Format(time.RFC3339))
+	for _, name := range sortedKeys(s.Counters) {
+		fmt.Fprintf(&b, " %s=%d", name, s.Counters[name])
+	}
+	if h, ok := s.Histograms[HistogramLatency]; ok && h.Count > 0 {
+		fmt.Fprintf(&b, " p50=%s p99=%s", time.Duration(h.Quantile(0.5)), time.Duration(h.Quantile(0.99)))
+	}
+	for _, name := range sortedKeys(s.Gauges) {
+		fmt.Fprintf(&b, " %s=%g", name, s.Gauges[name])
+	}
+	b.WriteByte('\n')
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+// JSONFileExporter keeps the latest snapshot in a JSON file, replaced atomically
+// on every export so readers never see a partial document
+type JSONFileExporter struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewJSONFileExporter creates an exporter writing to path
+func NewJSONFileExporter(path string) *JSONFileExporter {
+	return &JSONFileExporter{path: path}
+}
+
+// Export replaces the file with s
+func (e *JSONFileExporter) Export(s MetricsSnapshot) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return writeFileAtomic(e.path, func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(s)
+	})
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
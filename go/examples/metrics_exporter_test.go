@@ -0,0 +1,139 @@
+package examples
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// recordingExporter keeps every snapshot it receives
+type recordingExporter struct {
+	mu        sync.Mutex
+	snapshots []MetricsSnapshot
+}
+
+func (e *recordingExporter) Export(s MetricsSnapshot) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.snapshots = append(e.snapshots, s)
+	return nil
+}
+
+func (e *recordingExporter) requests() []int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var out []int64
+	for _, s := range e.snapshots {
+		out = append(out, s.Counters[CounterRequests])
+	}
+	return out
+}
+
+func TestPushLoop(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	m := &Metrics{}
+	a, b := &recordingExporter{}, &recordingExporter{}
+
+	loop := NewPushLoop(m, 10*time.Second, clock, nil)
+	loop.Register(a)
+	loop.Start()
+	clock.BlockUntil(1)
+
+	m.RecordRequest()
+	clock.Advance(10 * time.Second)
+	g.Eventually(a.requests).Should(Equal([]int64{1}))
+
+	// Exporters registered later join from the next push
+	loop.Register(b)
+	m.RecordRequest()
+	clock.Advance(10 * time.Second)
+	g.Eventually(a.requests).Should(Equal([]int64{1, 2}))
+	g.Eventually(b.requests).Should(Equal([]int64{2}))
+
+	// Stop flushes what was recorded since the last tick
+	m.RecordRequest()
+	g.Expect(loop.Stop()).To(Succeed())
+	g.Expect(a.requests()).To(Equal([]int64{1, 2, 3}))
+	g.Expect(b.requests()).To(Equal([]int64{2, 3}))
+	g.Expect(clock.Waiters()).To(Equal(0))
+}
+
+func TestPushLoopErrors(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	var reported int64
+	loop := NewPushLoop(&Metrics{}, time.Second, clock, func(err error) {
+		atomic.AddInt64(&reported, 1)
+	})
+
+	boom := errors.New("collector down")
+	ok := &recordingExporter{}
+	loop.Register(ExporterFunc(func(MetricsSnapshot) error { return boom }), ok)
+
+	// A failing exporter does not prevent the others from receiving the snapshot
+	g.Expect(loop.Push()).To(MatchError(boom))
+	g.Expect(ok.requests()).To(HaveLen(1))
+
+	loop.Start()
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	g.Eventually(func() int64 { return atomic.LoadInt64(&reported) }).Should(Equal(int64(1)))
+
+	g.Expect(loop.Stop()).To(MatchError(boom))
+	g.Expect(loop.Stop()).To(MatchError(boom))
+}
+
+func TestTextExporter(t *testing.T) {
+	g := NewWithT(t)
+
+	m := &Metrics{}
+	m.RecordRequest()
+	m.RecordBytes(64)
+	m.RecordLatency(time.Millisecond)
+	m.SetGauge("workers", 4)
+	s := m.Snapshot()
+	s.Timestamp = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	var buf strings.Builder
+	g.Expect(NewTextExporter(&buf).Export(s)).To(Succeed())
+	line := buf.String()
+	g.Expect(line).To(HavePrefix("2024-05-01T12:00:00Z bytes=64 errors=0 requests=1 p50="))
+	g.Expect(line).To(HaveSuffix(" workers=4\n"))
+}
+
+func TestJSONFileExporter(t *testing.T) {
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "metrics.json")
+	e := NewJSONFileExporter(path)
+
+	m := &Metrics{}
+	m.RecordRequest()
+	g.Expect(e.Export(m.Snapshot())).To(Succeed())
+	m.RecordRequest()
+	g.Expect(e.Export(m.Snapshot())).To(Succeed())
+
+	// The file holds only the latest snapshot
+	data, err := os.ReadFile(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	var s MetricsSnapshot
+	g.Expect(json.Unmarshal(data, &s)).To(Succeed())
+	g.Expect(s.Counters[CounterRequests]).To(Equal(int64(2)))
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(entries).To(HaveLen(1))
+
+	g.Expect(NewJSONFileExporter(filepath.Join(path, "nested")).Export(s)).NotTo(Succeed())
+}
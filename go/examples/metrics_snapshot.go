@@ -0,0 +1,129 @@
+package examples
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Counter names used in MetricsSnapshot.Counters
+const (
+	CounterRequests = "requests"
+	CounterErrors   = "errors"
+	CounterBytes    = "bytes"
+)
+
+// HistogramLatency is the MetricsSnapshot.Histograms key of the latency histogram.
+// Other keys are names passed to ObserveDuration; all histograms hold nanoseconds.
+const HistogramLatency = "latency"
+
+// MetricsSnapshot is a point-in-time copy of everything in Metrics.
+// It encodes to JSON as-is, and Diff turns two snapshots into per-interval values.
+type MetricsSnapshot struct {
+	Timestamp  time.Time                    `json:"timestamp"`
+	Interval   time.Duration                `json:"interval,omitempty"` // Set only on diffs
+	Counters   map[string]int64             `json:"counters"`
+	Gauges     map[string]float64           `json:"gauges"`
+	Histograms map[string]HistogramSnapshot `json:"histograms"`
+	Latency    LatencyStats                 `json:"latency"`
+}
+
+// Snapshot copies the current counters, gauges and duration histograms.
+// Each value is read atomically, but not all of them at the same instant.
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	return MetricsSnapshot{
+		Timestamp: time.Now(),
+		Counters: map[string]int64{
+			CounterRequests: atomic.LoadInt64(&m.requests),
+			CounterErrors:   atomic.LoadInt64(&m.errors),
+			CounterBytes:    atomic.LoadInt64(&m.totalBytes),
+		},
+		Gauges:     m.gaugeValues(),
+		Histograms: m.histogramSnapshots((*AtomicHistogram).Snapshot),
+		Latency:    m.latencyRange.load(),
+	}
+}
+
+// SwapAndReset captures the counters and duration histograms and zeroes them in
+// the same atomic swap, so a periodic reporter never loses events recorded
+// between reading and resetting; each event is in exactly one returned snapshot.
+// Gauges are levels rather than totals, so they are reported but not reset.
+func (m *Metrics) SwapAndReset() MetricsSnapshot {
+	return MetricsSnapshot{
+		Timestamp: time.Now(),
+		Counters: map[string]int64{
+			CounterRequests: atomic.SwapInt64(&m.requests, 0),
+			CounterErrors:   atomic.SwapInt64(&m.errors, 0),
+			CounterBytes:    atomic.SwapInt64(&m.totalBytes, 0),
+		},
+		Gauges:     m.gaugeValues(),
+		Histograms: m.histogramSnapshots((*AtomicHistogram).SnapshotAndReset),
+		Latency:    m.latencyRange.swap(),
+	}
+}
+
+func (m *Metrics) histogramSnapshots(snap func(*AtomicHistogram) HistogramSnapshot) map[string]HistogramSnapshot {
+	histograms := map[string]HistogramSnapshot{
+		HistogramLatency: snap(m.latencyHistogram()),
+	}
+	m.histograms.Range(func(k, h interface{}) bool {
+		histograms[k.(string)] = snap(h.(*AtomicHistogram))
+		return true
+	})
+	return histograms
+}
+
+func (m *Metrics) gaugeValues() map[string]float64 {
+	gauges := map[string]float64{}
+	m.gauges.Range(func(k, _ interface{}) bool {
+		gauges[k.(string)] = m.Gauge(k.(string))
+		return true
+	})
+	return gauges
+}
+
+// Diff returns what changed between prev and s: counters and histograms hold
+// the increase over the interval, gauges keep their latest value. A counter
+// that went down was reset, so its current value is taken as the increase.
+// Latency min and max cannot be subtracted, so they are the latest too.
+func (s MetricsSnapshot) Diff(prev MetricsSnapshot) MetricsSnapshot {
+	d := MetricsSnapshot{
+		Timestamp:  s.Timestamp,
+		Interval:   s.Timestamp.Sub(prev.Timestamp),
+		Counters:   make(map[string]int64, len(s.Counters)),
+		Gauges:     make(map[string]float64, len(s.Gauges)),
+		Histograms: make(map[string]HistogramSnapshot, len(s.Histograms)),
+		Latency:    s.Latency,
+	}
+	if s.Latency.Count >= prev.Latency.Count {
+		d.Latency.Count -= prev.Latency.Count
+	}
+	for name, v := range s.Counters {
+		if before := prev.Counters[name]; v >= before {
+			v -= before
+		}
+		d.Counters[name] = v
+	}
+	for name, v := range s.Gauges {
+		d.Gauges[name] = v
+	}
+	for name, h := range s.Histograms {
+		d.Histograms[name] = h.Sub(prev.Histograms[name])
+	}
+	return d
+}
+
+// Rate returns the per-second increase of a counter in a diff, or 0 without an interval
+func (s MetricsSnapshot) Rate(counter string) float64 {
+	if s.Interval <= 0 {
+		return 0
+	}
+	return float64(s.Counters[counter]) / s.Interval.Seconds()
+}
+
+// ErrorRatio returns the fraction of requests that failed, or 0 without requests
+func (s MetricsSnapshot) ErrorRatio() float64 {
+	if s.Counters[CounterRequests] == 0 {
+		return 0
+	}
+	return float64(s.Counters[CounterErrors]) / float64(s.Counters[CounterRequests])
+}
@@ -0,0 +1,166 @@
+package examples
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestMetricsSnapshot(t *testing.T) {
+	g := NewWithT(t)
+
+	m := &Metrics{}
+	m.RecordRequest()
+	m.RecordRequest()
+	m.RecordError()
+	m.RecordBytes(512)
+	m.RecordLatency(time.Millisecond)
+	m.SetGauge("queue_depth", 3)
+
+	s := m.Snapshot()
+	g.Expect(s.Timestamp).NotTo(BeZero())
+	g.Expect(s.Counters).To(Equal(map[string]int64{
+		CounterRequests: 2, CounterErrors: 1, CounterBytes: 512,
+	}))
+	g.Expect(s.Gauges).To(Equal(map[string]float64{"queue_depth": 3}))
+	g.Expect(s.Histograms[HistogramLatency].Count).To(Equal(int64(1)))
+
+	// The legacy accessor still works
+	req, errs, bytes := m.GetSnapshot()
+	g.Expect([]int64{req, errs, bytes}).To(Equal([]int64{2, 1, 512}))
+}
+
+func TestMetricsSnapshotJSON(t *testing.T) {
+	g := NewWithT(t)
+
+	m := &Metrics{}
+	m.RecordRequest()
+	m.RecordLatency(2 * time.Millisecond)
+	m.SetGauge("workers", 4)
+	s := m.Snapshot()
+
+	data, err := json.Marshal(s)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(data)).To(ContainSubstring(`"counters":{"bytes":0,"errors":0,"requests":1}`))
+	g.Expect(string(data)).NotTo(ContainSubstring(`"interval"`))
+
+	var decoded MetricsSnapshot
+	g.Expect(json.Unmarshal(data, &decoded)).To(Succeed())
+	g.Expect(decoded.Timestamp.Equal(s.Timestamp)).To(BeTrue())
+	decoded.Timestamp = s.Timestamp
+	g.Expect(decoded).To(Equal(s))
+}
+
+func TestMetricsSnapshotDiff(t *testing.T) {
+	g := NewWithT(t)
+
+	m := &Metrics{}
+	m.RecordRequest()
+	m.RecordLatency(time.Millisecond)
+	m.SetGauge("workers", 2)
+	before := m.Snapshot()
+
+	for i := 0; i < 10; i++ {
+		m.RecordRequest()
+	}
+	m.RecordLatency(time.Second)
+	m.SetGauge("workers", 5)
+	after := m.Snapshot()
+	after.Timestamp = before.Timestamp.Add(2 * time.Second)
+
+	d := after.Diff(before)
+	g.Expect(d.Interval).To(Equal(2 * time.Second))
+	g.Expect(d.Counters[CounterRequests]).To(Equal(int64(10)))
+	g.Expect(d.Rate(CounterRequests)).To(BeNumerically("~", 5.0, 0.001))
+	g.Expect(d.Gauges["workers"]).To(Equal(5.0))
+
+	latency := d.Histograms[HistogramLatency]
+	g.Expect(latency.Count).To(Equal(int64(1)))
+	g.Expect(latency.Sum).To(Equal(int64(time.Second)))
+
+	// A reset between snapshots reports the post-reset values
+	m.Reset()
+	m.RecordRequest()
+	d = m.Snapshot().Diff(after)
+	g.Expect(d.Counters[CounterRequests]).To(Equal(int64(1)))
+	g.Expect(d.Histograms[HistogramLatency].Count).To(Equal(int64(0)))
+
+	g.Expect(MetricsSnapshot{}.Rate(CounterRequests)).To(Equal(0.0))
+}
+
+func TestMetricsSwapAndReset(t *testing.T) {
+	g := NewWithT(t)
+
+	m := &Metrics{}
+	m.RecordRequest()
+	m.RecordBytes(10)
+	m.RecordLatency(time.Millisecond)
+	m.SetGauge("workers", 2)
+
+	s := m.SwapAndReset()
+	g.Expect(s.Counters).To(Equal(map[string]int64{CounterRequests: 1, CounterErrors: 0, CounterBytes: 10}))
+	g.Expect(s.Histograms[HistogramLatency].Count).To(Equal(int64(1)))
+	g.Expect(s.Gauges).To(Equal(map[string]float64{"workers": 2}))
+
+	// Counters and histogram start over; gauges keep their level
+	after := m.Snapshot()
+	g.Expect(after.Counters[CounterRequests]).To(Equal(int64(0)))
+	g.Expect(after.Histograms[HistogramLatency].Count).To(Equal(int64(0)))
+	g.Expect(after.Gauges["workers"]).To(Equal(2.0))
+}
+
+func TestMetricsSwapAndResetNoLoss(t *testing.T) {
+	g := NewWithT(t)
+
+	const writers, perWriter = 8, 20000
+	m := &Metrics{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perWriter; j++ {
+				m.RecordRequest()
+				m.RecordBytes(3)
+				m.RecordLatency(time.Millisecond)
+			}
+		}()
+	}
+
+	// A reporter swaps continuously while writers run; every event must
+	// show up in exactly one of its snapshots
+	done := make(chan struct{})
+	var requests, bytes, latencies int64
+	reporter := make(chan struct{})
+	go func() {
+		defer close(reporter)
+		for {
+			s := m.SwapAndReset()
+			requests += s.Counters[CounterRequests]
+			bytes += s.Counters[CounterBytes]
+			latencies += s.Histograms[HistogramLatency].Count
+			select {
+			case <-done:
+				return
+			default:
+			}
+		}
+	}()
+
+	wg.Wait()
+	close(done)
+	<-reporter
+
+	final := m.SwapAndReset()
+	requests += final.Counters[CounterRequests]
+	bytes += final.Counters[CounterBytes]
+	latencies += final.Histograms[HistogramLatency].Count
+
+	g.Expect(requests).To(Equal(int64(writers * perWriter)))
+	g.Expect(bytes).To(Equal(int64(3 * writers * perWriter)))
+	g.Expect(latencies).To(Equal(int64(writers * perWriter)))
+}
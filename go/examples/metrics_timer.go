@@ -0,0 +1,37 @@
+package examples
+
+import (
+	"time"
+)
+
+// ObserveDuration records d in the histogram called name, creating it with
+// DefaultLatencyBounds on first use. HistogramLatency is the same as RecordLatency.
+func (m *Metrics) ObserveDuration(name string, d time.Duration) {
+	if name == HistogramLatency {
+		m.RecordLatency(d)
+		return
+	}
+	m.durationHistogram(name).Observe(int64(d))
+}
+
+// Time starts timing a code section and returns the function that stops the
+// timer and records the elapsed time under name:
+//
+//	defer m.Time("db_query")()
+func (m *Metrics) Time(name string) func() {
+	start := time.Now()
+	return func() {
+		m.ObserveDuration(name, time.Since(start))
+	}
+}
+
+func (m *Metrics) durationHistogram(name string) *AtomicHistogram {
+	if name == HistogramLatency {
+		return m.latencyHistogram()
+	}
+	if h, ok := m.histograms.Load(name); ok {
+		return h.(*AtomicHistogram)
+	}
+	h, _ := m.histograms.LoadOrStore(name, NewAtomicHistogram(DefaultLatencyBounds))
+	return h.(*AtomicHistogram)
+}
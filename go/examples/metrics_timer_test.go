@@ -0,0 +1,47 @@
+package examples
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestMetricsTime(t *testing.T) {
+	g := NewWithT(t)
+
+	m := &Metrics{}
+	func() {
+		defer m.Time("db_query")()
+		time.Sleep(2 * time.Millisecond)
+	}()
+	m.ObserveDuration("db_query", 10*time.Millisecond)
+	m.ObserveDuration(HistogramLatency, time.Millisecond)
+
+	s := m.Snapshot()
+	g.Expect(s.Histograms).To(HaveKey("db_query"))
+	query := s.Histograms["db_query"]
+	g.Expect(query.Count).To(Equal(int64(2)))
+	g.Expect(query.Sum).To(BeNumerically(">=", int64(12*time.Millisecond)))
+
+	// The latency name shares the RecordLatency histogram
+	g.Expect(s.Histograms[HistogramLatency].Count).To(Equal(int64(1)))
+	g.Expect(s.Latency).To(Equal(LatencyStats{Count: 1, Min: time.Millisecond, Max: time.Millisecond}))
+
+	// Named histograms are reset and exported like the latency one
+	var buf strings.Builder
+	g.Expect(m.WritePrometheus(&buf)).To(Succeed())
+	samples, types, err := parsePromText(strings.NewReader(buf.String()))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(types).To(HaveKeyWithValue("db_query_duration_seconds", "histogram"))
+	g.Expect(samples).To(HaveKeyWithValue("db_query_duration_seconds_count", 2.0))
+
+	swapped := m.SwapAndReset()
+	g.Expect(swapped.Histograms["db_query"].Count).To(Equal(int64(2)))
+	g.Expect(m.Snapshot().Histograms["db_query"].Count).To(Equal(int64(0)))
+
+	m.ObserveDuration("db_query", time.Millisecond)
+	m.Reset()
+	g.Expect(m.Snapshot().Histograms["db_query"].Count).To(Equal(int64(0)))
+}
@@ -0,0 +1,88 @@
+package examples
+
+import "sync/atomic"
+
+type mpmcCell[T any] struct {
+	seq atomic.Uint64 // Position this cell is ready for: pos to fill, pos+1 to drain
+	v   T
+}
+
+// MPMCQueue is a bounded lock-free queue for any number of producers and
+// consumers (Dmitry Vyukov's design). Each cell carries a sequence number
+// saying whose turn it is: producers claim a position by CAS on the enqueue
+// index, fill the cell and advance its sequence for the consumer, and
+// consumers do the mirror image. A producer and a consumer only ever touch
+// the same cell one after the other, so unlike LockFreeQueue it needs no
+// node allocation or reclamation, at the price of a fixed capacity.
+type MPMCQueue[T any] struct {
+	cells []mpmcCell[T]
+	mask  uint64
+	_     [cacheLinePad]byte
+	enq   atomic.Uint64 // Next position to fill
+	_     [cacheLinePad]byte
+	deq   atomic.Uint64 // Next position to drain
+	_     [cacheLinePad]byte
+}
+
+// NewMPMCQueue creates a queue of capacity cells, rounded up to a power of two
+func NewMPMCQueue[T any](capacity int) *MPMCQueue[T] {
+	n := 2
+	for n < capacity {
+		n <<= 1
+	}
+	q := &MPMCQueue[T]{cells: make([]mpmcCell[T], n), mask: uint64(n - 1)}
+	for i := range q.cells {
+		q.cells[i].seq.Store(uint64(i))
+	}
+	return q
+}
+
+// TryEnqueue adds v at the back, or reports false if the queue is full
+func (q *MPMCQueue[T]) TryEnqueue(v T) bool {
+	pos := q.enq.Load()
+	for {
+		c := &q.cells[pos&q.mask]
+		switch diff := int64(c.seq.Load() - pos); {
+		case diff == 0:
+			if q.enq.CompareAndSwap(pos, pos+1) {
+				c.v = v
+				c.seq.Store(pos + 1)
+				return true
+			}
+			pos = q.enq.Load()
+		case diff < 0:
+			return false // The cell still holds the item from one lap ago
+		default:
+			pos = q.enq.Load() // Another producer took pos
+		}
+	}
+}
+
+// TryDequeue removes the front item, or reports false if the queue is empty
+func (q *MPMCQueue[T]) TryDequeue() (T, bool) {
+	pos := q.deq.Load()
+	for {
+		c := &q.cells[pos&q.mask]
+		switch diff := int64(c.seq.Load() - (pos + 1)); {
+		case diff == 0:
+			if q.deq.CompareAndSwap(pos, pos+1) {
+				v := c.v
+				var zero T
+				c.v = zero
+				c.seq.Store(pos + q.mask + 1) // Ready for the producer one lap on
+				return v, true
+			}
+			pos = q.deq.Load()
+		case diff < 0:
+			var zero T
+			return zero, false // Not filled yet
+		default:
+			pos = q.deq.Load() // Another consumer took pos
+		}
+	}
+}
+
+// Cap returns the capacity
+func (q *MPMCQueue[T]) Cap() int {
+	return len(q.cells)
+}
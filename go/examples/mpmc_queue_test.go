@@ -0,0 +1,74 @@
+package examples
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestMPMCQueue(t *testing.T) {
+	g := NewWithT(t)
+
+	q := NewMPMCQueue[int](3)
+	g.Expect(q.Cap()).To(Equal(4))
+	_, ok := q.TryDequeue()
+	g.Expect(ok).To(BeFalse())
+	for i := 1; i <= 4; i++ {
+		g.Expect(q.TryEnqueue(i)).To(BeTrue())
+	}
+	g.Expect(q.TryEnqueue(5)).To(BeFalse())
+	for i := 1; i <= 4; i++ {
+		v, ok := q.TryDequeue()
+		g.Expect(ok).To(BeTrue())
+		g.Expect(v).To(Equal(i))
+	}
+	// Wrapped around the ring
+	g.Expect(q.TryEnqueue(6)).To(BeTrue())
+	v, _ := q.TryDequeue()
+	g.Expect(v).To(Equal(6))
+}
+
+func TestMPMCQueueConcurrent(t *testing.T) {
+	g := NewWithT(t)
+
+	q := NewMPMCQueue[int](16)
+	const producers, perProducer = 4, 2000
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				for !q.TryEnqueue(p*perProducer + i) {
+					runtime.Gosched()
+				}
+			}
+		}(p)
+	}
+
+	results := make(chan []int, 4)
+	for c := 0; c < 4; c++ {
+		go func() {
+			var got []int
+			for len(got) < producers*perProducer/4 {
+				if v, ok := q.TryDequeue(); ok {
+					got = append(got, v)
+				} else {
+					runtime.Gosched()
+				}
+			}
+			results <- got
+		}()
+	}
+	seen := make([]bool, producers*perProducer)
+	for c := 0; c < 4; c++ {
+		for _, v := range <-results {
+			g.Expect(seen[v]).To(BeFalse(), "value %d dequeued twice", v)
+			seen[v] = true
+		}
+	}
+	wg.Wait()
+	g.Expect(seen).NotTo(ContainElement(false))
+}
@@ -0,0 +1,36 @@
+package examples
+
+import "context"
+
+// OrDone forwards values from in until in closes or ctx is done, so a consumer
+// can range over a channel it does not own and still stop on cancellation.
+// The forwarding goroutine exits on cancellation even if in never closes.
+func OrDone[T any](ctx context.Context, in <-chan T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for {
+			v, err := RecvCtx(ctx, in)
+			if err != nil || SendCtx(ctx, out, v) != nil {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Take forwards at most the first n values from in, then closes its output.
+// It stops reading in after n values, leaving the rest for other readers.
+func Take[T any](ctx context.Context, in <-chan T, n int) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for i := 0; i < n; i++ {
+			v, err := RecvCtx(ctx, in)
+			if err != nil || SendCtx(ctx, out, v) != nil {
+				return
+			}
+		}
+	}()
+	return out
+}
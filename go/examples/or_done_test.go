@@ -0,0 +1,91 @@
+package examples
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// counting returns an endless stream 0, 1, 2, ... that stops when ctx is done
+func counting(ctx context.Context) <-chan int {
+	ch := make(chan int)
+	go func() {
+		defer close(ch)
+		for i := 0; ; i++ {
+			select {
+			case ch <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+func TestOrDone(t *testing.T) {
+	g := NewWithT(t)
+
+	var got []int
+	for v := range OrDone(context.Background(), sendAll(1, 2, 3)) {
+		got = append(got, v)
+	}
+	g.Expect(got).To(Equal([]int{1, 2, 3}))
+}
+
+func TestOrDoneAbandoned(t *testing.T) {
+	g := NewWithT(t)
+	before := runtime.NumGoroutine()
+
+	// The input never closes and the consumer stops reading after two values
+	ctx, cancel := context.WithCancel(context.Background())
+	never := make(chan int, 1)
+	out := OrDone(ctx, never)
+	never <- 1
+	never <- 2
+	g.Expect(<-out).To(Equal(1))
+	g.Expect(<-out).To(Equal(2))
+	never <- 3 // Picked up and stuck sending, since nobody reads out any more
+	cancel()
+
+	g.Eventually(out).Should(BeClosed())
+	g.Eventually(runtime.NumGoroutine, time.Second).Should(BeNumerically("<=", before))
+}
+
+func TestTake(t *testing.T) {
+	g := NewWithT(t)
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var got []int
+	for v := range Take(ctx, counting(ctx), 5) {
+		got = append(got, v)
+	}
+	g.Expect(got).To(Equal([]int{0, 1, 2, 3, 4}))
+
+	// Fewer values than asked for is fine
+	got = nil
+	for v := range Take(ctx, sendAll(7), 3) {
+		got = append(got, v)
+	}
+	g.Expect(got).To(Equal([]int{7}))
+
+	// Cancelling releases the producer Take stopped reading from
+	cancel()
+	g.Eventually(runtime.NumGoroutine, time.Second).Should(BeNumerically("<=", before))
+}
+
+func TestTakeAbandoned(t *testing.T) {
+	g := NewWithT(t)
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := Take(ctx, OrDone(ctx, counting(ctx)), 100)
+	g.Expect(<-out).To(Equal(0))
+	cancel() // The consumer walks away after one value
+
+	g.Eventually(out).Should(BeClosed())
+	g.Eventually(runtime.NumGoroutine, time.Second).Should(BeNumerically("<=", before))
+}
@@ -0,0 +1,161 @@
+package examples
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrExecutorClosed is returned when submitting to a closed OrderedExecutor
+var ErrExecutorClosed = errors.New("ordered executor closed")
+
+type seqItem[T any] struct {
+	seq int64
+	v   T
+}
+
+// seqHeap is a min-heap of results by sequence number
+type seqHeap[T any] []seqItem[T]
+
+func (h seqHeap[T]) Len() int           { return len(h) }
+func (h seqHeap[T]) Less(i, j int) bool { return h[i].seq < h[j].seq }
+func (h seqHeap[T]) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *seqHeap[T]) Push(x any)        { *h = append(*h, x.(seqItem[T])) }
+func (h *seqHeap[T]) Pop() any {
+	old := *h
+	it := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return it
+}
+
+// OrderedExecutor runs fn on submitted items concurrently but emits results in
+// submission order. Each item is numbered as it is submitted; results that
+// finish early wait in a min-heap until every earlier one has been emitted.
+// At most window items are in flight or waiting at once, so one slow item
+// stalls Submit rather than letting the heap grow without bound.
+type OrderedExecutor[I, O any] struct {
+	ctx     context.Context
+	jobs    chan seqItem[I]
+	done    chan seqItem[O]
+	results chan O
+	window  chan struct{} // One token per item submitted but not yet emitted
+	wg      sync.WaitGroup
+
+	mu     sync.Mutex // Serialises Submits, so sequence numbers follow submission order, and Close
+	closed bool
+	next   int64
+}
+
+// NewOrderedExecutor starts workers goroutines running fn, with at most window
+// items between Submit and Results (at least workers). Everything stops when
+// ctx is done; Results then closes without the remaining results.
+func NewOrderedExecutor[I, O any](ctx context.Context, workers, window int, fn func(context.Context, I) O) *OrderedExecutor[I, O] {
+	if workers < 1 {
+		workers = 1
+	}
+	if window < workers {
+		window = workers
+	}
+	e := &OrderedExecutor[I, O]{
+		ctx:     ctx,
+		jobs:    make(chan seqItem[I]),
+		done:    make(chan seqItem[O], workers),
+		results: make(chan O),
+		window:  make(chan struct{}, window),
+	}
+	e.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go e.work(fn)
+	}
+	go func() {
+		e.wg.Wait()
+		close(e.done)
+	}()
+	go e.reorder()
+	return e
+}
+
+// Submit queues item, blocking while the window is full until ctx is done
+func (e *OrderedExecutor[I, O]) Submit(ctx context.Context, item I) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return ErrExecutorClosed
+	}
+	select {
+	case e.window <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-e.ctx.Done():
+		return e.ctx.Err()
+	}
+	select {
+	case e.jobs <- seqItem[I]{seq: e.next, v: item}:
+		e.next++
+		return nil
+	case <-ctx.Done():
+		<-e.window
+		return ctx.Err()
+	case <-e.ctx.Done():
+		return e.ctx.Err()
+	}
+}
+
+// Results returns the channel results arrive on, in submission order. It
+// closes after Close once every submitted item has been emitted.
+func (e *OrderedExecutor[I, O]) Results() <-chan O {
+	return e.results
+}
+
+// Close stops accepting items; those already submitted still complete
+func (e *OrderedExecutor[I, O]) Close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.closed {
+		e.closed = true
+		close(e.jobs)
+	}
+}
+
+func (e *OrderedExecutor[I, O]) work(fn func(context.Context, I) O) {
+	defer e.wg.Done()
+	for {
+		var job seqItem[I]
+		select {
+		case j, ok := <-e.jobs:
+			if !ok {
+				return
+			}
+			job = j
+		case <-e.ctx.Done():
+			return
+		}
+		r := seqItem[O]{seq: job.seq, v: fn(e.ctx, job.v)}
+		select {
+		case e.done <- r:
+		case <-e.ctx.Done():
+			return
+		}
+	}
+}
+
+// reorder holds back results until their turn comes
+func (e *OrderedExecutor[I, O]) reorder() {
+	defer close(e.results)
+	var pending seqHeap[O]
+	var next int64
+	for r := range e.done {
+		heap.Push(&pending, r)
+		for pending.Len() > 0 && pending[0].seq == next {
+			it := heap.Pop(&pending).(seqItem[O])
+			select {
+			case e.results <- it.v:
+			case <-e.ctx.Done():
+				return
+			}
+			<-e.window
+			next++
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package examples
+
+import (
+	"context"
+	"math/rand"
+	"runtime"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestOrderedExecutorReverseCompletion(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	// Each item waits for its own gate; gates open last-to-first, so every
+	// result but the last finishes out of order
+	const n = 8
+	gates := make([]chan struct{}, n)
+	for i := range gates {
+		gates[i] = make(chan struct{})
+	}
+	started := make(chan int, n)
+	e := NewOrderedExecutor(ctx, n, n, func(_ context.Context, i int) int {
+		started <- i
+		<-gates[i]
+		return i * 10
+	})
+	for i := 0; i < n; i++ {
+		g.Expect(e.Submit(ctx, i)).To(Succeed())
+	}
+	e.Close()
+	for i := 0; i < n; i++ {
+		<-started
+	}
+
+	for i := n - 1; i > 0; i-- {
+		close(gates[i])
+	}
+	g.Consistently(e.Results(), 20*time.Millisecond).ShouldNot(Receive())
+	close(gates[0])
+
+	var got []int
+	for v := range e.Results() {
+		got = append(got, v)
+	}
+	g.Expect(got).To(Equal([]int{0, 10, 20, 30, 40, 50, 60, 70}))
+	g.Expect(e.Submit(ctx, 9)).To(MatchError(ErrExecutorClosed))
+}
+
+func TestOrderedExecutorRandomCompletion(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	delays := make([]time.Duration, 200)
+	for i := range delays {
+		delays[i] = time.Duration(rand.Intn(2000)) * time.Microsecond
+	}
+	e := NewOrderedExecutor(ctx, 16, 32, func(_ context.Context, i int) int {
+		time.Sleep(delays[i])
+		return i
+	})
+	go func() {
+		defer e.Close()
+		for i := range delays {
+			_ = e.Submit(ctx, i)
+		}
+	}()
+
+	var got []int
+	for v := range e.Results() {
+		got = append(got, v)
+	}
+	g.Expect(got).To(HaveLen(len(delays)))
+	for i, v := range got {
+		g.Expect(v).To(Equal(i))
+	}
+}
+
+func TestOrderedExecutorWindow(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	// The first item is stuck: once the window is full of finished results
+	// waiting behind it, Submit blocks
+	release := make(chan struct{})
+	e := NewOrderedExecutor(ctx, 2, 4, func(_ context.Context, i int) int {
+		if i == 0 {
+			<-release
+		}
+		return i
+	})
+	for i := 0; i < 4; i++ {
+		g.Expect(e.Submit(ctx, i)).To(Succeed())
+	}
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	g.Expect(e.Submit(timeout, 4)).To(MatchError(context.DeadlineExceeded))
+
+	close(release)
+	g.Eventually(e.Results()).Should(Receive(Equal(0)))
+	g.Expect(e.Submit(ctx, 4)).To(Succeed())
+	e.Close()
+	var rest []int
+	for v := range e.Results() {
+		rest = append(rest, v)
+	}
+	g.Expect(rest).To(Equal([]int{1, 2, 3, 4}))
+}
+
+func TestOrderedExecutorCancellation(t *testing.T) {
+	g := NewWithT(t)
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e := NewOrderedExecutor(ctx, 4, 4, func(ctx context.Context, i int) int {
+		<-ctx.Done()
+		return i
+	})
+	g.Expect(e.Submit(ctx, 1)).To(Succeed())
+	cancel()
+
+	g.Eventually(e.Results()).Should(BeClosed())
+	g.Eventually(runtime.NumGoroutine, time.Second).Should(BeNumerically("<=", before))
+}
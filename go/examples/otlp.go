@@ -0,0 +1,178 @@
+package examples
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// OTLP aggregation temporality values
+const (
+	OTLPTemporalityDelta      = 1
+	OTLPTemporalityCumulative = 2
+)
+
+// OTLPMetrics mirrors the JSON shape of an OTLP ExportMetricsServiceRequest,
+// trimmed to what Metrics produces, without depending on the OpenTelemetry SDK
+type OTLPMetrics struct {
+	ResourceMetrics []OTLPResourceMetrics `json:"resourceMetrics"`
+}
+
+type OTLPResourceMetrics struct {
+	Resource     OTLPResource       `json:"resource"`
+	ScopeMetrics []OTLPScopeMetrics `json:"scopeMetrics"`
+}
+
+type OTLPResource struct {
+	Attributes []OTLPKeyValue `json:"attributes"`
+}
+
+type OTLPKeyValue struct {
+	Key   string       `json:"key"`
+	Value OTLPAnyValue `json:"value"`
+}
+
+type OTLPAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type OTLPScopeMetrics struct {
+	Scope   OTLPScope    `json:"scope"`
+	Metrics []OTLPMetric `json:"metrics"`
+}
+
+type OTLPScope struct {
+	Name string `json:"name"`
+}
+
+// OTLPMetric holds exactly one of Sum, Gauge or Histogram
+type OTLPMetric struct {
+	Name      string         `json:"name"`
+	Unit      string         `json:"unit,omitempty"`
+	Sum       *OTLPSum       `json:"sum,omitempty"`
+	Gauge     *OTLPGauge     `json:"gauge,omitempty"`
+	Histogram *OTLPHistogram `json:"histogram,omitempty"`
+}
+
+type OTLPSum struct {
+	DataPoints             []OTLPNumberDataPoint `json:"dataPoints"`
+	AggregationTemporality int                   `json:"aggregationTemporality"`
+	IsMonotonic            bool                  `json:"isMonotonic"`
+}
+
+type OTLPGauge struct {
+	DataPoints []OTLPNumberDataPoint `json:"dataPoints"`
+}
+
+type OTLPHistogram struct {
+	DataPoints             []OTLPHistogramDataPoint `json:"dataPoints"`
+	AggregationTemporality int                      `json:"aggregationTemporality"`
+}
+
+// Timestamps and other 64-bit integers are decimal strings, as in OTLP/JSON's
+// proto3 encoding of int64 and fixed64
+type OTLPNumberDataPoint struct {
+	StartTimeUnixNano string   `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string   `json:"timeUnixNano"`
+	AsInt             string   `json:"asInt,omitempty"`
+	AsDouble          *float64 `json:"asDouble,omitempty"`
+}
+
+type OTLPHistogramDataPoint struct {
+	StartTimeUnixNano string    `json:"startTimeUnixNano"`
+	TimeUnixNano      string    `json:"timeUnixNano"`
+	Count             string    `json:"count"`
+	Sum               float64   `json:"sum"`
+	BucketCounts      []string  `json:"bucketCounts"`
+	ExplicitBounds    []float64 `json:"explicitBounds"`
+}
+
+// OTLPExporter converts cumulative snapshots to the OTLP shape and passes them
+// to Send, which would typically POST them to a collector's /v1/metrics. It is
+// safe for concurrent Export calls once its fields are set.
+type OTLPExporter struct {
+	ServiceName string
+	Start       time.Time // Start of the cumulative series; defaults to the first export
+	Send        func(OTLPMetrics) error
+
+	startOnce sync.Once
+	start     time.Time // Start, or the first export's timestamp; set once
+}
+
+// Export converts s and sends it
+func (e *OTLPExporter) Export(s MetricsSnapshot) error {
+	e.startOnce.Do(func() {
+		e.start = e.Start
+		if e.start.IsZero() {
+			e.start = s.Timestamp
+		}
+	})
+	return e.Send(ToOTLP(s, e.ServiceName, e.start))
+}
+
+// ToOTLP converts a snapshot into a single-resource OTLP payload.
+// Counters become monotonic cumulative sums starting at start.
+func ToOTLP(s MetricsSnapshot, serviceName string, start time.Time) OTLPMetrics {
+	startNano := unixNano(start)
+	now := unixNano(s.Timestamp)
+
+	var metrics []OTLPMetric
+	for _, name := range sortedKeys(s.Counters) {
+		v := strconv.FormatInt(s.Counters[name], 10)
+		metrics = append(metrics, OTLPMetric{
+			Name: name,
+			Sum: &OTLPSum{
+				DataPoints:             []OTLPNumberDataPoint{{StartTimeUnixNano: startNano, TimeUnixNano: now, AsInt: v}},
+				AggregationTemporality: OTLPTemporalityCumulative,
+				IsMonotonic:            true,
+			},
+		})
+	}
+	for _, name := range sortedKeys(s.Gauges) {
+		v := s.Gauges[name]
+		metrics = append(metrics, OTLPMetric{
+			Name:  name,
+			Gauge: &OTLPGauge{DataPoints: []OTLPNumberDataPoint{{TimeUnixNano: now, AsDouble: &v}}},
+		})
+	}
+	for _, name := range sortedKeys(s.Histograms) {
+		h := s.Histograms[name]
+		bounds := make([]float64, len(h.Bounds))
+		for i, b := range h.Bounds {
+			bounds[i] = float64(b) / float64(time.Second)
+		}
+		counts := make([]string, len(h.Counts))
+		for i, n := range h.Counts {
+			counts[i] = strconv.FormatInt(n, 10)
+		}
+		metrics = append(metrics, OTLPMetric{
+			Name: name,
+			Unit: "s",
+			Histogram: &OTLPHistogram{
+				DataPoints: []OTLPHistogramDataPoint{{
+					StartTimeUnixNano: startNano,
+					TimeUnixNano:      now,
+					Count:             strconv.FormatInt(h.Count, 10),
+					Sum:               float64(h.Sum) / float64(time.Second),
+					BucketCounts:      counts,
+					ExplicitBounds:    bounds,
+				}},
+				AggregationTemporality: OTLPTemporalityCumulative,
+			},
+		})
+	}
+
+	return OTLPMetrics{ResourceMetrics: []OTLPResourceMetrics{{
+		Resource: OTLPResource{Attributes: []OTLPKeyValue{
+			{Key: "service.name", Value: OTLPAnyValue{StringValue: serviceName}},
+		}},
+		ScopeMetrics: []OTLPScopeMetrics{{
+			Scope:   OTLPScope{Name: "github.com/camilbenameur/learning/go/examples"},
+			Metrics: metrics,
+		}},
+	}}}
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
@@ -0,0 +1,118 @@
+package examples
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestToOTLP(t *testing.T) {
+	g := NewWithT(t)
+
+	m := &Metrics{}
+	m.RecordRequest()
+	m.RecordRequest()
+	m.RecordLatency(time.Second)
+	m.SetGauge("workers", 3)
+	s := m.Snapshot()
+	start := s.Timestamp.Add(-time.Minute)
+
+	payload := ToOTLP(s, "demo", start)
+	g.Expect(payload.ResourceMetrics).To(HaveLen(1))
+	rm := payload.ResourceMetrics[0]
+	g.Expect(rm.Resource.Attributes).To(ConsistOf(OTLPKeyValue{Key: "service.name", Value: OTLPAnyValue{StringValue: "demo"}}))
+
+	metrics := map[string]OTLPMetric{}
+	for _, metric := range rm.ScopeMetrics[0].Metrics {
+		metrics[metric.Name] = metric
+	}
+	g.Expect(metrics).To(HaveLen(5))
+
+	requests := metrics[CounterRequests].Sum
+	g.Expect(requests).NotTo(BeNil())
+	g.Expect(requests.IsMonotonic).To(BeTrue())
+	g.Expect(requests.AggregationTemporality).To(Equal(OTLPTemporalityCumulative))
+	g.Expect(requests.DataPoints[0].AsInt).To(Equal("2"))
+	g.Expect(requests.DataPoints[0].StartTimeUnixNano).To(Equal(unixNano(start)))
+
+	g.Expect(*metrics["workers"].Gauge.DataPoints[0].AsDouble).To(Equal(3.0))
+
+	latency := metrics[HistogramLatency]
+	g.Expect(latency.Unit).To(Equal("s"))
+	point := latency.Histogram.DataPoints[0]
+	g.Expect(point.Count).To(Equal("1"))
+	g.Expect(point.Sum).To(BeNumerically("~", 1.0, 1e-9))
+	g.Expect(point.BucketCounts).To(HaveLen(len(point.ExplicitBounds) + 1))
+	g.Expect(point.ExplicitBounds[0]).To(BeNumerically("~", 50e-6, 1e-12))
+
+	// Each metric carries exactly one data kind, so absent ones are omitted
+	data, err := json.Marshal(metrics["workers"])
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(data)).NotTo(ContainSubstring("sum"))
+
+	// 64-bit integers are encoded as strings, as proto3 JSON requires
+	data, err = json.Marshal(payload)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(data)).To(ContainSubstring(`"asInt":"2"`))
+	g.Expect(string(data)).To(ContainSubstring(`"count":"1"`))
+	g.Expect(string(data)).To(MatchRegexp(`"bucketCounts":\["0"`))
+}
+
+func TestOTLPExporter(t *testing.T) {
+	g := NewWithT(t)
+
+	var sent []OTLPMetrics
+	e := &OTLPExporter{ServiceName: "demo", Send: func(p OTLPMetrics) error {
+		sent = append(sent, p)
+		return nil
+	}}
+
+	m := &Metrics{}
+	first := m.Snapshot()
+	g.Expect(e.Export(first)).To(Succeed())
+
+	second := m.Snapshot()
+	second.Timestamp = first.Timestamp.Add(time.Minute)
+	g.Expect(e.Export(second)).To(Succeed())
+
+	// The cumulative start stays fixed across exports
+	g.Expect(sent).To(HaveLen(2))
+	for _, p := range sent {
+		sum := p.ResourceMetrics[0].ScopeMetrics[0].Metrics[0].Sum
+		g.Expect(sum.DataPoints[0].StartTimeUnixNano).To(Equal(unixNano(first.Timestamp)))
+	}
+}
+
+// TestOTLPExporterConcurrent exports from several goroutines, as reporters
+// sharing one exporter do; every payload gets the same start, and -race
+// checks the lazy default is set safely
+func TestOTLPExporterConcurrent(t *testing.T) {
+	g := NewWithT(t)
+
+	var mu sync.Mutex
+	starts := map[string]bool{}
+	e := &OTLPExporter{ServiceName: "demo", Send: func(p OTLPMetrics) error {
+		mu.Lock()
+		defer mu.Unlock()
+		starts[p.ResourceMetrics[0].ScopeMetrics[0].Metrics[0].Sum.DataPoints[0].StartTimeUnixNano] = true
+		return nil
+	}}
+
+	m := &Metrics{}
+	m.RecordRequest()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s := m.Snapshot()
+			s.Timestamp = time.Unix(int64(i+1), 0)
+			g.Expect(e.Export(s)).To(Succeed())
+		}(i)
+	}
+	wg.Wait()
+	g.Expect(starts).To(HaveLen(1))
+}
@@ -0,0 +1,60 @@
+package examples
+
+import (
+	"runtime"
+	"unsafe"
+)
+
+// PCounter is a counter for extremely hot increments. It spreads them over
+// roughly GOMAXPROCS padded slots so that goroutines on different cores
+// mostly hit different cache lines, and sums the slots on Load. Increments
+// stay exact; Load is a sum of slots read one at a time, so it may miss
+// increments racing with it.
+//
+// The runtime does not expose which P a goroutine runs on, so the slot comes
+// from the address of a variable on the calling goroutine's stack. Goroutines
+// have separate stacks, so concurrent callers tend to land on different
+// slots, and the hash costs nothing; a stack that moves just changes slot.
+type PCounter struct {
+	slots []PaddedCounter
+	mask  uintptr
+}
+
+// NewPCounter creates a counter with GOMAXPROCS slots rounded up to a power of two
+func NewPCounter() *PCounter {
+	n := 1
+	for n < runtime.GOMAXPROCS(0) {
+		n <<= 1
+	}
+	return &PCounter{slots: make([]PaddedCounter, n), mask: uintptr(n - 1)}
+}
+
+// Add adds delta to the calling goroutine's slot
+func (c *PCounter) Add(delta int64) {
+	c.slots[stackHint()&c.mask].Add(delta)
+}
+
+// Inc adds one
+func (c *PCounter) Inc() {
+	c.Add(1)
+}
+
+// Load returns the sum of all slots
+func (c *PCounter) Load() int64 {
+	var total int64
+	for i := range c.slots {
+		total += c.slots[i].Load()
+	}
+	return total
+}
+
+// stackHint hashes the address of a local, which differs between goroutines
+// because each runs on its own stack. Stacks are at least 2KB apart, so the
+// low bits are dropped before mixing.
+//
+//go:noinline
+func stackHint() uintptr {
+	var marker byte
+	p := uintptr(unsafe.Pointer(&marker)) >> 11
+	return p ^ p>>7 ^ p>>13
+}
@@ -0,0 +1,63 @@
+package examples
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/camilbenameur/learning/go/profiling"
+	. "github.com/onsi/gomega"
+)
+
+func TestPCounter(t *testing.T) {
+	g := NewWithT(t)
+
+	c := NewPCounter()
+	g.Expect(c.Load()).To(BeZero())
+
+	var wg sync.WaitGroup
+	for w := 0; w < 16; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				c.Inc()
+			}
+			c.Add(-500)
+		}()
+	}
+	wg.Wait()
+	g.Expect(c.Load()).To(Equal(int64(16 * 500)))
+}
+
+// BenchmarkHotCounter increments one counter from every goroutine. PCounter
+// and the sharded counter only pull ahead of AtomicCounter once several
+// cores are incrementing at once.
+func BenchmarkHotCounter(b *testing.B) {
+	b.Run("AtomicCounter", func(b *testing.B) {
+		var c AtomicCounter
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				c.Increment()
+			}
+		})
+	})
+	b.Run("ShardedCounter", func(b *testing.B) {
+		var c profiling.ShardedCounter
+		var ids atomic.Int64
+		b.RunParallel(func(pb *testing.PB) {
+			id := int(ids.Add(1))
+			for pb.Next() {
+				c.Increment(id)
+			}
+		})
+	})
+	b.Run("PCounter", func(b *testing.B) {
+		c := NewPCounter()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				c.Inc()
+			}
+		})
+	})
+}
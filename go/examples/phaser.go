@@ -0,0 +1,154 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrPhaserTerminated is returned by Phaser methods once the phaser has
+// terminated, because onAdvance asked it to or every party deregistered. The
+// waits released by that final advance get it too.
+var ErrPhaserTerminated = errors.New("phaser: terminated")
+
+// Phaser is a reusable barrier whose set of parties can change between
+// rounds. Parties Register to join, Arrive at the end of each phase, and
+// ArriveAndDeregister to leave; the phase advances once every registered
+// party has arrived. That suits multi-round simulations where workers finish
+// at different times, which a fixed-size Barrier cannot express.
+//
+// Unlike Barrier, a party whose ctx ends while waiting does not break the
+// phaser: it has already arrived, so the phase still advances without it
+// waiting for the result.
+type Phaser struct {
+	mu         sync.Mutex
+	onAdvance  func(phase, registered int) bool
+	phase      int
+	registered int
+	arrived    int           // Parties arrived in the current phase
+	advanced   chan struct{} // Closed when the current phase advances
+	terminated bool
+}
+
+// NewPhaser creates a phaser with parties already registered. If onAdvance is
+// not nil, the last party to arrive calls it with the finishing phase and the
+// number of parties registered for the next one, before anyone is released;
+// returning true terminates the phaser. Without onAdvance, the phaser
+// terminates when the last party deregisters. onAdvance must not call back
+// into the phaser.
+func NewPhaser(parties int, onAdvance func(phase, registered int) bool) *Phaser {
+	if onAdvance == nil {
+		onAdvance = func(_, registered int) bool { return registered == 0 }
+	}
+	return &Phaser{onAdvance: onAdvance, registered: parties, advanced: make(chan struct{})}
+}
+
+// Register adds a party and returns the phase it joins
+func (p *Phaser) Register() (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.terminated {
+		return p.phase, ErrPhaserTerminated
+	}
+	p.registered++
+	return p.phase, nil
+}
+
+// Arrive records that a party finished the current phase without waiting for
+// the others, and returns the phase it arrived at
+func (p *Phaser) Arrive() (int, error) {
+	return p.arrive(false)
+}
+
+// ArriveAndDeregister records the arrival and removes the party, which must
+// not use the phaser again. It returns the phase it arrived at.
+func (p *Phaser) ArriveAndDeregister() (int, error) {
+	return p.arrive(true)
+}
+
+// ArriveAndAwaitAdvance arrives and waits for the phase to advance, like
+// Barrier.Await, and returns the new phase
+func (p *Phaser) ArriveAndAwaitAdvance(ctx context.Context) (int, error) {
+	phase, err := p.Arrive()
+	if err != nil {
+		return phase, err
+	}
+	return p.AwaitAdvance(ctx, phase)
+}
+
+// AwaitAdvance waits until the phaser has moved past phase and returns the
+// new phase. It returns at once if phase is already over, and does not count
+// as an arrival, so non-parties can use it to follow progress.
+func (p *Phaser) AwaitAdvance(ctx context.Context, phase int) (int, error) {
+	p.mu.Lock()
+	advanced := p.advanced
+	current := p.phase
+	p.mu.Unlock()
+	if current == phase {
+		select {
+		case <-advanced:
+		case <-ctx.Done():
+			return phase, ctx.Err()
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.terminated {
+		return p.phase, ErrPhaserTerminated
+	}
+	return p.phase, nil
+}
+
+// Phase returns the current phase number, starting at 0
+func (p *Phaser) Phase() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.phase
+}
+
+// Registered returns the number of registered parties
+func (p *Phaser) Registered() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.registered
+}
+
+// Arrived returns the number of parties that have arrived in the current phase
+func (p *Phaser) Arrived() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.arrived
+}
+
+// Terminated reports whether the phaser has terminated
+func (p *Phaser) Terminated() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.terminated
+}
+
+func (p *Phaser) arrive(deregister bool) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	phase := p.phase
+	if p.terminated {
+		return phase, ErrPhaserTerminated
+	}
+	if deregister {
+		p.registered--
+	} else {
+		p.arrived++
+	}
+	if p.arrived >= p.registered {
+		p.advanceLocked()
+	}
+	return phase, nil
+}
+
+func (p *Phaser) advanceLocked() {
+	p.terminated = p.onAdvance(p.phase, p.registered)
+	p.phase++
+	p.arrived = 0
+	close(p.advanced)
+	p.advanced = make(chan struct{})
+}
@@ -0,0 +1,105 @@
+package examples
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestPhaserDynamicParties(t *testing.T) {
+	g := NewWithT(t)
+
+	// Worker w runs w+1 rounds of a simulation and then leaves; the
+	// callback records how many parties each phase advanced with
+	const workers = 4
+	var perPhase []int
+	p := NewPhaser(workers, func(phase, registered int) bool {
+		perPhase = append(perPhase, registered)
+		return registered == 0
+	})
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	steps := make(map[int]int) // Phase -> workers that stepped in it
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for r := 0; ; r++ {
+				phase := p.Phase()
+				mu.Lock()
+				steps[phase]++
+				mu.Unlock()
+				if r == w {
+					p.ArriveAndDeregister()
+					return
+				}
+				if _, err := p.ArriveAndAwaitAdvance(context.Background()); err != nil {
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	g.Expect(perPhase).To(Equal([]int{3, 2, 1, 0}))
+	g.Expect(steps).To(Equal(map[int]int{0: 4, 1: 3, 2: 2, 3: 1}))
+	g.Expect(p.Terminated()).To(BeTrue())
+	_, err := p.Register()
+	g.Expect(err).To(MatchError(ErrPhaserTerminated))
+}
+
+func TestPhaserRegisterAndTerminate(t *testing.T) {
+	g := NewWithT(t)
+
+	p := NewPhaser(1, func(phase, _ int) bool { return phase == 1 })
+	phase, err := p.Register()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(phase).To(BeZero())
+	g.Expect(p.Registered()).To(Equal(2))
+
+	// One party arriving is not enough with two registered
+	g.Expect(p.Arrive()).To(BeZero())
+	g.Expect(p.Arrived()).To(Equal(1))
+	g.Expect(p.Phase()).To(BeZero())
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := p.ArriveAndAwaitAdvance(context.Background())
+		done <- err
+	}()
+	g.Eventually(done).Should(Receive(BeNil()))
+	g.Expect(p.Phase()).To(Equal(1))
+
+	// The advance out of phase 1 terminates the phaser, releasing the waiter with the error
+	go func() {
+		_, err := p.ArriveAndAwaitAdvance(context.Background())
+		done <- err
+	}()
+	g.Eventually(p.Arrived).Should(Equal(1))
+	g.Expect(p.Terminated()).To(BeFalse())
+	g.Expect(p.Arrive()).To(Equal(1))
+	g.Eventually(done).Should(Receive(MatchError(ErrPhaserTerminated)))
+	g.Expect(p.Terminated()).To(BeTrue())
+	g.Expect(p.Phase()).To(Equal(2))
+	_, err = p.AwaitAdvance(context.Background(), 0)
+	g.Expect(err).To(MatchError(ErrPhaserTerminated))
+}
+
+func TestPhaserCancelDoesNotBreak(t *testing.T) {
+	g := NewWithT(t)
+
+	p := NewPhaser(2, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := p.ArriveAndAwaitAdvance(ctx)
+	g.Expect(err).To(MatchError(context.DeadlineExceeded))
+
+	// The cancelled party still arrived, so the other alone completes the phase
+	phase, err := p.ArriveAndAwaitAdvance(context.Background())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(phase).To(Equal(1))
+}
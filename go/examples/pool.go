@@ -0,0 +1,270 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Errors returned by Pool.Get
+var (
+	ErrPoolExhausted      = errors.New("pool exhausted")
+	ErrResourcePoolClosed = errors.New("resource pool closed")
+)
+
+// PoolOverflow says what Pool.Get does when MaxSize objects are already live
+// and none is idle
+type PoolOverflow int
+
+const (
+	// PoolWait blocks until an object is returned or destroyed
+	PoolWait PoolOverflow = iota
+	// PoolCreate makes an extra object beyond MaxSize, destroyed when it is put
+	// back and the idle channel is full
+	PoolCreate
+	// PoolFail returns ErrPoolExhausted
+	PoolFail
+)
+
+// PoolOptions configures a Pool
+type PoolOptions[T any] struct {
+	MaxSize     int                              // Live objects before Overflow applies, and idle objects kept; at least 1
+	IdleTimeout time.Duration                    // Idle objects older than this are destroyed instead of reused; 0 keeps them
+	New         func(context.Context) (T, error) // Creates an object; required
+	Destroy     func(T)                          // Optional; releases an object the pool drops
+	Overflow    PoolOverflow
+	Clock       Clock // Ages idle objects; RealClock if nil
+}
+
+// PoolStats is a point-in-time view of a Pool
+type PoolStats struct {
+	Idle      int   // Objects waiting in the pool
+	Created   int64 // Calls to New that succeeded
+	Destroyed int64 // Objects handed to Destroy
+	Overflow  int64 // Objects currently live beyond MaxSize
+}
+
+type pooled[T any] struct {
+	v     T
+	since time.Time // When it was put back
+}
+
+// Pool keeps expensive objects such as connections for reuse. Idle objects
+// wait in a buffered channel of MaxSize, and a second channel of MaxSize
+// tokens counts live objects, so Get is a select between reusing, creating
+// and waiting. Unlike sync.Pool, which may drop anything at the next garbage
+// collection and never runs a destructor, Pool bounds how many objects exist
+// and closes the ones it drops, which is what connections need.
+type Pool[T any] struct {
+	opts   PoolOptions[T]
+	idle   chan pooled[T]
+	tokens chan struct{} // One per live object within MaxSize
+
+	overflow  int64
+	created   int64
+	destroyed int64
+
+	mu     sync.RWMutex // Guards closed against Puts racing Close's drain
+	closed bool
+	done   chan struct{} // Closed by Close to wake waiting Gets
+}
+
+// NewPool creates an empty pool; objects are created on demand
+func NewPool[T any](opts PoolOptions[T]) *Pool[T] {
+	if opts.MaxSize < 1 {
+		opts.MaxSize = 1
+	}
+	if opts.Clock == nil {
+		opts.Clock = RealClock
+	}
+	return &Pool[T]{
+		opts:   opts,
+		idle:   make(chan pooled[T], opts.MaxSize),
+		tokens: make(chan struct{}, opts.MaxSize),
+		done:   make(chan struct{}),
+	}
+}
+
+// Get returns an idle object, or creates one if fewer than MaxSize are live.
+// Otherwise the overflow policy decides whether it waits, until ctx is done,
+// creates an extra object or fails. Idle objects past IdleTimeout are
+// destroyed and skipped.
+func (p *Pool[T]) Get(ctx context.Context) (T, error) {
+	var zero T
+	for {
+		select {
+		case <-p.done:
+			return zero, ErrResourcePoolClosed
+		default:
+		}
+		// Reuse first, so that an idle object is never passed over for a new one
+		select {
+		case it := <-p.idle:
+			if p.expired(it) {
+				p.destroy(it.v)
+				continue
+			}
+			return it.v, nil
+		default:
+		}
+		select {
+		case p.tokens <- struct{}{}:
+			return p.create(ctx, false)
+		default:
+		}
+		switch p.opts.Overflow {
+		case PoolCreate:
+			return p.create(ctx, true)
+		case PoolFail:
+			return zero, ErrPoolExhausted
+		}
+		select {
+		case it := <-p.idle:
+			if p.expired(it) {
+				p.destroy(it.v)
+				continue
+			}
+			return it.v, nil
+		case p.tokens <- struct{}{}:
+			return p.create(ctx, false)
+		case <-p.done:
+			return zero, ErrResourcePoolClosed
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// Put returns v for reuse. If the idle channel is full, or the pool is
+// closed, v is destroyed instead.
+func (p *Pool[T]) Put(v T) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if !p.closed {
+		select {
+		case p.idle <- pooled[T]{v: v, since: p.opts.Clock.Now()}:
+			return
+		default:
+		}
+	}
+	p.destroy(v)
+}
+
+// Discard destroys v instead of returning it, for an object found broken;
+// this frees its slot for a new one
+func (p *Pool[T]) Discard(v T) {
+	p.destroy(v)
+}
+
+// Stats returns current counts
+func (p *Pool[T]) Stats() PoolStats {
+	return PoolStats{
+		Idle:      len(p.idle),
+		Created:   atomic.LoadInt64(&p.created),
+		Destroyed: atomic.LoadInt64(&p.destroyed),
+		Overflow:  atomic.LoadInt64(&p.overflow),
+	}
+}
+
+// Close destroys the idle objects and makes Get fail. Objects still in use
+// are destroyed when they are put back.
+func (p *Pool[T]) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	p.closed = true
+	close(p.done)
+	for {
+		select {
+		case it := <-p.idle:
+			p.destroy(it.v)
+		default:
+			return
+		}
+	}
+}
+
+func (p *Pool[T]) expired(it pooled[T]) bool {
+	return p.opts.IdleTimeout > 0 && p.opts.Clock.Since(it.since) > p.opts.IdleTimeout
+}
+
+// create calls New for a caller that has taken a token, or for an overflow
+// object, undoing the accounting if New fails
+func (p *Pool[T]) create(ctx context.Context, overflow bool) (T, error) {
+	if overflow {
+		atomic.AddInt64(&p.overflow, 1)
+	}
+	v, err := p.opts.New(ctx)
+	if err != nil {
+		p.release()
+		return v, err
+	}
+	atomic.AddInt64(&p.created, 1)
+	return v, nil
+}
+
+func (p *Pool[T]) destroy(v T) {
+	if p.opts.Destroy != nil {
+		p.opts.Destroy(v)
+	}
+	atomic.AddInt64(&p.destroyed, 1)
+	p.release()
+}
+
+// release frees the slot of an object that no longer exists. Objects are
+// interchangeable, so overflow slots are given up before tokens.
+func (p *Pool[T]) release() {
+	for {
+		n := atomic.LoadInt64(&p.overflow)
+		if n == 0 {
+			break
+		}
+		if atomic.CompareAndSwapInt64(&p.overflow, n, n-1) {
+			return
+		}
+	}
+	select {
+	case <-p.tokens:
+	default:
+	}
+}
+
+// SimConn stands in for a network connection in the pool examples: opening
+// one costs a dial latency and it must be closed when no longer wanted
+type SimConn struct {
+	ID     int64
+	closed int32
+}
+
+// Close marks the connection closed
+func (c *SimConn) Close() {
+	atomic.StoreInt32(&c.closed, 1)
+}
+
+// Closed reports whether Close has been called
+func (c *SimConn) Closed() bool {
+	return atomic.LoadInt32(&c.closed) == 1
+}
+
+// NewSimConnPool returns a pool of at most size SimConns, each taking latency
+// to dial, closed when the pool drops them
+func NewSimConnPool(size int, latency time.Duration, overflow PoolOverflow) *Pool[*SimConn] {
+	var ids int64
+	return NewPool(PoolOptions[*SimConn]{
+		MaxSize: size,
+		New: func(ctx context.Context) (*SimConn, error) {
+			select {
+			case <-time.After(latency):
+				return &SimConn{ID: atomic.AddInt64(&ids, 1)}, nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		},
+		Destroy:  (*SimConn).Close,
+		Overflow: overflow,
+	})
+}
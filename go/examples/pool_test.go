@@ -0,0 +1,251 @@
+package examples
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// countingPool returns a pool of ints numbered from 1, recording what it destroys
+func countingPool(size int, overflow PoolOverflow, clock Clock) (*Pool[int], *[]int) {
+	var next int
+	var destroyed []int
+	p := NewPool(PoolOptions[int]{
+		MaxSize:     size,
+		IdleTimeout: time.Minute,
+		New:         func(context.Context) (int, error) { next++; return next, nil },
+		Destroy:     func(v int) { destroyed = append(destroyed, v) },
+		Overflow:    overflow,
+		Clock:       clock,
+	})
+	return p, &destroyed
+}
+
+func TestPoolReuse(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	p, destroyed := countingPool(2, PoolWait, nil)
+	a, _ := p.Get(ctx)
+	p.Put(a)
+	b, _ := p.Get(ctx)
+	g.Expect(b).To(Equal(a))
+	g.Expect(p.Stats()).To(Equal(PoolStats{Created: 1}))
+
+	// A broken object is discarded and replaced
+	p.Discard(b)
+	c, _ := p.Get(ctx)
+	g.Expect(c).To(Equal(2))
+	g.Expect(*destroyed).To(Equal([]int{1}))
+}
+
+func TestPoolWait(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	p, _ := countingPool(2, PoolWait, nil)
+	a, _ := p.Get(ctx)
+	_, _ = p.Get(ctx)
+
+	// Both slots are taken: Get waits until its deadline
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err := p.Get(timeout)
+	g.Expect(err).To(MatchError(context.DeadlineExceeded))
+
+	// ...or until an object comes back
+	got := make(chan int)
+	go func() {
+		v, _ := p.Get(ctx)
+		got <- v
+	}()
+	g.Consistently(got, 20*time.Millisecond).ShouldNot(Receive())
+	p.Put(a)
+	g.Eventually(got).Should(Receive(Equal(a)))
+	g.Expect(p.Stats().Created).To(Equal(int64(2)))
+}
+
+func TestPoolOverflowPolicies(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	p, _ := countingPool(1, PoolFail, nil)
+	_, _ = p.Get(ctx)
+	_, err := p.Get(ctx)
+	g.Expect(err).To(MatchError(ErrPoolExhausted))
+
+	// PoolCreate goes past MaxSize, and the extra object does not outlive its use
+	p, destroyed := countingPool(1, PoolCreate, nil)
+	a, _ := p.Get(ctx)
+	b, err := p.Get(ctx)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(p.Stats().Overflow).To(Equal(int64(1)))
+	p.Put(a)
+	p.Put(b)
+	g.Expect(*destroyed).To(Equal([]int{b}))
+	g.Expect(p.Stats()).To(Equal(PoolStats{Idle: 1, Created: 2, Destroyed: 1}))
+}
+
+func TestPoolIdleTimeout(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	p, destroyed := countingPool(2, PoolWait, clock)
+	a, _ := p.Get(ctx)
+	b, _ := p.Get(ctx)
+	p.Put(a)
+	clock.Advance(45 * time.Second)
+	p.Put(b)
+	clock.Advance(30 * time.Second)
+
+	// a has been idle 75s and is dropped; b, idle 30s, is reused
+	v, _ := p.Get(ctx)
+	g.Expect(v).To(Equal(b))
+	g.Expect(*destroyed).To(Equal([]int{a}))
+}
+
+func TestPoolClose(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	p, destroyed := countingPool(3, PoolWait, nil)
+	a, _ := p.Get(ctx)
+	b, _ := p.Get(ctx)
+	_, _ = p.Get(ctx)
+
+	// Every slot is in use: a Get waits, and Close wakes it
+	waiting := make(chan error)
+	go func() {
+		_, err := p.Get(ctx)
+		waiting <- err
+	}()
+	g.Consistently(waiting, 20*time.Millisecond).ShouldNot(Receive())
+	p.Put(a)
+	g.Eventually(waiting).Should(Receive(BeNil()))
+
+	go func() {
+		_, err := p.Get(ctx)
+		waiting <- err
+	}()
+	g.Consistently(waiting, 20*time.Millisecond).ShouldNot(Receive())
+	p.Close()
+	g.Eventually(waiting).Should(Receive(MatchError(ErrResourcePoolClosed)))
+
+	// Objects put back after Close are destroyed
+	p.Put(b)
+	g.Expect(*destroyed).To(Equal([]int{b}))
+	_, err := p.Get(ctx)
+	g.Expect(err).To(MatchError(ErrResourcePoolClosed))
+}
+
+func TestPoolNewFails(t *testing.T) {
+	g := NewWithT(t)
+
+	boom := errors.New("dial failed")
+	p := NewPool(PoolOptions[int]{MaxSize: 1, New: func(context.Context) (int, error) { return 0, boom }})
+	_, err := p.Get(context.Background())
+	g.Expect(err).To(MatchError(boom))
+
+	// The failed attempt did not keep the only slot
+	_, err = p.Get(context.Background())
+	g.Expect(err).To(MatchError(boom))
+}
+
+func TestSimConnPool(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	p := NewSimConnPool(4, time.Millisecond, PoolWait)
+	var mu sync.Mutex
+	inUse := map[*SimConn]bool{}
+	var peak int
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				c, err := p.Get(ctx)
+				if err != nil {
+					return
+				}
+				mu.Lock()
+				inUse[c] = true
+				if len(inUse) > peak {
+					peak = len(inUse)
+				}
+				mu.Unlock()
+				time.Sleep(100 * time.Microsecond)
+				mu.Lock()
+				delete(inUse, c)
+				mu.Unlock()
+				p.Put(c)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 200 uses shared at most 4 connections, each dialled once
+	g.Expect(peak).To(BeNumerically("<=", 4))
+	g.Expect(p.Stats().Created).To(BeNumerically("<=", 4))
+
+	c, _ := p.Get(ctx)
+	p.Put(c)
+	p.Close()
+	g.Expect(c.Closed()).To(BeTrue())
+}
+
+var poolBenchSink int64
+
+// BenchmarkPoolVsSyncPool reuses scratch buffers from both pools under
+// parallel load. sync.Pool is faster, with per-P caches and no bound, but may
+// drop anything at a GC without telling anyone, so it suits scratch memory and
+// not connections; Pool pays for its bound and destructor with channel operations.
+func BenchmarkPoolVsSyncPool(b *testing.B) {
+	use := func(buf *bytes.Buffer) {
+		buf.Reset()
+		buf.WriteString("payload")
+		atomic.AddInt64(&poolBenchSink, int64(buf.Len()))
+	}
+
+	b.Run("Pool", func(b *testing.B) {
+		var created int64
+		p := NewPool(PoolOptions[*bytes.Buffer]{
+			MaxSize: 64,
+			New: func(context.Context) (*bytes.Buffer, error) {
+				atomic.AddInt64(&created, 1)
+				return new(bytes.Buffer), nil
+			},
+			Overflow: PoolCreate,
+		})
+		ctx := context.Background()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				buf, _ := p.Get(ctx)
+				use(buf)
+				p.Put(buf)
+			}
+		})
+		b.ReportMetric(float64(created)/float64(b.N), "allocs-by-pool/op")
+	})
+
+	b.Run("SyncPool", func(b *testing.B) {
+		var created int64
+		p := sync.Pool{New: func() any { atomic.AddInt64(&created, 1); return new(bytes.Buffer) }}
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				buf := p.Get().(*bytes.Buffer)
+				use(buf)
+				p.Put(buf)
+			}
+		})
+		b.ReportMetric(float64(created)/float64(b.N), "allocs-by-pool/op")
+	})
+}
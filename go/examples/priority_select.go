@@ -0,0 +1,104 @@
+package examples
+
+import (
+	"context"
+	"reflect"
+)
+
+// PriorityOptions configures PrioritySelect
+type PriorityOptions struct {
+	// FairEvery, when positive, makes every FairEvery-th value be taken
+	// scanning from a lower level, rotating through the levels below the
+	// highest, so a ready level is served at least once every
+	// FairEvery*(len(levels)-1) values however busy the levels above it are.
+	// Zero means strict priority, where a busy high level starves the rest.
+	FairEvery int
+}
+
+// PrioritySelect merges levels into one channel, preferring lower indices:
+// before each value it polls levels[0], then levels[1] and so on, which is the
+// nested select idiom generalised to any number of channels. Only when every
+// level is empty does it block, waking on whichever level delivers first. A
+// closed level is dropped; the output closes once all levels have closed or
+// ctx is done.
+func PrioritySelect[T any](ctx context.Context, opts PriorityOptions, levels ...<-chan T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		levels := append([]<-chan T(nil), levels...) // Closed levels are set to nil, which never polls ready
+		open := len(levels)
+		for _, l := range levels {
+			if l == nil {
+				open--
+			}
+		}
+		rot := 0 // Last level a fair pick started from
+		for n := 1; open > 0; n++ {
+			start := 0
+			if opts.FairEvery > 0 && len(levels) > 1 && n%opts.FairEvery == 0 {
+				rot = rot%(len(levels)-1) + 1
+				start = rot
+			}
+			v, ok, closed := pollFrom(levels, start)
+			if closed >= 0 {
+				levels[closed] = nil
+				open--
+				continue
+			}
+			if !ok {
+				v, ok, closed = waitAny(ctx, levels)
+				if closed >= 0 {
+					levels[closed] = nil
+					open--
+					continue
+				}
+				if !ok {
+					return
+				}
+			}
+			select {
+			case out <- v:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// pollFrom tries each level without blocking, from start to the end and then
+// wrapping round. It reports the index of a level found closed, or -1.
+func pollFrom[T any](levels []<-chan T, start int) (v T, ok bool, closed int) {
+	for i := range levels {
+		l := (start + i) % len(levels)
+		select {
+		case v, ok := <-levels[l]:
+			if !ok {
+				return v, false, l
+			}
+			return v, true, -1
+		default:
+		}
+	}
+	return v, false, -1
+}
+
+// waitAny blocks until some level delivers or closes, or ctx is done. A
+// select over a run-time number of channels needs reflect.Select.
+func waitAny[T any](ctx context.Context, levels []<-chan T) (v T, ok bool, closed int) {
+	cases := make([]reflect.SelectCase, len(levels)+1)
+	cases[0] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())}
+	for i, l := range levels {
+		cases[i+1] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(l)}
+	}
+	chosen, rv, recvOK := reflect.Select(cases)
+	if chosen == 0 {
+		return v, false, -1
+	}
+	if !recvOK {
+		return v, false, chosen - 1
+	}
+	// Set rather than Interface().(T), which panics on a nil interface value
+	reflect.ValueOf(&v).Elem().Set(rv)
+	return v, true, -1
+}
@@ -0,0 +1,111 @@
+package examples
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// filled returns a closed channel already holding n copies of v, so every
+// level is ready at once, as under sustained load
+func filled(v, n int) <-chan int {
+	ch := make(chan int, n)
+	for i := 0; i < n; i++ {
+		ch <- v
+	}
+	close(ch)
+	return ch
+}
+
+// levelCounts counts how many of the first n values came from each level
+func levelCounts(out <-chan int, n, levels int) []int {
+	counts := make([]int, levels)
+	for i := 0; i < n; i++ {
+		counts[<-out]++
+	}
+	return counts
+}
+
+func TestPrioritySelectStrict(t *testing.T) {
+	g := NewWithT(t)
+
+	out := PrioritySelect(context.Background(), PriorityOptions{}, filled(0, 100), filled(1, 100), filled(2, 100))
+	var got []int
+	for v := range out {
+		got = append(got, v)
+	}
+	// Every level is fully drained before the next is looked at
+	g.Expect(got).To(HaveLen(300))
+	for i, v := range got {
+		g.Expect(v).To(Equal(i/100), "value %d", i)
+	}
+}
+
+func TestPrioritySelectFairEvery(t *testing.T) {
+	g := NewWithT(t)
+
+	// Two levels: every 4th value comes from the low level
+	out := PrioritySelect(context.Background(), PriorityOptions{FairEvery: 4}, filled(0, 100), filled(1, 100))
+	g.Expect(levelCounts(out, 80, 2)).To(Equal([]int{60, 20}))
+
+	// Three levels: the fair picks alternate between levels 1 and 2, so even
+	// the lowest level gets one value in every FairEvery*2
+	out = PrioritySelect(context.Background(), PriorityOptions{FairEvery: 4}, filled(0, 100), filled(1, 100), filled(2, 100))
+	g.Expect(levelCounts(out, 80, 3)).To(Equal([]int{60, 10, 10}))
+}
+
+func TestPrioritySelectBlocksUntilAnyLevel(t *testing.T) {
+	g := NewWithT(t)
+
+	high, low := make(chan int), make(chan int)
+	out := PrioritySelect(context.Background(), PriorityOptions{}, high, low)
+
+	// Only the low level has anything: it is delivered rather than waiting on high
+	go func() { low <- 1 }()
+	g.Eventually(out).Should(Receive(Equal(1)))
+	go func() { high <- 0 }()
+	g.Eventually(out).Should(Receive(Equal(0)))
+
+	// The output closes once every level has
+	close(high)
+	go func() { low <- 2 }()
+	g.Eventually(out).Should(Receive(Equal(2)))
+	close(low)
+	g.Eventually(out).Should(BeClosed())
+}
+
+// TestPrioritySelectNilInterface sends a nil error while every level is
+// empty, so it arrives through reflect.Select rather than the polling pass
+func TestPrioritySelectNilInterface(t *testing.T) {
+	g := NewWithT(t)
+
+	high, low := make(chan error), make(chan error)
+	go func() { low <- nil }()
+	v, ok, closed := waitAny(context.Background(), []<-chan error{high, low})
+	g.Expect(v).To(BeNil())
+	g.Expect(ok).To(BeTrue())
+	g.Expect(closed).To(Equal(-1))
+
+	out := PrioritySelect(context.Background(), PriorityOptions{}, high, low)
+	go func() { low <- nil }()
+	g.Eventually(out).Should(Receive(BeNil()))
+	close(high)
+	close(low)
+	g.Eventually(out).Should(BeClosed())
+}
+
+func TestPrioritySelectCancellation(t *testing.T) {
+	g := NewWithT(t)
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := PrioritySelect(ctx, PriorityOptions{FairEvery: 2}, counting(ctx), make(chan int))
+	g.Expect(<-out).To(Equal(0))
+	cancel()
+
+	g.Eventually(out).Should(BeClosed())
+	g.Eventually(runtime.NumGoroutine, time.Second).Should(BeNumerically("<=", before))
+}
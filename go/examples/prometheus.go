@@ -0,0 +1,113 @@
+package examples
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PromContentType is the content type of the Prometheus text exposition format
+const PromContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// PromHandler serves the metrics in Prometheus text exposition format
+func (m *Metrics) PromHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", PromContentType)
+		m.WritePrometheus(w)
+	})
+}
+
+// WritePrometheus renders counters, gauges and the duration histograms to w
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	s := m.Snapshot()
+
+	writePromCounter(bw, "requests_total", "Total number of requests.", float64(s.Counters[CounterRequests]))
+	writePromCounter(bw, "errors_total", "Total number of failed requests.", float64(s.Counters[CounterErrors]))
+	writePromCounter(bw, "bytes_total", "Total bytes processed.", float64(s.Counters[CounterBytes]))
+
+	names := make([]string, 0, len(s.Gauges))
+	for name := range s.Gauges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writePromGauge(bw, promName(name), "Gauge "+name+".", s.Gauges[name])
+	}
+
+	writePromHistogram(bw, "request_duration_seconds", "Request latency in seconds.",
+		s.Histograms[HistogramLatency], float64(time.Second))
+	writePromGauge(bw, "request_duration_min_seconds", "Fastest request latency in seconds.", s.Latency.Min.Seconds())
+	writePromGauge(bw, "request_duration_max_seconds", "Slowest request latency in seconds.", s.Latency.Max.Seconds())
+	for _, name := range sortedKeys(s.Histograms) {
+		if name != HistogramLatency {
+			writePromHistogram(bw, promName(name)+"_duration_seconds", "Duration of "+name+" in seconds.",
+				s.Histograms[name], float64(time.Second))
+		}
+	}
+
+	return bw.Flush()
+}
+
+func writePromCounter(w io.Writer, name, help string, value float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %s\n", name, help, name, name, promFloat(value))
+}
+
+func writePromGauge(w io.Writer, name, help string, value float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n", name, help, name, name, promFloat(value))
+}
+
+// writePromHistogram writes cumulative buckets; unit divides the raw values (e.g. ns -> s)
+func writePromHistogram(w io.Writer, name, help string, snap HistogramSnapshot, unit float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	var cumulative int64
+	for i, bound := range snap.Bounds {
+		cumulative += snap.Counts[i]
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", name, promFloat(float64(bound)/unit), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, snap.Count)
+	fmt.Fprintf(w, "%s_sum %s\n", name, promFloat(float64(snap.Sum)/unit))
+	fmt.Fprintf(w, "%s_count %d\n", name, snap.Count)
+}
+
+func promFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// promName replaces characters that are not valid in a Prometheus metric name
+func promName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		valid := r == '_' || r == ':' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') ||
+			(i > 0 && r >= '0' && r <= '9')
+		if valid {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// promLabelEscaper escapes a label value for the text exposition format,
+// which defines only \\, \" and \n; everything else is written as is
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// promLabelValue quotes a label value for the text exposition format
+func promLabelValue(v string) string {
+	return `"` + promLabelEscaper.Replace(v) + `"`
+}
@@ -0,0 +1,102 @@
+package examples
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// parsePromText parses exposition text into sample values keyed by
+// "name{labels}" and metric types keyed by name
+func parsePromText(r io.Reader) (samples map[string]float64, types map[string]string, err error) {
+	samples = map[string]float64{}
+	types = map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "# TYPE ") {
+			fields := strings.Fields(line)
+			types[fields[2]] = fields[3]
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		idx := strings.LastIndex(line, " ")
+		value, err := strconv.ParseFloat(line[idx+1:], 64)
+		if err != nil {
+			return nil, nil, err
+		}
+		samples[line[:idx]] = value
+	}
+	return samples, types, scanner.Err()
+}
+
+func TestMetricsPromHandler(t *testing.T) {
+	g := NewWithT(t)
+
+	metrics := &Metrics{}
+	metrics.RecordRequest()
+	metrics.RecordRequest()
+	metrics.RecordError()
+	metrics.RecordBytes(2048)
+	metrics.RecordLatency(100 * time.Microsecond)
+	metrics.RecordLatency(2 * time.Second)
+	metrics.SetGauge("queue_depth", 7)
+	metrics.SetGauge("load.1m", 0.25)
+
+	server := httptest.NewServer(metrics.PromHandler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	g.Expect(err).NotTo(HaveOccurred())
+	defer resp.Body.Close()
+	g.Expect(resp.Header.Get("Content-Type")).To(Equal(PromContentType))
+
+	samples, types, err := parsePromText(resp.Body)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// Counters
+	g.Expect(types).To(HaveKeyWithValue("requests_total", "counter"))
+	g.Expect(samples).To(HaveKeyWithValue("requests_total", 2.0))
+	g.Expect(samples).To(HaveKeyWithValue("errors_total", 1.0))
+	g.Expect(samples).To(HaveKeyWithValue("bytes_total", 2048.0))
+
+	// Gauges, with invalid name characters replaced
+	g.Expect(types).To(HaveKeyWithValue("queue_depth", "gauge"))
+	g.Expect(samples).To(HaveKeyWithValue("queue_depth", 7.0))
+	g.Expect(samples).To(HaveKeyWithValue("load_1m", 0.25))
+
+	// Histogram buckets are cumulative and end with +Inf == count
+	g.Expect(types).To(HaveKeyWithValue("request_duration_seconds", "histogram"))
+	g.Expect(samples).To(HaveKeyWithValue(`request_duration_seconds_bucket{le="+Inf"}`, 2.0))
+	g.Expect(samples).To(HaveKeyWithValue("request_duration_seconds_count", 2.0))
+	g.Expect(samples["request_duration_seconds_sum"]).To(BeNumerically("~", 2.0001, 1e-9))
+	g.Expect(samples).To(HaveKeyWithValue(`request_duration_seconds_bucket{le="0.0001125"}`, 1.0))
+
+	var prev float64
+	for _, bound := range DefaultLatencyBounds {
+		key := `request_duration_seconds_bucket{le="` + promFloat(float64(bound)/float64(time.Second)) + `"}`
+		g.Expect(samples).To(HaveKey(key))
+		g.Expect(samples[key]).To(BeNumerically(">=", prev))
+		prev = samples[key]
+	}
+}
+
+func TestPromFormatting(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(promName("http.requests-total")).To(Equal("http_requests_total"))
+	g.Expect(promName("9lives")).To(Equal("_lives"))
+	g.Expect(promName("ok_name:sub")).To(Equal("ok_name:sub"))
+
+	g.Expect(promFloat(1.5)).To(Equal("1.5"))
+	g.Expect(promFloat(1e-05)).To(Equal("1e-05"))
+}
@@ -0,0 +1,201 @@
+package examples
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+)
+
+// benchQueue is the shape QueueBenchmark drives every queue through: both
+// operations fail rather than block, and callers retry
+type benchQueue[T any] interface {
+	TryEnqueue(v T) bool
+	TryDequeue() (T, bool)
+}
+
+type chanQueue[T any] chan T
+
+func (c chanQueue[T]) TryEnqueue(v T) bool {
+	select {
+	case c <- v:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c chanQueue[T]) TryDequeue() (T, bool) {
+	select {
+	case v := <-c:
+		return v, true
+	default:
+		var zero T
+		return zero, false
+	}
+}
+
+type mutexBenchQueue[T any] struct{ q *BoundedQueue[T] }
+
+func (m mutexBenchQueue[T]) TryEnqueue(v T) bool   { return m.q.Put(v) == nil }
+func (m mutexBenchQueue[T]) TryDequeue() (T, bool) { return m.q.TryGet() }
+
+type msBenchQueue[T any] struct{ q *LockFreeQueue[T] }
+
+func (m msBenchQueue[T]) TryEnqueue(v T) bool   { m.q.Enqueue(v); return true }
+func (m msBenchQueue[T]) TryDequeue() (T, bool) { return m.q.Dequeue() }
+
+// benchQueueKind is one queue implementation in the matrix
+type benchQueueKind[T any] struct {
+	name string
+	spsc bool // Only valid with one producer and one consumer
+	new  func(capacity int) benchQueue[T]
+}
+
+func benchQueueKinds[T any]() []benchQueueKind[T] {
+	return []benchQueueKind[T]{
+		{"Channel", false, func(n int) benchQueue[T] { return make(chanQueue[T], n) }},
+		{"Mutex", false, func(n int) benchQueue[T] { return mutexBenchQueue[T]{NewBoundedQueue[T](n, OverflowError)} }},
+		{"MPMC", false, func(n int) benchQueue[T] { return NewMPMCQueue[T](n) }},
+		{"MSQueue", false, func(int) benchQueue[T] { return msBenchQueue[T]{NewLockFreeQueue[T]()} }},
+		{"SPSC", true, func(n int) benchQueue[T] { return NewSPSCRing[T](n) }},
+	}
+}
+
+// QueuePayloadSizes are the item sizes, in bytes, QueueBenchmark supports
+var QueuePayloadSizes = []int{8, 64, 512}
+
+// QueueBenchOptions sizes QueueBenchmark. Every combination of producer
+// count, consumer count and payload size is run against every queue.
+type QueueBenchOptions struct {
+	Producers    []int // Defaults to 1 and 4
+	Consumers    []int // Defaults to 1 and 4
+	PayloadBytes []int // Each one of QueuePayloadSizes; defaults to all of them
+	Items        int   // Items per run; defaults to 100,000
+	Capacity     int   // Bound for the bounded queues; defaults to 1024
+}
+
+// QueueBenchResult is one queue under one workload, in a form the reporting
+// tooling can serialise directly
+type QueueBenchResult struct {
+	Queue          string  `json:"queue"`
+	Producers      int     `json:"producers"`
+	Consumers      int     `json:"consumers"`
+	PayloadBytes   int     `json:"payload_bytes"`
+	Items          int     `json:"items"`
+	NsPerItem      float64 `json:"ns_per_item"`
+	ItemsPerSecond float64 `json:"items_per_second"`
+}
+
+// QueueBenchmark moves Items items from producers to consumers through each
+// queue in turn: channels, BoundedQueue as the mutex queue, MPMCQueue,
+// LockFreeQueue (Michael-Scott) and, in single-producer single-consumer runs
+// only, SPSCRing. Full and empty queues are retried after runtime.Gosched,
+// the same for every queue, so only the queues differ.
+func QueueBenchmark(opts QueueBenchOptions) ([]QueueBenchResult, error) {
+	if len(opts.Producers) == 0 {
+		opts.Producers = []int{1, 4}
+	}
+	if len(opts.Consumers) == 0 {
+		opts.Consumers = []int{1, 4}
+	}
+	if len(opts.PayloadBytes) == 0 {
+		opts.PayloadBytes = QueuePayloadSizes
+	}
+	if opts.Items == 0 {
+		opts.Items = 100_000
+	}
+	if opts.Capacity == 0 {
+		opts.Capacity = 1024
+	}
+
+	var results []QueueBenchResult
+	for _, size := range opts.PayloadBytes {
+		var run []QueueBenchResult
+		switch size {
+		case 8:
+			run = runQueueMatrix[[8]byte](opts, size)
+		case 64:
+			run = runQueueMatrix[[64]byte](opts, size)
+		case 512:
+			run = runQueueMatrix[[512]byte](opts, size)
+		default:
+			return nil, fmt.Errorf("queue benchmark: unsupported payload size %d, want one of %v", size, QueuePayloadSizes)
+		}
+		results = append(results, run...)
+	}
+	return results, nil
+}
+
+func runQueueMatrix[T any](opts QueueBenchOptions, size int) []QueueBenchResult {
+	var results []QueueBenchResult
+	for _, producers := range opts.Producers {
+		for _, consumers := range opts.Consumers {
+			for _, kind := range benchQueueKinds[T]() {
+				if kind.spsc && (producers != 1 || consumers != 1) {
+					continue
+				}
+				elapsed := moveItems(kind.new(opts.Capacity), producers, consumers, opts.Items)
+				results = append(results, QueueBenchResult{
+					Queue:          kind.name,
+					Producers:      producers,
+					Consumers:      consumers,
+					PayloadBytes:   size,
+					Items:          opts.Items,
+					NsPerItem:      float64(elapsed.Nanoseconds()) / float64(opts.Items),
+					ItemsPerSecond: float64(opts.Items) / elapsed.Seconds(),
+				})
+			}
+		}
+	}
+	return results
+}
+
+// moveItems pushes items through q and returns how long it took for the last
+// one to be consumed
+func moveItems[T any](q benchQueue[T], producers, consumers, items int) time.Duration {
+	var wg sync.WaitGroup
+	var produced, consumed atomic.Int64
+	start := time.Now()
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var v T
+			for produced.Add(1) <= int64(items) {
+				for !q.TryEnqueue(v) {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+	for c := 0; c < consumers; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for consumed.Load() < int64(items) {
+				if _, ok := q.TryDequeue(); ok {
+					consumed.Add(1)
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return time.Since(start)
+}
+
+// WriteQueueTable writes results as an aligned text table
+func WriteQueueTable(w io.Writer, results []QueueBenchResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "queue\tproducers\tconsumers\tpayload\tns/item\titems/s\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.1f\t%.0f\t\n",
+			r.Queue, r.Producers, r.Consumers, r.PayloadBytes, r.NsPerItem, r.ItemsPerSecond)
+	}
+	return tw.Flush()
+}
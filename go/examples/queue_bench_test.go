@@ -0,0 +1,60 @@
+package examples
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestQueueBenchmark(t *testing.T) {
+	g := NewWithT(t)
+
+	results, err := QueueBenchmark(QueueBenchOptions{
+		Producers:    []int{1, 2},
+		Consumers:    []int{1},
+		PayloadBytes: []int{8, 512},
+		Items:        2000,
+		Capacity:     16,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// 5 queues for 1×1, 4 for 2×1 with SPSC left out, at each payload size
+	g.Expect(results).To(HaveLen(2 * (5 + 4)))
+	for _, r := range results {
+		g.Expect(r.Items).To(Equal(2000))
+		g.Expect(r.NsPerItem).To(BeNumerically(">", 0))
+		if r.Queue == "SPSC" {
+			g.Expect(r.Producers).To(Equal(1))
+		}
+	}
+
+	out, err := json.Marshal(results[0])
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(HavePrefix(`{"queue":"Channel","producers":1,"consumers":1,"payload_bytes":8,`))
+
+	var table bytes.Buffer
+	g.Expect(WriteQueueTable(&table, results)).To(Succeed())
+	g.Expect(table.String()).To(ContainSubstring("MSQueue"))
+	g.Expect(bytes.Count(table.Bytes(), []byte("\n"))).To(Equal(len(results) + 1))
+
+	_, err = QueueBenchmark(QueueBenchOptions{PayloadBytes: []int{3}})
+	g.Expect(err).To(MatchError(ContainSubstring("unsupported payload size 3")))
+}
+
+// BenchmarkQueues runs the QueueBenchmark workload under go test -bench with
+// 64-byte items
+func BenchmarkQueues(b *testing.B) {
+	for _, shape := range [][2]int{{1, 1}, {4, 4}} {
+		for _, kind := range benchQueueKinds[[64]byte]() {
+			if kind.spsc && shape != [2]int{1, 1} {
+				continue
+			}
+			b.Run(fmt.Sprintf("%dx%d/%s", shape[0], shape[1], kind.name), func(b *testing.B) {
+				moveItems(kind.new(1024), shape[0], shape[1], b.N)
+			})
+		}
+	}
+}
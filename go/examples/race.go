@@ -0,0 +1,8 @@
+//go:build race
+
+package examples
+
+// raceEnabled reports whether the race detector is built in. Code whose
+// synchronisation the detector cannot follow, such as SnapshotCell's
+// validated reads, switches to a plainly synchronised fallback when it is.
+const raceEnabled = true
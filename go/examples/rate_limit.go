@@ -0,0 +1,224 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBucketFull is returned by LeakyBucket.Wait when too many callers are queued
+var ErrBucketFull = errors.New("leaky bucket full")
+
+// TokenBucket is a rate limiter that allows bursts: tokens accrue at rate per
+// second up to burst, and each call spends one. After a quiet spell a whole
+// burst passes at once, then calls are held to the rate. Rate and burst can be
+// changed at run time and are read with atomics.
+type TokenBucket struct {
+	rate  uint64 // float64 bits, tokens per second
+	burst int64
+	clock Clock
+
+	mu     sync.Mutex
+	tokens float64 // Negative while Wait callers hold reservations
+	last   time.Time
+}
+
+// NewTokenBucket creates a full bucket driven by clock (RealClock if nil)
+func NewTokenBucket(rate float64, burst int, clock Clock) *TokenBucket {
+	if clock == nil {
+		clock = RealClock
+	}
+	b := &TokenBucket{clock: clock, tokens: float64(burst), last: clock.Now()}
+	atomic.StoreUint64(&b.rate, math.Float64bits(rate))
+	atomic.StoreInt64(&b.burst, int64(burst))
+	return b
+}
+
+// Rate returns the tokens added per second
+func (b *TokenBucket) Rate() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&b.rate))
+}
+
+// Burst returns the most tokens the bucket holds
+func (b *TokenBucket) Burst() int {
+	return int(atomic.LoadInt64(&b.burst))
+}
+
+// SetRate changes the refill rate. Tokens earned so far are credited at the
+// old rate first; callers already waiting keep the delay they were given.
+func (b *TokenBucket) SetRate(rate float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refillLocked()
+	atomic.StoreUint64(&b.rate, math.Float64bits(rate))
+}
+
+// SetBurst changes the bucket's capacity, discarding tokens above it
+func (b *TokenBucket) SetBurst(burst int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refillLocked()
+	atomic.StoreInt64(&b.burst, int64(burst))
+	b.tokens = math.Min(b.tokens, float64(burst))
+}
+
+// Allow spends a token if one is available now
+func (b *TokenBucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refillLocked()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Wait spends a token, blocking until one has accrued or ctx is done. It
+// reserves the token up front, so waiters are served in order; a cancelled
+// wait hands its reservation back.
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	b.mu.Lock()
+	b.refillLocked()
+	rate := b.Rate()
+	if b.tokens < 1 && rate <= 0 {
+		b.mu.Unlock()
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	b.tokens--
+	wait := time.Duration(-b.tokens / rate * float64(time.Second))
+	b.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := b.clock.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens = math.Min(b.tokens+1, float64(b.Burst()))
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (b *TokenBucket) refillLocked() {
+	now := b.clock.Now()
+	elapsed := now.Sub(b.last).Seconds()
+	b.last = now
+	b.tokens = math.Min(b.tokens+elapsed*b.Rate(), float64(b.Burst()))
+}
+
+// LeakyBucket is a rate limiter that smooths instead of allowing bursts: calls
+// leave at evenly spaced instants, one per 1/rate, however they arrive. Allow
+// passes only when the next instant has come; Wait queues for the next free
+// one, with at most capacity callers queued. The rate can be changed at run
+// time and is read with atomics; a rate of zero or less lets nothing through.
+type LeakyBucket struct {
+	interval int64 // Nanoseconds between calls, or 0 while the rate is <= 0
+	capacity int
+	clock    Clock
+
+	mu     sync.Mutex
+	next   time.Time // Earliest instant the next call may leave
+	queued int
+}
+
+// NewLeakyBucket creates an empty bucket driven by clock (RealClock if nil)
+func NewLeakyBucket(rate float64, capacity int, clock Clock) *LeakyBucket {
+	if clock == nil {
+		clock = RealClock
+	}
+	b := &LeakyBucket{capacity: capacity, clock: clock}
+	b.SetRate(rate)
+	return b
+}
+
+// Rate returns the calls let through per second
+func (b *LeakyBucket) Rate() float64 {
+	interval := atomic.LoadInt64(&b.interval)
+	if interval == 0 {
+		return 0
+	}
+	return float64(time.Second) / float64(interval)
+}
+
+// SetRate changes the spacing of calls from the next one scheduled onwards.
+// A rate of zero or less stops new calls until the rate is raised again;
+// rates above one per nanosecond are held to one.
+func (b *LeakyBucket) SetRate(rate float64) {
+	var interval int64
+	if rate > 0 {
+		interval = max(int64(float64(time.Second)/rate), 1)
+	}
+	atomic.StoreInt64(&b.interval, interval)
+}
+
+// Allow lets a call through if its instant has come and nobody is queued
+func (b *LeakyBucket) Allow() bool {
+	interval := atomic.LoadInt64(&b.interval)
+	if interval == 0 {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.clock.Now()
+	if now.Before(b.next) {
+		return false
+	}
+	b.next = now.Add(time.Duration(interval))
+	return true
+}
+
+// Wait blocks until the caller's instant, or until ctx is done. It returns
+// ErrBucketFull at once if capacity callers are already queued.
+func (b *LeakyBucket) Wait(ctx context.Context) error {
+	if atomic.LoadInt64(&b.interval) == 0 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	b.mu.Lock()
+	now := b.clock.Now()
+	slot := b.next
+	if slot.Before(now) {
+		slot = now
+	}
+	wait := slot.Sub(now)
+	if wait > 0 && b.queued >= b.capacity {
+		b.mu.Unlock()
+		return ErrBucketFull
+	}
+	b.next = slot.Add(time.Duration(atomic.LoadInt64(&b.interval)))
+	if wait <= 0 {
+		b.mu.Unlock()
+		return nil
+	}
+	b.queued++
+	b.mu.Unlock()
+
+	timer := b.clock.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		b.mu.Lock()
+		b.queued--
+		b.mu.Unlock()
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.queued--
+		// Give the instant back only if nobody has been scheduled after it
+		if b.next.Equal(slot.Add(time.Duration(atomic.LoadInt64(&b.interval)))) {
+			b.next = slot
+		}
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,195 @@
+package examples
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// allowed counts how many of n calls to allow pass
+func allowed(allow func() bool, n int) int {
+	count := 0
+	for i := 0; i < n; i++ {
+		if allow() {
+			count++
+		}
+	}
+	return count
+}
+
+func TestTokenBucket(t *testing.T) {
+	g := NewWithT(t)
+	clock := NewFakeClock(time.Unix(0, 0))
+	b := NewTokenBucket(10, 5, clock)
+
+	// A full bucket passes a whole burst, then one call per 100ms
+	g.Expect(allowed(b.Allow, 10)).To(Equal(5))
+	clock.Advance(100 * time.Millisecond)
+	g.Expect(allowed(b.Allow, 10)).To(Equal(1))
+
+	// Tokens stop accruing at burst
+	clock.Advance(10 * time.Second)
+	g.Expect(allowed(b.Allow, 10)).To(Equal(5))
+
+	b.SetRate(20)
+	clock.Advance(100 * time.Millisecond)
+	g.Expect(allowed(b.Allow, 10)).To(Equal(2))
+	g.Expect(b.Rate()).To(Equal(20.0))
+
+	clock.Advance(10 * time.Second)
+	b.SetBurst(2)
+	g.Expect(allowed(b.Allow, 10)).To(Equal(2))
+}
+
+func TestLeakyBucket(t *testing.T) {
+	g := NewWithT(t)
+	clock := NewFakeClock(time.Unix(0, 0))
+	b := NewLeakyBucket(10, 5, clock)
+
+	// No burst, even after a quiet spell
+	g.Expect(allowed(b.Allow, 10)).To(Equal(1))
+	clock.Advance(100 * time.Millisecond)
+	g.Expect(allowed(b.Allow, 10)).To(Equal(1))
+	clock.Advance(10 * time.Second)
+	g.Expect(allowed(b.Allow, 10)).To(Equal(1))
+
+	b.SetRate(20)
+	clock.Advance(100 * time.Millisecond)
+	g.Expect(allowed(b.Allow, 10)).To(Equal(1))
+	clock.Advance(50 * time.Millisecond)
+	g.Expect(allowed(b.Allow, 10)).To(Equal(1))
+	g.Expect(b.Rate()).To(Equal(20.0))
+}
+
+// TestLeakyBucketZeroRate checks that a rate of zero or less lets nothing
+// through rather than leaving calls unspaced
+func TestLeakyBucketZeroRate(t *testing.T) {
+	g := NewWithT(t)
+	clock := NewFakeClock(time.Unix(0, 0))
+
+	for _, rate := range []float64{0, -5} {
+		b := NewLeakyBucket(rate, 5, clock)
+		g.Expect(b.Rate()).To(BeZero())
+		g.Expect(allowed(b.Allow, 10)).To(BeZero())
+		clock.Advance(time.Hour)
+		g.Expect(allowed(b.Allow, 10)).To(BeZero())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		g.Expect(b.Wait(ctx)).To(MatchError(context.DeadlineExceeded))
+		cancel()
+
+		b.SetRate(10)
+		g.Expect(allowed(b.Allow, 10)).To(Equal(1))
+	}
+}
+
+func TestRateLimiterBurstComparison(t *testing.T) {
+	g := NewWithT(t)
+	clock := NewFakeClock(time.Unix(0, 0))
+	token := NewTokenBucket(10, 5, clock)
+	leaky := NewLeakyBucket(10, 5, clock)
+
+	// Three calls every 100ms for a second, after the buckets sat idle: the
+	// token bucket spends its saved burst up front, the leaky bucket keeps the pace
+	var tokenPassed, leakyPassed []int
+	for tick := 0; tick < 10; tick++ {
+		tokenPassed = append(tokenPassed, allowed(token.Allow, 3))
+		leakyPassed = append(leakyPassed, allowed(leaky.Allow, 3))
+		clock.Advance(100 * time.Millisecond)
+	}
+	g.Expect(tokenPassed).To(Equal([]int{3, 3, 1, 1, 1, 1, 1, 1, 1, 1}))
+	g.Expect(leakyPassed).To(Equal([]int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}))
+}
+
+// waitAsync runs wait on its own goroutine, delivering its result on the returned channel
+func waitAsync(ctx context.Context, wait func(context.Context) error) <-chan error {
+	done := make(chan error, 1)
+	go func() { done <- wait(ctx) }()
+	return done
+}
+
+func TestTokenBucketWait(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	clock := NewFakeClock(time.Unix(0, 0))
+	b := NewTokenBucket(10, 1, clock)
+
+	g.Expect(b.Wait(ctx)).To(Succeed())
+
+	// The next token is 100ms away; the one after that 200ms
+	first := waitAsync(ctx, b.Wait)
+	clock.BlockUntil(1)
+	second := waitAsync(ctx, b.Wait)
+	clock.BlockUntil(2)
+	clock.Advance(99 * time.Millisecond)
+	g.Consistently(first, 20*time.Millisecond).ShouldNot(Receive())
+	clock.Advance(time.Millisecond)
+	g.Eventually(first).Should(Receive(BeNil()))
+	g.Expect(second).NotTo(Receive())
+	clock.Advance(100 * time.Millisecond)
+	g.Eventually(second).Should(Receive(BeNil()))
+
+	// A cancelled wait returns its reservation
+	cctx, cancel := context.WithCancel(ctx)
+	cancelled := waitAsync(cctx, b.Wait)
+	clock.BlockUntil(1)
+	cancel()
+	g.Eventually(cancelled).Should(Receive(MatchError(context.Canceled)))
+	clock.Advance(100 * time.Millisecond)
+	g.Expect(b.Allow()).To(BeTrue())
+}
+
+func TestLeakyBucketWait(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	clock := NewFakeClock(time.Unix(0, 0))
+	b := NewLeakyBucket(10, 1, clock)
+
+	g.Expect(b.Wait(ctx)).To(Succeed())
+
+	// One caller may queue for the next instant; a second is turned away
+	queued := waitAsync(ctx, b.Wait)
+	clock.BlockUntil(1)
+	g.Expect(b.Wait(ctx)).To(MatchError(ErrBucketFull))
+	clock.Advance(100 * time.Millisecond)
+	g.Eventually(queued).Should(Receive(BeNil()))
+
+	// A cancelled caller gives its instant back to the next one
+	cctx, cancel := context.WithCancel(ctx)
+	cancelled := waitAsync(cctx, b.Wait)
+	clock.BlockUntil(1)
+	cancel()
+	g.Eventually(cancelled).Should(Receive(MatchError(context.Canceled)))
+	clock.Advance(100 * time.Millisecond)
+	g.Expect(b.Allow()).To(BeTrue())
+}
+
+// BenchmarkRateLimiterAllow measures the cost of a rejected-or-admitted
+// decision under contention for both limiters
+func BenchmarkRateLimiterAllow(b *testing.B) {
+	limiters := []struct {
+		name  string
+		allow func() bool
+	}{
+		{"TokenBucket", NewTokenBucket(1e6, 1000, nil).Allow},
+		{"LeakyBucket", NewLeakyBucket(1e6, 1000, nil).Allow},
+	}
+	for _, l := range limiters {
+		b.Run(l.name, func(b *testing.B) {
+			var passed int64
+			b.RunParallel(func(pb *testing.PB) {
+				n := int64(0)
+				for pb.Next() {
+					if l.allow() {
+						n++
+					}
+				}
+				atomic.AddInt64(&passed, n)
+			})
+			b.ReportMetric(float64(passed)/float64(b.N), "allowed/op")
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package examples
+
+import "sync/atomic"
+
+// RCUCell holds a value that is read far more often than it changes, using
+// read-copy-update: readers load the current pointer with no locks, and a
+// writer builds a new value from a copy of the old one and swaps it in.
+// AtomicConfig is the same pattern fixed to Config; RCUCell generalises it.
+//
+// Values handed out by Load are shared by every reader and must be treated as
+// immutable. An Update that modifies a map or slice inside old in place,
+// instead of copying it, races with those readers.
+type RCUCell[T any] struct {
+	p atomic.Pointer[T]
+}
+
+// NewRCUCell creates a cell holding initial
+func NewRCUCell[T any](initial T) *RCUCell[T] {
+	c := &RCUCell[T]{}
+	c.p.Store(&initial)
+	return c
+}
+
+// Load returns the current value. It never blocks, even while writers run.
+func (c *RCUCell[T]) Load() T {
+	return *c.p.Load()
+}
+
+// Store replaces the value outright, discarding any concurrent Update's work
+func (c *RCUCell[T]) Store(v T) {
+	c.p.Store(&v)
+}
+
+// Update replaces the value with fn(old) and returns the value it stored.
+// If another writer got in between reading old and swapping in the result,
+// fn is called again on the newer value, so fn must be free of side effects
+// and may run more than once; under heavy write contention a mutex
+// serialising writers wastes less work.
+func (c *RCUCell[T]) Update(fn func(old T) T) T {
+	for {
+		old := c.p.Load()
+		next := fn(*old)
+		if c.p.CompareAndSwap(old, &next) {
+			return next
+		}
+	}
+}
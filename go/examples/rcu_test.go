@@ -0,0 +1,113 @@
+package examples
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRCUCell(t *testing.T) {
+	g := NewWithT(t)
+
+	c := NewRCUCell(Config{MaxConnections: 10})
+	g.Expect(c.Load().MaxConnections).To(Equal(10))
+
+	got := c.Update(func(old Config) Config {
+		old.Debug = true
+		return old
+	})
+	g.Expect(got).To(Equal(Config{MaxConnections: 10, Debug: true}))
+	g.Expect(c.Load()).To(Equal(got))
+
+	c.Store(Config{Timeout: 5})
+	g.Expect(c.Load()).To(Equal(Config{Timeout: 5}))
+}
+
+func TestRCUCellConcurrentUpdates(t *testing.T) {
+	g := NewWithT(t)
+
+	// Each Update copies the slice, so readers never see it change under them
+	c := NewRCUCell([]int(nil))
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				c.Update(func(old []int) []int {
+					return append(append([]int(nil), old...), w)
+				})
+			}
+		}(w)
+	}
+	// A reader sees each version whole, and never an older one after a newer
+	prev := 0
+	for i := 0; i < 100; i++ {
+		n := len(c.Load())
+		g.Expect(n).To(BeNumerically(">=", prev))
+		prev = n
+	}
+	wg.Wait()
+
+	// No update is lost to a conflicting writer
+	g.Expect(c.Load()).To(HaveLen(800))
+}
+
+// rwMutexCell is the lock-based baseline for RCUCell
+type rwMutexCell[T any] struct {
+	mu sync.RWMutex
+	v  T
+}
+
+func (c *rwMutexCell[T]) Load() T {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.v
+}
+
+func (c *rwMutexCell[T]) Update(fn func(old T) T) T {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.v = fn(c.v)
+	return c.v
+}
+
+// BenchmarkRCUCell compares RCUCell with an RWMutex-guarded value, with one
+// write per ratio reads
+func BenchmarkRCUCell(b *testing.B) {
+	type cell interface {
+		Load() Config
+		Update(func(Config) Config) Config
+	}
+	impls := []struct {
+		name string
+		new  func() cell
+	}{
+		{"RCU", func() cell { return NewRCUCell(Config{}) }},
+		{"RWMutex", func() cell { return &rwMutexCell[Config]{} }},
+	}
+	bump := func(old Config) Config {
+		old.MaxConnections++
+		return old
+	}
+	for _, ratio := range []int{10, 100, 1000} {
+		for _, impl := range impls {
+			b.Run(fmt.Sprintf("reads=%d/%s", ratio, impl.name), func(b *testing.B) {
+				c := impl.new()
+				b.RunParallel(func(pb *testing.PB) {
+					var sink int
+					for i := 0; pb.Next(); i++ {
+						if i%ratio == 0 {
+							c.Update(bump)
+						} else {
+							sink += c.Load().MaxConnections
+						}
+					}
+					_ = sink
+				})
+			})
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package examples
+
+import (
+	"sync"
+	"time"
+)
+
+// Reporter takes a SwapAndReset snapshot of a Metrics every interval, so each
+// report holds exactly the events of its interval, and hands it either to a
+// callback or, when no callback is given, to the channel returned by C.
+type Reporter struct {
+	metrics  *Metrics
+	interval time.Duration
+	clock    Clock
+	report   func(MetricsSnapshot)
+	ch       chan MetricsSnapshot
+	latest   SnapshotCell[MetricsSnapshot] // Written only by flush
+
+	last      time.Time // Start of the current interval; owned by the loop
+	startOnce sync.Once
+	stopOnce  sync.Once
+	done      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewReporter creates a reporter for m driven by clock (RealClock if nil).
+// If report is nil, snapshots are sent on C instead; sends block, so the
+// consumer must keep receiving until the channel is closed by Stop.
+func NewReporter(m *Metrics, interval time.Duration, clock Clock, report func(MetricsSnapshot)) *Reporter {
+	if clock == nil {
+		clock = RealClock
+	}
+	r := &Reporter{
+		metrics:  m,
+		interval: interval,
+		clock:    clock,
+		report:   report,
+		last:     clock.Now(),
+		done:     make(chan struct{}),
+	}
+	if report == nil {
+		r.ch = make(chan MetricsSnapshot, 1)
+		r.report = func(s MetricsSnapshot) { r.ch <- s }
+	}
+	return r
+}
+
+// C returns the report channel, or nil when a callback was given.
+// It is closed after Stop has delivered the final report.
+func (r *Reporter) C() <-chan MetricsSnapshot {
+	return r.ch
+}
+
+// Latest returns the most recent report without locking or waiting, so any
+// number of goroutines, such as stats endpoints, can read it while the
+// reporter runs. ok is false before the first report.
+func (r *Reporter) Latest() (s MetricsSnapshot, ok bool) {
+	s, version := r.latest.Load()
+	return s, version > 0
+}
+
+// Start begins reporting in the background; later calls do nothing
+func (r *Reporter) Start() {
+	r.startOnce.Do(func() {
+		r.last = r.clock.Now()
+		ticker := r.clock.NewTicker(r.interval)
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C():
+					r.flush()
+				case <-r.done:
+					return
+				}
+			}
+		}()
+	})
+}
+
+// Stop halts the ticker and delivers a final report covering everything
+// recorded since the last one, then closes C
+func (r *Reporter) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.done)
+		r.wg.Wait()
+		r.flush()
+		if r.ch != nil {
+			close(r.ch)
+		}
+	})
+}
+
+func (r *Reporter) flush() {
+	s := r.metrics.SwapAndReset()
+	now := r.clock.Now()
+	s.Timestamp = now
+	s.Interval = now.Sub(r.last)
+	r.last = now
+	r.latest.Publish(s)
+	r.report(s)
+}
@@ -0,0 +1,121 @@
+package examples
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestReporterCallback(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	m := &Metrics{}
+
+	var mu sync.Mutex
+	var reports []MetricsSnapshot
+	r := NewReporter(m, time.Second, clock, func(s MetricsSnapshot) {
+		mu.Lock()
+		defer mu.Unlock()
+		reports = append(reports, s)
+	})
+	requests := func() []int64 {
+		mu.Lock()
+		defer mu.Unlock()
+		var out []int64
+		for _, s := range reports {
+			out = append(out, s.Counters[CounterRequests])
+		}
+		return out
+	}
+
+	r.Start()
+	clock.BlockUntil(1)
+
+	m.RecordRequest()
+	m.RecordRequest()
+	clock.Advance(time.Second)
+	g.Eventually(requests).Should(Equal([]int64{2}))
+
+	// Each report only holds its own interval
+	m.RecordRequest()
+	clock.Advance(time.Second)
+	g.Eventually(requests).Should(Equal([]int64{2, 1}))
+
+	// Stop flushes the partial interval
+	m.RecordRequest()
+	clock.Advance(500 * time.Millisecond)
+	r.Stop()
+	g.Expect(requests()).To(Equal([]int64{2, 1, 1}))
+
+	mu.Lock()
+	defer mu.Unlock()
+	g.Expect(reports[0].Interval).To(Equal(time.Second))
+	g.Expect(reports[2].Interval).To(Equal(500 * time.Millisecond))
+	g.Expect(reports[2].Timestamp).To(Equal(time.Unix(2, 5e8)))
+	g.Expect(r.C()).To(BeNil())
+}
+
+func TestReporterChannel(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	m := &Metrics{}
+	r := NewReporter(m, time.Second, clock, nil)
+	r.Start()
+	clock.BlockUntil(1)
+
+	m.RecordError()
+	clock.Advance(time.Second)
+	var s MetricsSnapshot
+	g.Eventually(r.C()).Should(Receive(&s))
+	g.Expect(s.Counters[CounterErrors]).To(Equal(int64(1)))
+
+	// The final report is delivered before the channel closes
+	m.RecordBytes(7)
+	go r.Stop()
+	g.Eventually(r.C()).Should(Receive(&s))
+	g.Expect(s.Counters[CounterBytes]).To(Equal(int64(7)))
+	g.Eventually(r.C()).Should(BeClosed())
+}
+
+func TestReporterStopWithoutStart(t *testing.T) {
+	g := NewWithT(t)
+
+	m := &Metrics{}
+	m.RecordRequest()
+	var got int64
+	r := NewReporter(m, time.Hour, nil, func(s MetricsSnapshot) {
+		got += s.Counters[CounterRequests]
+	})
+	r.Stop()
+	r.Stop()
+	g.Expect(got).To(Equal(int64(1)))
+}
+
+func TestReporterLatest(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	m := &Metrics{}
+	r := NewReporter(m, time.Second, clock, func(MetricsSnapshot) {})
+	_, ok := r.Latest()
+	g.Expect(ok).To(BeFalse())
+
+	r.Start()
+	defer r.Stop()
+	clock.BlockUntil(1)
+	m.RecordRequest()
+	clock.Advance(time.Second)
+
+	latestRequests := func() int64 {
+		s, _ := r.Latest()
+		return s.Counters[CounterRequests]
+	}
+	g.Eventually(latestRequests).Should(Equal(int64(1)))
+	s, ok := r.Latest()
+	g.Expect(ok).To(BeTrue())
+	g.Expect(s.Interval).To(Equal(time.Second))
+}
@@ -0,0 +1,73 @@
+package examples
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+)
+
+// ReservoirSampler keeps a uniform random sample of at most size values from a
+// stream of unknown length (Vitter's Algorithm R): after n values, every one of
+// them is in the reservoir with probability size/n. Memory is fixed at size.
+// It is not safe for concurrent use; guard it with the owner's lock.
+type ReservoirSampler struct {
+	samples []int64
+	size    int
+	seen    int64
+	rng     *rand.Rand
+}
+
+// NewReservoirSampler creates a sampler holding at most size values
+func NewReservoirSampler(size int) *ReservoirSampler {
+	return newReservoirSampler(size, rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())))
+}
+
+func newReservoirSampler(size int, rng *rand.Rand) *ReservoirSampler {
+	if size < 1 {
+		size = 1
+	}
+	return &ReservoirSampler{samples: make([]int64, 0, size), size: size, rng: rng}
+}
+
+// Add offers v to the reservoir
+func (r *ReservoirSampler) Add(v int64) {
+	r.seen++
+	if len(r.samples) < r.size {
+		r.samples = append(r.samples, v)
+		return
+	}
+	if j := r.rng.Int64N(r.seen); j < int64(r.size) {
+		r.samples[j] = v
+	}
+}
+
+// Seen returns how many values have been offered in total
+func (r *ReservoirSampler) Seen() int64 {
+	return r.seen
+}
+
+// Samples returns a copy of the current reservoir
+func (r *ReservoirSampler) Samples() []int64 {
+	return append([]int64(nil), r.samples...)
+}
+
+// Quantile returns the q-th quantile (0 <= q <= 1) of the sample, or 0 when empty
+func (r *ReservoirSampler) Quantile(q float64) int64 {
+	if len(r.samples) == 0 {
+		return 0
+	}
+	sorted := r.Samples()
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	q = math.Max(0, math.Min(1, q))
+	idx := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
+
+// Reset empties the reservoir
+func (r *ReservoirSampler) Reset() {
+	r.samples = r.samples[:0]
+	r.seen = 0
+}
@@ -0,0 +1,71 @@
+package examples
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestReservoirSampler(t *testing.T) {
+	g := NewWithT(t)
+
+	r := newReservoirSampler(5, rand.New(rand.NewPCG(1, 2)))
+	g.Expect(r.Quantile(0.5)).To(Equal(int64(0)))
+
+	// Below capacity every value is kept
+	for v := int64(1); v <= 3; v++ {
+		r.Add(v)
+	}
+	g.Expect(r.Samples()).To(Equal([]int64{1, 2, 3}))
+	g.Expect(r.Quantile(0)).To(Equal(int64(1)))
+	g.Expect(r.Quantile(0.5)).To(Equal(int64(2)))
+	g.Expect(r.Quantile(1)).To(Equal(int64(3)))
+
+	// Memory stays bounded however many values arrive
+	for v := int64(4); v <= 10000; v++ {
+		r.Add(v)
+	}
+	g.Expect(r.Samples()).To(HaveLen(5))
+	g.Expect(r.Seen()).To(Equal(int64(10000)))
+
+	r.Reset()
+	g.Expect(r.Samples()).To(BeEmpty())
+	g.Expect(r.Seen()).To(Equal(int64(0)))
+}
+
+func TestReservoirSamplerUniform(t *testing.T) {
+	g := NewWithT(t)
+
+	// Feed 0..99 into a 10-slot reservoir many times: each value should be
+	// kept with probability 10/100, early and late values alike
+	const stream, size, trials = 100, 10, 20000
+	rng := rand.New(rand.NewPCG(42, 7))
+	kept := make([]int, stream)
+	for i := 0; i < trials; i++ {
+		r := newReservoirSampler(size, rng)
+		for v := int64(0); v < stream; v++ {
+			r.Add(v)
+		}
+		for _, v := range r.Samples() {
+			kept[v]++
+		}
+	}
+
+	expected := float64(trials*size) / stream // 2000
+	for v, n := range kept {
+		g.Expect(float64(n)).To(BeNumerically("~", expected, expected*0.1), "value %d", v)
+	}
+}
+
+func TestReservoirSamplerPercentiles(t *testing.T) {
+	g := NewWithT(t)
+
+	r := newReservoirSampler(1000, rand.New(rand.NewPCG(3, 4)))
+	for v := int64(1); v <= 100000; v++ {
+		r.Add(v)
+	}
+	// A 1000-value sample estimates percentiles of a uniform stream to within a few percent
+	g.Expect(r.Quantile(0.5)).To(BeNumerically("~", 50000, 5000))
+	g.Expect(r.Quantile(0.99)).To(BeNumerically("~", 99000, 2000))
+}
@@ -0,0 +1,42 @@
+package examples
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// RetryOnce is sync.Once for initialization that can fail. Do runs fn until
+// it succeeds once: a returned error (or a panic) leaves the RetryOnce unset,
+// so the next caller tries again, while success is remembered and later calls
+// return nil without running anything. With sync.Once a failed init is
+// swallowed and the value stays broken for the life of the process.
+//
+// Attempts never overlap: callers arriving during one wait for it, and only
+// run their own fn if it failed.
+type RetryOnce struct {
+	done atomic.Bool
+	mu   sync.Mutex
+}
+
+// Do calls fn unless an earlier call succeeded, and returns fn's error. A
+// caller that waited on another's successful attempt gets nil.
+func (o *RetryOnce) Do(fn func() error) error {
+	if o.done.Load() {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.done.Load() {
+		return nil
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	o.done.Store(true)
+	return nil
+}
+
+// Done reports whether an attempt has succeeded
+func (o *RetryOnce) Done() bool {
+	return o.done.Load()
+}
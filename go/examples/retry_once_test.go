@@ -0,0 +1,69 @@
+package examples
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRetryOnceRetryStorm(t *testing.T) {
+	g := NewWithT(t)
+
+	// The first failures attempts fail; every goroutine calls Do at once
+	const callers, failures = 64, 5
+	errInit := errors.New("init failed")
+	var once RetryOnce
+	var attempts, inFlight, overlaps atomic.Int64
+	init := func() error {
+		if inFlight.Add(1) > 1 {
+			overlaps.Add(1)
+		}
+		defer inFlight.Add(-1)
+		if attempts.Add(1) <= failures {
+			return errInit
+		}
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	var succeeded, failed atomic.Int64
+	start := make(chan struct{})
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			if err := once.Do(init); err != nil {
+				failed.Add(1)
+			} else {
+				succeeded.Add(1)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	// Each failure is reported to the caller that made it, and no attempt
+	// runs after the first success
+	g.Expect(attempts.Load()).To(BeEquivalentTo(failures + 1))
+	g.Expect(failed.Load()).To(BeEquivalentTo(failures))
+	g.Expect(succeeded.Load()).To(BeEquivalentTo(callers - failures))
+	g.Expect(overlaps.Load()).To(BeZero())
+	g.Expect(once.Done()).To(BeTrue())
+}
+
+func TestRetryOncePanicAllowsRetry(t *testing.T) {
+	g := NewWithT(t)
+
+	var once RetryOnce
+	g.Expect(func() { once.Do(func() error { panic("boom") }) }).To(Panic())
+	g.Expect(once.Done()).To(BeFalse())
+
+	calls := 0
+	g.Expect(once.Do(func() error { calls++; return nil })).To(Succeed())
+	g.Expect(once.Do(func() error { calls++; return nil })).To(Succeed())
+	g.Expect(calls).To(Equal(1))
+}
@@ -0,0 +1,47 @@
+package examples
+
+// RunGroup runs a set of long-lived actors, such as a server, a reporter and
+// a signal listener, and ties their lifetimes together: when the first one
+// returns, every actor is interrupted, and Run waits for all of them before
+// reporting why the group stopped. It follows github.com/oklog/run.
+// The zero value is an empty group.
+type RunGroup struct {
+	actors []runActor
+}
+
+type runActor struct {
+	execute   func() error
+	interrupt func(error)
+}
+
+// Add registers an actor. execute runs it and blocks until it is done;
+// interrupt must make execute return promptly, and is called with the error
+// of the actor that returned first, even if that actor is this one.
+func (g *RunGroup) Add(execute func() error, interrupt func(error)) {
+	g.actors = append(g.actors, runActor{execute: execute, interrupt: interrupt})
+}
+
+// Run starts every actor on its own goroutine and blocks until all have
+// returned. It returns the error of the first actor to return, which may be
+// nil; the others' errors are discarded, since they are usually just the
+// consequence of being interrupted.
+func (g *RunGroup) Run() error {
+	if len(g.actors) == 0 {
+		return nil
+	}
+	errs := make(chan error, len(g.actors))
+	for _, a := range g.actors {
+		go func(a runActor) {
+			errs <- a.execute()
+		}(a)
+	}
+
+	err := <-errs
+	for _, a := range g.actors {
+		a.interrupt(err)
+	}
+	for i := 1; i < len(g.actors); i++ {
+		<-errs
+	}
+	return err
+}
@@ -0,0 +1,55 @@
+package examples
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRunGroupFirstReturnStopsAll(t *testing.T) {
+	g := NewWithT(t)
+
+	errListen := errors.New("listen failed")
+	failed := make(chan struct{})
+	var group RunGroup
+	var interruptedWith []error
+	var stopped atomic.Int64
+
+	// Two actors that run until interrupted, and one that fails on its own
+	for i := 0; i < 2; i++ {
+		stop := make(chan struct{})
+		group.Add(func() error {
+			<-stop
+			stopped.Add(1)
+			return errors.New("interrupted")
+		}, func(err error) {
+			interruptedWith = append(interruptedWith, err)
+			close(stop)
+		})
+	}
+	group.Add(func() error {
+		<-failed
+		return errListen
+	}, func(err error) {
+		interruptedWith = append(interruptedWith, err)
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- group.Run() }()
+	g.Consistently(done).ShouldNot(Receive())
+	close(failed)
+
+	// Run reports the originating error, after every actor has returned
+	g.Eventually(done).Should(Receive(MatchError(errListen)))
+	g.Expect(stopped.Load()).To(BeEquivalentTo(2))
+	g.Expect(interruptedWith).To(Equal([]error{errListen, errListen, errListen}))
+}
+
+func TestRunGroupEmpty(t *testing.T) {
+	g := NewWithT(t)
+
+	var group RunGroup
+	g.Expect(group.Run()).To(Succeed())
+}
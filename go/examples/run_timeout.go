@@ -0,0 +1,64 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrRunTimeout is returned by RunWithTimeout when fn has not returned by its deadline
+var ErrRunTimeout = errors.New("run: timed out")
+
+// RunWithTimeout runs fn with a ctx that ends after d and returns fn's error,
+// or an error wrapping ErrRunTimeout as soon as d passes, or ctx.Err() if the
+// parent ctx ends first. fn runs on its own goroutine so the caller is never
+// held up past the deadline; fn must return once its ctx is done, or that
+// goroutine outlives the call. Wrap functions that cannot watch a ctx with
+// Detach, which makes the leak explicit.
+func RunWithTimeout(ctx context.Context, d time.Duration, fn func(ctx context.Context) error) error {
+	runCtx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+	done := make(chan error, 1) // Buffered so fn's goroutine can exit after we stop listening
+	go func() {
+		done <- fn(runCtx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-runCtx.Done():
+		// fn may have finished at the same moment: prefer its result
+		select {
+		case err = <-done:
+		default:
+			err = runCtx.Err()
+		}
+	}
+	// Our own deadline, whether seen here or passed back by fn, is a timeout
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil && runCtx.Err() != nil {
+		return fmt.Errorf("%w after %v", ErrRunTimeout, d)
+	}
+	return err
+}
+
+// Detach adapts fn, which cannot be interrupted, for RunWithTimeout: the
+// returned function runs fn on another goroutine and gives up waiting when
+// ctx ends, leaving fn to finish in the background. That goroutine is
+// deliberately leaked until fn returns, so use Detach only for calls that do
+// return eventually, such as a blocking read on a connection with its own
+// timeout.
+func Detach(fn func() error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		done := make(chan error, 1)
+		go func() {
+			done <- fn()
+		}()
+		select {
+		case err := <-done:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
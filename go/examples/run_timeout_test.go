@@ -0,0 +1,56 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRunWithTimeout(t *testing.T) {
+	expectNoLeaks(t)
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	errFailed := errors.New("failed")
+	g.Expect(RunWithTimeout(ctx, time.Second, func(context.Context) error { return errFailed })).To(MatchError(errFailed))
+
+	// fn honours its ctx, so after the timeout its goroutine exits too
+	start := time.Now()
+	err := RunWithTimeout(ctx, 20*time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(200 * time.Millisecond) // Slow cleanup the caller does not wait for
+		return ctx.Err()
+	})
+	g.Expect(err).To(MatchError(ErrRunTimeout))
+	g.Expect(time.Since(start)).To(BeNumerically("<", 150*time.Millisecond))
+
+	// A parent ctx that ends first is reported as itself
+	parent, cancel := context.WithCancel(ctx)
+	cancel()
+	err = RunWithTimeout(parent, time.Second, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	g.Expect(err).To(MatchError(context.Canceled))
+	g.Expect(err).NotTo(MatchError(ErrRunTimeout))
+}
+
+func TestRunWithTimeoutDetach(t *testing.T) {
+	expectNoLeaks(t)
+	g := NewWithT(t)
+
+	// A blocking call that ignores ctx; Detach lets the caller give up on it
+	release := make(chan struct{})
+	defer close(release) // Before the leak check, which runs in cleanup
+	blocking := func() error {
+		<-release
+		return nil
+	}
+	err := RunWithTimeout(context.Background(), 10*time.Millisecond, Detach(blocking))
+	g.Expect(err).To(MatchError(ErrRunTimeout))
+	// The detached call keeps running until it returns on its own; the leak
+	// checker would catch it if release were never closed
+}
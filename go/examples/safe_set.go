@@ -0,0 +1,66 @@
+package examples
+
+// SafeSet is a concurrent set built on ShardedMap
+type SafeSet[T comparable] struct {
+	m *ShardedMap[T, struct{}]
+}
+
+// NewSafeSet creates a set containing the given items
+func NewSafeSet[T comparable](items ...T) *SafeSet[T] {
+	s := &SafeSet[T]{m: NewShardedMap[T, struct{}](DefaultShardCount)}
+	for _, item := range items {
+		s.Add(item)
+	}
+	return s
+}
+
+// Add inserts item; returns true if it was not already present
+func (s *SafeSet[T]) Add(item T) bool {
+	return s.m.SetIfAbsent(item, struct{}{})
+}
+
+// Remove deletes item; returns true if it was present
+func (s *SafeSet[T]) Remove(item T) bool {
+	return s.m.Delete(item)
+}
+
+// Contains reports whether item is in the set
+func (s *SafeSet[T]) Contains(item T) bool {
+	_, ok := s.m.Get(item)
+	return ok
+}
+
+// Len returns the number of items
+func (s *SafeSet[T]) Len() int {
+	return s.m.Len()
+}
+
+// Values returns the items in unspecified order
+func (s *SafeSet[T]) Values() []T {
+	values := make([]T, 0, s.Len())
+	s.m.Range(func(item T, _ struct{}) bool {
+		values = append(values, item)
+		return true
+	})
+	return values
+}
+
+// Union returns a new set with the items of both sets
+func (s *SafeSet[T]) Union(other *SafeSet[T]) *SafeSet[T] {
+	result := NewSafeSet(s.Values()...)
+	for _, item := range other.Values() {
+		result.Add(item)
+	}
+	return result
+}
+
+// Intersect returns a new set with the items present in both sets
+func (s *SafeSet[T]) Intersect(other *SafeSet[T]) *SafeSet[T] {
+	result := NewSafeSet[T]()
+	for _, item := range s.Values() {
+		if other.Contains(item) {
+			result.Add(item)
+		}
+	}
+	return result
+}
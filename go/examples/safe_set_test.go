@@ -0,0 +1,87 @@
+package examples
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSafeSet(t *testing.T) {
+	g := NewWithT(t)
+
+	s := NewSafeSet[string]()
+	g.Expect(s.Len()).To(Equal(0))
+
+	// Add reports whether the item was new
+	g.Expect(s.Add("a")).To(BeTrue())
+	g.Expect(s.Add("a")).To(BeFalse())
+	g.Expect(s.Add("b")).To(BeTrue())
+	g.Expect(s.Len()).To(Equal(2))
+	g.Expect(s.Contains("a")).To(BeTrue())
+	g.Expect(s.Contains("z")).To(BeFalse())
+
+	// Remove reports whether the item was present
+	g.Expect(s.Remove("a")).To(BeTrue())
+	g.Expect(s.Remove("a")).To(BeFalse())
+	g.Expect(s.Values()).To(ConsistOf("b"))
+}
+
+func TestSafeSetUnionIntersect(t *testing.T) {
+	g := NewWithT(t)
+
+	left := NewSafeSet(1, 2, 3, 4)
+	right := NewSafeSet(3, 4, 5)
+
+	g.Expect(left.Union(right).Values()).To(ConsistOf(1, 2, 3, 4, 5))
+	g.Expect(left.Intersect(right).Values()).To(ConsistOf(3, 4))
+	g.Expect(left.Intersect(NewSafeSet[int]()).Len()).To(Equal(0))
+
+	// Inputs are untouched
+	g.Expect(left.Len()).To(Equal(4))
+	g.Expect(right.Len()).To(Equal(3))
+}
+
+func TestSafeSetConcurrency(t *testing.T) {
+	g := NewWithT(t)
+
+	s := NewSafeSet[int]()
+	var wg sync.WaitGroup
+	var added sync.Map
+	var duplicates int64
+
+	// Every goroutine races to add the same 1000 items
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				if s.Add(j) {
+					// Only one goroutine may win each item
+					if _, dup := added.LoadOrStore(j, true); dup {
+						atomic.AddInt64(&duplicates, 1)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	g.Expect(duplicates).To(Equal(int64(0)))
+	g.Expect(s.Len()).To(Equal(1000))
+
+	// Concurrent removals of even items
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j += 2 {
+				s.Remove(j)
+			}
+		}()
+	}
+	wg.Wait()
+	g.Expect(s.Len()).To(Equal(500))
+	g.Expect(s.Contains(1)).To(BeTrue())
+	g.Expect(s.Contains(2)).To(BeFalse())
+}
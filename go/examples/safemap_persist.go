@@ -0,0 +1,115 @@
+package examples
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Snapshot returns a consistent copy of the map's contents.
+// Writers are paused for the duration of the copy; readers are not.
+func (sm *SafeMap) Snapshot() map[string]interface{} {
+	sm.snapMu.Lock()
+	defer sm.snapMu.Unlock()
+
+	snap := make(map[string]interface{}, sm.Size())
+	sm.m.Range(func(k, v interface{}) bool {
+		snap[k.(string)] = v
+		return true
+	})
+	return snap
+}
+
+// SaveTo writes a consistent snapshot of the map to w as JSON.
+// Values round-trip as their JSON types (numbers come back as float64).
+func (sm *SafeMap) SaveTo(w io.Writer) error {
+	return json.NewEncoder(w).Encode(sm.Snapshot())
+}
+
+// LoadFrom reads a snapshot written by SaveTo and merges it into the map
+func (sm *SafeMap) LoadFrom(r io.Reader) error {
+	var snap map[string]interface{}
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return err
+	}
+	for k, v := range snap {
+		sm.Set(k, v)
+	}
+	return nil
+}
+
+// SaveFile writes a snapshot to path via a temp file and rename,
+// so a crash mid-write never leaves a truncated checkpoint behind
+func (sm *SafeMap) SaveFile(path string) error {
+	return writeFileAtomic(path, sm.SaveTo)
+}
+
+// writeFileAtomic writes path through a temp file in the same directory and
+// renames it into place, so readers see either the old or the new contents
+func writeFileAtomic(path string, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadFile merges the snapshot stored at path into the map
+func (sm *SafeMap) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return sm.LoadFrom(f)
+}
+
+// StartCheckpoints saves the map to path every interval in a background goroutine.
+// onError (optional) receives failed saves. Checkpointing runs until stop is called;
+// there is no context to cancel it. stop does not interrupt a checkpoint in progress:
+// it waits for that save to finish and the goroutine to exit, then writes a final
+// checkpoint and returns its error. Later calls to stop return the same error.
+func (sm *SafeMap) StartCheckpoints(path string, interval time.Duration, onError func(error)) (stop func() error) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := sm.SaveFile(path); err != nil && onError != nil {
+					onError(err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	var finalErr error
+	return func() error {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+			finalErr = sm.SaveFile(path)
+		})
+		return finalErr
+	}
+}
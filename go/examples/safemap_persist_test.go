@@ -0,0 +1,131 @@
+package examples
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSafeMapSaveAndLoad(t *testing.T) {
+	g := NewWithT(t)
+
+	sm := &SafeMap{}
+	sm.Set("name", "gopher")
+	sm.Set("count", 42)
+	sm.Set("enabled", true)
+
+	var buf bytes.Buffer
+	g.Expect(sm.SaveTo(&buf)).To(Succeed())
+
+	// Restore into a fresh map
+	restored := &SafeMap{}
+	g.Expect(restored.LoadFrom(&buf)).To(Succeed())
+	g.Expect(restored.Size()).To(Equal(int64(3)))
+
+	val, _ := restored.Get("name")
+	g.Expect(val).To(Equal("gopher"))
+	val, _ = restored.Get("count")
+	g.Expect(val).To(Equal(float64(42))) // JSON numbers decode as float64
+	val, _ = restored.Get("enabled")
+	g.Expect(val).To(BeTrue())
+
+	// Garbage input is rejected
+	g.Expect(restored.LoadFrom(strings.NewReader("not json"))).NotTo(Succeed())
+}
+
+func TestSafeMapSnapshotConsistency(t *testing.T) {
+	g := NewWithT(t)
+
+	sm := &SafeMap{}
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+
+	// Writers always move a key pair together: "a_N" is inserted before
+	// "b_N" and deleted after it, so "b_N" never exists without "a_N"
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for j := 0; ; j++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				n := fmt.Sprintf("%d_%d", id, j%32)
+				sm.Set("a_"+n, j)
+				sm.Set("b_"+n, j)
+				sm.Delete("b_" + n)
+				sm.Delete("a_" + n)
+			}
+		}(i)
+	}
+
+	for i := 0; i < 200; i++ {
+		snap := sm.Snapshot()
+		for k := range snap {
+			if strings.HasPrefix(k, "b_") {
+				g.Expect(snap).To(HaveKey("a_" + strings.TrimPrefix(k, "b_")))
+			}
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+}
+
+func TestSafeMapCheckpoints(t *testing.T) {
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "cache.json")
+	sm := &SafeMap{}
+	sm.Set("warm", "value")
+
+	stop := sm.StartCheckpoints(path, 10*time.Millisecond, nil)
+
+	// A periodic checkpoint eventually lands on disk
+	g.Eventually(func() error {
+		return (&SafeMap{}).LoadFile(path)
+	}, "2s", "10ms").Should(Succeed())
+
+	// Stop flushes the latest state
+	sm.Set("late", "write")
+	g.Expect(stop()).To(Succeed())
+	g.Expect(stop()).To(Succeed()) // Idempotent
+
+	// Warm restart
+	restarted := &SafeMap{}
+	g.Expect(restarted.LoadFile(path)).To(Succeed())
+	g.Expect(restarted.Size()).To(Equal(int64(2)))
+	val, ok := restarted.Get("late")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(val).To(Equal("write"))
+}
+
+func TestSafeMapCheckpointErrors(t *testing.T) {
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "missing-dir", "cache.json")
+	sm := &SafeMap{}
+
+	var mu sync.Mutex
+	var errs []error
+	stop := sm.StartCheckpoints(path, 5*time.Millisecond, func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	})
+
+	g.Eventually(func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(errs)
+	}, "2s", "10ms").Should(BeNumerically(">", 0))
+	g.Expect(stop()).NotTo(Succeed())
+}
@@ -0,0 +1,130 @@
+package examples
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrWeightTooLarge is returned by Semaphore.Acquire for a weight above the
+// semaphore's size, which could never be granted
+var ErrWeightTooLarge = errors.New("semaphore: weight exceeds size")
+
+// Semaphore is a weighted semaphore: Acquire takes n units out of a fixed
+// size and Release gives them back. Unlike a buffered channel used as a
+// semaphore, one call can take several units, and waiters are served strictly
+// in arrival order, so a large request is not starved by a stream of small
+// ones that would fit in the gaps.
+type Semaphore struct {
+	mu      sync.Mutex
+	size    int64
+	held    int64
+	waiters list.List // Of *semWaiter, oldest first
+}
+
+type semWaiter struct {
+	n     int64
+	ready chan struct{} // Closed once the units have been granted
+}
+
+// NewSemaphore creates a semaphore with size units
+func NewSemaphore(size int64) *Semaphore {
+	return &Semaphore{size: size}
+}
+
+// Acquire takes n units, blocking until they are free and every earlier
+// waiter has been served, or until ctx is done. On error nothing is held.
+func (s *Semaphore) Acquire(ctx context.Context, n int64) error {
+	s.mu.Lock()
+	if n > s.size {
+		s.mu.Unlock()
+		return ErrWeightTooLarge
+	}
+	if s.held+n <= s.size && s.waiters.Len() == 0 {
+		s.held += n
+		s.mu.Unlock()
+		return nil
+	}
+	w := &semWaiter{n: n, ready: make(chan struct{})}
+	elem := s.waiters.PushBack(w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		select {
+		case <-w.ready:
+			// Granted while we were cancelled: hand the units back
+			s.held -= n
+			s.notifyLocked()
+		default:
+			front := elem == s.waiters.Front()
+			s.waiters.Remove(elem)
+			// Leaving the head of the queue may let the waiters behind us in
+			if front {
+				s.notifyLocked()
+			}
+		}
+		return ctx.Err()
+	}
+}
+
+// TryAcquire takes n units only if they are free right now and nobody is
+// queued ahead, reporting whether it did
+func (s *Semaphore) TryAcquire(n int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.held+n <= s.size && s.waiters.Len() == 0 {
+		s.held += n
+		return true
+	}
+	return false
+}
+
+// Release returns n units and wakes the waiters they let through. Releasing
+// more than is held is a bug in the caller and panics.
+func (s *Semaphore) Release(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n > s.held {
+		panic("semaphore: released more than held")
+	}
+	s.held -= n
+	s.notifyLocked()
+}
+
+// Held returns the units currently held
+func (s *Semaphore) Held() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.held
+}
+
+// QueueLen returns the number of callers blocked in Acquire
+func (s *Semaphore) QueueLen() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.waiters.Len()
+}
+
+// notifyLocked grants units to waiters in order, stopping at the first that
+// does not fit so that later, smaller waiters cannot overtake it
+func (s *Semaphore) notifyLocked() {
+	for {
+		front := s.waiters.Front()
+		if front == nil {
+			return
+		}
+		w := front.Value.(*semWaiter)
+		if s.held+w.n > s.size {
+			return
+		}
+		s.held += w.n
+		s.waiters.Remove(front)
+		close(w.ready)
+	}
+}
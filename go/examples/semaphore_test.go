@@ -0,0 +1,124 @@
+package examples
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSemaphore(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	s := NewSemaphore(10)
+	g.Expect(s.Acquire(ctx, 6)).To(Succeed())
+	g.Expect(s.TryAcquire(4)).To(BeTrue())
+	g.Expect(s.TryAcquire(1)).To(BeFalse())
+	g.Expect(s.Held()).To(Equal(int64(10)))
+
+	s.Release(10)
+	g.Expect(s.Held()).To(BeZero())
+	g.Expect(s.Acquire(ctx, 11)).To(MatchError(ErrWeightTooLarge))
+	g.Expect(func() { s.Release(1) }).To(Panic())
+}
+
+// acquireAsync runs Acquire on its own goroutine, recording the order in which
+// acquisitions succeed
+func acquireAsync(s *Semaphore, n int64, id string, mu *sync.Mutex, order *[]string) {
+	go func() {
+		if s.Acquire(context.Background(), n) == nil {
+			mu.Lock()
+			*order = append(*order, id)
+			mu.Unlock()
+		}
+	}()
+}
+
+func TestSemaphoreFIFO(t *testing.T) {
+	g := NewWithT(t)
+
+	s := NewSemaphore(10)
+	g.Expect(s.TryAcquire(8)).To(BeTrue())
+
+	// A large waiter queues first; a small one behind it must not overtake it
+	// even though 2 units are free
+	var mu sync.Mutex
+	var order []string
+	acquireAsync(s, 5, "large", &mu, &order)
+	g.Eventually(s.QueueLen).Should(Equal(1))
+	acquireAsync(s, 1, "small", &mu, &order)
+	g.Eventually(s.QueueLen).Should(Equal(2))
+	g.Expect(s.TryAcquire(1)).To(BeFalse())
+	g.Consistently(s.Held, 20*time.Millisecond).Should(Equal(int64(8)))
+
+	// Freeing just enough for the large waiter lets only it in
+	s.Release(3)
+	g.Eventually(s.QueueLen).Should(Equal(1))
+	g.Eventually(func() []string { mu.Lock(); defer mu.Unlock(); return append([]string(nil), order...) }).
+		Should(Equal([]string{"large"}))
+	g.Expect(s.Held()).To(Equal(int64(10)))
+
+	s.Release(1)
+	g.Eventually(s.QueueLen).Should(BeZero())
+	g.Eventually(func() []string { mu.Lock(); defer mu.Unlock(); return append([]string(nil), order...) }).
+		Should(Equal([]string{"large", "small"}))
+	g.Expect(s.Held()).To(Equal(int64(10)))
+}
+
+func TestSemaphoreCancelledWaiter(t *testing.T) {
+	g := NewWithT(t)
+
+	s := NewSemaphore(4)
+	g.Expect(s.TryAcquire(3)).To(BeTrue())
+
+	// The head waiter gives up: the one behind it, which fits, is let in
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() { errs <- s.Acquire(ctx, 4) }()
+	g.Eventually(s.QueueLen).Should(Equal(1))
+
+	var mu sync.Mutex
+	var order []string
+	acquireAsync(s, 1, "small", &mu, &order)
+	g.Eventually(s.QueueLen).Should(Equal(2))
+
+	cancel()
+	g.Eventually(errs).Should(Receive(MatchError(context.Canceled)))
+	g.Eventually(s.QueueLen).Should(BeZero())
+	g.Eventually(s.Held).Should(Equal(int64(4)))
+}
+
+func TestSemaphoreLimitsConcurrency(t *testing.T) {
+	g := NewWithT(t)
+
+	s := NewSemaphore(3)
+	var mu sync.Mutex
+	var running, peak int64
+	var wg sync.WaitGroup
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func(n int64) {
+			defer wg.Done()
+			if err := s.Acquire(context.Background(), n); err != nil {
+				return
+			}
+			defer s.Release(n)
+			mu.Lock()
+			running += n
+			if running > peak {
+				peak = running
+			}
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			running -= n
+			mu.Unlock()
+		}(int64(i%3 + 1))
+	}
+	wg.Wait()
+	g.Expect(peak).To(BeNumerically("<=", 3))
+	g.Expect(s.Held()).To(BeZero())
+}
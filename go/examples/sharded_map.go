@@ -0,0 +1,159 @@
+package examples
+
+import (
+	"hash/maphash"
+	"sync"
+)
+
+// DefaultShardCount mirrors the 16 shards used by ShardedCounter in the pitfalls examples
+const DefaultShardCount = 16
+
+type mapShard[K comparable, V any] struct {
+	mu   sync.RWMutex
+	data map[K]V
+}
+
+// ShardedMap spreads keys over independently locked shards to reduce contention.
+// Operations on different shards never wait on each other.
+type ShardedMap[K comparable, V any] struct {
+	seed   maphash.Seed
+	shards []mapShard[K, V]
+}
+
+// NewShardedMap creates a map with the given number of shards (DefaultShardCount if < 1)
+func NewShardedMap[K comparable, V any](shards int) *ShardedMap[K, V] {
+	if shards < 1 {
+		shards = DefaultShardCount
+	}
+	m := &ShardedMap[K, V]{
+		seed:   maphash.MakeSeed(),
+		shards: make([]mapShard[K, V], shards),
+	}
+	for i := range m.shards {
+		m.shards[i].data = make(map[K]V)
+	}
+	return m
+}
+
+func (m *ShardedMap[K, V]) shard(key K) *mapShard[K, V] {
+	h := maphash.Comparable(m.seed, key)
+	return &m.shards[h%uint64(len(m.shards))]
+}
+
+// Get returns the value stored for key
+func (m *ShardedMap[K, V]) Get(key K) (V, bool) {
+	s := m.shard(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.data[key]
+	return v, ok
+}
+
+// Set stores value for key; returns true if the key was newly inserted
+func (m *ShardedMap[K, V]) Set(key K, value V) bool {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, exists := s.data[key]
+	s.data[key] = value
+	return !exists
+}
+
+// SetIfAbsent stores value only if key is missing; returns true if stored
+func (m *ShardedMap[K, V]) SetIfAbsent(key K, value V) bool {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.data[key]; exists {
+		return false
+	}
+	s.data[key] = value
+	return true
+}
+
+// LoadOrStore returns the value stored for key if there is one, and otherwise
+// stores value and returns it; loaded reports which. Unlike SetIfAbsent
+// followed by Get, a concurrent Delete cannot slip in between.
+func (m *ShardedMap[K, V]) LoadOrStore(key K, value V) (actual V, loaded bool) {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v, exists := s.data[key]; exists {
+		return v, true
+	}
+	s.data[key] = value
+	return value, false
+}
+
+// Delete removes key; returns true if it was present
+func (m *ShardedMap[K, V]) Delete(key K) bool {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, exists := s.data[key]
+	delete(s.data, key)
+	return exists
+}
+
+// Len returns the total number of entries, summing shards one at a time
+func (m *ShardedMap[K, V]) Len() int {
+	total := 0
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.RLock()
+		total += len(s.data)
+		s.mu.RUnlock()
+	}
+	return total
+}
+
+// Range calls fn for each entry until fn returns false.
+// It is weakly consistent: each shard is copied under its own lock, so writes
+// to shards not yet visited may or may not be observed. fn may modify the map.
+func (m *ShardedMap[K, V]) Range(fn func(key K, value V) bool) {
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.RLock()
+		keys := make([]K, 0, len(s.data))
+		values := make([]V, 0, len(s.data))
+		for k, v := range s.data {
+			keys = append(keys, k)
+			values = append(values, v)
+		}
+		s.mu.RUnlock()
+
+		for j := range keys {
+			if !fn(keys[j], values[j]) {
+				return
+			}
+		}
+	}
+}
+
+// ConsistentRange calls fn for each entry of a point-in-time view of the map.
+// All shards are read-locked together, always in index order so concurrent
+// ConsistentRange calls cannot deadlock, and copied before any lock is released;
+// fn then iterates the frozen copy, so it may modify the map freely.
+// Writers are blocked while the copy is taken.
+func (m *ShardedMap[K, V]) ConsistentRange(fn func(key K, value V) bool) {
+	for i := range m.shards {
+		m.shards[i].mu.RLock()
+	}
+	keys := make([]K, 0)
+	values := make([]V, 0)
+	for i := range m.shards {
+		for k, v := range m.shards[i].data {
+			keys = append(keys, k)
+			values = append(values, v)
+		}
+	}
+	for i := range m.shards {
+		m.shards[i].mu.RUnlock()
+	}
+
+	for j := range keys {
+		if !fn(keys[j], values[j]) {
+			return
+		}
+	}
+}
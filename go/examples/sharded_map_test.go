@@ -0,0 +1,155 @@
+package examples
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestShardedMap(t *testing.T) {
+	g := NewWithT(t)
+
+	m := NewShardedMap[string, int](4)
+
+	// Insert and overwrite
+	g.Expect(m.Set("a", 1)).To(BeTrue())
+	g.Expect(m.Set("a", 2)).To(BeFalse())
+	g.Expect(m.SetIfAbsent("a", 3)).To(BeFalse())
+	g.Expect(m.SetIfAbsent("b", 4)).To(BeTrue())
+
+	val, ok := m.Get("a")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(val).To(Equal(2))
+	g.Expect(m.Len()).To(Equal(2))
+
+	// Delete
+	g.Expect(m.Delete("a")).To(BeTrue())
+	g.Expect(m.Delete("a")).To(BeFalse())
+	_, ok = m.Get("a")
+	g.Expect(ok).To(BeFalse())
+
+	// Range visits everything and can stop early
+	for i := 0; i < 10; i++ {
+		m.Set(fmt.Sprintf("k%d", i), i)
+	}
+	seen := map[string]int{}
+	m.Range(func(k string, v int) bool {
+		seen[k] = v
+		return true
+	})
+	g.Expect(seen).To(HaveLen(11))
+
+	visited := 0
+	m.Range(func(string, int) bool {
+		visited++
+		return visited < 3
+	})
+	g.Expect(visited).To(Equal(3))
+
+	// Zero shards falls back to the default
+	g.Expect(NewShardedMap[int, int](0).shards).To(HaveLen(DefaultShardCount))
+}
+
+func TestShardedMapLoadOrStore(t *testing.T) {
+	g := NewWithT(t)
+
+	m := NewShardedMap[string, int](4)
+	v, loaded := m.LoadOrStore("a", 1)
+	g.Expect(v).To(Equal(1))
+	g.Expect(loaded).To(BeFalse())
+	v, loaded = m.LoadOrStore("a", 2)
+	g.Expect(v).To(Equal(1))
+	g.Expect(loaded).To(BeTrue())
+}
+
+func TestShardedMapConcurrency(t *testing.T) {
+	g := NewWithT(t)
+
+	m := NewShardedMap[int, int](DefaultShardCount)
+	var wg sync.WaitGroup
+
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				m.Set(id*100+j, j)
+				m.Get(id*100 + j)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	g.Expect(m.Len()).To(Equal(10000))
+}
+
+func TestShardedMapRangeVersusConsistentRange(t *testing.T) {
+	g := NewWithT(t)
+
+	// Inserting during iteration stands in for a racing writer, deterministically
+	insertDuring := func(rangeFn func(*ShardedMap[int, int], func(int, int) bool)) int {
+		m := NewShardedMap[int, int](DefaultShardCount)
+		// Seed the first shard so iteration starts before any shard holding new keys
+		first := -1
+		for m.shard(first) != &m.shards[0] {
+			first--
+		}
+		m.Set(first, 0)
+		seen := 0
+		inserted := false
+		rangeFn(m, func(int, int) bool {
+			if !inserted {
+				for i := 0; i < 1000; i++ {
+					m.Set(i, i)
+				}
+				inserted = true
+			}
+			seen++
+			return true
+		})
+		return seen
+	}
+
+	// Weakly consistent Range observes writes to shards it has not visited yet
+	weak := insertDuring(func(m *ShardedMap[int, int], fn func(int, int) bool) { m.Range(fn) })
+	g.Expect(weak).To(BeNumerically(">", 1))
+
+	// ConsistentRange only ever sees the view from when it started
+	frozen := insertDuring(func(m *ShardedMap[int, int], fn func(int, int) bool) { m.ConsistentRange(fn) })
+	g.Expect(frozen).To(Equal(1))
+}
+
+func TestShardedMapConsistentRangePrefix(t *testing.T) {
+	g := NewWithT(t)
+
+	m := NewShardedMap[int, bool](DefaultShardCount)
+	done := make(chan struct{})
+
+	// A single writer inserts 0, 1, 2, ... in order, so any point-in-time
+	// view must contain exactly the keys 0..n-1 for some n
+	go func() {
+		defer close(done)
+		for i := 0; i < 20000; i++ {
+			m.Set(i, true)
+		}
+	}()
+
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+		count, max := 0, -1
+		m.ConsistentRange(func(k int, _ bool) bool {
+			count++
+			if k > max {
+				max = k
+			}
+			return true
+		})
+		g.Expect(count).To(Equal(max + 1))
+	}
+}
@@ -0,0 +1,157 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// HookResult is the outcome of one shutdown hook
+type HookResult struct {
+	Name     string
+	Duration time.Duration
+	Err      error // The hook's error, or context.DeadlineExceeded if it timed out
+	TimedOut bool
+}
+
+// ShutdownReport lists every hook's result in the order they ran
+type ShutdownReport struct {
+	Hooks []HookResult
+}
+
+// TimedOut returns the names of the hooks that did not finish in time
+func (r ShutdownReport) TimedOut() []string {
+	var names []string
+	for _, h := range r.Hooks {
+		if h.TimedOut {
+			names = append(names, h.Name)
+		}
+	}
+	return names
+}
+
+// Err joins the errors of every hook that failed or timed out, each prefixed
+// with the hook's name, or returns nil if all succeeded
+func (r ShutdownReport) Err() error {
+	var errs []error
+	for _, h := range r.Hooks {
+		if h.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", h.Name, h.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type shutdownHook struct {
+	name    string
+	timeout time.Duration
+	fn      func(ctx context.Context) error
+}
+
+// Shutdown stops a process's components in order. Components register a hook
+// as they start; on SIGINT/SIGTERM or Trigger, hooks run one at a time in
+// reverse registration order, so whatever started last, typically the part
+// facing clients, stops first and the things it depends on stop after it.
+//
+// Each hook gets a ctx that ends after its timeout. A hook that has not
+// returned by then is reported as timed out and left running while the next
+// hook starts, so one stuck component cannot stall the whole shutdown.
+type Shutdown struct {
+	defaultTimeout time.Duration
+
+	mu        sync.Mutex
+	hooks     []shutdownHook
+	trigger   chan struct{}
+	once      sync.Once
+	triggered sync.Once
+	done      chan struct{}
+	report    ShutdownReport
+}
+
+// NewShutdown creates a manager whose hooks get defaultTimeout unless they
+// register their own
+func NewShutdown(defaultTimeout time.Duration) *Shutdown {
+	return &Shutdown{
+		defaultTimeout: defaultTimeout,
+		trigger:        make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+}
+
+// Register adds a hook to run at shutdown with the given timeout, or the
+// default if timeout is zero. Hooks registered once shutdown has begun are
+// not run.
+func (s *Shutdown) Register(name string, timeout time.Duration, fn func(ctx context.Context) error) {
+	if timeout <= 0 {
+		timeout = s.defaultTimeout
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, shutdownHook{name: name, timeout: timeout, fn: fn})
+}
+
+// Trigger asks Wait to begin shutdown, as a signal would; later calls do nothing
+func (s *Shutdown) Trigger() {
+	s.triggered.Do(func() { close(s.trigger) })
+}
+
+// Wait blocks until the process receives SIGINT or SIGTERM, or Trigger is
+// called, then runs the hooks and returns the report
+func (s *Shutdown) Wait() ShutdownReport {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	select {
+	case <-signals:
+	case <-s.trigger:
+	}
+	return s.Run()
+}
+
+// Run runs the hooks now, in reverse registration order, and returns the
+// report. Only the first call runs them; later calls wait for it and return
+// the same report.
+func (s *Shutdown) Run() ShutdownReport {
+	s.once.Do(func() {
+		s.mu.Lock()
+		hooks := s.hooks
+		s.hooks = nil
+		s.mu.Unlock()
+
+		for i := len(hooks) - 1; i >= 0; i-- {
+			s.report.Hooks = append(s.report.Hooks, runHook(hooks[i]))
+		}
+		close(s.done)
+	})
+	<-s.done
+	return s.report
+}
+
+// Done returns a channel that is closed once every hook has run or timed out
+func (s *Shutdown) Done() <-chan struct{} {
+	return s.done
+}
+
+func runHook(h shutdownHook) HookResult {
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+	start := time.Now()
+	result := make(chan error, 1) // Buffered so a late hook can still finish
+	go func() {
+		result <- h.fn(ctx)
+	}()
+
+	r := HookResult{Name: h.name}
+	select {
+	case r.Err = <-result:
+	case <-ctx.Done():
+		r.Err, r.TimedOut = ctx.Err(), true
+	}
+	r.Duration = time.Since(start)
+	return r
+}
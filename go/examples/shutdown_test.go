@@ -0,0 +1,85 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestShutdownReverseOrderAndTimeouts(t *testing.T) {
+	g := NewWithT(t)
+
+	sd := NewShutdown(time.Second)
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, name)
+	}
+	errFlush := errors.New("flush failed")
+
+	sd.Register("reporter", 0, func(context.Context) error {
+		record("reporter")
+		return errFlush
+	})
+	sd.Register("workers", 0, func(context.Context) error {
+		record("workers")
+		return nil
+	})
+	stuck := make(chan struct{})
+	defer close(stuck)
+	sd.Register("http", 20*time.Millisecond, func(ctx context.Context) error {
+		record("http")
+		<-stuck // Ignores ctx, as a wedged component would
+		return nil
+	})
+
+	done := make(chan ShutdownReport, 1)
+	go func() { done <- sd.Wait() }()
+	g.Consistently(sd.Done(), 20*time.Millisecond).ShouldNot(BeClosed())
+	sd.Trigger()
+	sd.Trigger()
+
+	var report ShutdownReport
+	g.Eventually(done).Should(Receive(&report))
+	g.Expect(order).To(Equal([]string{"http", "workers", "reporter"}))
+	g.Expect(report.TimedOut()).To(Equal([]string{"http"}))
+	g.Expect(report.Hooks[0].Err).To(MatchError(context.DeadlineExceeded))
+	g.Expect(report.Hooks[0].Duration).To(BeNumerically(">=", 20*time.Millisecond))
+	g.Expect(report.Hooks[1].Err).NotTo(HaveOccurred())
+	g.Expect(report.Err()).To(MatchError(errFlush))
+	g.Expect(report.Err()).To(MatchError(ContainSubstring("reporter: flush failed")))
+	g.Expect(report.Err()).To(MatchError(ContainSubstring("http: context deadline exceeded")))
+
+	// Hooks ran once; a second Run returns the same report
+	g.Expect(sd.Run()).To(Equal(report))
+	g.Expect(order).To(HaveLen(3))
+}
+
+func TestShutdownIntegratesComponents(t *testing.T) {
+	g := NewWithT(t)
+
+	m := &Metrics{}
+	var reports []MetricsSnapshot
+	reporter := NewReporter(m, time.Hour, nil, func(s MetricsSnapshot) { reports = append(reports, s) })
+	reporter.Start()
+	worker := NewWorker()
+	worker.Start()
+
+	sd := NewShutdown(time.Second)
+	sd.Register("reporter", 0, func(context.Context) error { reporter.Stop(); return nil })
+	sd.Register("worker", 0, func(context.Context) error { worker.Stop(); return nil })
+
+	m.RecordRequest()
+	report := sd.Run()
+	g.Expect(report.Err()).NotTo(HaveOccurred())
+	g.Expect(worker.IsRunning()).To(BeFalse())
+	// Stopping the reporter flushed the request recorded before shutdown
+	g.Expect(reports).To(HaveLen(1))
+	g.Expect(reports[0].Counters[CounterRequests]).To(Equal(int64(1)))
+}
@@ -0,0 +1,44 @@
+package examples
+
+import "sync/atomic"
+
+// Signal notifies any number of goroutines that some state changed, like
+// sync.Cond.Broadcast but through a channel, so a waiter can select on it
+// together with ctx.Done() or a timer. Wait returns the current channel and
+// Broadcast swaps in a fresh one and closes the old, waking everyone who
+// fetched it. The zero value is ready to use.
+//
+// To avoid missing a change, fetch the channel before checking the
+// condition, then wait on it only if the condition was false:
+//
+//	for {
+//		ch := s.Wait()
+//		if ready() {
+//			break
+//		}
+//		<-ch
+//	}
+type Signal struct {
+	ch atomic.Pointer[chan struct{}]
+}
+
+// Wait returns a channel that is closed by the next Broadcast
+func (s *Signal) Wait() <-chan struct{} {
+	for {
+		if p := s.ch.Load(); p != nil {
+			return *p
+		}
+		ch := make(chan struct{})
+		if s.ch.CompareAndSwap(nil, &ch) {
+			return ch
+		}
+	}
+}
+
+// Broadcast wakes every goroutine waiting on a channel from Wait
+func (s *Signal) Broadcast() {
+	ch := make(chan struct{})
+	if old := s.ch.Swap(&ch); old != nil {
+		close(*old)
+	}
+}
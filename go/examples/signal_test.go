@@ -0,0 +1,66 @@
+package examples
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSignalBroadcast(t *testing.T) {
+	g := NewWithT(t)
+
+	var s Signal
+	first := s.Wait()
+	g.Expect(s.Wait()).To(Equal(first)) // Same round until a Broadcast
+	g.Consistently(first, 10*time.Millisecond).ShouldNot(BeClosed())
+
+	s.Broadcast()
+	g.Expect(first).To(BeClosed())
+	second := s.Wait()
+	g.Expect(second).NotTo(BeClosed())
+
+	// Back-to-back Broadcasts never close a channel twice, and the next
+	// round always starts open
+	s.Broadcast()
+	s.Broadcast()
+	g.Expect(second).To(BeClosed())
+	g.Expect(s.Wait()).NotTo(BeClosed())
+}
+
+func TestSignalNoLostWakeups(t *testing.T) {
+	g := NewWithT(t)
+
+	// Waiters follow the fetch-then-check pattern against a counter the
+	// broadcaster bumps; all must observe the final value
+	var s Signal
+	var state atomic.Int64
+	const waiters, updates = 16, 200
+	var wg sync.WaitGroup
+	for w := 0; w < waiters; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				ch := s.Wait()
+				if state.Load() == updates {
+					return
+				}
+				<-ch
+			}
+		}()
+	}
+	for i := 0; i < updates; i++ {
+		state.Add(1)
+		s.Broadcast()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	g.Eventually(done).Should(BeClosed())
+}
@@ -0,0 +1,261 @@
+package examples
+
+import (
+	"cmp"
+	"math/bits"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// skipListMaxLevel bounds tower height; 2^16 expected entries before towers saturate
+const skipListMaxLevel = 16
+
+// skipNode is one tower in the skip list. next pointers are read without locks;
+// the node mutex is only taken by writers linking or unlinking around it.
+type skipNode[K cmp.Ordered, V any] struct {
+	key         K
+	value       atomic.Pointer[V]
+	next        []atomic.Pointer[skipNode[K, V]]
+	mu          sync.Mutex
+	marked      int32 // Logically deleted
+	fullyLinked int32 // Linked at every level of its tower
+}
+
+func newSkipNode[K cmp.Ordered, V any](key K, value V, level int) *skipNode[K, V] {
+	n := &skipNode[K, V]{key: key, next: make([]atomic.Pointer[skipNode[K, V]], level)}
+	n.value.Store(&value)
+	return n
+}
+
+func (n *skipNode[K, V]) isMarked() bool {
+	return atomic.LoadInt32(&n.marked) == 1
+}
+
+func (n *skipNode[K, V]) isFullyLinked() bool {
+	return atomic.LoadInt32(&n.fullyLinked) == 1
+}
+
+func (n *skipNode[K, V]) topLevel() int {
+	return len(n.next)
+}
+
+// ConcurrentSkipListMap is an ordered map implemented as a lazy skip list
+// (Herlihy & Shavit): Get and range scans never lock, while Put and Delete
+// lock only the predecessor nodes they splice, validating after locking.
+type ConcurrentSkipListMap[K cmp.Ordered, V any] struct {
+	head   *skipNode[K, V]
+	length int64
+}
+
+// NewConcurrentSkipListMap creates an empty ordered map
+func NewConcurrentSkipListMap[K cmp.Ordered, V any]() *ConcurrentSkipListMap[K, V] {
+	var zeroK K
+	var zeroV V
+	return &ConcurrentSkipListMap[K, V]{head: newSkipNode(zeroK, zeroV, skipListMaxLevel)}
+}
+
+// randomSkipLevel picks a tower height with P(level > n) = 1/2^n
+func randomSkipLevel() int {
+	level := 1 + bits.TrailingZeros64(rand.Uint64())
+	if level > skipListMaxLevel {
+		level = skipListMaxLevel
+	}
+	return level
+}
+
+// find fills preds/succs for key at every level and returns the highest
+// level at which key was found, or -1
+func (m *ConcurrentSkipListMap[K, V]) find(key K, preds, succs []*skipNode[K, V]) int {
+	found := -1
+	pred := m.head
+	for level := skipListMaxLevel - 1; level >= 0; level-- {
+		curr := pred.next[level].Load()
+		for curr != nil && curr.key < key {
+			pred = curr
+			curr = pred.next[level].Load()
+		}
+		if found == -1 && curr != nil && curr.key == key {
+			found = level
+		}
+		preds[level] = pred
+		succs[level] = curr
+	}
+	return found
+}
+
+// unlockPreds releases the distinct predecessors locked at levels [0, highest]
+func unlockPreds[K cmp.Ordered, V any](preds []*skipNode[K, V], highest int) {
+	var prev *skipNode[K, V]
+	for level := 0; level <= highest; level++ {
+		if preds[level] != prev {
+			preds[level].mu.Unlock()
+			prev = preds[level]
+		}
+	}
+}
+
+// Get returns the value for key without taking any locks
+func (m *ConcurrentSkipListMap[K, V]) Get(key K) (V, bool) {
+	pred := m.head
+	for level := skipListMaxLevel - 1; level >= 0; level-- {
+		curr := pred.next[level].Load()
+		for curr != nil && curr.key < key {
+			pred = curr
+			curr = pred.next[level].Load()
+		}
+		if curr != nil && curr.key == key {
+			if curr.isFullyLinked() && !curr.isMarked() {
+				return *curr.value.Load(), true
+			}
+			break
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Put stores value for key; returns true if the key was newly inserted
+func (m *ConcurrentSkipListMap[K, V]) Put(key K, value V) bool {
+	topLevel := randomSkipLevel()
+	var preds, succs [skipListMaxLevel]*skipNode[K, V]
+
+	for {
+		if found := m.find(key, preds[:], succs[:]); found != -1 {
+			node := succs[found]
+			if !node.isMarked() {
+				// Another Put may still be linking this node
+				for !node.isFullyLinked() {
+					runtime.Gosched()
+				}
+				node.value.Store(&value)
+				return false
+			}
+			// Being deleted: retry until it is unlinked
+			continue
+		}
+
+		// Lock predecessors bottom-up and check nothing changed since find
+		highestLocked := -1
+		var prevPred *skipNode[K, V]
+		valid := true
+		for level := 0; valid && level < topLevel; level++ {
+			pred, succ := preds[level], succs[level]
+			if pred != prevPred {
+				pred.mu.Lock()
+				highestLocked = level
+				prevPred = pred
+			}
+			valid = !pred.isMarked() && (succ == nil || !succ.isMarked()) &&
+				pred.next[level].Load() == succ
+		}
+		if !valid {
+			unlockPreds(preds[:], highestLocked)
+			continue
+		}
+
+		node := newSkipNode(key, value, topLevel)
+		for level := 0; level < topLevel; level++ {
+			node.next[level].Store(succs[level])
+		}
+		for level := 0; level < topLevel; level++ {
+			preds[level].next[level].Store(node)
+		}
+		atomic.StoreInt32(&node.fullyLinked, 1)
+		unlockPreds(preds[:], highestLocked)
+		atomic.AddInt64(&m.length, 1)
+		return true
+	}
+}
+
+// Delete removes key; returns true if this call removed it
+func (m *ConcurrentSkipListMap[K, V]) Delete(key K) bool {
+	var preds, succs [skipListMaxLevel]*skipNode[K, V]
+	var victim *skipNode[K, V]
+	isMarked := false
+
+	for {
+		found := m.find(key, preds[:], succs[:])
+		if !isMarked {
+			if found == -1 {
+				return false
+			}
+			victim = succs[found]
+			// Only delete nodes that are completely inserted, found at their top level
+			if !victim.isFullyLinked() || victim.topLevel()-1 != found || victim.isMarked() {
+				return false
+			}
+			victim.mu.Lock()
+			if victim.isMarked() {
+				victim.mu.Unlock()
+				return false
+			}
+			// Logical deletion: from here on the key is gone
+			atomic.StoreInt32(&victim.marked, 1)
+			isMarked = true
+		}
+
+		highestLocked := -1
+		var prevPred *skipNode[K, V]
+		valid := true
+		for level := 0; valid && level < victim.topLevel(); level++ {
+			pred := preds[level]
+			if pred != prevPred {
+				pred.mu.Lock()
+				highestLocked = level
+				prevPred = pred
+			}
+			valid = !pred.isMarked() && pred.next[level].Load() == victim
+		}
+		if !valid {
+			unlockPreds(preds[:], highestLocked)
+			continue
+		}
+
+		// Physical deletion, top-down so the tower never has a gap below a link
+		for level := victim.topLevel() - 1; level >= 0; level-- {
+			preds[level].next[level].Store(victim.next[level].Load())
+		}
+		victim.mu.Unlock()
+		unlockPreds(preds[:], highestLocked)
+		atomic.AddInt64(&m.length, -1)
+		return true
+	}
+}
+
+// Len returns the number of entries
+func (m *ConcurrentSkipListMap[K, V]) Len() int {
+	return int(atomic.LoadInt64(&m.length))
+}
+
+// Range calls fn in key order for every entry with from <= key < to, until fn returns false.
+// Like sync.Map.Range it is weakly consistent and does not block writers.
+func (m *ConcurrentSkipListMap[K, V]) Range(from, to K, fn func(key K, value V) bool) {
+	pred := m.head
+	for level := skipListMaxLevel - 1; level >= 0; level-- {
+		curr := pred.next[level].Load()
+		for curr != nil && curr.key < from {
+			pred = curr
+			curr = pred.next[level].Load()
+		}
+	}
+	for curr := pred.next[0].Load(); curr != nil && curr.key < to; curr = curr.next[0].Load() {
+		if curr.isFullyLinked() && !curr.isMarked() {
+			if !fn(curr.key, *curr.value.Load()) {
+				return
+			}
+		}
+	}
+}
+
+// Ascend calls fn for every entry in key order until fn returns false
+func (m *ConcurrentSkipListMap[K, V]) Ascend(fn func(key K, value V) bool) {
+	for curr := m.head.next[0].Load(); curr != nil; curr = curr.next[0].Load() {
+		if curr.isFullyLinked() && !curr.isMarked() {
+			if !fn(curr.key, *curr.value.Load()) {
+				return
+			}
+		}
+	}
+}
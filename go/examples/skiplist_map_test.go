@@ -0,0 +1,173 @@
+package examples
+
+import (
+	"sort"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestConcurrentSkipListMap(t *testing.T) {
+	g := NewWithT(t)
+
+	m := NewConcurrentSkipListMap[int, string]()
+	g.Expect(m.Len()).To(Equal(0))
+
+	// Insert out of order
+	for _, k := range []int{5, 1, 9, 3, 7} {
+		g.Expect(m.Put(k, "v")).To(BeTrue())
+	}
+	g.Expect(m.Len()).To(Equal(5))
+
+	// Overwrite keeps the length
+	g.Expect(m.Put(3, "three")).To(BeFalse())
+	val, ok := m.Get(3)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(val).To(Equal("three"))
+	g.Expect(m.Len()).To(Equal(5))
+
+	// Missing keys
+	_, ok = m.Get(4)
+	g.Expect(ok).To(BeFalse())
+
+	// Delete
+	g.Expect(m.Delete(9)).To(BeTrue())
+	g.Expect(m.Delete(9)).To(BeFalse())
+	g.Expect(m.Delete(100)).To(BeFalse())
+	_, ok = m.Get(9)
+	g.Expect(ok).To(BeFalse())
+	g.Expect(m.Len()).To(Equal(4))
+}
+
+func TestConcurrentSkipListMapRange(t *testing.T) {
+	g := NewWithT(t)
+
+	m := NewConcurrentSkipListMap[int, int]()
+	for i := 20; i > 0; i-- {
+		m.Put(i*10, i)
+	}
+
+	// Half-open range [50, 100)
+	var keys []int
+	m.Range(50, 100, func(k, _ int) bool {
+		keys = append(keys, k)
+		return true
+	})
+	g.Expect(keys).To(Equal([]int{50, 60, 70, 80, 90}))
+
+	// Bounds need not exist in the map
+	keys = nil
+	m.Range(55, 85, func(k, _ int) bool {
+		keys = append(keys, k)
+		return true
+	})
+	g.Expect(keys).To(Equal([]int{60, 70, 80}))
+
+	// Early stop
+	keys = nil
+	m.Range(0, 1000, func(k, _ int) bool {
+		keys = append(keys, k)
+		return len(keys) < 3
+	})
+	g.Expect(keys).To(Equal([]int{10, 20, 30}))
+
+	// Full ascending iteration
+	keys = nil
+	m.Ascend(func(k, _ int) bool {
+		keys = append(keys, k)
+		return true
+	})
+	g.Expect(keys).To(HaveLen(20))
+	g.Expect(sort.IntsAreSorted(keys)).To(BeTrue())
+}
+
+func TestConcurrentSkipListMapConcurrency(t *testing.T) {
+	g := NewWithT(t)
+
+	m := NewConcurrentSkipListMap[int, int]()
+	var wg sync.WaitGroup
+
+	// Writers contend on a shared key space; writer id also deletes
+	// the keys with i%16 == id, so keys with i%16 >= 8 are never deleted
+	for id := 0; id < 8; id++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				m.Put(i, id)
+			}
+			for i := id; i < 2000; i += 16 {
+				m.Delete(i)
+			}
+		}(id)
+	}
+
+	// Readers scan while writers run; every scan must be sorted and duplicate-free
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 50; n++ {
+				prev := -1
+				m.Ascend(func(k, _ int) bool {
+					if k <= prev {
+						t.Errorf("out of order: %d after %d", k, prev)
+						return false
+					}
+					prev = k
+					return true
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Deleted keys may have been re-inserted by slower writers, so only
+	// check the length invariant and the keys nobody deletes
+	count := 0
+	m.Ascend(func(k, _ int) bool {
+		count++
+		return true
+	})
+	g.Expect(m.Len()).To(Equal(count))
+	for i := 0; i < 2000; i++ {
+		if i%16 >= 8 {
+			_, ok := m.Get(i)
+			g.Expect(ok).To(BeTrue())
+		}
+	}
+}
+
+func TestConcurrentSkipListMapDeleteRace(t *testing.T) {
+	g := NewWithT(t)
+
+	m := NewConcurrentSkipListMap[int, int]()
+	for i := 0; i < 1000; i++ {
+		m.Put(i, i)
+	}
+
+	// Many goroutines race to delete the same keys; each key is deleted once
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	deleted := 0
+	for n := 0; n < 8; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			local := 0
+			for i := 0; i < 1000; i++ {
+				if m.Delete(i) {
+					local++
+				}
+			}
+			mu.Lock()
+			deleted += local
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	g.Expect(deleted).To(Equal(1000))
+	g.Expect(m.Len()).To(Equal(0))
+}
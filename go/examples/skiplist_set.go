@@ -0,0 +1,47 @@
+package examples
+
+import "cmp"
+
+// ConcurrentSkipListSet is an ordered set backed by ConcurrentSkipListMap, so
+// lookups and ordered scans take no locks and updates lock only the nodes
+// they splice
+type ConcurrentSkipListSet[K cmp.Ordered] struct {
+	m *ConcurrentSkipListMap[K, struct{}]
+}
+
+// NewConcurrentSkipListSet creates an empty set
+func NewConcurrentSkipListSet[K cmp.Ordered]() *ConcurrentSkipListSet[K] {
+	return &ConcurrentSkipListSet[K]{m: NewConcurrentSkipListMap[K, struct{}]()}
+}
+
+// Add inserts key, reporting whether it was newly added
+func (s *ConcurrentSkipListSet[K]) Add(key K) bool {
+	return s.m.Put(key, struct{}{})
+}
+
+// Remove deletes key, reporting whether this call removed it
+func (s *ConcurrentSkipListSet[K]) Remove(key K) bool {
+	return s.m.Delete(key)
+}
+
+// Contains reports whether key is present
+func (s *ConcurrentSkipListSet[K]) Contains(key K) bool {
+	_, ok := s.m.Get(key)
+	return ok
+}
+
+// Len returns the number of keys
+func (s *ConcurrentSkipListSet[K]) Len() int {
+	return s.m.Len()
+}
+
+// Ascend calls fn for every key in order until fn returns false. It is weakly
+// consistent, like ConcurrentSkipListMap.Ascend.
+func (s *ConcurrentSkipListSet[K]) Ascend(fn func(key K) bool) {
+	s.m.Ascend(func(key K, _ struct{}) bool { return fn(key) })
+}
+
+// Range calls fn in order for every key with from <= key < to, until fn returns false
+func (s *ConcurrentSkipListSet[K]) Range(from, to K, fn func(key K) bool) {
+	s.m.Range(from, to, func(key K, _ struct{}) bool { return fn(key) })
+}
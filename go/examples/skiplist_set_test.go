@@ -0,0 +1,152 @@
+package examples
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestConcurrentSkipListSet(t *testing.T) {
+	g := NewWithT(t)
+
+	s := NewConcurrentSkipListSet[int]()
+	for _, k := range []int{4, 2, 8, 6} {
+		g.Expect(s.Add(k)).To(BeTrue())
+	}
+	g.Expect(s.Add(4)).To(BeFalse())
+	g.Expect(s.Contains(6)).To(BeTrue())
+	g.Expect(s.Remove(6)).To(BeTrue())
+	g.Expect(s.Remove(6)).To(BeFalse())
+	g.Expect(s.Len()).To(Equal(3))
+
+	var keys []int
+	s.Ascend(func(k int) bool { keys = append(keys, k); return true })
+	g.Expect(keys).To(Equal([]int{2, 4, 8}))
+
+	keys = nil
+	s.Range(3, 8, func(k int) bool { keys = append(keys, k); return true })
+	g.Expect(keys).To(Equal([]int{4}))
+}
+
+func TestConcurrentSkipListSetOrderedUnderWrites(t *testing.T) {
+	g := NewWithT(t)
+
+	s := NewConcurrentSkipListSet[int]()
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(int64(w)))
+			for i := 0; i < 2000; i++ {
+				k := rng.Intn(500)
+				if rng.Intn(2) == 0 {
+					s.Add(k)
+				} else {
+					s.Remove(k)
+				}
+			}
+		}(w)
+	}
+
+	// Scans running alongside the writers always see strictly ascending keys
+	for i := 0; i < 50; i++ {
+		prev := -1
+		s.Ascend(func(k int) bool {
+			g.Expect(k).To(BeNumerically(">", prev))
+			prev = k
+			return true
+		})
+	}
+	wg.Wait()
+}
+
+// mutexTree is the baseline for the skip list benchmarks: an unbalanced binary
+// search tree under one mutex, kept shallow by random insertion order
+type mutexTree[K cmp.Ordered] struct {
+	mu   sync.RWMutex
+	root *treeNode[K]
+}
+
+type treeNode[K cmp.Ordered] struct {
+	key         K
+	left, right *treeNode[K]
+}
+
+func (t *mutexTree[K]) Add(key K) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	link := &t.root
+	for *link != nil {
+		switch c := cmp.Compare(key, (*link).key); {
+		case c == 0:
+			return false
+		case c < 0:
+			link = &(*link).left
+		default:
+			link = &(*link).right
+		}
+	}
+	*link = &treeNode[K]{key: key}
+	return true
+}
+
+func (t *mutexTree[K]) Contains(key K) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for n := t.root; n != nil; {
+		switch c := cmp.Compare(key, n.key); {
+		case c == 0:
+			return true
+		case c < 0:
+			n = n.left
+		default:
+			n = n.right
+		}
+	}
+	return false
+}
+
+// BenchmarkOrderedSet mixes lookups and inserts over a preloaded set, at
+// several write percentages
+func BenchmarkOrderedSet(b *testing.B) {
+	type set interface {
+		Add(int) bool
+		Contains(int) bool
+	}
+	impls := []struct {
+		name string
+		new  func() set
+	}{
+		{"SkipList", func() set { return NewConcurrentSkipListSet[int]() }},
+		{"MutexTree", func() set { return &mutexTree[int]{} }},
+	}
+	const keySpace = 1 << 16
+	for _, writePct := range []int{1, 10, 50} {
+		for _, impl := range impls {
+			b.Run(fmt.Sprintf("writes=%d%%/%s", writePct, impl.name), func(b *testing.B) {
+				s := impl.new()
+				rng := rand.New(rand.NewSource(1))
+				for i := 0; i < keySpace/2; i++ {
+					s.Add(rng.Intn(keySpace))
+				}
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					rng := rand.New(rand.NewSource(rand.Int63()))
+					for pb.Next() {
+						k := rng.Intn(keySpace)
+						if rng.Intn(100) < writePct {
+							s.Add(k)
+						} else {
+							s.Contains(k)
+						}
+					}
+				})
+			})
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package examples
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// SnapshotCell publishes values from a single writer to any number of readers.
+// Publish never waits and Load never locks, yet a reader always gets one
+// whole published value, never half of one and half of the next.
+//
+// The cell keeps two buffers. The writer fills the one readers are not being
+// pointed at, then flips version to it, so a reader copying the current
+// buffer is only disturbed if the writer publishes twice during its copy.
+// Each buffer carries a sequence number, odd while it is being written, and
+// the version it holds: a reader that sees the sequence change across its
+// copy retries, and one that was lapped still returns the value with its own
+// version. Unlike RCUCell, a publish allocates nothing.
+//
+// Publish must only be called from one goroutine at a time. Values that hold
+// maps or slices must not be mutated once published.
+//
+// The speculative copy is a data race by the letter of the memory model, made
+// safe by the validation, and the race detector would report it. Under -race
+// the cell publishes heap copies through an atomic pointer instead.
+type SnapshotCell[T any] struct {
+	version atomic.Uint64 // Count of publishes; version%2 is the current buffer
+	bufs    [2]snapshotBuf[T]
+
+	raced atomic.Pointer[racedSnapshot[T]] // Used instead of bufs when raceEnabled
+}
+
+type racedSnapshot[T any] struct {
+	v       T
+	version uint64
+}
+
+type snapshotBuf[T any] struct {
+	seq     atomic.Uint64 // Odd while the writer is filling the buffer
+	version uint64        // Which publish v is; written inside the seq window
+	v       T
+	_       [cacheLinePad]byte
+}
+
+// Publish makes v the current value
+func (c *SnapshotCell[T]) Publish(v T) {
+	n := c.version.Load() + 1
+	if raceEnabled {
+		c.raced.Store(&racedSnapshot[T]{v, n})
+		c.version.Store(n)
+		return
+	}
+	b := &c.bufs[n%2]
+	b.seq.Add(1)
+	b.version = n
+	b.v = v
+	b.seq.Add(1)
+	c.version.Store(n)
+}
+
+// Load returns the current value and its version, the number of publishes so
+// far. Version 0 means nothing has been published and the value is T's zero.
+func (c *SnapshotCell[T]) Load() (T, uint64) {
+	if raceEnabled {
+		if p := c.raced.Load(); p != nil {
+			return p.v, p.version
+		}
+		var zero T
+		return zero, 0
+	}
+	for {
+		b := &c.bufs[c.version.Load()%2]
+		seq := b.seq.Load()
+		if seq%2 == 0 {
+			// May overlap a write, which the seq check below detects
+			v, n := b.v, b.version
+			if b.seq.Load() == seq {
+				return v, n
+			}
+		}
+		runtime.Gosched() // Lapped by the writer: let it finish
+	}
+}
@@ -0,0 +1,102 @@
+package examples
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSnapshotCell(t *testing.T) {
+	g := NewWithT(t)
+
+	var c SnapshotCell[string]
+	v, version := c.Load()
+	g.Expect(v).To(BeEmpty())
+	g.Expect(version).To(BeZero())
+
+	c.Publish("a")
+	c.Publish("b")
+	v, version = c.Load()
+	g.Expect(v).To(Equal("b"))
+	g.Expect(version).To(Equal(uint64(2)))
+}
+
+// TestSnapshotCellPublishAllocs checks the double buffer's point: publishing
+// reuses the two buffers instead of allocating, except under -race, where
+// the cell falls back to heap copies
+func TestSnapshotCellPublishAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the -race fallback allocates on every publish")
+	}
+	g := NewWithT(t)
+
+	var c SnapshotCell[wideValue]
+	g.Expect(testing.AllocsPerRun(100, func() {
+		c.Publish(wideValue{a: 1})
+	})).To(BeZero())
+}
+
+// wideValue is too big to copy in one instruction, so a torn read would show
+// up as fields that disagree
+type wideValue struct {
+	a, b, c, d, e, f, g, h int
+}
+
+func TestSnapshotCellNeverTorn(t *testing.T) {
+	g := NewWithT(t)
+
+	var c SnapshotCell[wideValue]
+	const publishes = 20000
+	go func() {
+		for i := 1; i <= publishes; i++ {
+			c.Publish(wideValue{i, i, i, i, i, i, i, i})
+		}
+	}()
+
+	var wg sync.WaitGroup
+	torn := make(chan wideValue, 4)
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var lastVersion uint64
+			for lastVersion < publishes {
+				v, version := c.Load()
+				if v != (wideValue{v.a, v.a, v.a, v.a, v.a, v.a, v.a, v.a}) || uint64(v.a) != version || version < lastVersion {
+					torn <- v
+					return
+				}
+				lastVersion = version
+			}
+		}()
+	}
+	wg.Wait()
+	close(torn)
+	g.Expect(torn).To(BeEmpty())
+}
+
+func BenchmarkSnapshotCellLoad(b *testing.B) {
+	var c SnapshotCell[wideValue]
+	c.Publish(wideValue{a: 1})
+	stop := make(chan struct{})
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+				c.Publish(wideValue{a: i})
+			}
+		}
+	}()
+	b.RunParallel(func(pb *testing.PB) {
+		var sink int
+		for pb.Next() {
+			v, _ := c.Load()
+			sink += v.a
+		}
+		_ = sink
+	})
+	close(stop)
+}
@@ -0,0 +1,58 @@
+package examples
+
+import "sync/atomic"
+
+// SPSCRing is a bounded queue for exactly one producer goroutine and one
+// consumer goroutine. With a single writer per index it needs no CAS at all:
+// the producer writes the slot then publishes the tail, and the consumer
+// reads the slot then publishes the head, each a plain atomic store. It is
+// the fastest queue here when the shape of the pipeline allows it, and
+// incorrect with more than one goroutine on either side.
+type SPSCRing[T any] struct {
+	buf  []T
+	mask uint64
+	_    [cacheLinePad]byte
+	head atomic.Uint64 // Next position to read; written only by the consumer
+	_    [cacheLinePad]byte
+	tail atomic.Uint64 // Next position to write; written only by the producer
+	_    [cacheLinePad]byte
+}
+
+// NewSPSCRing creates a ring of capacity slots, rounded up to a power of two
+func NewSPSCRing[T any](capacity int) *SPSCRing[T] {
+	n := 1
+	for n < capacity {
+		n <<= 1
+	}
+	return &SPSCRing[T]{buf: make([]T, n), mask: uint64(n - 1)}
+}
+
+// TryEnqueue adds v, or reports false if the ring is full. Producer only.
+func (r *SPSCRing[T]) TryEnqueue(v T) bool {
+	tail := r.tail.Load()
+	if tail-r.head.Load() == uint64(len(r.buf)) {
+		return false
+	}
+	r.buf[tail&r.mask] = v
+	r.tail.Store(tail + 1)
+	return true
+}
+
+// TryDequeue removes the oldest item, or reports false if the ring is empty.
+// Consumer only.
+func (r *SPSCRing[T]) TryDequeue() (T, bool) {
+	head := r.head.Load()
+	var zero T
+	if head == r.tail.Load() {
+		return zero, false
+	}
+	v := r.buf[head&r.mask]
+	r.buf[head&r.mask] = zero
+	r.head.Store(head + 1)
+	return v, true
+}
+
+// Cap returns the capacity
+func (r *SPSCRing[T]) Cap() int {
+	return len(r.buf)
+}
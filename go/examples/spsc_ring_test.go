@@ -0,0 +1,49 @@
+package examples
+
+import (
+	"runtime"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSPSCRing(t *testing.T) {
+	g := NewWithT(t)
+
+	r := NewSPSCRing[string](2)
+	g.Expect(r.TryEnqueue("a")).To(BeTrue())
+	g.Expect(r.TryEnqueue("b")).To(BeTrue())
+	g.Expect(r.TryEnqueue("c")).To(BeFalse())
+	v, ok := r.TryDequeue()
+	g.Expect(ok).To(BeTrue())
+	g.Expect(v).To(Equal("a"))
+	g.Expect(r.TryEnqueue("c")).To(BeTrue())
+	v, _ = r.TryDequeue()
+	g.Expect(v).To(Equal("b"))
+	v, _ = r.TryDequeue()
+	g.Expect(v).To(Equal("c"))
+	_, ok = r.TryDequeue()
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestSPSCRingInOrder(t *testing.T) {
+	g := NewWithT(t)
+
+	r := NewSPSCRing[int](8)
+	const n = 10000
+	go func() {
+		for i := 0; i < n; i++ {
+			for !r.TryEnqueue(i) {
+				runtime.Gosched()
+			}
+		}
+	}()
+	for want := 0; want < n; {
+		if v, ok := r.TryDequeue(); ok {
+			g.Expect(v).To(Equal(want))
+			want++
+		} else {
+			runtime.Gosched()
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package examples
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// StatsTracker counts requests, errors and latency. Latency is kept as a
+// running total plus an AtomicHistogram of its distribution rather than a
+// slice of every sample, so memory stays constant however many requests are
+// recorded and percentiles come from every request, not a sample. Setting
+// StatsTrackerOptions.SampleSize also keeps a fixed-size reservoir sample,
+// which Percentile then reads instead, for exact values from a uniform sample
+// rather than bucket-width estimates.
+//
+// Writers serialise on a mutex, but GetStats takes no lock at all: it reads
+// under a sequence lock. Each writer makes seq odd before updating and even
+// again after, and a reader that sees seq odd, or changed across its reads,
+// retries. Readers therefore never block writers, however many poll, and
+// still never see a request counted without its latency. The counters are
+// atomics only so the race detector accepts the optimistic reads.
+type StatsTracker struct {
+	mu           sync.Mutex    // Serialises writers
+	seq          atomic.Uint64 // Odd while a writer is updating
+	requests     atomic.Int64
+	errors       atomic.Int64
+	totalLatency atomic.Int64
+	latencies    *AtomicHistogram  // Atomic on its own, so outside the seqlock
+	sample       *ReservoirSampler // Nil unless SampleSize is set; guarded by mu
+}
+
+// StatsTrackerOptions configures how StatsTracker keeps latencies
+type StatsTrackerOptions struct {
+	// Bounds are the histogram bucket bounds in nanoseconds
+	// (defaults to DefaultLatencyBounds)
+	Bounds []int64
+
+	// SampleSize, if positive, also keeps a reservoir of at most this many
+	// latencies and makes Percentile read from it
+	SampleSize int
+}
+
+// NewStatsTracker creates a tracker configured by opts
+func NewStatsTracker(opts StatsTrackerOptions) *StatsTracker {
+	if opts.Bounds == nil {
+		opts.Bounds = DefaultLatencyBounds
+	}
+	s := &StatsTracker{latencies: NewAtomicHistogram(opts.Bounds)}
+	if opts.SampleSize > 0 {
+		s.sample = NewReservoirSampler(opts.SampleSize)
+	}
+	return s
+}
+
+// RecordRequest counts one request and its latency
+func (s *StatsTracker) RecordRequest(duration time.Duration, isError bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq.Add(1)
+	s.requests.Add(1)
+	if isError {
+		s.errors.Add(1)
+	}
+	s.totalLatency.Add(int64(duration))
+	s.seq.Add(1)
+	s.latencies.Observe(int64(duration))
+	if s.sample != nil {
+		s.sample.Add(int64(duration))
+	}
+}
+
+// GetStats returns a consistent view of the counters without locking
+func (s *StatsTracker) GetStats() (requests, errors int64, avgLatency time.Duration) {
+	for {
+		seq := s.seq.Load()
+		if seq%2 == 0 {
+			requests = s.requests.Load()
+			errors = s.errors.Load()
+			total := s.totalLatency.Load()
+			if s.seq.Load() == seq {
+				if requests > 0 {
+					avgLatency = time.Duration(total / requests)
+				}
+				return
+			}
+		}
+		runtime.Gosched() // A writer is mid-update: let it finish
+	}
+}
+
+// Percentile estimates a latency percentile (e.g. 0.99) from the reservoir
+// sample if there is one, or else from the histogram, to within the width of
+// the bucket it falls in
+func (s *StatsTracker) Percentile(q float64) time.Duration {
+	if s.sample != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return time.Duration(s.sample.Quantile(q))
+	}
+	return time.Duration(s.latencies.Snapshot().Quantile(q))
+}
+
+// Latencies returns the latency distribution recorded so far
+func (s *StatsTracker) Latencies() HistogramSnapshot {
+	return s.latencies.Snapshot()
+}
@@ -0,0 +1,89 @@
+package examples
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestStatsTracker(t *testing.T) {
+	g := NewWithT(t)
+
+	ms := int64(time.Millisecond)
+	s := NewStatsTracker(StatsTrackerOptions{Bounds: []int64{10 * ms, 20 * ms, 30 * ms, 40 * ms}})
+	requests, errors, avg := s.GetStats()
+	g.Expect([]int64{requests, errors}).To(Equal([]int64{0, 0}))
+	g.Expect(avg).To(BeZero())
+
+	s.RecordRequest(10*time.Millisecond, false)
+	s.RecordRequest(30*time.Millisecond, true)
+	requests, errors, avg = s.GetStats()
+	g.Expect(requests).To(Equal(int64(2)))
+	g.Expect(errors).To(Equal(int64(1)))
+	g.Expect(avg).To(Equal(20 * time.Millisecond))
+	g.Expect(s.Percentile(0.5)).To(Equal(10 * time.Millisecond))
+	g.Expect(s.Percentile(1)).To(Equal(30 * time.Millisecond))
+	g.Expect(s.Latencies().Counts).To(Equal([]int64{1, 0, 1, 0, 0}))
+}
+
+// TestStatsTrackerSampledPercentiles checks that with a reservoir, Percentile
+// returns recorded latencies rather than bucket bounds
+func TestStatsTrackerSampledPercentiles(t *testing.T) {
+	g := NewWithT(t)
+
+	s := NewStatsTracker(StatsTrackerOptions{SampleSize: 10})
+	for _, d := range []time.Duration{3, 7, 11} {
+		s.RecordRequest(d*time.Millisecond, false)
+	}
+	g.Expect(s.Percentile(0)).To(Equal(3 * time.Millisecond))
+	g.Expect(s.Percentile(0.5)).To(Equal(7 * time.Millisecond))
+	g.Expect(s.Percentile(1)).To(Equal(11 * time.Millisecond))
+	g.Expect(s.Latencies().Count).To(Equal(int64(3)))
+}
+
+// TestStatsTrackerNoTornReads has writers record every request as an error
+// with the same latency, so any consistent read has errors == requests and an
+// exact average. A read that mixed two writes, such as requests from after an
+// update and errors from before it, breaks one of them.
+func TestStatsTrackerNoTornReads(t *testing.T) {
+	g := NewWithT(t)
+
+	const latency = time.Millisecond
+	s := NewStatsTracker(StatsTrackerOptions{SampleSize: 100})
+	var writers sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		writers.Add(1)
+		go func() {
+			defer writers.Done()
+			for i := 0; i < 5000; i++ {
+				s.RecordRequest(latency, true)
+			}
+		}()
+	}
+
+	var stop atomic.Bool
+	var torn atomic.Int64
+	var readers sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for !stop.Load() {
+				requests, errors, avg := s.GetStats()
+				if errors != requests || (requests > 0 && avg != latency) {
+					torn.Add(1)
+				}
+			}
+		}()
+	}
+	writers.Wait()
+	stop.Store(true)
+	readers.Wait()
+
+	g.Expect(torn.Load()).To(BeZero())
+	requests, _, _ := s.GetStats()
+	g.Expect(requests).To(Equal(int64(20000)))
+}
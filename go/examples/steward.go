@@ -0,0 +1,99 @@
+package examples
+
+import (
+	"context"
+	"time"
+)
+
+// WithHeartbeat calls work over and over until ctx is done, sending a
+// heartbeat at most once per interval between calls. work should do one
+// bounded unit per call: a call that hangs stops the heartbeats, which is how
+// a watcher such as Steward tells a stuck goroutine from a slow one.
+// Heartbeats are dropped rather than queued when nobody is listening. The
+// channel closes once ctx is done and the last call has returned. clock
+// drives the interval (RealClock if nil).
+func WithHeartbeat(ctx context.Context, interval time.Duration, work func(context.Context), clock Clock) <-chan struct{} {
+	if clock == nil {
+		clock = RealClock
+	}
+	heartbeat := make(chan struct{}, 1)
+	go func() {
+		defer close(heartbeat)
+		ticker := clock.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+				select {
+				case heartbeat <- struct{}{}:
+				default:
+				}
+			default:
+			}
+			work(ctx)
+		}
+	}()
+	return heartbeat
+}
+
+// StewardOptions configures Steward
+type StewardOptions struct {
+	Interval  time.Duration      // How often the ward heartbeats
+	Timeout   time.Duration      // Silence after which the ward is restarted; defaults to 2*Interval
+	Clock     Clock              // RealClock if nil
+	OnRestart func(restarts int) // Optional; called each time the ward is replaced
+}
+
+// Steward runs work through WithHeartbeat, the ward, and restarts it whenever
+// its heartbeats stop for Timeout. A restart cancels the ward's context and
+// starts a fresh one; a ward that ignores its context keeps its goroutine, since
+// Go cannot kill one, but its results no longer matter. The returned channel
+// closes once ctx is done and the steward has stopped.
+func Steward(ctx context.Context, opts StewardOptions, work func(context.Context)) <-chan struct{} {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 2 * opts.Interval
+	}
+	if opts.Clock == nil {
+		opts.Clock = RealClock
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for restarts := 0; ; restarts++ {
+			if restarts > 0 && opts.OnRestart != nil {
+				opts.OnRestart(restarts)
+			}
+			if !superviseWard(ctx, opts, work) {
+				return
+			}
+		}
+	}()
+	return done
+}
+
+// superviseWard runs one ward until it goes quiet, reporting true, or until
+// ctx is done, reporting false
+func superviseWard(ctx context.Context, opts StewardOptions, work func(context.Context)) bool {
+	wardCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	heartbeat := WithHeartbeat(wardCtx, opts.Interval, work, opts.Clock)
+	timer := opts.Clock.NewTimer(opts.Timeout)
+	defer func() { timer.Stop() }()
+	for {
+		select {
+		case _, ok := <-heartbeat:
+			if !ok {
+				return ctx.Err() == nil
+			}
+			// A fresh timer rather than Reset, so a timeout already sent is discarded with it
+			timer.Stop()
+			timer = opts.Clock.NewTimer(opts.Timeout)
+		case <-timer.C():
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package examples
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestWithHeartbeat(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	ctx, cancel := context.WithCancel(context.Background())
+	var units int64
+	heartbeat := WithHeartbeat(ctx, time.Second, func(context.Context) {
+		atomic.AddInt64(&units, 1)
+		runtime.Gosched()
+	}, clock)
+	clock.BlockUntil(1)
+
+	// Work runs continuously; a beat comes once per interval
+	g.Eventually(func() int64 { return atomic.LoadInt64(&units) }).Should(BeNumerically(">", 10))
+	g.Consistently(heartbeat, 20*time.Millisecond).ShouldNot(Receive())
+	clock.Advance(time.Second)
+	g.Eventually(heartbeat).Should(Receive())
+
+	cancel()
+	g.Eventually(heartbeat).Should(BeClosed())
+}
+
+func TestSteward(t *testing.T) {
+	g := NewWithT(t)
+	before := runtime.NumGoroutine()
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	ctx, cancel := context.WithCancel(context.Background())
+	var once sync.Once
+	var hung context.Context
+	var restarts int64
+	done := Steward(ctx, StewardOptions{
+		Interval:  time.Second,
+		Timeout:   5 * time.Second,
+		Clock:     clock,
+		OnRestart: func(n int) { atomic.StoreInt64(&restarts, int64(n)) },
+	}, func(ctx context.Context) {
+		// The first ward hangs on its first unit until it is cancelled; later ones are healthy
+		once.Do(func() { hung = ctx })
+		if ctx == hung {
+			<-ctx.Done()
+			return
+		}
+		runtime.Gosched()
+	})
+	clock.BlockUntil(2) // Steward timeout and ward ticker
+
+	// The hung ward never beats: after Timeout it is replaced
+	clock.Advance(5 * time.Second)
+	g.Eventually(func() int64 { return atomic.LoadInt64(&restarts) }).Should(Equal(int64(1)))
+
+	// The replacement beats every second and is left alone
+	for i := 0; i < 20; i++ {
+		clock.BlockUntil(2)
+		clock.Advance(time.Second)
+		time.Sleep(time.Millisecond)
+	}
+	g.Expect(atomic.LoadInt64(&restarts)).To(Equal(int64(1)))
+
+	cancel()
+	g.Eventually(done).Should(BeClosed())
+	g.Eventually(runtime.NumGoroutine, time.Second).Should(BeNumerically("<=", before))
+}
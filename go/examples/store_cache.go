@@ -0,0 +1,370 @@
+package examples
+
+import (
+	"errors"
+	"hash/maphash"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrCacheClosed is returned by a write-behind WriteThroughCache's Put after
+// Close, since nothing would flush the write to the store any more
+var ErrCacheClosed = errors.New("cache closed")
+
+// Store is a slow backing store that caches can sit in front of
+type Store interface {
+	Get(key string) (value interface{}, found bool, err error)
+	Put(key string, value interface{}) error
+}
+
+// BatchStore is implemented by stores that accept many writes in one call
+type BatchStore interface {
+	Store
+	PutBatch(entries map[string]interface{}) error
+}
+
+// SlowStore is an in-memory Store where every call pays a fixed latency
+type SlowStore struct {
+	mu      sync.RWMutex
+	data    map[string]interface{}
+	latency time.Duration
+
+	gets    int64
+	puts    int64
+	batches int64
+}
+
+// NewSlowStore creates a store that sleeps latency on every call
+func NewSlowStore(latency time.Duration) *SlowStore {
+	return &SlowStore{
+		data:    make(map[string]interface{}),
+		latency: latency,
+	}
+}
+
+// Get implements Store
+func (s *SlowStore) Get(key string) (interface{}, bool, error) {
+	atomic.AddInt64(&s.gets, 1)
+	time.Sleep(s.latency)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	val, ok := s.data[key]
+	return val, ok, nil
+}
+
+// Put implements Store
+func (s *SlowStore) Put(key string, value interface{}) error {
+	atomic.AddInt64(&s.puts, 1)
+	time.Sleep(s.latency)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = value
+	return nil
+}
+
+// PutBatch implements BatchStore, paying the latency once for the whole batch
+func (s *SlowStore) PutBatch(entries map[string]interface{}) error {
+	atomic.AddInt64(&s.batches, 1)
+	time.Sleep(s.latency)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, v := range entries {
+		s.data[k] = v
+	}
+	return nil
+}
+
+// Calls returns how many Get, Put and PutBatch calls the store has served
+func (s *SlowStore) Calls() (gets, puts, batches int64) {
+	return atomic.LoadInt64(&s.gets), atomic.LoadInt64(&s.puts), atomic.LoadInt64(&s.batches)
+}
+
+// keyedMutex serializes operations per key using a fixed set of lock stripes
+type keyedMutex struct {
+	seed    maphash.Seed
+	stripes [DefaultShardCount]sync.Mutex
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{seed: maphash.MakeSeed()}
+}
+
+func (k *keyedMutex) lock(key string) *sync.Mutex {
+	mu := &k.stripes[maphash.String(k.seed, key)%DefaultShardCount]
+	mu.Lock()
+	return mu
+}
+
+// ReadThroughCache loads misses from a Store and caches the result.
+// Writes go to the Store and invalidate the cached copy.
+type ReadThroughCache struct {
+	cache *BoundedCache
+	store Store
+	locks *keyedMutex
+
+	counters cacheCounters // Store reads
+}
+
+// NewReadThroughCache puts a BoundedCache configured by opts in front of store
+func NewReadThroughCache(store Store, opts CacheOptions) *ReadThroughCache {
+	return &ReadThroughCache{
+		cache: NewBoundedCache(opts),
+		store: store,
+		locks: newKeyedMutex(),
+	}
+}
+
+// Get returns the cached value or loads it from the store
+func (c *ReadThroughCache) Get(key string) (interface{}, bool, error) {
+	if val, ok := c.cache.Get(key); ok {
+		return val, true, nil
+	}
+
+	// Fill under the key's lock so a concurrent Put cannot be overwritten by a stale read
+	mu := c.locks.lock(key)
+	defer mu.Unlock()
+	if val, ok := c.cache.Get(key); ok {
+		return val, true, nil
+	}
+	val, found, err := loadFromStore(c.store, &c.counters, key)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	c.cache.Set(key, val)
+	return val, true, nil
+}
+
+// Put writes to the store and drops the cached copy so the next Get reloads it
+func (c *ReadThroughCache) Put(key string, value interface{}) error {
+	mu := c.locks.lock(key)
+	defer mu.Unlock()
+	c.cache.Delete(key)
+	return c.store.Put(key, value)
+}
+
+// CacheStats returns the front cache's hits, misses and evictions, with
+// every store read counted as a load
+func (c *ReadThroughCache) CacheStats() CacheStats {
+	return c.counters.withLoads(c.cache.CacheStats())
+}
+
+// loadFromStore reads key from s, recording the read as a load in counters
+func loadFromStore(s Store, counters *cacheCounters, key string) (interface{}, bool, error) {
+	start := time.Now()
+	val, found, err := s.Get(key)
+	counters.load(time.Since(start), err)
+	return val, found, err
+}
+
+// WriteBehindOptions controls batching for NewWriteBehindCache
+type WriteBehindOptions struct {
+	BatchSize     int           // Flush once this many keys are pending (default 100)
+	FlushInterval time.Duration // Flush at least this often (default 100ms)
+}
+
+// WriteThroughCache keeps a cache and its Store in sync on every write.
+// In write-behind mode writes land in the cache immediately and are
+// flushed to the store in coalesced batches by a background goroutine.
+type WriteThroughCache struct {
+	cache    *BoundedCache
+	store    Store
+	locks    *keyedMutex
+	counters cacheCounters // Store reads
+
+	writeBehind bool
+	batchSize   int
+
+	pendingMu sync.Mutex
+	pending   map[string]interface{} // Written to cache, not yet flushed
+	flushing  map[string]interface{} // Batch currently being written to the store
+
+	flushMu sync.Mutex // Serializes flushes so batches reach the store in order
+	kick    chan struct{}
+	done    chan struct{}
+	wg      sync.WaitGroup
+	closed  int32
+}
+
+// NewWriteThroughCache creates a cache where Put writes the store synchronously
+func NewWriteThroughCache(store Store, opts CacheOptions) *WriteThroughCache {
+	return &WriteThroughCache{
+		cache: NewBoundedCache(opts),
+		store: store,
+		locks: newKeyedMutex(),
+	}
+}
+
+// NewWriteBehindCache creates a cache where Put returns once the cache is updated
+// and the store is updated later in batches. Call Close to flush and stop.
+func NewWriteBehindCache(store Store, opts CacheOptions, wb WriteBehindOptions) *WriteThroughCache {
+	if wb.BatchSize < 1 {
+		wb.BatchSize = 100
+	}
+	if wb.FlushInterval <= 0 {
+		wb.FlushInterval = 100 * time.Millisecond
+	}
+	c := NewWriteThroughCache(store, opts)
+	c.writeBehind = true
+	c.batchSize = wb.BatchSize
+	c.pending = make(map[string]interface{})
+	c.kick = make(chan struct{}, 1)
+	c.done = make(chan struct{})
+	c.wg.Add(1)
+	go c.flushLoop(wb.FlushInterval)
+	return c
+}
+
+// Get returns the cached value, falling back to unflushed writes and then the store
+func (c *WriteThroughCache) Get(key string) (interface{}, bool, error) {
+	if val, ok := c.cache.Get(key); ok {
+		return val, true, nil
+	}
+
+	mu := c.locks.lock(key)
+	defer mu.Unlock()
+	if val, ok := c.cache.Get(key); ok {
+		return val, true, nil
+	}
+	// An evicted entry may still be waiting to reach the store
+	if val, ok := c.unflushed(key); ok {
+		c.cache.Set(key, val)
+		return val, true, nil
+	}
+	val, found, err := loadFromStore(c.store, &c.counters, key)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	c.cache.Set(key, val)
+	return val, true, nil
+}
+
+// Put updates the store and the cache as one step per key.
+// In write-behind mode the store update is queued instead, and once the
+// cache is closed Put returns ErrCacheClosed and changes nothing.
+func (c *WriteThroughCache) Put(key string, value interface{}) error {
+	mu := c.locks.lock(key)
+	defer mu.Unlock()
+
+	if !c.writeBehind {
+		if err := c.store.Put(key, value); err != nil {
+			return err
+		}
+		c.cache.Set(key, value)
+		return nil
+	}
+
+	// Checked under pendingMu: Close marks the cache closed before its final
+	// Flush takes the lock, so a write queued here is always flushed
+	c.pendingMu.Lock()
+	if atomic.LoadInt32(&c.closed) != 0 {
+		c.pendingMu.Unlock()
+		return ErrCacheClosed
+	}
+	c.pending[key] = value
+	full := len(c.pending) >= c.batchSize
+	c.pendingMu.Unlock()
+	c.cache.Set(key, value)
+	if full {
+		select {
+		case c.kick <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Pending returns how many keys are waiting to be written behind
+func (c *WriteThroughCache) Pending() int {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+	return len(c.pending)
+}
+
+// Flush writes all pending entries to the store now (no-op in write-through mode)
+func (c *WriteThroughCache) Flush() error {
+	if !c.writeBehind {
+		return nil
+	}
+	c.flushMu.Lock()
+	defer c.flushMu.Unlock()
+
+	c.pendingMu.Lock()
+	batch := c.pending
+	if len(batch) == 0 {
+		c.pendingMu.Unlock()
+		return nil
+	}
+	c.pending = make(map[string]interface{})
+	c.flushing = batch
+	c.pendingMu.Unlock()
+
+	err := c.writeBatch(batch)
+
+	c.pendingMu.Lock()
+	c.flushing = nil
+	if err != nil {
+		// Re-queue failed writes unless a newer value has been written since
+		for k, v := range batch {
+			if _, ok := c.pending[k]; !ok {
+				c.pending[k] = v
+			}
+		}
+	}
+	c.pendingMu.Unlock()
+	return err
+}
+
+// Close stops the write-behind goroutine after a final flush
+func (c *WriteThroughCache) Close() error {
+	if !c.writeBehind || !atomic.CompareAndSwapInt32(&c.closed, 0, 1) {
+		return nil
+	}
+	close(c.done)
+	c.wg.Wait()
+	return c.Flush()
+}
+
+// CacheStats returns the front cache's hits, misses and evictions, with
+// every store read counted as a load
+func (c *WriteThroughCache) CacheStats() CacheStats {
+	return c.counters.withLoads(c.cache.CacheStats())
+}
+
+func (c *WriteThroughCache) unflushed(key string) (interface{}, bool) {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+	if val, ok := c.pending[key]; ok {
+		return val, true
+	}
+	val, ok := c.flushing[key]
+	return val, ok
+}
+
+func (c *WriteThroughCache) writeBatch(batch map[string]interface{}) error {
+	if bs, ok := c.store.(BatchStore); ok {
+		return bs.PutBatch(batch)
+	}
+	for k, v := range batch {
+		if err := c.store.Put(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *WriteThroughCache) flushLoop(interval time.Duration) {
+	defer c.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.Flush()
+		case <-c.kick:
+			c.Flush()
+		case <-c.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package examples
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// flakyStore fails the first n Put/PutBatch calls
+type flakyStore struct {
+	*SlowStore
+	failures int64
+}
+
+func (s *flakyStore) Put(key string, value interface{}) error {
+	if atomic.AddInt64(&s.failures, -1) >= 0 {
+		return errors.New("store unavailable")
+	}
+	return s.SlowStore.Put(key, value)
+}
+
+func (s *flakyStore) PutBatch(entries map[string]interface{}) error {
+	if atomic.AddInt64(&s.failures, -1) >= 0 {
+		return errors.New("store unavailable")
+	}
+	return s.SlowStore.PutBatch(entries)
+}
+
+func TestReadThroughCache(t *testing.T) {
+	g := NewWithT(t)
+
+	store := NewSlowStore(time.Millisecond)
+	store.Put("a", 1)
+	cache := NewReadThroughCache(store, CacheOptions{})
+
+	// Miss loads from the store, then hits
+	val, found, err := cache.Get("a")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(found).To(BeTrue())
+	g.Expect(val).To(Equal(1))
+	cache.Get("a")
+	gets, _, _ := store.Calls()
+	g.Expect(gets).To(Equal(int64(1)))
+
+	// Unknown keys are not cached
+	_, found, _ = cache.Get("missing")
+	g.Expect(found).To(BeFalse())
+
+	// Put goes to the store and invalidates the cache
+	g.Expect(cache.Put("a", 2)).To(Succeed())
+	val, _, _ = cache.Get("a")
+	g.Expect(val).To(Equal(2))
+	gets, puts, _ := store.Calls()
+	g.Expect(gets).To(Equal(int64(3)))
+	g.Expect(puts).To(Equal(int64(2)))
+
+	// Every store read shows up as a load, with its latency
+	stats := cache.CacheStats()
+	g.Expect(stats.Loads).To(Equal(gets))
+	g.Expect(stats.TotalLoadTime).To(BeNumerically(">=", 3*time.Millisecond))
+}
+
+func TestWriteThroughCacheConsistency(t *testing.T) {
+	g := NewWithT(t)
+
+	store := NewSlowStore(0)
+	cache := NewWriteThroughCache(store, CacheOptions{MaxEntries: 8})
+
+	// Writers and readers race on a small key set; the bounded cache
+	// forces evictions and reloads in the middle of the race
+	var wg sync.WaitGroup
+	for w := 0; w < 20; w++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				key := fmt.Sprintf("key_%d", i%10)
+				if i%3 == 0 {
+					cache.Get(key)
+				} else {
+					cache.Put(key, id*1000+i)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	// Whatever the cache holds must match the store
+	for i := 0; i < 10; i++ {
+		key := fmt.Sprintf("key_%d", i)
+		if cached, ok := cache.cache.Get(key); ok {
+			stored, _, _ := store.Get(key)
+			g.Expect(cached).To(Equal(stored), key)
+		}
+	}
+}
+
+func TestWriteThroughCacheLoadStats(t *testing.T) {
+	g := NewWithT(t)
+
+	store := NewSlowStore(2 * time.Millisecond)
+	store.Put("a", 1)
+	cache := NewWriteThroughCache(store, CacheOptions{})
+	cache.Get("a")
+	cache.Get("a")
+	cache.Get("missing")
+
+	stats := cache.CacheStats()
+	g.Expect(stats.Loads).To(Equal(int64(2)))
+	g.Expect(stats.LoadErrors).To(BeZero())
+	g.Expect(stats.AvgLoadTime()).To(BeNumerically(">=", 2*time.Millisecond))
+}
+
+func TestWriteBehindCache(t *testing.T) {
+	g := NewWithT(t)
+
+	store := NewSlowStore(time.Millisecond)
+	cache := NewWriteBehindCache(store, CacheOptions{}, WriteBehindOptions{
+		BatchSize:     1000,
+		FlushInterval: time.Hour, // Only explicit flushes in this test
+	})
+	defer cache.Close()
+
+	// Writes are visible in the cache immediately but not yet in the store
+	for i := 0; i < 50; i++ {
+		g.Expect(cache.Put(fmt.Sprintf("key_%d", i%10), i)).To(Succeed())
+	}
+	g.Expect(cache.Pending()).To(Equal(10)) // Coalesced per key
+	val, _, _ := cache.Get("key_0")
+	g.Expect(val).To(Equal(40))
+	_, found, _ := store.Get("key_0")
+	g.Expect(found).To(BeFalse())
+
+	// One flush writes one batch with the latest value per key
+	g.Expect(cache.Flush()).To(Succeed())
+	g.Expect(cache.Pending()).To(Equal(0))
+	_, puts, batches := store.Calls()
+	g.Expect(puts).To(Equal(int64(0)))
+	g.Expect(batches).To(Equal(int64(1)))
+	stored, _, _ := store.Get("key_9")
+	g.Expect(stored).To(Equal(49))
+}
+
+func TestWriteBehindCachePutAfterClose(t *testing.T) {
+	g := NewWithT(t)
+
+	store := NewSlowStore(0)
+	cache := NewWriteBehindCache(store, CacheOptions{}, WriteBehindOptions{FlushInterval: time.Hour})
+	g.Expect(cache.Put("before", 1)).To(Succeed())
+	g.Expect(cache.Close()).To(Succeed())
+	stored, _, _ := store.Get("before")
+	g.Expect(stored).To(Equal(1))
+
+	// Nothing would flush this, so it is refused rather than silently lost
+	g.Expect(cache.Put("after", 2)).To(MatchError(ErrCacheClosed))
+	g.Expect(cache.Pending()).To(BeZero())
+	_, found, _ := cache.Get("after")
+	g.Expect(found).To(BeFalse())
+}
+
+func TestWriteBehindCacheBackgroundFlush(t *testing.T) {
+	g := NewWithT(t)
+
+	store := NewSlowStore(0)
+	cache := NewWriteBehindCache(store, CacheOptions{}, WriteBehindOptions{
+		BatchSize:     5,
+		FlushInterval: 10 * time.Millisecond,
+	})
+
+	var wg sync.WaitGroup
+	for w := 0; w < 10; w++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				cache.Put(fmt.Sprintf("key_%d", i%20), id*1000+i)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	// Batches are flushed without an explicit call
+	g.Eventually(cache.Pending, "2s", "10ms").Should(Equal(0))
+	g.Expect(cache.Close()).To(Succeed())
+
+	// After close the store agrees with the cache for every key
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("key_%d", i)
+		cached, _, _ := cache.Get(key)
+		stored, _, _ := store.Get(key)
+		g.Expect(stored).To(Equal(cached), key)
+	}
+}
+
+func TestWriteBehindCacheEvictedPendingValue(t *testing.T) {
+	g := NewWithT(t)
+
+	store := NewSlowStore(0)
+	cache := NewWriteBehindCache(store, CacheOptions{MaxEntries: 1}, WriteBehindOptions{FlushInterval: time.Hour})
+	defer cache.Close()
+
+	cache.Put("a", 1)
+	cache.Put("b", 2) // Evicts "a" from the cache before it is flushed
+
+	// The unflushed value is still served instead of the store's (missing) one
+	val, found, err := cache.Get("a")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(found).To(BeTrue())
+	g.Expect(val).To(Equal(1))
+}
+
+func TestWriteBehindCacheFlushError(t *testing.T) {
+	g := NewWithT(t)
+
+	store := &flakyStore{SlowStore: NewSlowStore(0), failures: 1}
+	cache := NewWriteBehindCache(store, CacheOptions{}, WriteBehindOptions{FlushInterval: time.Hour})
+
+	cache.Put("a", 1)
+
+	// First flush fails and keeps the write queued
+	g.Expect(cache.Flush()).To(MatchError("store unavailable"))
+	g.Expect(cache.Pending()).To(Equal(1))
+
+	// Close retries it
+	g.Expect(cache.Close()).To(Succeed())
+	val, _, _ := store.Get("a")
+	g.Expect(val).To(Equal(1))
+}
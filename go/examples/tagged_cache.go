@@ -0,0 +1,194 @@
+package examples
+
+import (
+	"sync/atomic"
+)
+
+// taggedValue is what TaggedCache stores: the value plus the generation
+// of each of its tags at the time it was written
+type taggedValue struct {
+	value interface{}
+	tags  []string
+	gens  []int64
+}
+
+// TaggedCache groups entries under tags so related entries can be invalidated together.
+// Each tag has a generation counter: InvalidateTag bumps it, and any entry written under
+// an older generation is treated as gone, even if a concurrent SetWithTags raced with the
+// invalidation. A reverse index (tag -> keys) lets invalidation also free memory eagerly.
+type TaggedCache struct {
+	store *BoundedCache
+	gens  *ShardedMap[string, *int64]
+	index *ShardedMap[string, *SafeSet[string]]
+
+	counters cacheCounters
+}
+
+// NewTaggedCache creates a tagged cache backed by a BoundedCache configured with opts.
+// opts.OnEvict, if set, receives the untagged value.
+func NewTaggedCache(opts CacheOptions) *TaggedCache {
+	c := &TaggedCache{
+		gens:  NewShardedMap[string, *int64](DefaultShardCount),
+		index: NewShardedMap[string, *SafeSet[string]](DefaultShardCount),
+	}
+	userEvict := opts.OnEvict
+	opts.OnEvict = func(key string, value interface{}, reason EvictionReason) {
+		tv := value.(*taggedValue)
+		c.unindex(key, tv.tags)
+		if userEvict != nil {
+			userEvict(key, tv.value, reason)
+		}
+	}
+	if sizeOf := opts.SizeOf; sizeOf != nil {
+		opts.SizeOf = func(key string, value interface{}) int64 {
+			return sizeOf(key, value.(*taggedValue).value)
+		}
+	}
+	c.store = NewBoundedCache(opts)
+	return c
+}
+
+// generation returns the counter for tag, creating it on first use
+func (c *TaggedCache) generation(tag string) *int64 {
+	if gen, ok := c.gens.Get(tag); ok {
+		return gen
+	}
+	c.gens.SetIfAbsent(tag, new(int64))
+	gen, _ := c.gens.Get(tag)
+	return gen
+}
+
+// keys returns the reverse-index set for tag, creating it on first use
+func (c *TaggedCache) keys(tag string) *SafeSet[string] {
+	if set, ok := c.index.Get(tag); ok {
+		return set
+	}
+	c.index.SetIfAbsent(tag, NewSafeSet[string]())
+	set, _ := c.index.Get(tag)
+	return set
+}
+
+func (c *TaggedCache) unindex(key string, tags []string) {
+	for _, tag := range tags {
+		if set, ok := c.index.Get(tag); ok {
+			set.Remove(key)
+		}
+	}
+}
+
+// stale reports whether any of the entry's tags was invalidated after it was written
+func (c *TaggedCache) stale(tv *taggedValue) bool {
+	for i, tag := range tv.tags {
+		if atomic.LoadInt64(c.generation(tag)) != tv.gens[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// Set stores an untagged value
+func (c *TaggedCache) Set(key string, value interface{}) {
+	c.SetWithTags(key, value)
+}
+
+// SetWithTags stores value under key and associates it with tags
+func (c *TaggedCache) SetWithTags(key string, value interface{}, tags ...string) {
+	tv := &taggedValue{value: value, tags: tags, gens: make([]int64, len(tags))}
+	// Read generations before publishing: if an invalidation bumps one after
+	// this point, the entry is already considered stale
+	for i, tag := range tags {
+		tv.gens[i] = atomic.LoadInt64(c.generation(tag))
+	}
+	for _, tag := range tags {
+		c.keys(tag).Add(key)
+	}
+	// Replacing is not an eviction, so OnEvict will not unindex the old value:
+	// drop key from the tags it no longer carries here
+	if old, ok := c.store.replace(key, tv); ok {
+		for _, tag := range old.(*taggedValue).tags {
+			if !hasTag(tv, tag) {
+				c.unindex(key, []string{tag})
+			}
+		}
+	}
+}
+
+// Get returns the value for key unless it is missing or was invalidated by tag
+func (c *TaggedCache) Get(key string) (interface{}, bool) {
+	v, ok := c.store.Get(key)
+	if !ok {
+		c.counters.miss()
+		return nil, false
+	}
+	tv := v.(*taggedValue)
+	if c.stale(tv) {
+		// A SetWithTags may have replaced the stale value since the read
+		c.store.CompareAndDelete(key, tv)
+		c.counters.miss()
+		return nil, false
+	}
+	c.counters.hit()
+	return tv.value, true
+}
+
+// Delete removes key
+func (c *TaggedCache) Delete(key string) {
+	c.store.Delete(key)
+}
+
+// InvalidateTag logically removes every entry tagged with tag and
+// returns how many indexed entries were physically deleted
+func (c *TaggedCache) InvalidateTag(tag string) int {
+	atomic.AddInt64(c.generation(tag), 1)
+
+	set, ok := c.index.Get(tag)
+	if !ok {
+		return 0
+	}
+	removed := 0
+	for _, key := range set.Values() {
+		// Peek so that invalidation counts no hits and refreshes no recency
+		v, ok := c.store.Peek(key)
+		if !ok {
+			set.Remove(key)
+			continue
+		}
+		// The key may have been rewritten since it was indexed: only drop
+		// it if the current value still carries the tag and is stale, and
+		// is still the value that was checked
+		if tv := v.(*taggedValue); hasTag(tv, tag) && c.stale(tv) && c.store.CompareAndDelete(key, tv) {
+			removed++
+		}
+	}
+	return removed
+}
+
+func hasTag(tv *taggedValue, tag string) bool {
+	for _, t := range tv.tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// TaggedKeys returns the keys currently indexed under tag
+func (c *TaggedCache) TaggedKeys(tag string) []string {
+	if set, ok := c.index.Get(tag); ok {
+		return set.Values()
+	}
+	return nil
+}
+
+// Size returns the number of stored entries, including ones not yet purged
+func (c *TaggedCache) Size() int64 {
+	return c.store.Size()
+}
+
+// CacheStats reports hits and misses as seen by callers, so invalidated entries count as misses
+func (c *TaggedCache) CacheStats() CacheStats {
+	store := c.store.CacheStats()
+	stats := c.counters.snapshot(store.Entries)
+	stats.Evictions = store.Evictions
+	return stats
+}
@@ -0,0 +1,178 @@
+package examples
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestTaggedCache(t *testing.T) {
+	g := NewWithT(t)
+
+	cache := NewTaggedCache(CacheOptions{})
+	cache.SetWithTags("user:1", "alice", "users", "team:red")
+	cache.SetWithTags("user:2", "bob", "users", "team:blue")
+	cache.SetWithTags("team:red", "red", "teams")
+	cache.Set("plain", 42)
+
+	g.Expect(cache.TaggedKeys("users")).To(ConsistOf("user:1", "user:2"))
+
+	// Invalidate one group
+	g.Expect(cache.InvalidateTag("team:red")).To(Equal(1))
+	_, ok := cache.Get("user:1")
+	g.Expect(ok).To(BeFalse())
+	val, ok := cache.Get("user:2")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(val).To(Equal("bob"))
+
+	// Invalidate a larger group
+	g.Expect(cache.InvalidateTag("users")).To(Equal(1))
+	_, ok = cache.Get("user:2")
+	g.Expect(ok).To(BeFalse())
+
+	// Untagged and unrelated entries survive
+	_, ok = cache.Get("team:red")
+	g.Expect(ok).To(BeTrue())
+	_, ok = cache.Get("plain")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(cache.Size()).To(Equal(int64(2)))
+
+	// Unknown tags are a no-op
+	g.Expect(cache.InvalidateTag("nope")).To(Equal(0))
+}
+
+func TestTaggedCacheRetaggedKey(t *testing.T) {
+	g := NewWithT(t)
+
+	cache := NewTaggedCache(CacheOptions{})
+	cache.SetWithTags("k", "v1", "old", "kept")
+	cache.SetWithTags("k", "v2", "new", "kept")
+
+	// Replacing moved the key out of the tag it dropped only
+	g.Expect(cache.TaggedKeys("old")).To(BeEmpty())
+	g.Expect(cache.TaggedKeys("kept")).To(Equal([]string{"k"}))
+	g.Expect(cache.TaggedKeys("new")).To(Equal([]string{"k"}))
+
+	// The key no longer carries "old", so invalidating it does nothing
+	g.Expect(cache.InvalidateTag("old")).To(Equal(0))
+	val, ok := cache.Get("k")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(val).To(Equal("v2"))
+
+	// Writing after an invalidation makes the entry valid again
+	cache.InvalidateTag("new")
+	cache.SetWithTags("k", "v3", "new")
+	val, _ = cache.Get("k")
+	g.Expect(val).To(Equal("v3"))
+}
+
+func TestTaggedCacheInvalidationKeepsNewerValue(t *testing.T) {
+	g := NewWithT(t)
+
+	// Simulate a SetWithTags landing between the staleness check and the
+	// delete: the delete must only remove the value that was checked
+	cache := NewTaggedCache(CacheOptions{})
+	cache.SetWithTags("k", "old", "t")
+	checked, _ := cache.store.Peek("k")
+	cache.InvalidateTag("t")
+	cache.SetWithTags("k", "new", "t")
+	g.Expect(cache.store.CompareAndDelete("k", checked)).To(BeFalse())
+	val, ok := cache.Get("k")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(val).To(Equal("new"))
+}
+
+func TestTaggedCacheInvalidationDoesNotTouchLRU(t *testing.T) {
+	g := NewWithT(t)
+
+	// "a" stays indexed under "old" after being retagged, so invalidating
+	// "old" visits it without deleting it
+	cache := NewTaggedCache(CacheOptions{MaxEntries: 2})
+	cache.SetWithTags("a", 1, "old")
+	cache.SetWithTags("a", 1, "new")
+	cache.SetWithTags("b", 2, "new")
+	before := cache.store.CacheStats()
+	g.Expect(cache.InvalidateTag("old")).To(Equal(0))
+	g.Expect(cache.store.CacheStats().Hits).To(Equal(before.Hits))
+	g.Expect(cache.store.CacheStats().Misses).To(Equal(before.Misses))
+
+	// The visit did not refresh "a", so it is still the first to go
+	cache.Set("c", 3)
+	_, ok := cache.Get("a")
+	g.Expect(ok).To(BeFalse())
+	_, ok = cache.Get("b")
+	g.Expect(ok).To(BeTrue())
+}
+
+func TestTaggedCacheEvictionCleansIndex(t *testing.T) {
+	g := NewWithT(t)
+
+	var evicted []interface{}
+	cache := NewTaggedCache(CacheOptions{
+		MaxEntries: 2,
+		OnEvict: func(_ string, value interface{}, _ EvictionReason) {
+			evicted = append(evicted, value)
+		},
+	})
+	cache.SetWithTags("a", 1, "t")
+	cache.SetWithTags("b", 2, "t")
+	cache.SetWithTags("c", 3, "t")
+
+	// Callbacks see the user's value and the index forgets evicted keys
+	g.Expect(evicted).To(Equal([]interface{}{1}))
+	g.Expect(cache.TaggedKeys("t")).To(ConsistOf("b", "c"))
+	g.Expect(cache.CacheStats().Evictions).To(Equal(int64(1)))
+}
+
+func TestTaggedCacheConcurrentInvalidation(t *testing.T) {
+	g := NewWithT(t)
+
+	cache := NewTaggedCache(CacheOptions{})
+	var writers sync.WaitGroup
+	stop := make(chan struct{})
+	invalidatorDone := make(chan struct{})
+
+	// Keep invalidating "even" while the writers run so index updates
+	// and generation bumps interleave with SetWithTags
+	go func() {
+		defer close(invalidatorDone)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				cache.InvalidateTag("even")
+				cache.InvalidateTag("unrelated")
+			}
+		}
+	}()
+
+	for w := 0; w < 8; w++ {
+		writers.Add(1)
+		go func(id int) {
+			defer writers.Done()
+			for i := 0; i < 250; i++ {
+				n := id*250 + i
+				key := fmt.Sprintf("key_%d", n)
+				if n%2 == 0 {
+					cache.SetWithTags(key, n, "all", "even")
+				} else {
+					cache.SetWithTags(key, n, "all")
+				}
+				cache.Get(key)
+			}
+		}(w)
+	}
+	writers.Wait()
+	close(stop)
+	<-invalidatorDone
+
+	// One final invalidation must catch every even key written before it
+	cache.InvalidateTag("even")
+	for n := 0; n < 2000; n++ {
+		_, ok := cache.Get(fmt.Sprintf("key_%d", n))
+		g.Expect(ok).To(Equal(n%2 == 1), fmt.Sprintf("key_%d", n))
+	}
+}
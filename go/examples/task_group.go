@@ -0,0 +1,101 @@
+package examples
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrWaitTimeout is returned by TaskGroup.WaitTimeout when tasks are still
+// running at the deadline
+var ErrWaitTimeout = errors.New("taskgroup: wait timed out")
+
+// PanicError is the error a TaskGroup records for a task that panicked
+type PanicError struct {
+	Value any    // What the task panicked with
+	Stack []byte // The panicking goroutine's stack
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("task panicked: %v", e.Value)
+}
+
+// TaskGroup is a sync.WaitGroup that also collects the tasks' errors. A task
+// that panics is recorded as a *PanicError instead of crashing the program,
+// and WaitTimeout gives up waiting after a deadline, which is how a demo can
+// report a deadlock instead of hanging. Unlike errgroup, the first error does
+// not cancel the others: every task runs and every error is kept.
+type TaskGroup struct {
+	wg      sync.WaitGroup
+	clock   Clock
+	running atomic.Int64
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewTaskGroup creates an empty group timed by clock (RealClock if nil)
+func NewTaskGroup(clock Clock) *TaskGroup {
+	if clock == nil {
+		clock = RealClock
+	}
+	return &TaskGroup{clock: clock}
+}
+
+// Go runs fn on a new goroutine, recording its error or panic
+func (g *TaskGroup) Go(fn func() error) {
+	g.wg.Add(1)
+	g.running.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer g.running.Add(-1)
+		defer func() {
+			if r := recover(); r != nil {
+				g.record(&PanicError{Value: r, Stack: debug.Stack()})
+			}
+		}()
+		if err := fn(); err != nil {
+			g.record(err)
+		}
+	}()
+}
+
+func (g *TaskGroup) record(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.errs = append(g.errs, err)
+}
+
+// Wait blocks until every task has returned, then returns their errors
+// joined in the order they occurred, or nil
+func (g *TaskGroup) Wait() error {
+	g.wg.Wait()
+	return g.err()
+}
+
+// WaitTimeout is Wait giving up after d. On timeout it returns ErrWaitTimeout,
+// saying how many tasks are still running, joined with the errors of the
+// tasks that have finished; the others keep running.
+func (g *TaskGroup) WaitTimeout(d time.Duration) error {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return g.err()
+	case <-g.clock.After(d):
+		timeout := fmt.Errorf("%w: %d tasks still running after %v", ErrWaitTimeout, g.running.Load(), d)
+		return errors.Join(timeout, g.err())
+	}
+}
+
+func (g *TaskGroup) err() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return errors.Join(g.errs...)
+}
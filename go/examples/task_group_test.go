@@ -0,0 +1,55 @@
+package examples
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestTaskGroupCollectsErrorsAndPanics(t *testing.T) {
+	g := NewWithT(t)
+
+	tg := NewTaskGroup(nil)
+	errA := errors.New("a failed")
+	tg.Go(func() error { return nil })
+	tg.Go(func() error { return errA })
+	tg.Go(func() error { panic("boom") })
+
+	err := tg.Wait()
+	g.Expect(err).To(MatchError(errA))
+	var pe *PanicError
+	g.Expect(errors.As(err, &pe)).To(BeTrue())
+	g.Expect(pe.Value).To(Equal("boom"))
+	g.Expect(string(pe.Stack)).To(ContainSubstring("task_group_test.go"))
+
+	g.Expect(NewTaskGroup(nil).Wait()).To(Succeed())
+}
+
+func TestTaskGroupWaitTimeout(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	tg := NewTaskGroup(clock)
+	release := make(chan struct{})
+	errDone := errors.New("finished with error")
+	tg.Go(func() error { return errDone })
+	tg.Go(func() error { <-release; return nil })
+	g.Eventually(tg.running.Load).Should(Equal(int64(1)))
+
+	result := make(chan error)
+	go func() { result <- tg.WaitTimeout(time.Second) }()
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+
+	var err error
+	g.Eventually(result).Should(Receive(&err))
+	g.Expect(err).To(MatchError(ErrWaitTimeout))
+	g.Expect(err).To(MatchError(ContainSubstring("1 tasks still running after 1s")))
+	g.Expect(err).To(MatchError(errDone))
+
+	// Once the straggler finishes, waiting succeeds in time
+	close(release)
+	g.Expect(tg.WaitTimeout(time.Hour)).To(MatchError(errDone))
+}
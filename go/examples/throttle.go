@@ -0,0 +1,144 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrThrottled is returned by a throttled function for a call it suppressed
+var ErrThrottled = errors.New("throttle: call suppressed")
+
+// ThrottlePolicy says what a throttled function does with a call over the rate
+type ThrottlePolicy int
+
+const (
+	// ThrottleDrop suppresses the call and returns ErrThrottled
+	ThrottleDrop ThrottlePolicy = iota
+	// ThrottleQueue returns at once and runs the call later, when a token
+	// accrues; calls beyond QueueSize already waiting are suppressed
+	ThrottleQueue
+	// ThrottleBlock makes the caller wait for a token or for ctx to end
+	ThrottleBlock
+)
+
+// ThrottleOptions configures ThrottleFunc
+type ThrottleOptions struct {
+	Policy    ThrottlePolicy
+	QueueSize int   // For ThrottleQueue: most calls waiting to run
+	Clock     Clock // RealClock if nil
+}
+
+// ThrottleStats is a point-in-time view of a throttled function's activity
+type ThrottleStats struct {
+	Calls      int64 // Calls made to the throttled function
+	Executed   int64 // Runs of fn
+	Suppressed int64 // Calls dropped, turned away by a full queue, or cancelled while blocked
+	Queued     int64 // Calls waiting to run under ThrottleQueue
+}
+
+// throttled holds the state behind the function ThrottleFunc returns
+type throttled struct {
+	fn     func()
+	bucket *TokenBucket
+	opts   ThrottleOptions
+
+	calls      int64
+	executed   int64
+	suppressed int64
+
+	mu       sync.Mutex
+	queued   int
+	draining bool // A goroutine is running queued calls
+}
+
+// ThrottleFunc wraps fn so that it runs at most rate times per second, with
+// up to burst runs at once after a quiet spell; it is Throttle for function
+// calls instead of channel values. It shares TokenBucket with the rate
+// limiters, and opts.Policy decides what happens to calls over the limit. It
+// returns the wrapped function and a func reporting its stats.
+//
+// The wrapped function returns nil once fn has run, or for ThrottleQueue once
+// the call is queued. The queue is drained by a goroutine that exits when the
+// queue is empty, so an idle throttled function holds no goroutine.
+func ThrottleFunc(fn func(), rate float64, burst int, opts ThrottleOptions) (func(ctx context.Context) error, func() ThrottleStats) {
+	t := &throttled{fn: fn, bucket: NewTokenBucket(rate, burst, opts.Clock), opts: opts}
+	return t.call, t.stats
+}
+
+func (t *throttled) call(ctx context.Context) error {
+	atomic.AddInt64(&t.calls, 1)
+	switch t.opts.Policy {
+	case ThrottleBlock:
+		if err := t.bucket.Wait(ctx); err != nil {
+			atomic.AddInt64(&t.suppressed, 1)
+			return err
+		}
+	case ThrottleQueue:
+		return t.enqueue()
+	default:
+		if !t.bucket.Allow() {
+			atomic.AddInt64(&t.suppressed, 1)
+			return ErrThrottled
+		}
+	}
+	t.run()
+	return nil
+}
+
+// enqueue runs the call now if nothing is queued and a token is free, and
+// otherwise queues it behind the others
+func (t *throttled) enqueue() error {
+	t.mu.Lock()
+	if t.queued == 0 && t.bucket.Allow() {
+		t.mu.Unlock()
+		t.run()
+		return nil
+	}
+	if t.queued >= t.opts.QueueSize {
+		t.mu.Unlock()
+		atomic.AddInt64(&t.suppressed, 1)
+		return ErrThrottled
+	}
+	t.queued++
+	if !t.draining {
+		t.draining = true
+		go t.drain()
+	}
+	t.mu.Unlock()
+	return nil
+}
+
+// drain runs queued calls one token at a time until the queue is empty
+func (t *throttled) drain() {
+	for {
+		t.bucket.Wait(context.Background())
+		t.run()
+		t.mu.Lock()
+		t.queued--
+		if t.queued == 0 {
+			t.draining = false
+			t.mu.Unlock()
+			return
+		}
+		t.mu.Unlock()
+	}
+}
+
+func (t *throttled) run() {
+	t.fn()
+	atomic.AddInt64(&t.executed, 1)
+}
+
+func (t *throttled) stats() ThrottleStats {
+	t.mu.Lock()
+	queued := t.queued
+	t.mu.Unlock()
+	return ThrottleStats{
+		Calls:      atomic.LoadInt64(&t.calls),
+		Executed:   atomic.LoadInt64(&t.executed),
+		Suppressed: atomic.LoadInt64(&t.suppressed),
+		Queued:     int64(queued),
+	}
+}
@@ -0,0 +1,82 @@
+package examples
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestThrottleFuncDrop(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	clock := NewFakeClock(time.Unix(0, 0))
+	var runs atomic.Int64
+	call, stats := ThrottleFunc(func() { runs.Add(1) }, 10, 3, ThrottleOptions{Clock: clock})
+
+	// A burst of 3 runs, the rest are dropped until a token accrues
+	for i := 0; i < 5; i++ {
+		if i < 3 {
+			g.Expect(call(ctx)).To(Succeed())
+		} else {
+			g.Expect(call(ctx)).To(MatchError(ErrThrottled))
+		}
+	}
+	clock.Advance(100 * time.Millisecond)
+	g.Expect(call(ctx)).To(Succeed())
+	g.Expect(runs.Load()).To(BeEquivalentTo(4))
+	g.Expect(stats()).To(Equal(ThrottleStats{Calls: 6, Executed: 4, Suppressed: 2}))
+}
+
+func TestThrottleFuncQueue(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	clock := NewFakeClock(time.Unix(0, 0))
+	var runs atomic.Int64
+	call, stats := ThrottleFunc(func() { runs.Add(1) }, 10, 1, ThrottleOptions{
+		Policy: ThrottleQueue, QueueSize: 2, Clock: clock,
+	})
+
+	// The first call runs, two queue, and the fourth finds the queue full
+	for i := 0; i < 3; i++ {
+		g.Expect(call(ctx)).To(Succeed())
+	}
+	g.Expect(call(ctx)).To(MatchError(ErrThrottled))
+	g.Expect(stats()).To(Equal(ThrottleStats{Calls: 4, Executed: 1, Suppressed: 1, Queued: 2}))
+
+	// Queued calls run one per token
+	clock.BlockUntil(1)
+	clock.Advance(100 * time.Millisecond)
+	g.Eventually(runs.Load).Should(BeEquivalentTo(2))
+	clock.BlockUntil(1)
+	clock.Advance(100 * time.Millisecond)
+	g.Eventually(runs.Load).Should(BeEquivalentTo(3))
+	g.Eventually(func() int64 { return stats().Queued }).Should(BeZero())
+	g.Expect(clock.Waiters()).To(BeZero())
+}
+
+func TestThrottleFuncBlock(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	clock := NewFakeClock(time.Unix(0, 0))
+	var runs atomic.Int64
+	call, stats := ThrottleFunc(func() { runs.Add(1) }, 10, 1, ThrottleOptions{Policy: ThrottleBlock, Clock: clock})
+
+	g.Expect(call(ctx)).To(Succeed())
+	blocked := waitAsync(ctx, call)
+	clock.BlockUntil(1)
+	g.Consistently(blocked, 20*time.Millisecond).ShouldNot(Receive())
+	clock.Advance(100 * time.Millisecond)
+	g.Eventually(blocked).Should(Receive(BeNil()))
+
+	// A blocked call whose ctx ends counts as suppressed
+	cctx, cancel := context.WithCancel(ctx)
+	cancelled := waitAsync(cctx, call)
+	clock.BlockUntil(1)
+	cancel()
+	g.Eventually(cancelled).Should(Receive(MatchError(context.Canceled)))
+	g.Expect(runs.Load()).To(BeEquivalentTo(2))
+	g.Expect(stats()).To(Equal(ThrottleStats{Calls: 3, Executed: 2, Suppressed: 1}))
+}
@@ -0,0 +1,98 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Warmable is any cache that can be pre-populated with Set
+type Warmable interface {
+	Set(key string, value interface{})
+}
+
+// WarmUpProgress is reported once per key as warm-up proceeds
+type WarmUpProgress struct {
+	Key    string
+	Err    error // Non-nil if this key failed to load
+	Done   int   // Keys finished so far, including this one
+	Failed int   // Keys that failed so far
+	Total  int
+}
+
+// WarmUp loads keys into cache with at most concurrency loads in flight, so a
+// freshly started service does not send its first real requests to a cold cache.
+// onProgress (optional) is called sequentially after every key. Loader failures
+// are skipped and returned joined; cancelling ctx stops handing out new keys.
+func WarmUp(ctx context.Context, cache Warmable, keys []string, loader LoaderFunc, concurrency int, onProgress func(WarmUpProgress)) error {
+	return warmUp(ctx, keys, concurrency, onProgress, func(key string) error {
+		val, err := loader(key)
+		if err == nil {
+			cache.Set(key, val)
+		}
+		return err
+	})
+}
+
+// WarmUp pre-populates the cache using its own loader. Keys already being
+// loaded by concurrent Gets are shared rather than loaded twice.
+func (c *LoadingCache) WarmUp(ctx context.Context, keys []string, concurrency int, onProgress func(WarmUpProgress)) error {
+	return warmUp(ctx, keys, concurrency, onProgress, func(key string) error {
+		_, err := c.Get(key)
+		return err
+	})
+}
+
+func warmUp(ctx context.Context, keys []string, concurrency int, onProgress func(WarmUpProgress), load func(key string) error) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	work := make(chan string)
+	var mu sync.Mutex // Guards progress so callbacks see monotonically increasing counts
+	var errs []error
+	progress := WarmUpProgress{Total: len(keys)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range work {
+				err := load(key)
+
+				mu.Lock()
+				progress.Key = key
+				progress.Err = err
+				progress.Done++
+				if err != nil {
+					progress.Failed++
+					errs = append(errs, fmt.Errorf("warm up %q: %w", key, err))
+				}
+				if onProgress != nil {
+					onProgress(progress)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var cancelled error
+feed:
+	for _, key := range keys {
+		if cancelled = ctx.Err(); cancelled != nil {
+			break
+		}
+		select {
+		case work <- key:
+		case <-ctx.Done():
+			cancelled = ctx.Err()
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	return errors.Join(append(errs, cancelled)...)
+}
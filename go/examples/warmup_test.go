@@ -0,0 +1,105 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestWarmUp(t *testing.T) {
+	g := NewWithT(t)
+
+	keys := make([]string, 20)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key_%d", i)
+	}
+
+	var active, maxActive int64
+	loader := func(key string) (interface{}, error) {
+		n := atomic.AddInt64(&active, 1)
+		defer atomic.AddInt64(&active, -1)
+		for {
+			max := atomic.LoadInt64(&maxActive)
+			if n <= max || atomic.CompareAndSwapInt64(&maxActive, max, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		if key == "key_7" {
+			return nil, errors.New("backend error")
+		}
+		return "warm-" + key, nil
+	}
+
+	cache := &SafeMap{}
+	var reports []WarmUpProgress
+	err := WarmUp(context.Background(), cache, keys, loader, 4, func(p WarmUpProgress) {
+		reports = append(reports, p)
+	})
+
+	// Failures are reported but do not stop the warm-up
+	g.Expect(err).To(MatchError(ContainSubstring(`warm up "key_7": backend error`)))
+	g.Expect(cache.Size()).To(Equal(int64(19)))
+	g.Expect(atomic.LoadInt64(&maxActive)).To(BeNumerically("<=", int64(4)))
+
+	// One report per key with increasing Done counts
+	g.Expect(reports).To(HaveLen(20))
+	for i, p := range reports {
+		g.Expect(p.Done).To(Equal(i + 1))
+		g.Expect(p.Total).To(Equal(20))
+	}
+	g.Expect(reports[19].Failed).To(Equal(1))
+}
+
+func TestWarmUpCancellation(t *testing.T) {
+	g := NewWithT(t)
+
+	keys := make([]string, 100)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key_%d", i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cache := &SafeMap{}
+	err := WarmUp(ctx, cache, keys, func(key string) (interface{}, error) {
+		return key, nil
+	}, 2, func(p WarmUpProgress) {
+		if p.Done == 10 {
+			cancel()
+		}
+	})
+
+	g.Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+	g.Expect(cache.Size()).To(BeNumerically("<", int64(100)))
+}
+
+func TestLoadingCacheWarmUp(t *testing.T) {
+	g := NewWithT(t)
+
+	cache := NewLoadingCache(func(key string) (interface{}, error) {
+		time.Sleep(20 * time.Millisecond) // Cold backend
+		return "v-" + key, nil
+	}, 0)
+
+	// A cold cache makes the first request pay the backend latency
+	start := time.Now()
+	cache.Get("cold")
+	g.Expect(time.Since(start)).To(BeNumerically(">=", 20*time.Millisecond))
+
+	// After warming, the same requests are served immediately
+	keys := []string{"a", "b", "c", "d", "e", "f"}
+	g.Expect(cache.WarmUp(context.Background(), keys, 3, nil)).To(Succeed())
+	g.Expect(cache.Size()).To(Equal(int64(7)))
+
+	start = time.Now()
+	for _, k := range keys {
+		cache.Get(k)
+	}
+	g.Expect(time.Since(start)).To(BeNumerically("<", 20*time.Millisecond))
+	g.Expect(cache.Stats().Hits).To(Equal(int64(6)))
+}
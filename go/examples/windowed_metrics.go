@@ -0,0 +1,161 @@
+package examples
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Trailing windows reported by WindowedMetrics.Snapshot
+const (
+	ShortWindow  = 10 * time.Second
+	MediumWindow = time.Minute
+	LongWindow   = 5 * time.Minute
+)
+
+// windowResolution is the width of one ring-buffer bucket
+const windowResolution = time.Second
+
+type windowBucket struct {
+	requests int64
+	errors   int64
+	bytes    int64
+}
+
+// WindowTotals holds what was recorded during one trailing window
+type WindowTotals struct {
+	Window   time.Duration
+	Requests int64
+	Errors   int64
+	Bytes    int64
+}
+
+// RequestRate returns requests per second over the window
+func (t WindowTotals) RequestRate() float64 {
+	return float64(t.Requests) / t.Window.Seconds()
+}
+
+// ErrorRatio returns the fraction of requests that failed, or 0 when idle
+func (t WindowTotals) ErrorRatio() float64 {
+	return ratio(t.Errors, t.Requests)
+}
+
+// WindowedSnapshot reports the 10s, 1m and 5m trailing windows
+type WindowedSnapshot struct {
+	Last10s WindowTotals
+	Last1m  WindowTotals
+	Last5m  WindowTotals
+}
+
+// WindowedMetrics is like Metrics but answers "how much in the last N seconds".
+// Recordings go into one-second buckets of a ring buffer; a background ticker
+// advances the ring each second and clears the bucket it moves onto, so memory
+// stays fixed and recording is a couple of atomic operations.
+type WindowedMetrics struct {
+	buckets   []windowBucket
+	head      int64 // Index of the bucket currently being written
+	rotations int64
+
+	ticker   Ticker
+	done     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewWindowedMetrics starts a windowed recorder driven by clock (RealClock if nil).
+// Call Stop to release its ticker.
+func NewWindowedMetrics(clock Clock) *WindowedMetrics {
+	if clock == nil {
+		clock = RealClock
+	}
+	w := &WindowedMetrics{
+		buckets: make([]windowBucket, int(LongWindow/windowResolution)),
+		ticker:  clock.NewTicker(windowResolution),
+		done:    make(chan struct{}),
+	}
+	w.wg.Add(1)
+	go w.run()
+	return w
+}
+
+func (w *WindowedMetrics) run() {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.ticker.C():
+			w.rotate()
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// rotate clears the next bucket before publishing it as head, so writers
+// never add into a bucket that is about to be zeroed
+func (w *WindowedMetrics) rotate() {
+	next := (atomic.LoadInt64(&w.head) + 1) % int64(len(w.buckets))
+	b := &w.buckets[next]
+	atomic.StoreInt64(&b.requests, 0)
+	atomic.StoreInt64(&b.errors, 0)
+	atomic.StoreInt64(&b.bytes, 0)
+	atomic.StoreInt64(&w.head, next)
+	atomic.AddInt64(&w.rotations, 1)
+}
+
+func (w *WindowedMetrics) current() *windowBucket {
+	return &w.buckets[atomic.LoadInt64(&w.head)]
+}
+
+// RecordRequest counts one request in the current second
+func (w *WindowedMetrics) RecordRequest() {
+	atomic.AddInt64(&w.current().requests, 1)
+}
+
+// RecordError counts one error in the current second
+func (w *WindowedMetrics) RecordError() {
+	atomic.AddInt64(&w.current().errors, 1)
+}
+
+// RecordBytes adds bytes to the current second
+func (w *WindowedMetrics) RecordBytes(bytes int64) {
+	atomic.AddInt64(&w.current().bytes, bytes)
+}
+
+// Window sums the buckets covering the trailing d, including the partially
+// filled current second. d is rounded up to whole seconds and capped at LongWindow.
+func (w *WindowedMetrics) Window(d time.Duration) WindowTotals {
+	n := int((d + windowResolution - 1) / windowResolution)
+	if n > len(w.buckets) {
+		n = len(w.buckets)
+	}
+	if n < 1 {
+		n = 1
+	}
+	totals := WindowTotals{Window: time.Duration(n) * windowResolution}
+	head := int(atomic.LoadInt64(&w.head))
+	for i := 0; i < n; i++ {
+		b := &w.buckets[(head-i+len(w.buckets))%len(w.buckets)]
+		totals.Requests += atomic.LoadInt64(&b.requests)
+		totals.Errors += atomic.LoadInt64(&b.errors)
+		totals.Bytes += atomic.LoadInt64(&b.bytes)
+	}
+	return totals
+}
+
+// Snapshot returns the standard 10s, 1m and 5m windows
+func (w *WindowedMetrics) Snapshot() WindowedSnapshot {
+	return WindowedSnapshot{
+		Last10s: w.Window(ShortWindow),
+		Last1m:  w.Window(MediumWindow),
+		Last5m:  w.Window(LongWindow),
+	}
+}
+
+// Stop halts bucket rotation; recorded totals remain readable
+func (w *WindowedMetrics) Stop() {
+	w.stopOnce.Do(func() {
+		w.ticker.Stop()
+		close(w.done)
+	})
+	w.wg.Wait()
+}
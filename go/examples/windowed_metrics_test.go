@@ -0,0 +1,107 @@
+package examples
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// advanceWindow moves the fake clock one bucket at a time, waiting for each
+// rotation so no tick is dropped by the ticker's one-slot channel
+func advanceWindow(g *WithT, clock *FakeClock, w *WindowedMetrics, seconds int) {
+	for i := 0; i < seconds; i++ {
+		want := atomic.LoadInt64(&w.rotations) + 1
+		clock.Advance(time.Second)
+		g.Eventually(func() int64 { return atomic.LoadInt64(&w.rotations) }).Should(Equal(want))
+	}
+}
+
+func TestWindowedMetricsRotation(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	w := NewWindowedMetrics(clock)
+	defer w.Stop()
+
+	w.RecordRequest()
+	w.RecordBytes(100)
+	g.Expect(atomic.LoadInt64(&w.head)).To(Equal(int64(0)))
+
+	advanceWindow(g, clock, w, 1)
+	g.Expect(atomic.LoadInt64(&w.head)).To(Equal(int64(1)))
+	w.RecordRequest()
+	w.RecordError()
+
+	// Both seconds are inside every window
+	snap := w.Snapshot()
+	g.Expect(snap.Last10s).To(Equal(WindowTotals{Window: ShortWindow, Requests: 2, Errors: 1, Bytes: 100}))
+	g.Expect(snap.Last1m.Requests).To(Equal(int64(2)))
+	g.Expect(snap.Last5m.Requests).To(Equal(int64(2)))
+	g.Expect(snap.Last10s.ErrorRatio()).To(BeNumerically("~", 0.5, 0.001))
+	g.Expect(snap.Last10s.RequestRate()).To(BeNumerically("~", 0.2, 0.001))
+
+	// The first second falls out of the 10s window after 9 more rotations
+	advanceWindow(g, clock, w, 9)
+	snap = w.Snapshot()
+	g.Expect(snap.Last10s.Requests).To(Equal(int64(1)))
+	g.Expect(snap.Last10s.Bytes).To(Equal(int64(0)))
+	g.Expect(snap.Last1m.Requests).To(Equal(int64(2)))
+
+	// Both leave the 1m window, but not the 5m window
+	advanceWindow(g, clock, w, 51)
+	snap = w.Snapshot()
+	g.Expect(snap.Last1m.Requests).To(Equal(int64(0)))
+	g.Expect(snap.Last5m.Requests).To(Equal(int64(2)))
+
+	// Wrapping the ring clears reused buckets
+	advanceWindow(g, clock, w, 240)
+	g.Expect(atomic.LoadInt64(&w.head)).To(Equal(int64(1)))
+	g.Expect(w.Snapshot().Last5m).To(Equal(WindowTotals{Window: LongWindow}))
+}
+
+func TestWindowedMetricsWindow(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	w := NewWindowedMetrics(clock)
+	defer w.Stop()
+
+	for i := 0; i < 5; i++ {
+		for j := 0; j <= i; j++ {
+			w.RecordRequest()
+		}
+		advanceWindow(g, clock, w, 1)
+	}
+	// Buckets now hold 1..5 requests with an empty current second
+	g.Expect(w.Window(time.Second).Requests).To(Equal(int64(0)))
+	g.Expect(w.Window(2 * time.Second).Requests).To(Equal(int64(5)))
+	g.Expect(w.Window(1500 * time.Millisecond).Window).To(Equal(2 * time.Second))
+	g.Expect(w.Window(time.Hour).Window).To(Equal(LongWindow))
+	g.Expect(w.Window(time.Hour).Requests).To(Equal(int64(15)))
+}
+
+func TestWindowedMetricsConcurrent(t *testing.T) {
+	g := NewWithT(t)
+
+	w := NewWindowedMetrics(nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				w.RecordRequest()
+				w.RecordBytes(2)
+			}
+		}()
+	}
+	wg.Wait()
+	w.Stop()
+
+	snap := w.Snapshot()
+	g.Expect(snap.Last5m.Requests).To(Equal(int64(8000)))
+	g.Expect(snap.Last5m.Bytes).To(Equal(int64(16000)))
+}
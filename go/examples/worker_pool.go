@@ -0,0 +1,184 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/camilbenameur/learning/go/requestid"
+)
+
+// ErrPoolClosed is returned when submitting to a closed WorkerPool
+var ErrPoolClosed = errors.New("worker pool closed")
+
+// Task is a unit of work run by a WorkerPool. ctx is the context passed to Submit.
+type Task func(ctx context.Context) error
+
+type queuedTask struct {
+	ctx  context.Context
+	task Task
+}
+
+// WorkerPoolOptions configures a WorkerPool
+type WorkerPoolOptions struct {
+	Workers   int         // Goroutines running tasks; defaults to 1
+	QueueSize int         // Tasks buffered before Submit blocks
+	Clock     Clock       // Drives the load average; RealClock if nil
+	OnError   func(error) // Optional; receives errors returned by tasks
+	Events    *EventLog   // Optional; records failed tasks, with their request ID, and Close
+}
+
+// WorkerPoolStats is a point-in-time view of a WorkerPool
+type WorkerPoolStats struct {
+	Workers    int
+	QueueDepth int   // Tasks waiting for a worker
+	Active     int64 // Tasks currently running
+	Completed  int64 // Tasks finished without error
+	Failed     int64
+	Load       LoadAverages // Decaying averages of QueueDepth+Active
+}
+
+// WorkerPool runs submitted tasks on a fixed set of goroutines fed by a
+// buffered channel, the Worker example grown into a reusable pool
+type WorkerPool struct {
+	opts  WorkerPoolOptions
+	tasks chan queuedTask
+	load  *LoadAverage
+
+	active    int64
+	completed int64
+	failed    int64
+
+	mu        sync.RWMutex  // Held by senders, so Close cannot close tasks under them
+	done      chan struct{} // Closed first by Close, releasing blocked senders
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewWorkerPool starts the pool's workers
+func NewWorkerPool(opts WorkerPoolOptions) *WorkerPool {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	if opts.QueueSize < 0 {
+		opts.QueueSize = 0
+	}
+	p := &WorkerPool{
+		opts:  opts,
+		tasks: make(chan queuedTask, opts.QueueSize),
+		done:  make(chan struct{}),
+	}
+	p.load = NewLoadAverage(func() float64 {
+		return float64(len(p.tasks)) + float64(atomic.LoadInt64(&p.active))
+	}, opts.Clock)
+
+	for i := 0; i < opts.Workers; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+	return p
+}
+
+func (p *WorkerPool) work() {
+	defer p.wg.Done()
+	for t := range p.tasks {
+		atomic.AddInt64(&p.active, 1)
+		err := t.task(t.ctx)
+		atomic.AddInt64(&p.active, -1)
+		if err != nil {
+			atomic.AddInt64(&p.failed, 1)
+			p.logEvent(t.ctx, "task failed", "err", err)
+			if p.opts.OnError != nil {
+				p.opts.OnError(err)
+			}
+		} else {
+			atomic.AddInt64(&p.completed, 1)
+		}
+	}
+}
+
+// Submit queues task, blocking while the queue is full until ctx is done or
+// the pool is closed
+func (p *WorkerPool) Submit(ctx context.Context, task Task) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.isClosed() {
+		return ErrPoolClosed
+	}
+	select {
+	case p.tasks <- queuedTask{ctx: ctx, task: task}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.done:
+		return ErrPoolClosed
+	}
+}
+
+// TrySubmit queues task only if there is room right now
+func (p *WorkerPool) TrySubmit(ctx context.Context, task Task) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.isClosed() {
+		return false
+	}
+	select {
+	case p.tasks <- queuedTask{ctx: ctx, task: task}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Stats returns the pool's counters and load averages
+func (p *WorkerPool) Stats() WorkerPoolStats {
+	return WorkerPoolStats{
+		Workers:    p.opts.Workers,
+		QueueDepth: len(p.tasks),
+		Active:     atomic.LoadInt64(&p.active),
+		Completed:  atomic.LoadInt64(&p.completed),
+		Failed:     atomic.LoadInt64(&p.failed),
+		Load:       p.load.Load(),
+	}
+}
+
+func (p *WorkerPool) isClosed() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close stops accepting tasks, waits for queued ones to finish and stops the load average.
+// Submit calls blocked on a full queue return ErrPoolClosed.
+func (p *WorkerPool) Close() {
+	closing := false
+	p.closeOnce.Do(func() {
+		closing = true
+		// Release blocked senders before waiting for the write lock: a task
+		// that calls Submit must not wait on them behind Close
+		close(p.done)
+		p.mu.Lock()
+		close(p.tasks)
+		p.mu.Unlock()
+	})
+	p.wg.Wait()
+	p.load.Stop()
+	if closing {
+		p.logEvent(context.Background(), "pool closed", "completed", atomic.LoadInt64(&p.completed), "failed", atomic.LoadInt64(&p.failed))
+	}
+}
+
+// logEvent records an event, tagged with the request ID carried by ctx if any
+func (p *WorkerPool) logEvent(ctx context.Context, message string, keyvals ...interface{}) {
+	if p.opts.Events == nil {
+		return
+	}
+	if id, ok := requestid.FromContext(ctx); ok {
+		keyvals = append(keyvals, "request_id", id)
+	}
+	p.opts.Events.Log(message, keyvals...)
+}
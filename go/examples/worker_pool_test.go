@@ -0,0 +1,155 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestWorkerPool(t *testing.T) {
+	g := NewWithT(t)
+
+	var failures int64
+	p := NewWorkerPool(WorkerPoolOptions{
+		Workers:   4,
+		QueueSize: 16,
+		OnError:   func(error) { atomic.AddInt64(&failures, 1) },
+	})
+
+	var ran int64
+	for i := 0; i < 100; i++ {
+		i := i
+		g.Expect(p.Submit(context.Background(), func(ctx context.Context) error {
+			atomic.AddInt64(&ran, 1)
+			if i%10 == 0 {
+				return errors.New("boom")
+			}
+			return nil
+		})).To(Succeed())
+	}
+	p.Close()
+
+	g.Expect(atomic.LoadInt64(&ran)).To(Equal(int64(100)))
+	stats := p.Stats()
+	g.Expect(stats.Completed).To(Equal(int64(90)))
+	g.Expect(stats.Failed).To(Equal(int64(10)))
+	g.Expect(stats.Workers).To(Equal(4))
+	g.Expect(atomic.LoadInt64(&failures)).To(Equal(int64(10)))
+
+	g.Expect(p.Submit(context.Background(), func(context.Context) error { return nil })).To(MatchError(ErrPoolClosed))
+	g.Expect(p.TrySubmit(context.Background(), func(context.Context) error { return nil })).To(BeFalse())
+	p.Close()
+}
+
+func TestWorkerPoolBackpressure(t *testing.T) {
+	g := NewWithT(t)
+
+	p := NewWorkerPool(WorkerPoolOptions{Workers: 1, QueueSize: 1})
+	defer p.Close()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	block := func(context.Context) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}
+
+	g.Expect(p.Submit(context.Background(), block)).To(Succeed())
+	<-started
+	g.Expect(p.TrySubmit(context.Background(), block)).To(BeTrue())
+	g.Expect(p.TrySubmit(context.Background(), block)).To(BeFalse())
+
+	stats := p.Stats()
+	g.Expect(stats.Active).To(Equal(int64(1)))
+	g.Expect(stats.QueueDepth).To(Equal(1))
+
+	// A full queue makes Submit wait until its context gives up
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	g.Expect(p.Submit(ctx, block)).To(MatchError(context.DeadlineExceeded))
+
+	close(release)
+	<-started
+}
+
+// TestWorkerPoolSubmitFromTaskDuringClose has a running task call Submit
+// while Close waits and another Submit is blocked on the full queue. Close
+// must release the blocked sender rather than leave the task's Submit queued
+// behind it, which would stop the worker draining the queue for good.
+func TestWorkerPoolSubmitFromTaskDuringClose(t *testing.T) {
+	g := NewWithT(t)
+
+	p := NewWorkerPool(WorkerPoolOptions{Workers: 1, QueueSize: 1})
+	noop := func(context.Context) error { return nil }
+	started, release := make(chan struct{}), make(chan struct{})
+	nested := make(chan error, 1)
+	g.Expect(p.Submit(context.Background(), func(ctx context.Context) error {
+		close(started)
+		<-release
+		nested <- p.Submit(ctx, noop)
+		return nil
+	})).To(Succeed())
+	<-started
+	g.Expect(p.Submit(context.Background(), noop)).To(Succeed()) // Fills the queue
+
+	blocked := make(chan error, 1)
+	go func() { blocked <- p.Submit(context.Background(), noop) }()
+	g.Consistently(blocked, 20*time.Millisecond).ShouldNot(Receive())
+
+	closed := make(chan struct{})
+	go func() {
+		p.Close()
+		close(closed)
+	}()
+	g.Eventually(blocked).Should(Receive(MatchError(ErrPoolClosed)))
+	close(release)
+	g.Eventually(nested).Should(Receive(MatchError(ErrPoolClosed)))
+	g.Eventually(closed).Should(BeClosed())
+	g.Expect(p.Stats().Completed).To(Equal(int64(2)))
+}
+
+func TestWorkerPoolPassesContext(t *testing.T) {
+	g := NewWithT(t)
+
+	type key struct{}
+	p := NewWorkerPool(WorkerPoolOptions{})
+	got := make(chan interface{}, 1)
+	ctx := context.WithValue(context.Background(), key{}, "value")
+	g.Expect(p.Submit(ctx, func(ctx context.Context) error {
+		got <- ctx.Value(key{})
+		return nil
+	})).To(Succeed())
+	p.Close()
+	g.Expect(got).To(Receive(Equal("value")))
+}
+
+func TestWorkerPoolLoadAverage(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	p := NewWorkerPool(WorkerPoolOptions{Workers: 1, QueueSize: 8, Clock: clock})
+
+	release := make(chan struct{})
+	for i := 0; i < 5; i++ {
+		g.Expect(p.Submit(context.Background(), func(context.Context) error {
+			<-release
+			return nil
+		})).To(Succeed())
+	}
+	// One running plus four queued
+	g.Eventually(func() int64 { return p.Stats().Active }).Should(Equal(int64(1)))
+
+	clock.BlockUntil(1)
+	clock.Advance(LoadSampleInterval)
+	g.Eventually(func() float64 { return p.Stats().Load.Load1 }).Should(BeNumerically(">", 0))
+	g.Expect(p.Stats().Load.Load1).To(BeNumerically("~", 5*(1-math.Exp(-5.0/60)), 1e-9))
+
+	close(release)
+	p.Close()
+}
@@ -0,0 +1,137 @@
+// Package leaderelection simulates leader election among nodes in one
+// process. Nodes race to acquire a shared examples.Lease; the winner leads
+// for as long as it keeps renewing, and the others keep retrying so one of
+// them takes over within a lease duration of the leader going quiet. A node
+// can be partitioned to simulate it losing contact with the lease: it stops
+// renewing, its lease expires, and it steps down.
+package leaderelection
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/camilbenameur/learning/go/examples"
+)
+
+// Callbacks are invoked as a node gains and loses leadership
+type Callbacks struct {
+	// OnStartedLeading runs on its own goroutine when the node becomes
+	// leader; ctx is cancelled when it stops leading
+	OnStartedLeading func(ctx context.Context)
+	// OnStoppedLeading runs when the node stops leading, whether its lease
+	// expired or Run's ctx ended
+	OnStoppedLeading func()
+}
+
+// Config configures a node
+type Config struct {
+	Lease         *examples.Lease
+	Identity      string
+	LeaseDuration time.Duration  // How long leadership survives without renewal
+	RetryPeriod   time.Duration  // How often a candidate retries and a leader renews; well under LeaseDuration
+	Clock         examples.Clock // RealClock if nil; must be the one driving Lease
+	Callbacks     Callbacks
+}
+
+// Elector is one node taking part in an election
+type Elector struct {
+	cfg         Config
+	leading     atomic.Bool
+	partitioned atomic.Bool
+}
+
+// New creates a node; it takes part once Run is called
+func New(cfg Config) *Elector {
+	if cfg.Clock == nil {
+		cfg.Clock = examples.RealClock
+	}
+	return &Elector{cfg: cfg}
+}
+
+// Run campaigns for leadership until ctx ends, leading whenever it holds the
+// lease and going back to campaigning when it loses it. On return it has
+// given up any lease it held.
+func (e *Elector) Run(ctx context.Context) {
+	for {
+		grant := e.campaign(ctx)
+		if grant == nil {
+			return
+		}
+		e.lead(ctx, grant)
+	}
+}
+
+// IsLeader reports whether the node currently leads
+func (e *Elector) IsLeader() bool {
+	return e.leading.Load()
+}
+
+// Identity returns the node's name
+func (e *Elector) Identity() string {
+	return e.cfg.Identity
+}
+
+// Partition cuts the node off from the lease: it neither renews nor
+// acquires until Heal, so a partitioned leader loses its lease on expiry
+func (e *Elector) Partition() {
+	e.partitioned.Store(true)
+}
+
+// Heal reconnects a partitioned node
+func (e *Elector) Heal() {
+	e.partitioned.Store(false)
+}
+
+// campaign tries to acquire the lease now and every RetryPeriod after, and
+// returns the grant, or nil once ctx ends
+func (e *Elector) campaign(ctx context.Context) *examples.LeaseGrant {
+	ticker := e.cfg.Clock.NewTicker(e.cfg.RetryPeriod)
+	defer ticker.Stop()
+	for ctx.Err() == nil {
+		if !e.partitioned.Load() {
+			if grant, err := e.cfg.Lease.Acquire(e.cfg.Identity, e.cfg.LeaseDuration); err == nil {
+				return grant
+			}
+		}
+		select {
+		case <-ticker.C():
+		case <-ctx.Done():
+		}
+	}
+	return nil
+}
+
+// lead holds the lease, renewing it every RetryPeriod, until the lease is
+// lost or ctx ends, in which case it revokes the lease so a successor need
+// not wait out the duration
+func (e *Elector) lead(ctx context.Context, grant *examples.LeaseGrant) {
+	leadCtx, cancel := context.WithCancel(ctx)
+	e.leading.Store(true)
+	if fn := e.cfg.Callbacks.OnStartedLeading; fn != nil {
+		go fn(leadCtx)
+	}
+
+	ticker := e.cfg.Clock.NewTicker(e.cfg.RetryPeriod)
+loop:
+	for {
+		select {
+		case <-ticker.C():
+			if !e.partitioned.Load() && grant.Renew() != nil {
+				break loop
+			}
+		case <-grant.Lost():
+			break loop
+		case <-ctx.Done():
+			grant.Revoke()
+			break loop
+		}
+	}
+	ticker.Stop()
+
+	e.leading.Store(false)
+	cancel()
+	if fn := e.cfg.Callbacks.OnStoppedLeading; fn != nil {
+		fn()
+	}
+}
@@ -0,0 +1,139 @@
+package leaderelection
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/camilbenameur/learning/go/examples"
+)
+
+const (
+	leaseDuration = time.Second
+	retryPeriod   = 100 * time.Millisecond
+)
+
+// cluster is a set of nodes sharing one lease and one fake clock, recording
+// every leadership change in order
+type cluster struct {
+	clock  *examples.FakeClock
+	lease  *examples.Lease
+	nodes  []*Elector
+	cancel []context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	events []string
+}
+
+func newCluster(names ...string) *cluster {
+	c := &cluster{clock: examples.NewFakeClock(time.Unix(0, 0))}
+	c.lease = examples.NewLease(c.clock)
+	for _, name := range names {
+		c.nodes = append(c.nodes, New(Config{
+			Lease:         c.lease,
+			Identity:      name,
+			LeaseDuration: leaseDuration,
+			RetryPeriod:   retryPeriod,
+			Clock:         c.clock,
+			Callbacks: Callbacks{
+				OnStartedLeading: func(context.Context) { c.record("started " + name) },
+				OnStoppedLeading: func() { c.record("stopped " + name) },
+			},
+		}))
+	}
+	return c
+}
+
+func (c *cluster) record(event string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = append(c.events, event)
+}
+
+func (c *cluster) Events() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.events...)
+}
+
+// start runs every node, one at a time so the first one listed wins
+func (c *cluster) start(g *WithT) {
+	for _, n := range c.nodes {
+		ctx, cancel := context.WithCancel(context.Background())
+		c.cancel = append(c.cancel, cancel)
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			n.Run(ctx)
+		}()
+		g.Eventually(c.clock.Waiters).Should(BeNumerically(">", len(c.cancel)))
+	}
+}
+
+func (c *cluster) stop() {
+	for _, cancel := range c.cancel {
+		cancel()
+	}
+	c.wg.Wait()
+}
+
+// leader returns the node holding the lease, advancing the clock one retry
+// period per call so that Eventually drives the simulation forward. Polling
+// every 10ms leaves the nodes time to react to each tick, so a live leader
+// never misses enough renewals to lose its lease.
+func (c *cluster) leader() string {
+	c.clock.Advance(retryPeriod)
+	for _, n := range c.nodes {
+		if n.IsLeader() {
+			return n.Identity()
+		}
+	}
+	return ""
+}
+
+func TestElectionFailover(t *testing.T) {
+	g := NewWithT(t)
+
+	c := newCluster("a", "b", "c")
+	c.start(g)
+	defer c.stop()
+
+	// a acquired first; renewals keep it leader for many lease durations
+	g.Eventually(c.Events).Should(Equal([]string{"started a"}))
+	g.Consistently(c.leader, 300*time.Millisecond, 10*time.Millisecond).Should(Equal("a"))
+
+	// Partitioned, a stops renewing; once its lease expires it steps down
+	// and exactly one other node takes over
+	c.nodes[0].Partition()
+	g.Eventually(c.leader).Should(Or(Equal("b"), Equal("c")))
+	successor := c.leader()
+	// a may only notice its lease is gone after the successor has started,
+	// as a real deposed leader might, so the events can come in either order
+	g.Eventually(c.Events).Should(ConsistOf("started a", "stopped a", "started "+successor))
+
+	// Healed, a campaigns again but the successor holds on
+	c.nodes[0].Heal()
+	g.Consistently(c.leader, 300*time.Millisecond, 10*time.Millisecond).Should(Equal(successor))
+}
+
+func TestElectionStepDownOnCancel(t *testing.T) {
+	g := NewWithT(t)
+
+	c := newCluster("a", "b")
+	c.start(g)
+	defer c.stop()
+	g.Eventually(c.leader).Should(Equal("a"))
+
+	// Cancelling the leader's Run revokes the lease, so b takes over at its
+	// next retry rather than after a full lease duration
+	c.cancel[0]()
+	g.Eventually(c.Events).Should(ContainElement("stopped a"))
+	start := c.clock.Now()
+	g.Eventually(c.leader).Should(Equal("b"))
+	g.Expect(c.clock.Since(start)).To(BeNumerically("<", leaseDuration))
+	g.Eventually(c.Events).Should(Equal([]string{"started a", "stopped a", "started b"}))
+}
@@ -0,0 +1,29 @@
+// Command memmodel runs each happens-before example in the memmodel package
+// and prints the rule it relies on. Build with the racy tag to include the
+// racy hand-off, and with -race to watch the detector catch it:
+//
+//	go run ./memmodel/cmd/memmodel
+//	go run -race -tags racy ./memmodel/cmd/memmodel
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/camilbenameur/learning/go/memmodel"
+)
+
+func main() {
+	runs := flag.Int("runs", 1000, "times to run each example")
+	flag.Parse()
+
+	for _, ex := range memmodel.Examples {
+		lost := 0
+		for i := 0; i < *runs; i++ {
+			if ex.Run("hello") != "hello" {
+				lost++
+			}
+		}
+		fmt.Printf("%-10s %d/%d delivered  %s\n", ex.Name, *runs-lost, *runs, ex.Edge)
+	}
+}
@@ -0,0 +1,137 @@
+// Package memmodel demonstrates the happens-before edges of the Go memory
+// model (https://go.dev/ref/mem). Each example publishes a message from one
+// goroutine to another through a different synchronisation primitive and
+// returns what the receiving goroutine read. Writes made before the
+// synchronising operation are guaranteed visible after it, so every example
+// returns the message and passes under the race detector.
+//
+// racy.go, built only with the racy tag, adds the same hand-off through a
+// plain bool flag. It usually appears to work, but nothing orders the write of
+// the message before the read, so the race detector reports it:
+//
+//	go test -race -tags racy ./memmodel
+package memmodel
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Example is one message hand-off between two goroutines
+type Example struct {
+	Name string
+	Edge string                  // The happens-before rule the hand-off relies on
+	Run  func(msg string) string // Publishes msg and returns what the receiver read
+}
+
+// Examples lists the hand-offs in the order the memmodel command runs them
+var Examples = []Example{
+	{"channel", "a send happens before the matching receive completes", ChannelHandoff},
+	{"unbuffered", "a receive from an unbuffered channel happens before the send completes", UnbufferedHandoff},
+	{"close", "closing a channel happens before a receive that returns because it is closed", CloseHandoff},
+	{"mutex", "Unlock happens before the next Lock returns", MutexHandoff},
+	{"once", "the function passed to Once.Do returns before any Do call returns", OnceHandoff},
+	{"atomic", "an atomic store is synchronized before the atomic load that observes it", AtomicHandoff},
+}
+
+// ChannelHandoff writes msg, then sends on a channel; the receiver reads msg
+// after the receive
+func ChannelHandoff(msg string) string {
+	var shared string
+	ready := make(chan struct{}, 1)
+	go func() {
+		shared = msg
+		ready <- struct{}{}
+	}()
+	<-ready
+	return shared
+}
+
+// UnbufferedHandoff runs the edge the other way: the sender reads shared
+// after its send completes, which is only guaranteed because the channel is
+// unbuffered and so the send waits for the receiver
+func UnbufferedHandoff(msg string) string {
+	var shared string
+	ready := make(chan struct{})
+	result := make(chan string)
+	go func() {
+		ready <- struct{}{}
+		result <- shared
+	}()
+	shared = msg
+	<-ready
+	return <-result
+}
+
+// CloseHandoff signals with close instead of a send, which reaches every
+// receiver at once
+func CloseHandoff(msg string) string {
+	var shared string
+	done := make(chan struct{})
+	go func() {
+		shared = msg
+		close(done)
+	}()
+	<-done
+	return shared
+}
+
+// MutexHandoff writes msg under a mutex. The reader polls under the same
+// mutex until it sees the write; each Lock that follows the writer's Unlock
+// sees everything the writer did.
+func MutexHandoff(msg string) string {
+	var mu sync.Mutex
+	var shared string
+	go func() {
+		mu.Lock()
+		shared = msg
+		mu.Unlock()
+	}()
+	for {
+		mu.Lock()
+		got := shared
+		mu.Unlock()
+		if got != "" {
+			return got
+		}
+		runtime.Gosched()
+	}
+}
+
+// OnceHandoff initialises shared inside Once.Do from several goroutines. Only
+// one runs the function, and every caller reads the result after Do returns.
+func OnceHandoff(msg string) string {
+	var once sync.Once
+	var shared string
+	results := make(chan string, 4)
+	for i := 0; i < cap(results); i++ {
+		go func() {
+			once.Do(func() { shared = msg })
+			results <- shared
+		}()
+	}
+	got := <-results
+	for i := 1; i < cap(results); i++ {
+		if <-results != got {
+			return ""
+		}
+	}
+	return got
+}
+
+// AtomicHandoff writes msg with a plain store and then sets an atomic flag.
+// The reader spins on the flag; once it loads true, the plain write before
+// the store is visible too. This is the correct version of racy.go's hand-off.
+func AtomicHandoff(msg string) string {
+	var shared string
+	var ready atomic.Bool
+	go func() {
+		shared = msg
+		ready.Store(true)
+	}()
+	for !ready.Load() {
+		runtime.Gosched()
+	}
+	return shared
+}
@@ -0,0 +1,23 @@
+package memmodel
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// Run these with -race: each hand-off must be free of data races as well as
+// deliver the message
+func TestHandoffs(t *testing.T) {
+	for _, ex := range Examples {
+		if ex.Name == "racy" {
+			continue // Covered by racy_test.go
+		}
+		t.Run(ex.Name, func(t *testing.T) {
+			g := NewWithT(t)
+			for i := 0; i < 100; i++ {
+				g.Expect(ex.Run("hello")).To(Equal("hello"))
+			}
+		})
+	}
+}
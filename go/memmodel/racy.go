@@ -0,0 +1,27 @@
+//go:build racy
+
+package memmodel
+
+import "runtime"
+
+func init() {
+	Examples = append(Examples, Example{"racy", "none: a plain flag creates no happens-before edge", RacyHandoff})
+}
+
+// RacyHandoff is AtomicHandoff with the flag as a plain bool. It is a data
+// race: the compiler and CPU may reorder the two writes, or the reader may
+// never see the flag change, so the message is not guaranteed to arrive. On
+// most machines it still returns msg, which is what makes such bugs easy to
+// ship; the race detector flags it on the first run.
+func RacyHandoff(msg string) string {
+	var shared string
+	var ready bool
+	go func() {
+		shared = msg
+		ready = true
+	}()
+	for !ready {
+		runtime.Gosched()
+	}
+	return shared
+}
@@ -0,0 +1,14 @@
+//go:build racy
+
+package memmodel
+
+import "testing"
+
+// TestRacyHandoff fails under -race by design: the detector reports the
+// unsynchronised read of the message. Without -race it usually passes, which
+// is the point of the example.
+func TestRacyHandoff(t *testing.T) {
+	if got := RacyHandoff("hello"); got != "hello" {
+		t.Fatalf("message lost: got %q", got)
+	}
+}
@@ -0,0 +1,36 @@
+// Command contention runs the profiling walkthrough: it reproduces the lock
+// sharding pitfall under the mutex profiler and prints the hottest call sites.
+//
+//	go run ./profiling/cmd/contention -dir /tmp/profiles
+//	go tool pprof /tmp/profiles/contended/mutex.pprof
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/camilbenameur/learning/go/profiling"
+)
+
+func main() {
+	goroutines := flag.Int("goroutines", 16, "goroutines incrementing the counter")
+	iterations := flag.Int("iterations", 50, "increments per goroutine")
+	hold := flag.Duration("hold", 20*time.Microsecond, "time spent holding the lock per increment")
+	dir := flag.String("dir", "", "directory to write mutex and block profiles to")
+	top := flag.Int("top", 5, "call sites to show per run")
+	flag.Parse()
+
+	result, err := profiling.Walkthrough(profiling.WalkthroughOptions{
+		Goroutines: *goroutines,
+		Iterations: *iterations,
+		Hold:       *hold,
+		Dir:        *dir,
+		TopN:       *top,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	result.Print(os.Stdout)
+}
@@ -0,0 +1,267 @@
+// Package profiling turns on the runtime's mutex and block profilers around a
+// workload, writes the resulting pprof files, and summarises where goroutines
+// waited so tests can assert on contention instead of eyeballing `go tool pprof`.
+package profiling
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Profile names understood by runtime/pprof
+const (
+	MutexProfile = "mutex"
+	BlockProfile = "block"
+)
+
+// Options configures Run
+type Options struct {
+	MutexFraction int    // Report 1/n of mutex contention events; 0 means 1 (all)
+	BlockRate     int    // Sample one blocking event per n ns blocked; 0 means 1 (all)
+	Dir           string // Directory for mutex.pprof and block.pprof; empty skips writing
+	TopN          int    // Entries kept in each summary; 0 means 10
+}
+
+// Contention is where goroutines waited, aggregated by the first function
+// outside the runtime and sync packages (the code that took the lock)
+type Contention struct {
+	Function string
+	Stack    []string // Innermost frame first
+	Delay    time.Duration
+	Count    int64
+}
+
+// Summary is the top contention points of one profile
+type Summary struct {
+	Profile string
+	Total   time.Duration
+	Count   int64
+	Top     []Contention
+}
+
+// Enable turns on mutex and block profiling at the given rates and returns
+// a function restoring the previous settings
+func Enable(mutexFraction, blockRate int) (restore func()) {
+	prevMutex := runtime.SetMutexProfileFraction(mutexFraction)
+	runtime.SetBlockProfileRate(blockRate)
+	return func() {
+		runtime.SetMutexProfileFraction(prevMutex)
+		runtime.SetBlockProfileRate(0) // The runtime offers no way to read the old block rate
+	}
+}
+
+// Run profiles workload and returns mutex and block summaries covering only the
+// contention it caused. Profiles are cumulative for the process, so a summary is
+// the difference between what the profiler held before and after workload ran.
+func Run(opts Options, workload func()) (mutex, block Summary, err error) {
+	if opts.MutexFraction == 0 {
+		opts.MutexFraction = 1
+	}
+	if opts.BlockRate == 0 {
+		opts.BlockRate = 1
+	}
+	if opts.TopN == 0 {
+		opts.TopN = 10
+	}
+
+	restore := Enable(opts.MutexFraction, opts.BlockRate)
+	mutexBefore, err := capture(MutexProfile)
+	if err != nil {
+		restore()
+		return Summary{}, Summary{}, err
+	}
+	blockBefore, err := capture(BlockProfile)
+	if err != nil {
+		restore()
+		return Summary{}, Summary{}, err
+	}
+
+	workload()
+	restore()
+
+	if opts.Dir != "" {
+		for _, name := range []string{MutexProfile, BlockProfile} {
+			if err := WriteProfile(name, filepath.Join(opts.Dir, name+".pprof")); err != nil {
+				return Summary{}, Summary{}, err
+			}
+		}
+	}
+
+	mutexAfter, err := capture(MutexProfile)
+	if err != nil {
+		return Summary{}, Summary{}, err
+	}
+	blockAfter, err := capture(BlockProfile)
+	if err != nil {
+		return Summary{}, Summary{}, err
+	}
+	return summarize(MutexProfile, subtract(mutexAfter, mutexBefore), opts.TopN),
+		summarize(BlockProfile, subtract(blockAfter, blockBefore), opts.TopN), nil
+}
+
+// WriteProfile writes the named profile in pprof's protobuf format to path,
+// ready for `go tool pprof`
+func WriteProfile(name, path string) error {
+	p := pprof.Lookup(name)
+	if p == nil {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := p.WriteTo(f, 0); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Parse reads a mutex or block profile in the legacy text format
+// (pprof.Lookup(name).WriteTo(w, 1)) and returns its top n contention points
+func Parse(name string, r io.Reader, n int) (Summary, error) {
+	records, err := parseRecords(r)
+	if err != nil {
+		return Summary{}, err
+	}
+	return summarize(name, records, n), nil
+}
+
+// record is one stack from the text profile
+type record struct {
+	delay time.Duration
+	count int64
+	stack []string
+}
+
+func capture(name string) (map[string]record, error) {
+	var buf bytes.Buffer
+	if err := pprof.Lookup(name).WriteTo(&buf, 1); err != nil {
+		return nil, err
+	}
+	records, err := parseRecords(&buf)
+	if err != nil {
+		return nil, err
+	}
+	byStack := make(map[string]record, len(records))
+	for _, r := range records {
+		byStack[strings.Join(r.stack, "\n")] = r
+	}
+	return byStack, nil
+}
+
+func subtract(after, before map[string]record) []record {
+	var out []record
+	for key, r := range after {
+		if b, ok := before[key]; ok {
+			r.delay -= b.delay
+			r.count -= b.count
+		}
+		if r.count > 0 {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// parseRecords understands the text format:
+//
+//	--- mutex:
+//	cycles/second=2100000000
+//	<cycles> <count> @ 0x... 0x...
+//	#	0x...	sync.(*Mutex).Unlock+0x98	/path/mutex.go:65
+func parseRecords(r io.Reader) ([]record, error) {
+	var records []record
+	cyclesPerSecond := 1.0
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "cycles/second="):
+			v, err := strconv.ParseFloat(strings.TrimPrefix(line, "cycles/second="), 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse %q: %w", line, err)
+			}
+			cyclesPerSecond = v
+		case strings.Contains(line, " @ "):
+			fields := strings.Fields(line)
+			cycles, err := strconv.ParseFloat(fields[0], 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse %q: %w", line, err)
+			}
+			count, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse %q: %w", line, err)
+			}
+			records = append(records, record{
+				delay: time.Duration(cycles / cyclesPerSecond * float64(time.Second)),
+				count: count,
+			})
+		case strings.HasPrefix(line, "#\t") && len(records) > 0:
+			fields := strings.Fields(line)
+			if len(fields) >= 3 {
+				fn := fields[2]
+				if i := strings.LastIndex(fn, "+0x"); i > 0 {
+					fn = fn[:i]
+				}
+				last := &records[len(records)-1]
+				last.stack = append(last.stack, fn)
+			}
+		}
+	}
+	return records, scanner.Err()
+}
+
+func summarize(name string, records []record, n int) Summary {
+	s := Summary{Profile: name}
+	byFunc := map[string]*Contention{}
+	for _, r := range records {
+		s.Total += r.delay
+		s.Count += r.count
+		fn := callerOf(r.stack)
+		c, ok := byFunc[fn]
+		if !ok {
+			c = &Contention{Function: fn, Stack: r.stack}
+			byFunc[fn] = c
+		}
+		c.Delay += r.delay
+		c.Count += r.count
+	}
+	for _, c := range byFunc {
+		s.Top = append(s.Top, *c)
+	}
+	sort.Slice(s.Top, func(i, j int) bool {
+		if s.Top[i].Delay != s.Top[j].Delay {
+			return s.Top[i].Delay > s.Top[j].Delay
+		}
+		return s.Top[i].Function < s.Top[j].Function
+	})
+	if n > 0 && len(s.Top) > n {
+		s.Top = s.Top[:n]
+	}
+	return s
+}
+
+// callerOf returns the first frame that is not inside the runtime or sync packages
+func callerOf(stack []string) string {
+	for _, fn := range stack {
+		if !strings.HasPrefix(fn, "sync.") && !strings.HasPrefix(fn, "runtime.") &&
+			!strings.HasPrefix(fn, "internal/") {
+			return fn
+		}
+	}
+	if len(stack) > 0 {
+		return stack[0]
+	}
+	return "unknown"
+}
@@ -0,0 +1,129 @@
+package profiling
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+const sampleProfile = `--- mutex:
+cycles/second=1000000000
+sampling period=1
+3000000000 30 @ 0x1 0x2 0x3
+#	0x1	sync.(*Mutex).Unlock+0x98	/go/src/sync/mutex.go:65
+#	0x2	example.com/app.(*Store).Put+0x40	/app/store.go:10
+#	0x3	runtime.goexit+0x1	/go/src/runtime/asm.s:1
+
+1000000000 5 @ 0x1 0x4
+#	0x1	sync.(*Mutex).Unlock+0x98	/go/src/sync/mutex.go:65
+#	0x4	example.com/app.(*Store).Get+0x20	/app/store.go:20
+
+2000000000 10 @ 0x5 0x2
+#	0x5	sync.(*RWMutex).Unlock+0x10	/go/src/sync/rwmutex.go:200
+#	0x2	example.com/app.(*Store).Put+0x50	/app/store.go:12
+`
+
+func TestParse(t *testing.T) {
+	g := NewWithT(t)
+
+	s, err := Parse(MutexProfile, strings.NewReader(sampleProfile), 10)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(s.Profile).To(Equal(MutexProfile))
+	g.Expect(s.Total).To(Equal(6 * time.Second))
+	g.Expect(s.Count).To(Equal(int64(45)))
+
+	// Stacks are grouped by the caller outside sync, heaviest first
+	g.Expect(s.Top).To(HaveLen(2))
+	g.Expect(s.Top[0].Function).To(Equal("example.com/app.(*Store).Put"))
+	g.Expect(s.Top[0].Delay).To(Equal(5 * time.Second))
+	g.Expect(s.Top[0].Count).To(Equal(int64(40)))
+	g.Expect(s.Top[0].Stack[0]).To(Equal("sync.(*Mutex).Unlock"))
+	g.Expect(s.Top[1].Function).To(Equal("example.com/app.(*Store).Get"))
+
+	top1, err := Parse(MutexProfile, strings.NewReader(sampleProfile), 1)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(top1.Top).To(HaveLen(1))
+	g.Expect(top1.Total).To(Equal(6 * time.Second))
+
+	_, err = Parse(MutexProfile, strings.NewReader("cycles/second=x\n"), 1)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestRunContendedWorkload(t *testing.T) {
+	g := NewWithT(t)
+
+	dir := t.TempDir()
+	mutex, block, err := Run(Options{Dir: dir, TopN: 3}, ContendedWorkload(8, 25, 20*time.Microsecond))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// The single shared counter is where the workload waits
+	g.Expect(mutex.Count).To(BeNumerically(">", 0))
+	g.Expect(mutex.Top).NotTo(BeEmpty())
+	g.Expect(mutex.Top[0].Function).To(HaveSuffix("(*HighContentionCounter).IncrementHolding"))
+	g.Expect(len(mutex.Top)).To(BeNumerically("<=", 3))
+
+	g.Expect(block.Count).To(BeNumerically(">", 0))
+	g.Expect(block.Top).To(ContainElement(HaveField("Function", HaveSuffix("(*HighContentionCounter).IncrementHolding"))))
+
+	for _, name := range []string{"mutex.pprof", "block.pprof"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(info.Size()).To(BeNumerically(">", 0))
+	}
+}
+
+func TestRunOnlyCountsWorkload(t *testing.T) {
+	g := NewWithT(t)
+
+	// Contention from an earlier run is subtracted out
+	_, _, err := Run(Options{}, ContendedWorkload(8, 10, 20*time.Microsecond))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	mutex, _, err := Run(Options{}, func() {})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(mutex.Top).NotTo(ContainElement(HaveField("Function", HaveSuffix("(*HighContentionCounter).IncrementHolding"))))
+}
+
+func TestWorkloadsCount(t *testing.T) {
+	g := NewWithT(t)
+
+	sharded := &ShardedCounter{}
+	for i := 0; i < 32; i++ {
+		sharded.Increment(i)
+	}
+	g.Expect(sharded.Total()).To(Equal(int64(32)))
+
+	high := &HighContentionCounter{}
+	high.Increment()
+	g.Expect(high.Value()).To(Equal(int64(1)))
+
+	ShardedWorkload(4, 10, 0)()
+	g.Expect(WriteProfile("nope", filepath.Join(t.TempDir(), "x"))).To(MatchError(ContainSubstring("unknown profile")))
+}
+
+func TestWalkthrough(t *testing.T) {
+	g := NewWithT(t)
+
+	dir := t.TempDir()
+	result, err := Walkthrough(WalkthroughOptions{Goroutines: 8, Iterations: 25, Dir: dir, TopN: 3})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// The shared lock is the hottest site and sharding waits far less
+	g.Expect(result.Contended.Top).NotTo(BeEmpty())
+	g.Expect(result.Contended.Top[0].Function).To(HaveSuffix("(*HighContentionCounter).IncrementHolding"))
+	g.Expect(result.Sharded.Total).To(BeNumerically("<", result.Contended.Total/2))
+
+	for _, run := range []string{"contended", "sharded"} {
+		_, err := os.Stat(filepath.Join(dir, run, "mutex.pprof"))
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+
+	var buf strings.Builder
+	result.Print(&buf)
+	g.Expect(buf.String()).To(ContainSubstring("Single mutex:"))
+	g.Expect(buf.String()).To(ContainSubstring("1. "))
+}
@@ -0,0 +1,78 @@
+package profiling
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WalkthroughOptions sizes the contention walkthrough
+type WalkthroughOptions struct {
+	Goroutines int           // Defaults to 16
+	Iterations int           // Increments per goroutine; defaults to 50
+	Hold       time.Duration // Time spent inside each critical section; defaults to 20µs
+	Dir        string        // If set, profiles go to Dir/contended and Dir/sharded
+	TopN       int
+}
+
+// WalkthroughResult holds the mutex profile summaries of both workloads
+type WalkthroughResult struct {
+	Contended Summary // Single HighContentionCounter
+	Sharded   Summary // ShardedCounter doing the same work
+}
+
+// Walkthrough runs the sharding pitfall twice under the mutex profiler, first
+// with one shared lock and then sharded, and returns where each spent its time
+// waiting. The contended run's hottest call site is the shared Increment; the
+// sharded run should wait far less in total.
+func Walkthrough(opts WalkthroughOptions) (WalkthroughResult, error) {
+	if opts.Goroutines == 0 {
+		opts.Goroutines = 16
+	}
+	if opts.Iterations == 0 {
+		opts.Iterations = 50
+	}
+	if opts.Hold == 0 {
+		opts.Hold = 20 * time.Microsecond
+	}
+
+	var result WalkthroughResult
+	runs := []struct {
+		name     string
+		workload func()
+		summary  *Summary
+	}{
+		{"contended", ContendedWorkload(opts.Goroutines, opts.Iterations, opts.Hold), &result.Contended},
+		{"sharded", ShardedWorkload(opts.Goroutines, opts.Iterations, opts.Hold), &result.Sharded},
+	}
+	for _, run := range runs {
+		runOpts := Options{TopN: opts.TopN}
+		if opts.Dir != "" {
+			runOpts.Dir = filepath.Join(opts.Dir, run.name)
+			if err := os.MkdirAll(runOpts.Dir, 0o755); err != nil {
+				return WalkthroughResult{}, err
+			}
+		}
+		mutex, _, err := Run(runOpts, run.workload)
+		if err != nil {
+			return WalkthroughResult{}, fmt.Errorf("%s run: %w", run.name, err)
+		}
+		*run.summary = mutex
+	}
+	return result, nil
+}
+
+// Print writes both summaries as a small report
+func (r WalkthroughResult) Print(w io.Writer) {
+	for _, s := range []struct {
+		title   string
+		summary Summary
+	}{{"Single mutex", r.Contended}, {"Sharded", r.Sharded}} {
+		fmt.Fprintf(w, "%s: %v waiting across %d contention events\n", s.title, s.summary.Total, s.summary.Count)
+		for i, c := range s.summary.Top {
+			fmt.Fprintf(w, "  %d. %-60s %12v %6d\n", i+1, c.Function, c.Delay, c.Count)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package profiling
+
+import (
+	"sync"
+	"time"
+)
+
+// HighContentionCounter is the single-mutex counter from the pitfalls examples:
+// every goroutine serialises on one lock
+type HighContentionCounter struct {
+	mu    sync.Mutex
+	value int64
+}
+
+// Increment adds one under the shared lock
+func (c *HighContentionCounter) Increment() {
+	c.IncrementHolding(0)
+}
+
+// IncrementHolding adds one, keeping the lock for hold to simulate a slower
+// critical section. Without it, a single-CPU machine rarely preempts a goroutine
+// mid-increment and the profiler sees no contention at all.
+func (c *HighContentionCounter) IncrementHolding(hold time.Duration) {
+	c.mu.Lock()
+	c.value++
+	if hold > 0 {
+		time.Sleep(hold)
+	}
+	c.mu.Unlock()
+}
+
+// Value returns the count
+func (c *HighContentionCounter) Value() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.value
+}
+
+// ShardedCounter is the pitfalls examples' fix: 16 independently locked shards
+type ShardedCounter struct {
+	shards [16]struct {
+		mu    sync.Mutex
+		value int64
+	}
+}
+
+// Increment adds one to the shard chosen by id
+func (c *ShardedCounter) Increment(id int) {
+	c.IncrementHolding(id, 0)
+}
+
+// IncrementHolding is Increment keeping the shard lock for hold
+func (c *ShardedCounter) IncrementHolding(id int, hold time.Duration) {
+	shard := &c.shards[id%16]
+	shard.mu.Lock()
+	shard.value++
+	if hold > 0 {
+		time.Sleep(hold)
+	}
+	shard.mu.Unlock()
+}
+
+// Total sums every shard
+func (c *ShardedCounter) Total() int64 {
+	var total int64
+	for i := range c.shards {
+		c.shards[i].mu.Lock()
+		total += c.shards[i].value
+		c.shards[i].mu.Unlock()
+	}
+	return total
+}
+
+// ContendedWorkload returns a workload where goroutines each increment a
+// HighContentionCounter iterations times, as in demonstrateContention,
+// holding the lock for hold on every increment
+func ContendedWorkload(goroutines, iterations int, hold time.Duration) func() {
+	return func() {
+		c := &HighContentionCounter{}
+		var wg sync.WaitGroup
+		for i := 0; i < goroutines; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < iterations; j++ {
+					c.IncrementHolding(hold)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+// ShardedWorkload is ContendedWorkload using a ShardedCounter instead
+func ShardedWorkload(goroutines, iterations int, hold time.Duration) func() {
+	return func() {
+		c := &ShardedCounter{}
+		var wg sync.WaitGroup
+		for i := 0; i < goroutines; i++ {
+			wg.Add(1)
+			go func(id int) {
+				defer wg.Done()
+				for j := 0; j < iterations; j++ {
+					c.IncrementHolding(id, hold)
+				}
+			}(i)
+		}
+		wg.Wait()
+	}
+}
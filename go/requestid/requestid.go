@@ -0,0 +1,69 @@
+// Package requestid carries a request ID in a context.Context, so work handed
+// to other goroutines (worker pools, background jobs) can still be tied back to
+// the HTTP request that caused it in logs and metrics.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// Header is the HTTP header a request ID is read from and echoed in
+const Header = "X-Request-ID"
+
+// maxLen bounds client-supplied IDs so they cannot bloat logs
+const maxLen = 64
+
+// contextKey is unexported so no other package can collide with it
+type contextKey struct{}
+
+// WithRequestID returns a copy of ctx carrying id
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID in ctx, if any
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(contextKey{}).(string)
+	return id, ok && id != ""
+}
+
+// New returns a random 16-character hex ID
+func New() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err) // crypto/rand only fails if the OS entropy source is broken
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// Middleware gives every request an ID: the client's Header if it is a sane
+// token, otherwise a new one. The ID is stored in the request context and
+// echoed in the response Header.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(Header)
+		if !valid(id) {
+			id = New()
+		}
+		w.Header().Set(Header, id)
+		next.ServeHTTP(w, r.WithContext(WithRequestID(r.Context(), id)))
+	})
+}
+
+// valid accepts non-empty IDs of letters, digits, '-', '_' and '.'
+func valid(id string) bool {
+	if id == "" || len(id) > maxLen {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestContext(t *testing.T) {
+	g := NewWithT(t)
+
+	_, ok := FromContext(context.Background())
+	g.Expect(ok).To(BeFalse())
+
+	ctx := WithRequestID(context.Background(), "abc")
+	id, ok := FromContext(ctx)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(id).To(Equal("abc"))
+
+	// The ID survives being handed to another goroutine with the context
+	got := make(chan string, 1)
+	go func(ctx context.Context) {
+		id, _ := FromContext(ctx)
+		got <- id
+	}(ctx)
+	g.Expect(<-got).To(Equal("abc"))
+}
+
+func TestNew(t *testing.T) {
+	g := NewWithT(t)
+
+	a, b := New(), New()
+	g.Expect(a).To(MatchRegexp(`^[0-9a-f]{16}$`))
+	g.Expect(a).NotTo(Equal(b))
+}
+
+func TestMiddleware(t *testing.T) {
+	g := NewWithT(t)
+
+	var seen string
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = FromContext(r.Context())
+	}))
+
+	for _, tc := range []struct {
+		header string
+		keep   bool
+	}{
+		{"client-id_1.2", true},
+		{"", false},
+		{"has space", false},
+		{strings.Repeat("x", maxLen+1), false},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tc.header != "" {
+			req.Header.Set(Header, tc.header)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		g.Expect(rec.Header().Get(Header)).To(Equal(seen))
+		if tc.keep {
+			g.Expect(seen).To(Equal(tc.header))
+		} else {
+			g.Expect(seen).To(MatchRegexp(`^[0-9a-f]{16}$`), tc.header)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Package retry runs an operation until it succeeds, sleeping between
+// attempts with capped exponential backoff and full jitter: the n-th sleep is
+// a uniformly random duration between zero and min(Max, Initial*Multiplier^n).
+// The randomness spreads out clients that failed together, so they do not
+// come back in lockstep and knock the recovering service over again.
+//
+//	err := retry.Do(ctx, retry.Policy{MaxAttempts: 5}, func(ctx context.Context) error {
+//		return fetch(ctx, url)
+//	})
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/camilbenameur/learning/go/examples"
+)
+
+// Defaults for zero Policy fields
+const (
+	DefaultInitial    = 100 * time.Millisecond
+	DefaultMax        = 10 * time.Second
+	DefaultMultiplier = 2.0
+)
+
+// Policy says how often and for how long to retry
+type Policy struct {
+	MaxAttempts int           // Attempts in total, the first included; 0 means unlimited
+	MaxElapsed  time.Duration // Gives up rather than sleep past this since the first attempt; 0 means unlimited
+	Initial     time.Duration // Cap on the first sleep; DefaultInitial if zero
+	Max         time.Duration // Cap on any sleep; DefaultMax if zero
+	Multiplier  float64       // Growth of the cap per attempt; DefaultMultiplier if zero
+
+	// Retryable reports whether an error is worth retrying; nil retries
+	// every error. Errors wrapped with Permanent are never retried.
+	Retryable func(error) bool
+
+	Clock examples.Clock // RealClock if nil
+}
+
+// permanentError marks an error that must not be retried
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that Do returns it at once instead of retrying.
+// Do unwraps it again, so callers see err itself.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Do calls fn until it returns nil, returns a non-retryable error, or the
+// policy's limits are reached, and returns nil or the last error. The error
+// for a run that hit a limit, or whose ctx ended while sleeping, wraps both
+// the cause and fn's last error, so errors.Is works on either.
+func Do(ctx context.Context, p Policy, fn func(ctx context.Context) error) error {
+	p = p.withDefaults()
+	start := p.Clock.Now()
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		var perm *permanentError
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+		if p.Retryable != nil && !p.Retryable(err) {
+			return err
+		}
+		if p.MaxAttempts > 0 && attempt >= p.MaxAttempts {
+			return fmt.Errorf("retry: gave up after %d attempts: %w", attempt, err)
+		}
+
+		delay := p.Delay(attempt)
+		if p.MaxElapsed > 0 && p.Clock.Since(start)+delay > p.MaxElapsed {
+			return fmt.Errorf("retry: gave up after %d attempts in %v: %w", attempt, p.Clock.Since(start), err)
+		}
+		timer := p.Clock.NewTimer(delay)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("retry: %w after %d attempts: %w", ctx.Err(), attempt, err)
+		}
+	}
+}
+
+// Delay returns the sleep after the given failed attempt, counting from 1:
+// a random duration in [0, min(Max, Initial*Multiplier^(attempt-1))]
+func (p Policy) Delay(attempt int) time.Duration {
+	p = p.withDefaults()
+	limit := float64(p.Initial)
+	for i := 1; i < attempt && limit < float64(p.Max); i++ {
+		limit *= p.Multiplier
+	}
+	limit = min(limit, float64(p.Max))
+	return time.Duration(rand.Int64N(int64(limit) + 1))
+}
+
+func (p Policy) withDefaults() Policy {
+	if p.Initial <= 0 {
+		p.Initial = DefaultInitial
+	}
+	if p.Max <= 0 {
+		p.Max = DefaultMax
+	}
+	if p.Multiplier <= 0 {
+		p.Multiplier = DefaultMultiplier
+	}
+	if p.Clock == nil {
+		p.Clock = examples.RealClock
+	}
+	return p
+}
@@ -0,0 +1,126 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/camilbenameur/learning/go/examples"
+)
+
+var errUnavailable = errors.New("service unavailable")
+
+// flaky returns an fn that fails with err until it has been called n times
+func flaky(n int, err error, calls *int) func(context.Context) error {
+	return func(context.Context) error {
+		*calls++
+		if *calls <= n {
+			return err
+		}
+		return nil
+	}
+}
+
+func TestDoBacksOffBetweenAttempts(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := examples.NewFakeClock(time.Unix(0, 0))
+	p := Policy{Initial: 100 * time.Millisecond, Max: 300 * time.Millisecond, Clock: clock}
+	calls := 0
+	done := make(chan error, 1)
+	go func() { done <- Do(context.Background(), p, flaky(4, errUnavailable, &calls)) }()
+
+	// Each sleep is at most its cap: 100ms, 200ms, then 300ms for good
+	for _, limit := range []time.Duration{100, 200, 300, 300} {
+		clock.BlockUntil(1)
+		g.Expect(done).NotTo(Receive())
+		clock.Advance(limit * time.Millisecond)
+	}
+	g.Eventually(done).Should(Receive(BeNil()))
+	g.Expect(calls).To(Equal(5))
+}
+
+func TestDelayFullJitter(t *testing.T) {
+	g := NewWithT(t)
+
+	p := Policy{Initial: 10 * time.Millisecond, Max: 50 * time.Millisecond}
+	caps := []time.Duration{10, 20, 40, 50, 50}
+	for attempt, limit := range caps {
+		var lowest, highest time.Duration = time.Hour, 0
+		for i := 0; i < 1000; i++ {
+			d := p.Delay(attempt + 1)
+			lowest, highest = min(lowest, d), max(highest, d)
+		}
+		// Spread over the whole range, not bunched just under the cap
+		g.Expect(lowest).To(BeNumerically("<", limit*time.Millisecond/4))
+		g.Expect(highest).To(BeNumerically(">", limit*time.Millisecond*3/4))
+		g.Expect(highest).To(BeNumerically("<=", limit*time.Millisecond))
+	}
+}
+
+func TestDoLimits(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	clock := examples.NewFakeClock(time.Unix(0, 0))
+
+	// MaxAttempts counts the first call
+	calls := 0
+	p := Policy{MaxAttempts: 3, Initial: time.Nanosecond, Max: time.Nanosecond}
+	err := Do(ctx, p, flaky(10, errUnavailable, &calls))
+	g.Expect(err).To(MatchError(errUnavailable))
+	g.Expect(err).To(MatchError(ContainSubstring("gave up after 3 attempts")))
+	g.Expect(calls).To(Equal(3))
+
+	// MaxElapsed stops before a sleep that would overrun it
+	calls = 0
+	p = Policy{MaxElapsed: 250 * time.Millisecond, Initial: 100 * time.Millisecond, Max: 100 * time.Millisecond, Clock: clock}
+	done := make(chan error, 1)
+	go func() {
+		done <- Do(ctx, p, func(context.Context) error {
+			calls++
+			clock.Advance(100 * time.Millisecond) // Each attempt takes 100ms
+			return errUnavailable
+		})
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(100 * time.Millisecond)
+	g.Eventually(done).Should(Receive(MatchError(errUnavailable)))
+	g.Expect(calls).To(Equal(2))
+
+	// Ending ctx interrupts the sleep
+	cctx, cancel := context.WithCancel(ctx)
+	calls = 0
+	go func() {
+		done <- Do(cctx, Policy{Clock: clock}, flaky(10, errUnavailable, &calls))
+	}()
+	clock.BlockUntil(1)
+	cancel()
+	g.Eventually(done).Should(Receive(SatisfyAll(MatchError(context.Canceled), MatchError(errUnavailable))))
+	g.Expect(calls).To(Equal(1))
+}
+
+func TestDoClassifiesErrors(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	errNotFound := errors.New("not found")
+	p := Policy{
+		MaxAttempts: 5, Initial: time.Nanosecond,
+		Retryable: func(err error) bool { return !errors.Is(err, errNotFound) },
+	}
+
+	calls := 0
+	g.Expect(Do(ctx, p, flaky(10, errNotFound, &calls))).To(Equal(errNotFound))
+	g.Expect(calls).To(Equal(1))
+
+	// Permanent stops retrying even when the classifier would retry, and is unwrapped
+	calls = 0
+	g.Expect(Do(ctx, p, flaky(10, Permanent(errUnavailable), &calls))).To(Equal(errUnavailable))
+	g.Expect(calls).To(Equal(1))
+
+	calls = 0
+	g.Expect(Do(ctx, p, flaky(2, errUnavailable, &calls))).To(Succeed())
+	g.Expect(calls).To(Equal(3))
+}
@@ -0,0 +1,69 @@
+// Command server runs the demo HTTP server with a metrics reporter and stops
+// everything in order on SIGINT or SIGTERM: the listener first, then the
+// workers, then the reporter, which logs one final interval.
+//
+//	go run ./server/cmd/server -addr :8080
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/camilbenameur/learning/go/examples"
+	"github.com/camilbenameur/learning/go/server"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	workers := flag.Int("workers", 4, "background workers")
+	interval := flag.Duration("report", 10*time.Second, "metrics report interval")
+	timeout := flag.Duration("shutdown-timeout", 5*time.Second, "time each component gets to stop")
+	flag.Parse()
+
+	sd := examples.NewShutdown(*timeout)
+	s := server.New(examples.Config{MaxConnections: 100, Timeout: 30}, *workers)
+
+	reporter := examples.NewReporter(s.Metrics(), *interval, nil, func(snap examples.MetricsSnapshot) {
+		log.Printf("requests=%d errors=%d max latency=%v", snap.Counters[examples.CounterRequests],
+			snap.Counters[examples.CounterErrors], snap.Latency.Max)
+	})
+	reporter.Start()
+	sd.Register("reporter", 0, func(context.Context) error {
+		reporter.Stop()
+		return nil
+	})
+
+	hs := &http.Server{Addr: *addr, Handler: s.Handler()}
+	s.Start()
+	s.RegisterShutdown(sd, hs)
+
+	// The listener and the shutdown manager run as a group. A signal runs the
+	// hooks, which close the listener; a listener that fails triggers them.
+	// Either way both actors return the error, if any, of the same shutdown.
+	var group examples.RunGroup
+	group.Add(func() error {
+		report := sd.Wait()
+		for _, h := range report.Hooks {
+			log.Printf("stopped %s in %v", h.Name, h.Duration)
+		}
+		return report.Err()
+	}, func(error) {
+		sd.Trigger()
+	})
+	group.Add(func() error {
+		log.Printf("listening on %s", *addr)
+		if err := hs.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return sd.Run().Err()
+	}, func(error) {
+		sd.Trigger()
+	})
+	if err := group.Run(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,250 @@
+// Package server is a small HTTP service that wires the examples primitives together:
+// Metrics and a CounterVec for instrumentation, a pool of Workers doing background
+// work, and AtomicConfig for configuration that can be swapped while serving.
+// Every request gets a request ID, echoed in X-Request-ID and recorded with
+// each entry in the server's EventLog.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/camilbenameur/learning/go/examples"
+	"github.com/camilbenameur/learning/go/requestid"
+)
+
+// eventLogSize is how many recent requests the server remembers for debugging
+const eventLogSize = 256
+
+// ApdexThreshold is the response time under which a request counts as satisfied
+const ApdexThreshold = 100 * time.Millisecond
+
+// Server exposes /metrics, /healthz, /config and /work
+type Server struct {
+	metrics   *examples.Metrics
+	endpoints *examples.CounterVec
+	apdex     *examples.Apdex
+	config    *examples.AtomicConfig
+	events    *examples.EventLog
+	workers   []*examples.Worker
+	next      uint64 // Round-robin cursor over workers
+	mux       *http.ServeMux
+}
+
+// New creates a server with the given initial config and number of workers.
+// Workers are not started until Start is called.
+func New(cfg examples.Config, workers int) *Server {
+	if workers < 1 {
+		workers = 1
+	}
+	s := &Server{
+		metrics:   &examples.Metrics{},
+		endpoints: examples.NewCounterVec("http_requests_total", "Requests by method, route and status.", "method", "route", "status"),
+		apdex:     examples.NewApdex(ApdexThreshold),
+		config:    examples.NewAtomicConfig(cfg),
+		events:    examples.NewEventLog(eventLogSize, nil),
+		mux:       http.NewServeMux(),
+	}
+	for i := 0; i < workers; i++ {
+		s.workers = append(s.workers, examples.NewWorker())
+	}
+
+	s.handle("GET /metrics", s.handleMetrics)
+	s.handle("GET /healthz", s.handleHealth)
+	s.handle("GET /config", s.handleGetConfig)
+	s.handle("PUT /config", s.handlePutConfig)
+	s.handle("POST /work", s.handleWork)
+	return s
+}
+
+// Start launches every worker
+func (s *Server) Start() {
+	for _, w := range s.workers {
+		w.Start()
+	}
+}
+
+// Stop stops every worker; /healthz reports unhealthy afterwards
+func (s *Server) Stop() {
+	for _, w := range s.workers {
+		w.Stop()
+	}
+}
+
+// RegisterShutdown registers the server's shutdown hooks with sd: the workers
+// first, then hs, so that on shutdown hs stops taking requests and drains the
+// ones in flight before the workers those requests submit to are stopped
+func (s *Server) RegisterShutdown(sd *examples.Shutdown, hs *http.Server) {
+	sd.Register("workers", 0, func(context.Context) error {
+		s.Stop()
+		return nil
+	})
+	sd.Register("http", 0, hs.Shutdown)
+}
+
+// Handler returns the HTTP handler serving all routes
+func (s *Server) Handler() http.Handler {
+	return requestid.Middleware(s.mux)
+}
+
+// Metrics returns the server's request metrics
+func (s *Server) Metrics() *examples.Metrics {
+	return s.metrics
+}
+
+// Events returns the log of recent requests and work items, keyed by request ID
+func (s *Server) Events() *examples.EventLog {
+	return s.events
+}
+
+// handle registers h under pattern, instrumented with the pattern as its route label
+// so per-endpoint counters stay bounded no matter which paths clients request
+func (s *Server) handle(pattern string, h http.HandlerFunc) {
+	s.mux.Handle(pattern, s.instrument(pattern, h))
+}
+
+func (s *Server) instrument(route string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		elapsed := time.Since(start)
+		s.metrics.ObserveDuration(examples.HistogramLatency, elapsed)
+
+		s.metrics.RecordRequest()
+		s.metrics.RecordBytes(rec.bytes)
+		if rec.status >= http.StatusInternalServerError {
+			s.metrics.RecordError()
+			s.apdex.ObserveFrustrated()
+		} else {
+			s.apdex.Observe(elapsed)
+		}
+		s.endpoints.With(r.Method, route, strconv.Itoa(rec.status)).Inc()
+
+		id, _ := requestid.FromContext(r.Context())
+		s.events.Log("request", "request_id", id, "route", route, "status", rec.status, "duration", elapsed)
+	})
+}
+
+// statusRecorder captures the status code and body size written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// MetricsResponse is the body of GET /metrics
+type MetricsResponse struct {
+	Metrics   examples.MetricsSnapshot `json:"metrics"`
+	Endpoints []examples.CounterSample `json:"endpoints"`
+	Apdex     examples.ApdexSnapshot   `json:"apdex"`
+	Processed int64                    `json:"processed"`
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	resp := MetricsResponse{
+		Metrics:   s.metrics.Snapshot(),
+		Endpoints: s.endpoints.Snapshot(),
+		Apdex:     s.apdex.Snapshot(),
+	}
+	for _, worker := range s.workers {
+		resp.Processed += worker.ProcessedCount()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// HealthResponse is the body of GET /healthz
+type HealthResponse struct {
+	Status  string `json:"status"`
+	Running int    `json:"running"`
+	Workers int    `json:"workers"`
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	resp := HealthResponse{Status: "ok", Workers: len(s.workers)}
+	for _, worker := range s.workers {
+		if worker.IsRunning() {
+			resp.Running++
+		}
+	}
+	status := http.StatusOK
+	if resp.Running < resp.Workers {
+		resp.Status = "unavailable"
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, resp)
+}
+
+func (s *Server) handleGetConfig(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.config.Get())
+}
+
+func (s *Server) handlePutConfig(w http.ResponseWriter, r *http.Request) {
+	var cfg examples.Config
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := validateConfig(cfg); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	s.config.Update(cfg)
+	writeJSON(w, http.StatusOK, cfg)
+}
+
+func validateConfig(cfg examples.Config) error {
+	if cfg.MaxConnections < 0 {
+		return errors.New("MaxConnections must not be negative")
+	}
+	if cfg.Timeout < 0 {
+		return errors.New("Timeout must not be negative")
+	}
+	return nil
+}
+
+// handleWork hands one item to the next worker in round-robin order
+func (s *Server) handleWork(w http.ResponseWriter, r *http.Request) {
+	item, err := strconv.Atoi(r.URL.Query().Get("item"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("item must be an integer"))
+		return
+	}
+	worker := s.workers[(atomic.AddUint64(&s.next, 1)-1)%uint64(len(s.workers))]
+	if !worker.IsRunning() {
+		writeError(w, http.StatusServiceUnavailable, errors.New("worker not running"))
+		return
+	}
+	worker.Submit(item)
+	id, _ := requestid.FromContext(r.Context())
+	s.events.Log("work queued", "request_id", id, "item", item)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
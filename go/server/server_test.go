@@ -0,0 +1,200 @@
+package server
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/camilbenameur/learning/go/examples"
+	"github.com/camilbenameur/learning/go/requestid"
+)
+
+func newTestServer(t *testing.T) (*Server, *httptest.Server) {
+	s := New(examples.Config{MaxConnections: 10, Timeout: 30}, 2)
+	s.Start()
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(func() {
+		ts.Close()
+		s.Stop()
+	})
+	return s, ts
+}
+
+func getJSON(g *WithT, url string, v interface{}) int {
+	resp, err := http.Get(url)
+	g.Expect(err).NotTo(HaveOccurred())
+	defer resp.Body.Close()
+	g.Expect(resp.Header.Get("Content-Type")).To(Equal("application/json"))
+	g.Expect(json.NewDecoder(resp.Body).Decode(v)).To(Succeed())
+	return resp.StatusCode
+}
+
+func TestHealthz(t *testing.T) {
+	g := NewWithT(t)
+	s, ts := newTestServer(t)
+
+	var health HealthResponse
+	g.Expect(getJSON(g, ts.URL+"/healthz", &health)).To(Equal(http.StatusOK))
+	g.Expect(health).To(Equal(HealthResponse{Status: "ok", Running: 2, Workers: 2}))
+
+	s.Stop()
+	g.Expect(getJSON(g, ts.URL+"/healthz", &health)).To(Equal(http.StatusServiceUnavailable))
+	g.Expect(health.Status).To(Equal("unavailable"))
+	g.Expect(health.Running).To(Equal(0))
+}
+
+func TestConfig(t *testing.T) {
+	g := NewWithT(t)
+	_, ts := newTestServer(t)
+
+	var cfg examples.Config
+	g.Expect(getJSON(g, ts.URL+"/config", &cfg)).To(Equal(http.StatusOK))
+	g.Expect(cfg.MaxConnections).To(Equal(10))
+
+	put := func(body string) int {
+		req, err := http.NewRequest(http.MethodPut, ts.URL+"/config", strings.NewReader(body))
+		g.Expect(err).NotTo(HaveOccurred())
+		resp, err := http.DefaultClient.Do(req)
+		g.Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	g.Expect(put(`{"MaxConnections":50,"Timeout":5,"Debug":true}`)).To(Equal(http.StatusOK))
+	g.Expect(getJSON(g, ts.URL+"/config", &cfg)).To(Equal(http.StatusOK))
+	g.Expect(cfg).To(Equal(examples.Config{MaxConnections: 50, Timeout: 5, Debug: true}))
+
+	// Rejected updates leave the config untouched
+	g.Expect(put(`{"MaxConnections":-1}`)).To(Equal(http.StatusUnprocessableEntity))
+	g.Expect(put(`{"Unknown":1}`)).To(Equal(http.StatusBadRequest))
+	g.Expect(put(`not json`)).To(Equal(http.StatusBadRequest))
+	g.Expect(getJSON(g, ts.URL+"/config", &cfg)).To(Equal(http.StatusOK))
+	g.Expect(cfg.MaxConnections).To(Equal(50))
+
+	// Unsupported methods are refused by the mux
+	resp, err := http.Post(ts.URL+"/config", "application/json", strings.NewReader(`{}`))
+	g.Expect(err).NotTo(HaveOccurred())
+	resp.Body.Close()
+	g.Expect(resp.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+}
+
+func TestWorkAndMetrics(t *testing.T) {
+	g := NewWithT(t)
+	_, ts := newTestServer(t)
+
+	for i := 0; i < 10; i++ {
+		resp, err := http.Post(ts.URL+"/work?item="+strconv.Itoa(i), "", nil)
+		g.Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+		g.Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+	}
+	resp, err := http.Post(ts.URL+"/work?item=x", "", nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	resp.Body.Close()
+	g.Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+
+	// Workers drain the queue in the background
+	g.Eventually(func() int64 {
+		var m MetricsResponse
+		getJSON(g, ts.URL+"/metrics", &m)
+		return m.Processed
+	}).Should(Equal(int64(10)))
+
+	var m MetricsResponse
+	g.Expect(getJSON(g, ts.URL+"/metrics", &m)).To(Equal(http.StatusOK))
+	g.Expect(m.Metrics.Counters[examples.CounterRequests]).To(BeNumerically(">=", 11))
+	g.Expect(m.Metrics.Counters[examples.CounterErrors]).To(Equal(int64(0)))
+	g.Expect(m.Metrics.Histograms[examples.HistogramLatency].Count).To(BeNumerically(">=", 11))
+	g.Expect(m.Apdex.Threshold).To(Equal(ApdexThreshold))
+	g.Expect(m.Apdex.Satisfied).To(BeNumerically(">=", 11))
+	g.Expect(m.Apdex.Score).To(Equal(1.0))
+	g.Expect(m.Endpoints).To(ContainElement(examples.CounterSample{
+		Labels: map[string]string{"method": "POST", "route": "POST /work", "status": "202"},
+		Value:  10,
+	}))
+	g.Expect(m.Endpoints).To(ContainElement(examples.CounterSample{
+		Labels: map[string]string{"method": "POST", "route": "POST /work", "status": "400"},
+		Value:  1,
+	}))
+}
+
+func TestWorkWhenStopped(t *testing.T) {
+	g := NewWithT(t)
+	s, ts := newTestServer(t)
+	s.Stop()
+
+	resp, err := http.Post(ts.URL+"/work?item=1", "", nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	resp.Body.Close()
+	g.Expect(resp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+	g.Expect(s.Metrics().Snapshot().Counters[examples.CounterErrors]).To(Equal(int64(1)))
+
+	// Server errors frustrate users no matter how quickly they come back
+	g.Expect(s.apdex.Snapshot().Frustrated).To(Equal(int64(1)))
+	g.Expect(s.apdex.Score()).To(Equal(0.0))
+}
+
+func TestRequestIDs(t *testing.T) {
+	g := NewWithT(t)
+	s, ts := newTestServer(t)
+
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/work?item=7", nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	req.Header.Set(requestid.Header, "trace-42")
+	resp, err := http.DefaultClient.Do(req)
+	g.Expect(err).NotTo(HaveOccurred())
+	resp.Body.Close()
+	g.Expect(resp.Header.Get(requestid.Header)).To(Equal("trace-42"))
+
+	// Requests without an ID get a fresh one
+	resp, err = http.Get(ts.URL + "/healthz")
+	g.Expect(err).NotTo(HaveOccurred())
+	resp.Body.Close()
+	generated := resp.Header.Get(requestid.Header)
+	g.Expect(generated).NotTo(BeEmpty())
+
+	// The event log ties the work item and both requests back to their IDs
+	var entries []string
+	for _, e := range s.Events().Dump() {
+		entries = append(entries, e.Message+" "+e.Fields["request_id"].(string))
+	}
+	g.Expect(entries).To(Equal([]string{
+		"work queued trace-42",
+		"request trace-42",
+		"request " + generated,
+	}))
+}
+
+func TestRegisterShutdown(t *testing.T) {
+	g := NewWithT(t)
+
+	s := New(examples.Config{MaxConnections: 10, Timeout: 30}, 2)
+	s.Start()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	g.Expect(err).NotTo(HaveOccurred())
+	hs := &http.Server{Handler: s.Handler()}
+	served := make(chan error, 1)
+	go func() { served <- hs.Serve(ln) }()
+
+	sd := examples.NewShutdown(time.Second)
+	s.RegisterShutdown(sd, hs)
+	report := sd.Run()
+
+	// The listener stops before the workers, and both stop cleanly
+	g.Expect(report.Err()).NotTo(HaveOccurred())
+	g.Expect(report.Hooks).To(HaveLen(2))
+	g.Expect(report.Hooks[0].Name).To(Equal("http"))
+	g.Expect(report.Hooks[1].Name).To(Equal("workers"))
+	g.Eventually(served).Should(Receive(MatchError(http.ErrServerClosed)))
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	g.Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+}
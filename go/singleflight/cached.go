@@ -0,0 +1,125 @@
+package singleflight
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CachedGroupStats is a point-in-time view of a CachedGroup's activity
+type CachedGroupStats struct {
+	Calls      int64 // Calls to Do
+	Executions int64 // Runs of fn
+	Coalesced  int64 // Calls that joined a run in flight
+	CacheHits  int64 // Calls served a result finished within the TTL
+}
+
+// cachedResult is a successful result and when it stops being shared
+type cachedResult struct {
+	val     any
+	expires time.Time
+}
+
+// CachedGroup is a Group that goes on sharing a successful result with
+// callers arriving up to ttl after it finished, not just with those that
+// arrived while it ran. Under a steady stream of requests for a hot key that
+// turns one backend call per burst into one per ttl: request coalescing plus
+// a micro-cache. Errors are shared only with callers already waiting, so a
+// failure is retried by the next caller.
+type CachedGroup struct {
+	group Group
+	ttl   time.Duration
+	now   func() time.Time
+
+	mu      sync.Mutex
+	results map[string]cachedResult
+
+	calls      int64
+	executions int64
+	coalesced  int64
+	hits       int64
+}
+
+// NewCachedGroup creates a group sharing results for ttl, reading the time
+// from now (time.Now if nil)
+func NewCachedGroup(ttl time.Duration, now func() time.Time) *CachedGroup {
+	if now == nil {
+		now = time.Now
+	}
+	return &CachedGroup{ttl: ttl, now: now, results: make(map[string]cachedResult)}
+}
+
+// Do returns a result for key finished within the last ttl if there is one,
+// and otherwise behaves like Group.Do. shared reports whether the result went,
+// or may yet go, to more than one caller.
+func (g *CachedGroup) Do(key string, fn func() (any, error)) (v any, err error, shared bool) {
+	atomic.AddInt64(&g.calls, 1)
+	if v, ok := g.cached(key); ok {
+		atomic.AddInt64(&g.hits, 1)
+		return v, nil, true
+	}
+
+	led := false
+	v, err, shared = g.group.Do(key, func() (any, error) {
+		led = true
+		atomic.AddInt64(&g.executions, 1)
+		v, err := fn()
+		if err == nil {
+			// Stored before the call record is removed, so there is no gap in
+			// which a caller finds neither and runs fn again
+			g.mu.Lock()
+			g.results[key] = cachedResult{val: v, expires: g.now().Add(g.ttl)}
+			g.mu.Unlock()
+		}
+		return v, err
+	})
+	if !led {
+		atomic.AddInt64(&g.coalesced, 1)
+	}
+	return v, err, shared || err == nil
+}
+
+// cached returns key's result if it has not expired, dropping it if it has
+func (g *CachedGroup) cached(key string) (any, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	r, ok := g.results[key]
+	if !ok {
+		return nil, false
+	}
+	if !g.now().Before(r.expires) {
+		delete(g.results, key)
+		return nil, false
+	}
+	return r.val, true
+}
+
+// Forget drops key's shared result, so the next Do for it runs fn
+func (g *CachedGroup) Forget(key string) {
+	g.mu.Lock()
+	delete(g.results, key)
+	g.mu.Unlock()
+}
+
+// Purge drops every expired result. Expired results are otherwise only
+// dropped when their key is next requested.
+func (g *CachedGroup) Purge() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := g.now()
+	for key, r := range g.results {
+		if !now.Before(r.expires) {
+			delete(g.results, key)
+		}
+	}
+}
+
+// Stats returns the group's counters
+func (g *CachedGroup) Stats() CachedGroupStats {
+	return CachedGroupStats{
+		Calls:      atomic.LoadInt64(&g.calls),
+		Executions: atomic.LoadInt64(&g.executions),
+		Coalesced:  atomic.LoadInt64(&g.coalesced),
+		CacheHits:  atomic.LoadInt64(&g.hits),
+	}
+}
@@ -0,0 +1,110 @@
+package singleflight
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// manualClock is a time source that only moves when advanced. The examples
+// package's FakeClock cannot be used here, since examples imports this package.
+type manualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestCachedGroupSharesWithinTTL(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := &manualClock{now: time.Unix(0, 0)}
+	group := NewCachedGroup(time.Second, clock.Now)
+	runs := 0
+	release := make(chan struct{})
+	fn := func() (any, error) {
+		runs++
+		<-release
+		return runs, nil
+	}
+
+	// Callers during the run coalesce onto it
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			group.Do("key", fn)
+		}()
+	}
+	g.Eventually(func() int { return group.group.Duplicates("key") }).Should(Equal(9))
+	close(release)
+	wg.Wait()
+
+	// Callers after it, within the TTL, get the stored result
+	clock.Advance(999 * time.Millisecond)
+	v, err, shared := group.Do("key", fn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(v).To(Equal(1))
+	g.Expect(shared).To(BeTrue())
+	g.Expect(group.Stats()).To(Equal(CachedGroupStats{Calls: 11, Executions: 1, Coalesced: 9, CacheHits: 1}))
+
+	// Once it expires the next caller runs fn again
+	clock.Advance(time.Millisecond)
+	v, _, _ = group.Do("key", fn)
+	g.Expect(v).To(Equal(2))
+	g.Expect(group.Stats().Executions).To(BeEquivalentTo(2))
+}
+
+func TestCachedGroupDoesNotCacheErrors(t *testing.T) {
+	g := NewWithT(t)
+
+	group := NewCachedGroup(time.Minute, nil)
+	errFetch := errors.New("fetch failed")
+	runs := 0
+	fail := func() (any, error) {
+		runs++
+		return nil, errFetch
+	}
+
+	_, err, shared := group.Do("key", fail)
+	g.Expect(err).To(MatchError(errFetch))
+	g.Expect(shared).To(BeFalse())
+	_, err, _ = group.Do("key", fail)
+	g.Expect(err).To(MatchError(errFetch))
+	g.Expect(runs).To(Equal(2))
+	g.Expect(group.Stats().CacheHits).To(BeZero())
+}
+
+func TestCachedGroupForgetAndPurge(t *testing.T) {
+	g := NewWithT(t)
+
+	clock := &manualClock{now: time.Unix(0, 0)}
+	group := NewCachedGroup(time.Second, clock.Now)
+	value := func(v any) func() (any, error) {
+		return func() (any, error) { return v, nil }
+	}
+
+	group.Do("a", value(1))
+	group.Forget("a")
+	v, _, _ := group.Do("a", value(2))
+	g.Expect(v).To(Equal(2))
+
+	group.Do("b", value(3))
+	clock.Advance(time.Second)
+	group.Purge()
+	g.Expect(group.results).To(BeEmpty())
+}
@@ -0,0 +1,131 @@
+// Package singleflight suppresses duplicate work: while a call for a key is
+// in flight, later callers for the same key wait for it and share its result
+// instead of starting their own. It mirrors golang.org/x/sync/singleflight,
+// written out with one mutex and a map of per-key call records so the whole
+// mechanism fits on a screen.
+package singleflight
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrPanicked is returned to callers that were sharing a call whose function
+// panicked. The caller that ran the function gets the panic itself.
+var ErrPanicked = errors.New("singleflight: function panicked")
+
+// Result is what DoChan delivers
+type Result struct {
+	Val    any
+	Err    error
+	Shared bool // The result went to more than one caller
+}
+
+// call is one in-flight or just-finished execution of fn for a key
+type call struct {
+	wg    sync.WaitGroup // Done once val and err are set
+	val   any
+	err   error
+	dups  int             // Callers that joined after the first; guarded by Group.mu
+	chans []chan<- Result // DoChan callers to deliver to; guarded by Group.mu
+}
+
+// Group runs at most one call per key at a time. The zero value is ready to use.
+type Group struct {
+	mu sync.Mutex
+	m  map[string]*call // Lazily created
+}
+
+// Do runs fn for key and returns its result, unless a call for key is already
+// in flight, in which case it waits for that call and returns its result
+// instead. shared reports whether the result went to more than one caller.
+func (g *Group) Do(key string, fn func() (any, error)) (v any, err error, shared bool) {
+	g.mu.Lock()
+	if g.m == nil {
+		g.m = make(map[string]*call)
+	}
+	if c, ok := g.m[key]; ok {
+		c.dups++
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err, true
+	}
+	c := &call{}
+	c.wg.Add(1)
+	g.m[key] = c
+	g.mu.Unlock()
+
+	g.doCall(c, key, fn)
+	return c.val, c.err, c.dups > 0
+}
+
+// DoChan is like Do but returns a channel that receives the result when it is
+// ready, so the caller can select on it alongside a timeout or ctx. If fn runs
+// in the goroutine DoChan starts and panics, the program crashes.
+func (g *Group) DoChan(key string, fn func() (any, error)) <-chan Result {
+	ch := make(chan Result, 1)
+	g.mu.Lock()
+	if g.m == nil {
+		g.m = make(map[string]*call)
+	}
+	if c, ok := g.m[key]; ok {
+		c.dups++
+		c.chans = append(c.chans, ch)
+		g.mu.Unlock()
+		return ch
+	}
+	c := &call{chans: []chan<- Result{ch}}
+	c.wg.Add(1)
+	g.m[key] = c
+	g.mu.Unlock()
+
+	go g.doCall(c, key, fn)
+	return ch
+}
+
+// doCall runs fn, publishes the result to every waiter, and removes the call
+// so the next caller for key starts afresh
+func (g *Group) doCall(c *call, key string, fn func() (any, error)) {
+	var recovered any
+	func() {
+		defer func() {
+			if recovered = recover(); recovered != nil {
+				c.val, c.err = nil, ErrPanicked
+			}
+		}()
+		c.val, c.err = fn()
+	}()
+
+	g.mu.Lock()
+	c.wg.Done()
+	if g.m[key] == c {
+		delete(g.m, key)
+	}
+	for _, ch := range c.chans {
+		ch <- Result{Val: c.val, Err: c.err, Shared: c.dups > 0}
+	}
+	g.mu.Unlock()
+
+	if recovered != nil {
+		panic(recovered)
+	}
+}
+
+// Forget makes the next call for key run fn even if one is still in flight.
+// Callers already waiting keep waiting for the old call.
+func (g *Group) Forget(key string) {
+	g.mu.Lock()
+	delete(g.m, key)
+	g.mu.Unlock()
+}
+
+// Duplicates returns the number of callers waiting on the in-flight call for
+// key, not counting the one running it
+func (g *Group) Duplicates(key string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if c, ok := g.m[key]; ok {
+		return c.dups
+	}
+	return 0
+}
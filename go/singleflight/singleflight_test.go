@@ -0,0 +1,124 @@
+package singleflight
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestDoSuppressesDuplicates(t *testing.T) {
+	g := NewWithT(t)
+
+	var group Group
+	var calls atomic.Int64
+	release := make(chan struct{})
+	fn := func() (any, error) {
+		calls.Add(1)
+		<-release
+		return "value", nil
+	}
+
+	const callers = 50
+	var wg sync.WaitGroup
+	var shared, correct atomic.Int64
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err, s := group.Do("key", fn)
+			if err == nil && v == "value" {
+				correct.Add(1)
+			}
+			if s {
+				shared.Add(1)
+			}
+		}()
+	}
+	g.Eventually(func() int { return group.Duplicates("key") }).Should(Equal(callers - 1))
+	close(release)
+	wg.Wait()
+
+	g.Expect(calls.Load()).To(BeEquivalentTo(1))
+	g.Expect(correct.Load()).To(BeEquivalentTo(callers))
+	g.Expect(shared.Load()).To(BeEquivalentTo(callers))
+
+	// The finished call is gone, so the next Do runs fn again, unshared
+	_, _, s := group.Do("key", func() (any, error) { return nil, nil })
+	g.Expect(s).To(BeFalse())
+}
+
+func TestDoChan(t *testing.T) {
+	g := NewWithT(t)
+
+	var group Group
+	release := make(chan struct{})
+	errFetch := errors.New("fetch failed")
+	first := group.DoChan("key", func() (any, error) {
+		<-release
+		return nil, errFetch
+	})
+	second := group.DoChan("key", func() (any, error) { return "unused", nil })
+	g.Consistently(first, 20*time.Millisecond).ShouldNot(Receive())
+	close(release)
+
+	for _, ch := range []<-chan Result{first, second} {
+		var res Result
+		g.Eventually(ch).Should(Receive(&res))
+		g.Expect(res.Err).To(MatchError(errFetch))
+		g.Expect(res.Shared).To(BeTrue())
+	}
+}
+
+func TestForget(t *testing.T) {
+	g := NewWithT(t)
+
+	var group Group
+	release := make(chan struct{})
+	old := group.DoChan("key", func() (any, error) {
+		<-release
+		return 1, nil
+	})
+	g.Eventually(func() bool {
+		group.mu.Lock()
+		defer group.mu.Unlock()
+		return group.m["key"] != nil
+	}).Should(BeTrue())
+
+	// After Forget a new call runs instead of joining the old one
+	group.Forget("key")
+	v, _, s := group.Do("key", func() (any, error) { return 2, nil })
+	g.Expect(v).To(Equal(2))
+	g.Expect(s).To(BeFalse())
+
+	close(release)
+	g.Eventually(old).Should(Receive(Equal(Result{Val: 1})))
+}
+
+func TestDoPanic(t *testing.T) {
+	g := NewWithT(t)
+
+	var group Group
+	started := make(chan struct{})
+	waiter := make(chan error, 1)
+	go func() {
+		<-started
+		_, err, _ := group.Do("key", func() (any, error) { return nil, nil })
+		waiter <- err
+	}()
+
+	g.Expect(func() {
+		group.Do("key", func() (any, error) {
+			close(started)
+			g.Eventually(func() int { return group.Duplicates("key") }).Should(Equal(1))
+			panic("boom")
+		})
+	}).To(PanicWith("boom"))
+
+	// The waiter is released with an error rather than blocking forever
+	g.Eventually(waiter).Should(Receive(MatchError(ErrPanicked)))
+	g.Expect(group.Duplicates("key")).To(BeZero())
+}
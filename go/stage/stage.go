@@ -0,0 +1,107 @@
+// Package stage builds channel pipelines out of small composable steps. Each
+// Stage reads from an input channel, runs a function on a fixed number of
+// goroutines and writes results to a bounded output channel, with failures
+// reported on a separate error channel, so a multi-step pipeline reads as a
+// list of stages instead of hand-wired goroutines:
+//
+//	nums := stage.Source(ctx, 1, 2, 3)
+//	squares, errs1 := stage.Stage(ctx, nums, square, 4)
+//	lines, errs2 := stage.Stage(ctx, squares, format, 1)
+//	out, err := stage.Collect(ctx, lines, errs1, errs2)
+package stage
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/camilbenameur/learning/go/examples"
+)
+
+// Source returns a channel yielding items in order, closed after the last one
+// or when ctx is done. examples.Repeat and examples.RepeatFn make endless
+// sources to pair with examples.Take.
+func Source[T any](ctx context.Context, items ...T) <-chan T {
+	return examples.Generator(ctx, items...)
+}
+
+// Stage applies fn to every item from in on concurrency goroutines. Results go
+// to the returned output channel and errors to the error channel; an item whose
+// fn fails produces no output. Both channels are buffered to concurrency and
+// close once in is drained or ctx is done, so downstream stages see the end of
+// input. With concurrency above 1, output order is not input order.
+//
+// The caller must keep reading both channels (Collect does) or the stage stalls.
+func Stage[I, O any](ctx context.Context, in <-chan I, fn func(context.Context, I) (O, error), concurrency int) (<-chan O, <-chan error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	out := make(chan O, concurrency)
+	errs := make(chan error, concurrency)
+
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for {
+				var item I
+				select {
+				case v, ok := <-in:
+					if !ok {
+						return
+					}
+					item = v
+				case <-ctx.Done():
+					return
+				}
+
+				result, err := fn(ctx, item)
+				if err != nil {
+					select {
+					case errs <- err:
+					case <-ctx.Done():
+						return
+					}
+					continue
+				}
+				select {
+				case out <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+		close(errs)
+	}()
+	return out, errs
+}
+
+// Collect drains the final stage's output and every stage's error channel
+// concurrently, returning all results and all errors joined. If ctx is done
+// first, ctx.Err() is included in the error.
+func Collect[T any](ctx context.Context, in <-chan T, errs ...<-chan error) ([]T, error) {
+	var all []error
+	errsDone := make(chan struct{})
+	go func() {
+		defer close(errsDone)
+		// Background: error channels close on their own when ctx is cancelled
+		for err := range examples.Merge(context.Background(), errs...) {
+			all = append(all, err)
+		}
+	}()
+
+	var results []T
+	for v := range in {
+		results = append(results, v)
+	}
+	<-errsDone
+	if err := ctx.Err(); err != nil {
+		all = append(all, err)
+	}
+	return results, errors.Join(all...)
+}
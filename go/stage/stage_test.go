@@ -0,0 +1,84 @@
+package stage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func square(_ context.Context, n int) (int, error) {
+	return n * n, nil
+}
+
+func TestPipeline(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	nums := Source(ctx, 1, 2, 3, 4, 5, 6)
+	squares, errs1 := Stage(ctx, nums, square, 3)
+	labels, errs2 := Stage(ctx, squares, func(_ context.Context, n int) (string, error) {
+		if n%2 == 1 {
+			return "", fmt.Errorf("odd square %d", n)
+		}
+		return strconv.Itoa(n), nil
+	}, 1)
+
+	out, err := Collect(ctx, labels, errs1, errs2)
+	sort.Strings(out)
+	g.Expect(out).To(Equal([]string{"16", "36", "4"}))
+	g.Expect(err).To(MatchError(ContainSubstring("odd square 1")))
+	g.Expect(err).To(MatchError(ContainSubstring("odd square 9")))
+	g.Expect(err).To(MatchError(ContainSubstring("odd square 25")))
+}
+
+func TestStageConcurrency(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	var running, peak int64
+	slow := func(_ context.Context, n int) (int, error) {
+		now := atomic.AddInt64(&running, 1)
+		defer atomic.AddInt64(&running, -1)
+		for {
+			p := atomic.LoadInt64(&peak)
+			if now <= p || atomic.CompareAndSwapInt64(&peak, p, now) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		return n, nil
+	}
+
+	items := make([]int, 20)
+	out, errs := Stage(ctx, Source(ctx, items...), slow, 4)
+	results, err := Collect(ctx, out, errs)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(results).To(HaveLen(20))
+	g.Expect(atomic.LoadInt64(&peak)).To(BeNumerically("<=", 4))
+	g.Expect(atomic.LoadInt64(&peak)).To(BeNumerically(">", 1))
+}
+
+func TestStageCancellation(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int) // Never closed
+	block := func(ctx context.Context, n int) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}
+	out, errs := Stage(ctx, in, block, 2)
+	in <- 1
+	cancel()
+
+	results, err := Collect(ctx, out, errs)
+	g.Expect(results).To(BeEmpty())
+	g.Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+}
@@ -0,0 +1,87 @@
+package syncpool
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// MaxPooledBuffer is the largest buffer capacity BufferedEncoder keeps. One
+// huge response would otherwise pin its buffer in the pool, and every later
+// Get would hold that memory for a small payload.
+const MaxPooledBuffer = 64 << 10
+
+// Event is the payload the encoders write: a typical API response row, a few
+// hundred bytes of JSON
+type Event struct {
+	ID       int64             `json:"id"`
+	Kind     string            `json:"kind"`
+	Source   string            `json:"source"`
+	Time     time.Time         `json:"time"`
+	Tags     []string          `json:"tags"`
+	Attrs    map[string]string `json:"attrs"`
+	Payload  string            `json:"payload"`
+	Attempts int               `json:"attempts"`
+}
+
+// EncodeFresh writes v to w as JSON through a new buffer and encoder, the way
+// a handler typically does it. The buffer is built in full before writing so
+// an encoding error sends nothing, and it grows by doubling, so every call
+// allocates several times and leaves all of it to the GC.
+func EncodeFresh(w io.Writer, v any) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// encodeBuffer pairs a buffer with an encoder writing into it, so both are
+// reused together
+type encodeBuffer struct {
+	buf bytes.Buffer
+	enc *json.Encoder
+}
+
+// BufferedEncoder writes JSON like EncodeFresh, but through buffers kept in a
+// TypedPool. Once the pool is warm a buffer already has the capacity of the
+// payloads it served, so an encode allocates next to nothing.
+type BufferedEncoder struct {
+	pool *TypedPool[*encodeBuffer]
+}
+
+// NewBufferedEncoder creates an encoder with an empty pool
+func NewBufferedEncoder() *BufferedEncoder {
+	return &BufferedEncoder{pool: NewTypedPool(TypedPoolOptions[*encodeBuffer]{
+		New: func() *encodeBuffer {
+			b := &encodeBuffer{}
+			b.enc = json.NewEncoder(&b.buf)
+			return b
+		},
+		Reset: func(b *encodeBuffer) bool {
+			if b.buf.Cap() > MaxPooledBuffer {
+				return false
+			}
+			b.buf.Reset()
+			return true
+		},
+	})}
+}
+
+// Encode writes v to w as JSON
+func (e *BufferedEncoder) Encode(w io.Writer, v any) error {
+	b := e.pool.Get()
+	defer e.pool.Put(b)
+	if err := b.enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := w.Write(b.buf.Bytes())
+	return err
+}
+
+// Stats returns the counters of the encoder's pool
+func (e *BufferedEncoder) Stats() TypedPoolStats {
+	return e.pool.Stats()
+}
@@ -0,0 +1,94 @@
+package syncpool
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func sampleEvent(id int64) Event {
+	return Event{
+		ID:     id,
+		Kind:   "order.created",
+		Source: "checkout",
+		Time:   time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Tags:   []string{"eu", "web", "priority"},
+		Attrs: map[string]string{
+			"customer": fmt.Sprintf("cust-%d", id),
+			"currency": "EUR",
+			"total":    "129.90",
+		},
+		Payload:  strings.Repeat("x", 256),
+		Attempts: 1,
+	}
+}
+
+func TestBufferedEncoderMatchesFresh(t *testing.T) {
+	g := NewWithT(t)
+
+	e := NewBufferedEncoder()
+	for i := int64(0); i < 3; i++ {
+		var fresh, pooled bytes.Buffer
+		g.Expect(EncodeFresh(&fresh, sampleEvent(i))).To(Succeed())
+		g.Expect(e.Encode(&pooled, sampleEvent(i))).To(Succeed())
+		g.Expect(pooled.String()).To(Equal(fresh.String()))
+	}
+
+	// An encoding error writes nothing and the buffer still goes back clean
+	var out bytes.Buffer
+	g.Expect(e.Encode(&out, func() {})).NotTo(Succeed())
+	g.Expect(out.Len()).To(BeZero())
+	g.Expect(e.Encode(&out, sampleEvent(9))).To(Succeed())
+	g.Expect(out.String()).To(HavePrefix(`{"id":9,`))
+}
+
+func TestBufferedEncoderDropsLargeBuffers(t *testing.T) {
+	g := NewWithT(t)
+
+	e := NewBufferedEncoder()
+	big := sampleEvent(1)
+	big.Payload = strings.Repeat("x", 2*MaxPooledBuffer)
+	g.Expect(e.Encode(io.Discard, big)).To(Succeed())
+	g.Expect(e.Stats().Dropped).To(Equal(int64(1)))
+}
+
+func TestBufferedEncoderAllocatesLess(t *testing.T) {
+	g := NewWithT(t)
+
+	ev := sampleEvent(1)
+	e := NewBufferedEncoder()
+	fresh := testing.AllocsPerRun(100, func() { EncodeFresh(io.Discard, ev) })
+	pooled := testing.AllocsPerRun(100, func() { e.Encode(io.Discard, ev) })
+	g.Expect(pooled).To(BeNumerically("<", fresh))
+}
+
+// BenchmarkEncode writes the same event with a fresh buffer per call and with
+// pooled buffers. Compare B/op and allocs/op: the pooled encoder allocates
+// only what encoding/json itself needs, where the fresh one also grows a
+// buffer and builds an encoder each time.
+func BenchmarkEncode(b *testing.B) {
+	ev := sampleEvent(1)
+	b.Run("Fresh", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				EncodeFresh(io.Discard, ev)
+			}
+		})
+	})
+	b.Run("Pooled", func(b *testing.B) {
+		e := NewBufferedEncoder()
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				e.Encode(io.Discard, ev)
+			}
+		})
+		b.ReportMetric(float64(e.Stats().Created)/float64(b.N), "buffers-created/op")
+	})
+}
@@ -0,0 +1,65 @@
+package syncpool
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func newBufferPool() *TypedPool[*bytes.Buffer] {
+	return NewTypedPool(TypedPoolOptions[*bytes.Buffer]{
+		New: func() *bytes.Buffer { return new(bytes.Buffer) },
+		Reset: func(b *bytes.Buffer) bool {
+			if b.Cap() > 1024 {
+				return false
+			}
+			b.Reset()
+			return true
+		},
+	})
+}
+
+func TestTypedPoolReuses(t *testing.T) {
+	g := NewWithT(t)
+
+	// The race detector makes sync.Pool drop a quarter of Puts at random,
+	// so reuse is asserted on the total rather than on each Get
+	p := newBufferPool()
+	for i := 0; i < 100; i++ {
+		b := p.Get()
+		g.Expect(b.Len()).To(BeZero(), "Reset ran before the buffer was reused")
+		b.WriteString("scratch")
+		p.Put(b)
+	}
+	g.Expect(p.Stats().Created).To(BeNumerically("<", 50))
+	g.Expect(p.Stats().Dropped).To(BeZero())
+}
+
+func TestTypedPoolResetDrops(t *testing.T) {
+	g := NewWithT(t)
+
+	p := newBufferPool()
+	b := p.Get()
+	b.Write(make([]byte, 4096))
+	p.Put(b)
+	g.Expect(p.Stats()).To(Equal(TypedPoolStats{Created: 1, Dropped: 1}))
+
+	// The oversized buffer was not kept, so the next Get makes a small one
+	g.Expect(p.Get().Cap()).To(BeNumerically("<=", 1024))
+}
+
+func TestTypedPoolClearedByGC(t *testing.T) {
+	g := NewWithT(t)
+
+	p := newBufferPool()
+	p.Put(p.Get())
+
+	// The first GC moves pooled objects to the victim cache and the second
+	// drops them, so the Get after that has to create a new one
+	runtime.GC()
+	runtime.GC()
+	p.Get()
+	g.Expect(p.Stats().Created).To(Equal(int64(2)))
+}